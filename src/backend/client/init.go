@@ -1,6 +1,7 @@
 package client
 
 import (
+	"local/infra/provider/signing"
 	"local/model"
 )
 
@@ -8,8 +9,9 @@ type Client struct {
 	SocketClient SocketClient
 }
 
-func NewClient(params *model.InitParams) *Client {
+// NewClient builds the clients of other services; keys sign the tokens they present
+func NewClient(params *model.InitParams, keys *signing.KeySet) *Client {
 	return &Client{
-		SocketClient: NewSocketClient(),
+		SocketClient: NewSocketClient(keys),
 	}
 }
@@ -3,57 +3,120 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"local/config"
+	"local/infra/provider/signing"
 	"local/model"
 	"log"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
+
+// SocketAudience is the aud of the tokens the backend presents to the socket service.
+// User access tokens carry no audience, so they cannot call it
+const SocketAudience = "simple-chat-socket"
+
+// serviceTokenTTL bounds a service token; one is reused until it is close to expiring
+const serviceTokenTTL = 5 * time.Minute
+
 type SocketClient interface {
 	Broadcast(message *model.BroadcastMessage)
+	// Deliver broadcasts and reports whether the socket service accepted the message,
+	// for callers that retry
+	Deliver(message *model.BroadcastMessage) error
+	Disconnect(message *model.DisconnectMessage)
 }
 
 type socketClient struct {
-	token string
+	keys *signing.KeySet
+
+	lock      sync.Mutex
+	token     string
+	expiresAt time.Time
 }
 
 func (c *socketClient) Broadcast(message *model.BroadcastMessage) {
+	if err := c.post("/broadcast", message); err != nil {
+		log.Print(err)
+	}
+}
+
+func (c *socketClient) Deliver(message *model.BroadcastMessage) error {
+	return c.post("/broadcast", message)
+}
+
+// Disconnect closes the live sockets of revoked sessions
+func (c *socketClient) Disconnect(message *model.DisconnectMessage) {
+	if err := c.post("/disconnect", message); err != nil {
+		log.Print(err)
+	}
+}
+
+func (c *socketClient) post(path string, body any) error {
 	// Create HTTP request to socket server
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
 
 	// Convert message to JSON
-	jsonData, err := json.Marshal(message)
+	jsonData, err := json.Marshal(body)
 	if err != nil {
-		log.Printf("Error marshaling broadcast message: %v", err)
-		return
+		return fmt.Errorf("error marshaling %s message: %w", path, err)
 	}
 
 	// Create request
-	req, err := http.NewRequest("POST", config.Config.SocketServerURL+"/broadcast", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest("POST", config.Config.SocketServerURL+path, bytes.NewBuffer(jsonData))
 	if err != nil {
-		log.Printf("Error creating request: %v", err)
-		return
+		return fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	token, err := c.serviceToken()
+	if err != nil {
+		return fmt.Errorf("error signing %s request: %w", path, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	// Send request
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Error sending broadcast request: %v", err)
-		return
+		return fmt.Errorf("error sending %s request: %w", path, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("Broadcast request failed with status: %d", resp.StatusCode)
+		return fmt.Errorf("%s request failed with status: %d", path, resp.StatusCode)
+	}
+	return nil
+}
+
+// serviceToken signs a short-lived token the socket service verifies against our JWKS
+func (c *socketClient) serviceToken() (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	if c.token != "" && now.Add(serviceTokenTTL/5).Before(c.expiresAt) {
+		return c.token, nil
+	}
+	expiresAt := now.Add(serviceTokenTTL)
+	token, err := c.keys.Sign(jwt.RegisteredClaims{
+		Subject:   "backend",
+		Audience:  jwt.ClaimStrings{SocketAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	if err != nil {
+		return "", err
 	}
+	c.token, c.expiresAt = token, expiresAt
+	return token, nil
 }
 
-func NewSocketClient() SocketClient {
-	return &socketClient{token: config.Config.SocketToken}
+func NewSocketClient(keys *signing.KeySet) SocketClient {
+	return &socketClient{keys: keys}
 }
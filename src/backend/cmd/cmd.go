@@ -6,6 +6,15 @@ import (
 	"local/client"
 	"local/config"
 	"local/endpoint"
+	"local/infra/provider/blob"
+	"local/infra/provider/events"
+	"local/infra/provider/kafka"
+	"local/infra/provider/lockout"
+	"local/infra/provider/oidc"
+	"local/infra/provider/password"
+	"local/infra/provider/revocation"
+	"local/infra/provider/search"
+	"local/infra/provider/signing"
 	"local/infra/repo"
 	"local/model"
 	"local/service/common"
@@ -43,16 +52,71 @@ func Run() {
 		log.Fatalf("Failed to initialize repository: %v", err)
 	}
 
+	blobStore, err := blob.NewBlobStore()
+	if err != nil {
+		log.Fatalf("Failed to initialize blob store: %v", err)
+	}
+
+	revocations, err := revocation.NewStore(repository.Revocation())
+	if err != nil {
+		log.Fatalf("Failed to initialize revocation store: %v", err)
+	}
+
+	lockoutStore, err := lockout.NewStore(repository.LoginAttempt())
+	if err != nil {
+		log.Fatalf("Failed to initialize login lockout store: %v", err)
+	}
+
+	passwordPolicy, err := password.LoadPolicy()
+	if err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+
+	identityProviders, err := oidc.NewRegistryFromConfig()
+	if err != nil {
+		log.Fatalf("Failed to configure identity providers: %v", err)
+	}
+
+	searchIndex, err := search.NewSearchIndex(repository.Message())
+	if err != nil {
+		log.Fatalf("Failed to initialize search index: %v", err)
+	}
+
+	if config.Config.EventPublisher == "kafka" {
+		if err := kafka.InitProducers(); err != nil {
+			log.Fatalf("Failed to initialize Kafka producers: %v", err)
+		}
+		defer kafka.CloseProducers()
+	}
+	publisher, err := events.NewEventPublisher()
+	if err != nil {
+		log.Fatalf("Failed to initialize event publisher: %v", err)
+	}
+
+	keys, err := signing.LoadKeySet()
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+
 	initParams := &model.InitParams{
 		ServiceName: ServiceName,
 		Ctx:    ctx,
 	}
-	clt := client.NewClient(initParams)
+	clt := client.NewClient(initParams, keys)
 
 	// Run the service
 	svc := initial.NewService(&common.Params{
 		Repo:   repository,
 		Client: clt,
+		Blob:   blobStore,
+
+		Revocations:    revocations,
+		Keys:           keys,
+		PasswordPolicy: passwordPolicy,
+		Lockout:        lockout.NewGuardFromConfig(lockoutStore),
+		OIDC:           identityProviders,
+		Search:         searchIndex,
+		Events:         publisher,
 	})
 
 	endpoints := endpoint.NewEndpoints(&svc)
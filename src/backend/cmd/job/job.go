@@ -2,10 +2,19 @@ package job
 
 import (
 	"fmt"
+	"local/client"
 	"local/config"
+	"local/infra/provider/events"
+	"local/infra/provider/kafka"
+	"local/infra/provider/search"
+	"local/infra/provider/signing"
+	"local/infra/repo"
 	"local/job/consumer"
 	"local/job/scheduler"
 	"local/job/worker"
+	"local/model"
+	"local/service/common"
+	"local/service/outbox"
 	"local/util/logger"
 
 	"github.com/spf13/cobra"
@@ -25,7 +34,7 @@ var (
 )
 
 func init() {
-	JobCmd.Flags().StringVarP(&jobType, "type", "t", "", "Type of job to run (cleanup, sync, temporal, kafka-message, kafka-notification)")
+	JobCmd.Flags().StringVarP(&jobType, "type", "t", "", "Type of job to run (cleanup, sync, temporal, kafka-message, kafka-notification, outbox-relay)")
 }
 
 func RunJob() {
@@ -60,9 +69,11 @@ func RunJob() {
 		runKafkaMessageConsumer()
 	case "kafka-notification":
 		runKafkaNotificationConsumer()
+	case "outbox-relay":
+		runOutboxRelay()
 	default:
 		fmt.Printf("Unknown job type: %s\n", jobType)
-		fmt.Println("Available job types: cleanup, sync, temporal, kafka-message, kafka-notification")
+		fmt.Println("Available job types: cleanup, sync, temporal, kafka-message, kafka-notification, outbox-relay")
 	}
 }
 
@@ -101,7 +112,20 @@ func runTemporalWorker() {
 func runKafkaMessageConsumer() {
 	logger.Info(nil, "Starting Kafka message consumer", nil)
 
-	if err := consumer.StartMessageConsumer(); err != nil {
+	repository, err := repo.NewRepository()
+	if err != nil {
+		logger.Error(nil, "Failed to initialize repository", err)
+		fmt.Printf("Failed to initialize repository: %v\n", err)
+		return
+	}
+	index, err := search.NewSearchIndex(repository.Message())
+	if err != nil {
+		logger.Error(nil, "Failed to initialize search index", err)
+		fmt.Printf("Failed to initialize search index: %v\n", err)
+		return
+	}
+
+	if err := consumer.StartMessageConsumer(index); err != nil {
 		logger.Error(nil, "Kafka message consumer error", err)
 		fmt.Printf("Kafka message consumer error: %v\n", err)
 		return
@@ -122,3 +146,41 @@ func runKafkaNotificationConsumer() {
 	logger.Info(nil, "Kafka notification consumer stopped", nil)
 }
 
+
+func runOutboxRelay() {
+	logger.Info(nil, "Starting outbox relay", map[string]interface{}{"interval": config.Config.OutboxRelayInterval.String()})
+
+	repository, err := repo.NewRepository()
+	if err != nil {
+		logger.Error(nil, "Failed to initialize repository", err)
+		fmt.Printf("Failed to initialize repository: %v\n", err)
+		return
+	}
+	if config.Config.EventPublisher == "kafka" {
+		if err := kafka.InitProducers(); err != nil {
+			logger.Error(nil, "Failed to initialize Kafka producers", err)
+			fmt.Printf("Failed to initialize Kafka producers: %v\n", err)
+			return
+		}
+		defer kafka.CloseProducers()
+	}
+	publisher, err := events.NewEventPublisher()
+	if err != nil {
+		logger.Error(nil, "Failed to initialize event publisher", err)
+		fmt.Printf("Failed to initialize event publisher: %v\n", err)
+		return
+	}
+	keys, err := signing.LoadKeySet()
+	if err != nil {
+		logger.Error(nil, "Failed to load signing keys", err)
+		fmt.Printf("Failed to load signing keys: %v\n", err)
+		return
+	}
+
+	svc := outbox.NewOutboxService(&common.Params{
+		Repo:   repository,
+		Client: client.NewClient(&model.InitParams{ServiceName: "job-service"}, keys),
+		Events: publisher,
+	})
+	scheduler.RunOutboxRelay(svc, config.Config.OutboxRelayInterval)
+}
@@ -4,8 +4,36 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// Token lifetimes used when the environment does not override them
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// DefaultMFAChallengeTTL is how long a login may wait for its second factor
+	DefaultMFAChallengeTTL = 5 * time.Minute
+	// DefaultOIDCStateTTL is how long a single sign-on login may spend at the identity provider
+	DefaultOIDCStateTTL = 10 * time.Minute
+	// DefaultOutboxRelayInterval is how often the outbox relay looks for deliveries to retry
+	DefaultOutboxRelayInterval = 2 * time.Second
+	// DefaultOutboxMaxAttempts is how many times a delivery is tried before it is given up
+	DefaultOutboxMaxAttempts = 10
+)
+
+// OIDCProviderConfig is one OpenID Connect identity provider users may sign in with
+type OIDCProviderConfig struct {
+	// Name identifies the provider in URLs and in linked identities; it must not change
+	Name         string
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the frontend page the provider sends the user back to
+	RedirectURL string
+	Scopes      []string
+}
+
 type ServiceConfig struct {
 	HTTPPort int
 
@@ -18,8 +46,18 @@ type ServiceConfig struct {
 	DBName     string
 
 	SocketServerURL string
-	SocketToken     string
-	JwtSecret       string
+	// InternalServiceKey is the shared key the socket service presents on internal
+	// endpoints; internal endpoints refuse every call while it is empty
+	InternalServiceKey string
+
+	// JWT signing keys: every *.pem in JwtKeysDir verifies, JwtActiveKeyID signs
+	JwtKeysDir      string
+	JwtActiveKeyID  string
+	JwtKeyAlgorithm string
+
+	// AccessTokenTTL bounds a JWT; RefreshTokenTTL is how long a session may go without refreshing
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
 
 	// Temporal
 	TemporalAddress string
@@ -29,11 +67,60 @@ type ServiceConfig struct {
 	KafkaConsumerGroup  string
 	KafkaMessageTopic   string
 	KafkaNotificationTopic string
+	// EventPublisher is "kafka" to publish message events to KafkaMessageTopic, or "none"
+	EventPublisher string
 
 	// Rate Limiting
 	RateLimitEnabled        bool
 	RateLimitRequestsPerMin int
 	RateLimitBurst          int
+	// TrustedProxies are the addresses (IPs or CIDRs) whose X-Forwarded-For and
+	// X-Real-IP headers are believed; with none the client is the TCP peer
+	TrustedProxies          []string
+
+	// Attachments
+	BlobStore              string
+	BlobLocalDir           string
+	S3Endpoint             string
+	S3Bucket               string
+	S3Region               string
+	S3AccessKey            string
+	S3SecretKey            string
+	AttachmentMaxBytes     int64
+	AttachmentAllowedTypes []string
+
+	// Token revocation
+	RevocationStore    string
+	RevocationCacheTTL time.Duration
+
+	// Password policy
+	PasswordMinLength        int
+	PasswordBreachedListFile string
+
+	// Login lockout: after MaxFailures consecutive failures a key is locked for
+	// LockoutBase, doubling with every further failure up to LockoutMax
+	LoginLockoutStore       string
+	LoginAccountMaxFailures int
+	LoginIPMaxFailures      int
+	LoginLockoutBase        time.Duration
+	LoginLockoutMax         time.Duration
+
+	// Two-factor authentication: MFAIssuer names the account in authenticator apps
+	MFAIssuer       string
+	MFAChallengeTTL time.Duration
+
+	// Single sign-on: OIDCProviders come from OIDC_PROVIDERS and the OIDC_<NAME>_* variables
+	OIDCProviders []OIDCProviderConfig
+	OIDCStateTTL  time.Duration
+
+	// Message search: SearchIndex is "mysql" or "embedded", the latter kept in a log file at SearchIndexPath
+	SearchIndex     string
+	SearchIndexPath string
+
+	// Outbox: the relay retries failed deliveries every OutboxRelayInterval and
+	// gives one up after OutboxMaxAttempts
+	OutboxRelayInterval time.Duration
+	OutboxMaxAttempts   int
 }
 
 var Config = ServiceConfig{}
@@ -58,6 +145,26 @@ func splitAndTrim(s string, sep string) []string {
 	return result
 }
 
+// loadOIDCProviders reads OIDC_PROVIDERS, a comma separated list of names, and
+// for each name the OIDC_<NAME>_* variables, with dashes in the name read as underscores
+func loadOIDCProviders() []OIDCProviderConfig {
+	names := splitAndTrim(getEnv("OIDC_PROVIDERS", ""), ",")
+	providers := make([]OIDCProviderConfig, 0, len(names))
+	for _, name := range names {
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  getEnv(prefix+"DISPLAY_NAME", name),
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       splitAndTrim(getEnv(prefix+"SCOPES", "openid,profile,email"), ","),
+		})
+	}
+	return providers
+}
+
 func LoadConfig() {
 	httpPortStr := getEnv("HTTP_PORT", "80")
 	httpPortInt, err := strconv.Atoi(httpPortStr)
@@ -71,10 +178,25 @@ func LoadConfig() {
 	dbUser := getEnv("DB_USER", "root")
 	dbPassword := getEnv("DB_PASSWORD", "your_root_password")
 	dbName := getEnv("DB_NAME", "simple_chat")
-	jwtSecret := getEnv("JWT_SECRET", "your_jwt_secret")
+	jwtKeysDir := getEnv("JWT_KEYS_DIR", "./data/keys")
+	jwtActiveKeyID := getEnv("JWT_ACTIVE_KEY_ID", "")
+	jwtKeyAlgorithm := getEnv("JWT_KEY_ALGORITHM", "EdDSA")
+
+	accessTokenTTL := DefaultAccessTokenTTL
+	if minutes := getEnv("ACCESS_TOKEN_TTL_MINUTES", ""); minutes != "" {
+		if val, err := strconv.Atoi(minutes); err == nil && val > 0 {
+			accessTokenTTL = time.Duration(val) * time.Minute
+		}
+	}
+	refreshTokenTTL := DefaultRefreshTokenTTL
+	if hours := getEnv("REFRESH_TOKEN_TTL_HOURS", ""); hours != "" {
+		if val, err := strconv.Atoi(hours); err == nil && val > 0 {
+			refreshTokenTTL = time.Duration(val) * time.Hour
+		}
+	}
 
 	socketServerURL := getEnv("SOCKET_SERVER_URL", "http://localhost:8080")
-	socketToken := getEnv("SOCKET_TOKEN", "your_socket_token")
+	internalServiceKey := getEnv("INTERNAL_SERVICE_KEY", "")
 
 	// Temporal configuration
 	temporalAddress := getEnv("TEMPORAL_ADDRESS", "localhost:7233")
@@ -89,6 +211,7 @@ func LoadConfig() {
 	kafkaConsumerGroup := getEnv("KAFKA_CONSUMER_GROUP", "simple-chat-consumer-group")
 	kafkaMessageTopic := getEnv("KAFKA_MESSAGE_TOPIC", "chat-messages")
 	kafkaNotificationTopic := getEnv("KAFKA_NOTIFICATION_TOPIC", "chat-notifications")
+	eventPublisher := getEnv("EVENT_PUBLISHER", "none")
 
 	// Rate limiting configuration
 	rateLimitEnabled := getEnv("RATE_LIMIT_ENABLED", "true") == "true"
@@ -104,6 +227,103 @@ func LoadConfig() {
 			rateLimitBurst = val
 		}
 	}
+	trustedProxies := splitAndTrim(getEnv("TRUSTED_PROXIES", ""), ",")
+
+	// Attachment configuration
+	blobStore := getEnv("BLOB_STORE", "local")
+	blobLocalDir := getEnv("BLOB_LOCAL_DIR", "./data/attachments")
+	s3Endpoint := getEnv("S3_ENDPOINT", "")
+	s3Bucket := getEnv("S3_BUCKET", "")
+	s3Region := getEnv("S3_REGION", "us-east-1")
+	s3AccessKey := getEnv("S3_ACCESS_KEY", "")
+	s3SecretKey := getEnv("S3_SECRET_KEY", "")
+	attachmentMaxBytes := int64(10 << 20) // default 10 MiB
+	if maxBytes := getEnv("ATTACHMENT_MAX_BYTES", ""); maxBytes != "" {
+		if val, err := strconv.ParseInt(maxBytes, 10, 64); err == nil && val > 0 {
+			attachmentMaxBytes = val
+		}
+	}
+	attachmentAllowedTypes := splitAndTrim(getEnv("ATTACHMENT_ALLOWED_TYPES", "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain"), ",")
+
+	// Token revocation configuration
+	revocationStore := getEnv("REVOCATION_STORE", "mysql")
+	revocationCacheTTL := 30 * time.Second // default
+	if ttl := getEnv("REVOCATION_CACHE_TTL_SECONDS", ""); ttl != "" {
+		if val, err := strconv.Atoi(ttl); err == nil && val >= 0 {
+			revocationCacheTTL = time.Duration(val) * time.Second
+		}
+	}
+
+	// Password policy configuration
+	passwordMinLength := 8 // default
+	if minLength := getEnv("PASSWORD_MIN_LENGTH", ""); minLength != "" {
+		if val, err := strconv.Atoi(minLength); err == nil && val > 0 {
+			passwordMinLength = val
+		}
+	}
+	passwordBreachedListFile := getEnv("PASSWORD_BREACHED_LIST_FILE", "./config/breached-passwords.txt")
+
+	// Login lockout configuration
+	loginLockoutStore := getEnv("LOGIN_LOCKOUT_STORE", "mysql")
+	loginAccountMaxFailures := 5 // default
+	if failures := getEnv("LOGIN_ACCOUNT_MAX_FAILURES", ""); failures != "" {
+		if val, err := strconv.Atoi(failures); err == nil && val >= 0 {
+			loginAccountMaxFailures = val
+		}
+	}
+	loginIPMaxFailures := 20 // default
+	if failures := getEnv("LOGIN_IP_MAX_FAILURES", ""); failures != "" {
+		if val, err := strconv.Atoi(failures); err == nil && val >= 0 {
+			loginIPMaxFailures = val
+		}
+	}
+	loginLockoutBase := 30 * time.Second // default
+	if seconds := getEnv("LOGIN_LOCKOUT_BASE_SECONDS", ""); seconds != "" {
+		if val, err := strconv.Atoi(seconds); err == nil && val > 0 {
+			loginLockoutBase = time.Duration(val) * time.Second
+		}
+	}
+	loginLockoutMax := time.Hour // default
+	if seconds := getEnv("LOGIN_LOCKOUT_MAX_SECONDS", ""); seconds != "" {
+		if val, err := strconv.Atoi(seconds); err == nil && val > 0 {
+			loginLockoutMax = time.Duration(val) * time.Second
+		}
+	}
+
+	// Two-factor authentication configuration
+	mfaIssuer := getEnv("MFA_ISSUER", "Simple Chat")
+	mfaChallengeTTL := DefaultMFAChallengeTTL
+	if seconds := getEnv("MFA_CHALLENGE_TTL_SECONDS", ""); seconds != "" {
+		if val, err := strconv.Atoi(seconds); err == nil && val > 0 {
+			mfaChallengeTTL = time.Duration(val) * time.Second
+		}
+	}
+
+	// Single sign-on configuration
+	oidcStateTTL := DefaultOIDCStateTTL
+	if seconds := getEnv("OIDC_STATE_TTL_SECONDS", ""); seconds != "" {
+		if val, err := strconv.Atoi(seconds); err == nil && val > 0 {
+			oidcStateTTL = time.Duration(val) * time.Second
+		}
+	}
+
+	// Message search configuration
+	searchIndex := getEnv("SEARCH_INDEX", "mysql")
+	searchIndexPath := getEnv("SEARCH_INDEX_PATH", "./data/search/messages.log")
+
+	// Outbox configuration
+	outboxRelayInterval := DefaultOutboxRelayInterval
+	if seconds := getEnv("OUTBOX_RELAY_INTERVAL_SECONDS", ""); seconds != "" {
+		if val, err := strconv.Atoi(seconds); err == nil && val > 0 {
+			outboxRelayInterval = time.Duration(val) * time.Second
+		}
+	}
+	outboxMaxAttempts := DefaultOutboxMaxAttempts
+	if attempts := getEnv("OUTBOX_MAX_ATTEMPTS", ""); attempts != "" {
+		if val, err := strconv.Atoi(attempts); err == nil && val > 0 {
+			outboxMaxAttempts = val
+		}
+	}
 
 	Config = ServiceConfig{
 		HTTPPort: httpPortInt,
@@ -114,15 +334,47 @@ func LoadConfig() {
 		DBPassword: dbPassword,
 		DBName:     dbName,
 		SocketServerURL: socketServerURL,
-		SocketToken:     socketToken,
-		JwtSecret:       jwtSecret,
+		InternalServiceKey: internalServiceKey,
+		JwtKeysDir:      jwtKeysDir,
+		JwtActiveKeyID:  jwtActiveKeyID,
+		JwtKeyAlgorithm: jwtKeyAlgorithm,
+		AccessTokenTTL:  accessTokenTTL,
+		RefreshTokenTTL: refreshTokenTTL,
 		TemporalAddress: temporalAddress,
 		KafkaBrokers:        kafkaBrokers,
 		KafkaConsumerGroup:  kafkaConsumerGroup,
 		KafkaMessageTopic:   kafkaMessageTopic,
 		KafkaNotificationTopic: kafkaNotificationTopic,
+		EventPublisher:         eventPublisher,
 		RateLimitEnabled:        rateLimitEnabled,
 		RateLimitRequestsPerMin: rateLimitRequestsPerMin,
 		RateLimitBurst:          rateLimitBurst,
+		TrustedProxies:          trustedProxies,
+		BlobStore:              blobStore,
+		BlobLocalDir:           blobLocalDir,
+		S3Endpoint:             s3Endpoint,
+		S3Bucket:               s3Bucket,
+		S3Region:               s3Region,
+		S3AccessKey:            s3AccessKey,
+		S3SecretKey:            s3SecretKey,
+		AttachmentMaxBytes:     attachmentMaxBytes,
+		AttachmentAllowedTypes: attachmentAllowedTypes,
+		RevocationStore:    revocationStore,
+		RevocationCacheTTL: revocationCacheTTL,
+		PasswordMinLength:        passwordMinLength,
+		PasswordBreachedListFile: passwordBreachedListFile,
+		LoginLockoutStore:       loginLockoutStore,
+		LoginAccountMaxFailures: loginAccountMaxFailures,
+		LoginIPMaxFailures:      loginIPMaxFailures,
+		LoginLockoutBase:        loginLockoutBase,
+		LoginLockoutMax:         loginLockoutMax,
+		MFAIssuer:       mfaIssuer,
+		MFAChallengeTTL: mfaChallengeTTL,
+		OIDCProviders: loadOIDCProviders(),
+		OIDCStateTTL:  oidcStateTTL,
+		SearchIndex:     searchIndex,
+		SearchIndexPath: searchIndexPath,
+		OutboxRelayInterval: outboxRelayInterval,
+		OutboxMaxAttempts:   outboxMaxAttempts,
 	}
 }
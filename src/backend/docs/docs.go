@@ -22,6 +22,64 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/attachments/{attachmentID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams an attachment to a member of the conversation it was shared in",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Download an attachment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Attachment not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
         "/conversations": {
             "get": {
                 "security": [
@@ -29,7 +87,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a list of all conversations where the authenticated user is a participant",
+                "description": "Returns a page of conversations where the authenticated user is a participant, most recently active first",
                 "produces": [
                     "application/json"
                 ],
@@ -37,11 +95,31 @@ const docTemplate = `{
                     "conversations"
                 ],
                 "summary": "Get all conversations for the authenticated user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Return conversations older than this conversation ID",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return conversations newer than this conversation ID",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response-array_model_Conversation"
+                            "$ref": "#/definitions/model.Response-model_Page-model_Conversation"
                         }
                     },
                     "400": {
@@ -70,7 +148,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Creates a new private conversation between the authenticated user and another user",
+                "description": "Creates a private conversation between the authenticated user and another user, or returns the one they already have\nCreates a new private conversation between the authenticated user and another user",
                 "consumes": [
                     "application/json"
                 ],
@@ -80,7 +158,6 @@ const docTemplate = `{
                 "tags": [
                     "conversations"
                 ],
-                "summary": "Create a new private conversation",
                 "parameters": [
                     {
                         "description": "Conversation creation data",
@@ -88,7 +165,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/httpTransoprt.CreateConversationRequest"
+                            "$ref": "#/definitions/httpTransport.CreateConversationRequest"
                         }
                     }
                 ],
@@ -120,28 +197,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/conversations/user/{userID}": {
-            "get": {
+        "/conversations/group": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves the private conversation between the authenticated user and the specified user",
+                "description": "Creates a named group conversation owned by the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "conversations"
                 ],
-                "summary": "Get conversation between current user and another user",
+                "summary": "Create a new group conversation",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Target User ID",
-                        "name": "userID",
-                        "in": "path",
-                        "required": true
+                        "description": "Group conversation data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.CreateGroupConversationRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -163,8 +245,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response-any"
                         }
                     },
-                    "404": {
-                        "description": "Not Found - Conversation not found",
+                    "422": {
+                        "description": "Validation Error - Unknown user IDs",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
@@ -178,26 +260,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/conversations/{conversationID}/messages": {
+        "/conversations/user/{userID}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves all messages from the specified conversation, ordered by creation time (newest first)",
+                "description": "Retrieves the private conversation between the authenticated user and the specified user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "conversations"
                 ],
-                "summary": "Get all messages in a conversation",
+                "summary": "Get conversation between current user and another user",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Conversation ID",
-                        "name": "conversationID",
+                        "description": "Target User ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     }
@@ -206,7 +288,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response-array_model_Message"
+                            "$ref": "#/definitions/model.Response-model_Conversation"
                         }
                     },
                     "400": {
@@ -221,6 +303,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response-any"
                         }
                     },
+                    "404": {
+                        "description": "Not Found - Conversation not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -228,24 +316,26 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/conversations/{conversationID}/attachments": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Creates a new message in the specified conversation",
+                "description": "Stores a file for the conversation and returns the attachment to reference from an attachment message. The type is detected from the content",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "attachments"
                 ],
-                "summary": "Create a new message in a conversation",
+                "summary": "Upload a file to a conversation",
                 "parameters": [
                     {
                         "type": "integer",
@@ -255,36 +345,34 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Message data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/endpoint.CreateMessageRequest"
-                        }
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response-model_Message"
+                            "$ref": "#/definitions/model.Response-model_Attachment"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request - Invalid input",
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized - Invalid or missing token",
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
                     },
-                    "404": {
-                        "description": "Not Found - Conversation not found",
+                    "422": {
+                        "description": "Validation Error - Missing file, too large or type not allowed",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
@@ -298,35 +386,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Validates user credentials and returns a JWT token for authentication",
-                "consumes": [
-                    "application/json"
+        "/conversations/{conversationID}/messages": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Retrieves a page of messages from the specified conversation, ordered by creation time (newest first)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "messages"
                 ],
-                "summary": "Authenticate user and get JWT token",
+                "summary": "Get messages in a conversation",
                 "parameters": [
                     {
-                        "description": "User login credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/endpoint.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return messages older than this message ID",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return messages newer than this message ID",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response-endpoint_LoginResponse"
+                            "$ref": "#/definitions/model.Response-model_Page-model_Message"
                         }
                     },
                     "400": {
@@ -336,7 +442,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - Invalid credentials",
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
@@ -348,16 +460,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/logout": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Invalidates the current user's JWT token",
+                "description": "Creates a new message in the specified conversation",
                 "consumes": [
                     "application/json"
                 ],
@@ -365,14 +475,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "messages"
+                ],
+                "summary": "Create a new message in a conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.CreateMessageRequest"
+                        }
+                    }
                 ],
-                "summary": "Logout user and invalidate token",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response-string"
+                            "$ref": "#/definitions/model.Response-model_Message"
                         }
                     },
                     "400": {
@@ -387,6 +515,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response-any"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Conversation not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -396,33 +536,64 @@ const docTemplate = `{
                 }
             }
         },
-        "/me": {
-            "get": {
+        "/conversations/{conversationID}/messages/{messageID}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns the current user's information based on the JWT token",
+                "description": "Soft deletes a message so it is listed as a tombstone. Only the sender can delete",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "auth"
+                    "messages"
+                ],
+                "summary": "Delete a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get current authenticated user information",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response-model_User"
+                            "$ref": "#/definitions/model.Response-model_Message"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request - Invalid input",
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized - Invalid or missing token",
+                    "403": {
+                        "description": "Forbidden - Not a member or not the sender",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid input",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
@@ -434,11 +605,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/register": {
-            "post": {
-                "description": "Creates a new user account with username and password",
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the content of a message. Only the sender can edit; the previous content is kept in the edit history",
                 "consumes": [
                     "application/json"
                 ],
@@ -446,17 +620,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "messages"
                 ],
-                "summary": "Register a new user account",
+                "summary": "Edit a message",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New content",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/endpoint.RegisterRequest"
+                            "$ref": "#/definitions/endpoint.EditMessageRequest"
                         }
                     }
                 ],
@@ -464,17 +652,29 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response-model_User"
+                            "$ref": "#/definitions/model.Response-model_Message"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request - Invalid input",
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
                     },
-                    "409": {
-                        "description": "Conflict - User already exists",
+                    "403": {
+                        "description": "Forbidden - Not a member or not the sender",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid input",
                         "schema": {
                             "$ref": "#/definitions/model.Response-any"
                         }
@@ -488,26 +688,148 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/conversations/{conversationID}/mute": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops notifications for the conversation until the given time, or until it is unmuted when until is omitted. Messages are still delivered, flagged as muted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Mute a conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "End of the mute",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.MuteConversationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_ConversationParticipant"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid input or end in the past",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turns the conversation's notifications back on for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Unmute a conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_ConversationParticipant"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid conversation ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{conversationID}/participants": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns a list of all registered users in the system",
+                "description": "Returns every participant of the conversation together with their role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "conversations"
+                ],
+                "summary": "List the participants of a conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all registered users",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response-array_model_User"
+                            "$ref": "#/definitions/model.Response-array_model_ConversationParticipant"
                         }
                     },
                     "400": {
@@ -522,6 +844,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response-any"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -529,187 +857,3043 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "endpoint.CreateMessageRequest": {
-            "type": "object",
-            "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "conversation_id": {
-                    "type": "integer"
-                },
-                "sender_id": {
-                    "type": "integer"
-                },
-                "session_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "endpoint.LoginRequest": {
-            "type": "object",
-            "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
-                }
-            }
-        },
-        "endpoint.LoginResponse": {
-            "type": "object",
-            "properties": {
-                "token": {
-                    "type": "string"
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds users to a group conversation. Only owners and admins can add members",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Add members to a group conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Users to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.AddParticipantsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_Conversation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not allowed to manage members",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Unknown user IDs",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
                 }
             }
         },
-        "endpoint.RegisterRequest": {
-            "type": "object",
-            "properties": {
-                "password": {
-                    "type": "string"
+        "/conversations/{conversationID}/participants/me": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the authenticated user from a group conversation. Ownership passes to the longest-standing admin or member",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Leave a group conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{conversationID}/participants/{userID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a user from a group conversation. Only owners and admins can remove members",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Remove a member from a group conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID to remove",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not allowed to manage members",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Participant not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{conversationID}/participants/{userID}/promote": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Grants the admin role to a member of a group conversation. Only the owner can promote",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Promote a member to admin",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID to promote",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_ConversationParticipant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Only the owner can promote",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Participant not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/conversations/{conversationID}/read": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves the authenticated user's read marker to the given message, or to the latest message when message_id is omitted, and notifies the other participants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "conversations"
+                ],
+                "summary": "Mark a conversation as read",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message to mark as read",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.MarkAsReadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_ConversationParticipant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/presence/reset": {
+            "post": {
+                "security": [
+                    {
+                        "ServiceAuth": []
+                    }
+                ],
+                "description": "Internal: called by the socket service when it starts, since no socket survives a restart. Records last_seen_at for the users that were online",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "internal"
+                ],
+                "summary": "Mark every user offline",
+                "responses": {
+                    "200": {
+                        "description": "Number of users marked offline",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-int64"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing service key",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/users/{userID}/conversations/{conversationID}/participants": {
+            "get": {
+                "security": [
+                    {
+                        "ServiceAuth": []
+                    }
+                ],
+                "description": "Internal: called by the socket service to check that a user typing in a conversation is a member and to find who to tell",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "internal"
+                ],
+                "summary": "List the participants of a conversation on behalf of a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID of the member",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "conversationID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-array_model_ConversationParticipant"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing service key",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid user or conversation ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/users/{userID}/presence": {
+            "post": {
+                "security": [
+                    {
+                        "ServiceAuth": []
+                    }
+                ],
+                "description": "Internal: called by the socket service when a user's first socket connects or last socket disconnects. Records last_seen_at and notifies the user's contacts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "internal"
+                ],
+                "summary": "Update a user's presence",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Presence state",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.SetPresenceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_UserPresence"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing service key",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Validates user credentials and returns a short-lived JWT access token plus a refresh token. Users with two-factor authentication get mfa_required and an mfa_token to exchange at /login/mfa instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Authenticate user and get tokens",
+                "parameters": [
+                    {
+                        "description": "User login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-endpoint_LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - Too many failed attempts for the account or address",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa": {
+            "post": {
+                "description": "Exchanges the mfa_token from /login and a current TOTP code or an unused recovery code for the access and refresh tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete a login with a two-factor code",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.VerifyMFARequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-endpoint_LoginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid code or expired challenge token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Missing challenge token or code",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/oidc": {
+            "get": {
+                "description": "Lists the OpenID Connect identity providers users may sign in with; empty when single sign-on is not configured",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List single sign-on providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-array_endpoint_OIDCProviderResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/oidc/{provider}": {
+            "post": {
+                "description": "Starts the authorization code flow with PKCE. Send the user to authorization_url; the provider returns them to the configured redirect page with state and code, which go to the callback endpoint",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start a single sign-on login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-endpoint_OIDCAuthorizationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Unknown provider",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Provider unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/oidc/{provider}/callback": {
+            "post": {
+                "description": "Redeems the code the provider returned for the access and refresh tokens. A user is created on the first login with an identity. Users with two-factor authentication on get an mfa_token as from /login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete a single sign-on login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "State and code from the redirect",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.OIDCCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-endpoint_LoginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Unknown or expired state, or the provider rejected the login",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Unknown provider",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Missing state or code",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes the current session so its token stops working and its live sockets are disconnected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout user and invalidate token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout/all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes every token issued to the authenticated user, including the current one, and disconnects their live sockets",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout from all sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the current user's information based on the JWT token",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get current authenticated user information",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stores an image to pick as the avatar with PATCH /me/profile. The type is detected from the content",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Upload a profile picture",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_Attachment"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Missing file, too large or not an allowed image type",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/blocks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the users the authenticated user blocked, most recent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List blocked users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-array_model_UserBlock"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Blocks a user: neither can start a private conversation with or message the other, and they no longer find each other in the user directory",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Block a user",
+                "parameters": [
+                    {
+                        "description": "User to block",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.BlockUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_UserBlock"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Blocking yourself",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/blocks/{userID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a block the authenticated user placed on another user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Unblock a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Blocked user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-bool"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User is not blocked",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Verifies the first code from the authenticator app, turns two-factor authentication on and returns one-time recovery codes. The codes are not shown again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirm two-factor authentication enrollment",
+                "parameters": [
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-endpoint_MFARecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Enrollment not started",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the TOTP secret and recovery codes. Requires a current code or an unused recovery code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Turn off two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Current code or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Two-factor authentication is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid token or code",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a TOTP secret and returns it with an otpauth URI for authenticator apps. Logins do not ask for codes until the enrollment is confirmed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start two-factor authentication enrollment",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-endpoint_MFAEnrollmentResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Checks the current password, sets a new one that meets the password policy and signs out every other session. The session making the request stays signed in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Change the current user's password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Current password is incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - New password does not meet the requirements",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the display name, avatar, bio and custom status of the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the authenticated user's profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_UserProfile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the fields present in the body and notifies the users who share a conversation with a profile_updated event. An avatar_attachment_id of 0 removes the avatar; a status with an empty text clears it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update the authenticated user's profile",
+                "parameters": [
+                    {
+                        "description": "Profile fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_UserProfile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Field too long, past status expiry or unknown avatar",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{messageID}/reactions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds an emoji reaction from the current user. Reacting twice with the same emoji has no effect",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "React to a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Emoji",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.ReactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-array_model_ReactionSummary"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the current user's emoji reaction. Removing a reaction that does not exist has no effect",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Remove a reaction from a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Emoji to remove",
+                        "name": "emoji",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-array_model_ReactionSummary"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{messageID}/thread": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a page of replies in the thread started by the message (newest first). Passing a reply returns the thread it belongs to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get the replies in a message thread",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Thread root message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return replies older than this message ID",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return replies newer than this message ID",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_Page-model_Message"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Creates a new user account with username and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user account",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - User already exists",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Password does not meet the requirements",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/messages": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Finds messages in the caller's conversations containing a word starting with each word of q, newest first. Each result has an HTML-escaped excerpt with the matching words wrapped in \u003cmark\u003e",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Search messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Words to search for",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only search this conversation",
+                        "name": "conversation_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only messages from this user",
+                        "name": "sender_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages sent at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages sent at or before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return matches older than this message ID",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_Page-model_MessageSearchResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Not a member of the conversation",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the authenticated user's signed-in devices with their user agent, IP address and last use. The session making the request is flagged as current",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List active sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-array_model_Session"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes one of the authenticated user's sessions: its tokens stop working and its live sockets are disconnected",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign out a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Rotates the refresh token and issues a new short-lived access token. A refresh token works only once; reusing a rotated-out token revokes the whole session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Exchange a refresh token for a new token pair",
+                "parameters": [
+                    {
+                        "description": "Refresh token from login or the previous refresh",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoint.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-endpoint_LoginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid, expired or reused refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Missing refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a page of public user profiles. With q, users whose username or display name starts with it come first, then those containing its characters in order; without q every user is listed by username. Deactivated users and users blocked either way are left out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Search the user directory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text (max 64 characters)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_UserSearchPage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Query too long or invalid cursor",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/presence": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns whether each requested user is online and when they were last seen",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get online presence for users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated user IDs (max 100)",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-array_model_UserPresence"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the public profile of a user; an expired status is left out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user's profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-model_UserProfile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation Error - Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response-any"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "endpoint.AddParticipantsRequest": {
+            "type": "object",
+            "properties": {
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "endpoint.BlockUserRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "endpoint.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.CreateGroupConversationRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "endpoint.CreateMessageRequest": {
+            "type": "object",
+            "properties": {
+                "attachment_id": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "message_type": {
+                    "type": "string"
+                },
+                "reply_to_id": {
+                    "type": "integer"
+                },
+                "sender_id": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "thread_root_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "endpoint.EditMessageRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "mfa_required": {
+                    "type": "boolean"
+                },
+                "mfa_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.MFACodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.MFAEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.MFARecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "endpoint.MarkAsReadRequest": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "endpoint.MuteConversationRequest": {
+            "type": "object",
+            "properties": {
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.OIDCAuthorizationResponse": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.OIDCCallbackRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.OIDCProviderResponse": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.ReactionRequest": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoint.SetPresenceRequest": {
+            "type": "object",
+            "properties": {
+                "online": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "endpoint.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "avatar_attachment_id": {
+                    "type": "integer"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.UserStatus"
+                }
+            }
+        },
+        "endpoint.VerifyMFARequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "httpTransport.CreateConversationRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "uploader_id": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Conversation": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_joined": {
+                    "description": "EntityJoined names the pair of users of a private conversation, so there is\nonly ever one per pair; group conversations have none",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_message_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ConversationParticipant"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                },
+                "unread_count": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "model.ConversationParticipant": {
+            "type": "object",
+            "properties": {
+                "conversation": {
+                    "$ref": "#/definitions/model.Conversation"
+                },
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "last_read_message_id": {
+                    "type": "integer"
+                },
+                "muted_until": {
+                    "description": "MutedUntil silences the conversation's notifications for the participant; messages still arrive",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Internal Server Error"
+                }
+            }
+        },
+        "model.Message": {
+            "type": "object",
+            "properties": {
+                "attachment": {
+                    "$ref": "#/definitions/model.Attachment"
+                },
+                "attachment_id": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "conversation": {
+                    "$ref": "#/definitions/model.Conversation"
+                },
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message_type": {
+                    "type": "string"
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReactionSummary"
+                    }
+                },
+                "reply_count": {
+                    "type": "integer"
+                },
+                "reply_to": {
+                    "$ref": "#/definitions/model.MessagePreview"
+                },
+                "reply_to_id": {
+                    "type": "integer"
+                },
+                "sender": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "sender_id": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "thread_root_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MessagePreview": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "sender_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MessageSearchResult": {
+            "type": "object",
+            "properties": {
+                "highlight": {
+                    "type": "string"
+                },
+                "message": {
+                    "$ref": "#/definitions/model.Message"
+                }
+            }
+        },
+        "model.Page-model_Conversation": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Conversation"
+                    }
+                },
+                "next_cursor": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Page-model_Message": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Message"
+                    }
+                },
+                "next_cursor": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Page-model_MessageSearchResult": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MessageSearchResult"
+                    }
+                },
+                "next_cursor": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ReactionSummary": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "reacted_by_me": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.Response-any": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {},
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-array_endpoint_OIDCProviderResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/endpoint.OIDCProviderResponse"
+                    }
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-array_model_ConversationParticipant": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ConversationParticipant"
+                    }
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-array_model_ReactionSummary": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReactionSummary"
+                    }
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-array_model_Session": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Session"
+                    }
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-array_model_UserBlock": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UserBlock"
+                    }
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-array_model_UserPresence": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UserPresence"
+                    }
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-bool": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-endpoint_LoginResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/endpoint.LoginResponse"
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-endpoint_MFAEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/endpoint.MFAEnrollmentResponse"
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Response-endpoint_MFARecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/endpoint.MFARecoveryCodesResponse"
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
                 },
-                "username": {
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "httpTransoprt.CreateConversationRequest": {
+        "model.Response-endpoint_OIDCAuthorizationResponse": {
             "type": "object",
             "properties": {
-                "user_id": {
+                "code": {
                     "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/endpoint.OIDCAuthorizationResponse"
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
                 }
             }
         },
-        "model.Conversation": {
+        "model.Response-int64": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "entity_joined": {
-                    "type": "string"
-                },
-                "id": {
+                "code": {
                     "type": "integer"
                 },
-                "last_message_id": {
+                "data": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
-                },
-                "participants": {
+                "errors": {
+                    "description": "Array of errors with code and message",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.ConversationParticipant"
+                        "$ref": "#/definitions/model.Error"
                     }
                 },
-                "type": {
+                "message": {
                     "type": "string"
+                }
+            }
+        },
+        "model.Response-model_Attachment": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "data": {
+                    "$ref": "#/definitions/model.Attachment"
                 },
-                "user_ids": {
+                "errors": {
+                    "description": "Array of errors with code and message",
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/model.Error"
                     }
+                },
+                "message": {
+                    "type": "string"
                 }
             }
         },
-        "model.ConversationParticipant": {
+        "model.Response-model_Conversation": {
             "type": "object",
             "properties": {
-                "conversation": {
-                    "$ref": "#/definitions/model.Conversation"
-                },
-                "conversation_id": {
+                "code": {
                     "type": "integer"
                 },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
+                "data": {
+                    "$ref": "#/definitions/model.Conversation"
                 },
-                "joined_at": {
-                    "type": "string"
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
                 },
-                "updated_at": {
+                "message": {
                     "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/model.User"
-                },
-                "user_id": {
-                    "type": "integer"
                 }
             }
         },
-        "model.Error": {
+        "model.Response-model_ConversationParticipant": {
             "type": "object",
             "properties": {
                 "code": {
-                    "type": "integer",
-                    "example": 500
+                    "type": "integer"
+                },
+                "data": {
+                    "$ref": "#/definitions/model.ConversationParticipant"
+                },
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
                 },
                 "message": {
-                    "type": "string",
-                    "example": "Internal Server Error"
+                    "type": "string"
                 }
             }
         },
-        "model.Message": {
+        "model.Response-model_Message": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "conversation": {
-                    "$ref": "#/definitions/model.Conversation"
-                },
-                "conversation_id": {
-                    "type": "integer"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
+                "code": {
                     "type": "integer"
                 },
-                "message_type": {
-                    "type": "string"
-                },
-                "sender": {
-                    "$ref": "#/definitions/model.User"
-                },
-                "sender_id": {
-                    "type": "integer"
+                "data": {
+                    "$ref": "#/definitions/model.Message"
                 },
-                "session_id": {
-                    "type": "string"
+                "errors": {
+                    "description": "Array of errors with code and message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Error"
+                    }
                 },
-                "updated_at": {
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "model.Response-any": {
+        "model.Response-model_Page-model_Conversation": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
-                "data": {},
+                "data": {
+                    "$ref": "#/definitions/model.Page-model_Conversation"
+                },
                 "errors": {
                     "description": "Array of errors with code and message",
                     "type": "array",
@@ -722,17 +3906,14 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Response-array_model_Conversation": {
+        "model.Response-model_Page-model_Message": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Conversation"
-                    }
+                    "$ref": "#/definitions/model.Page-model_Message"
                 },
                 "errors": {
                     "description": "Array of errors with code and message",
@@ -746,17 +3927,14 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Response-array_model_Message": {
+        "model.Response-model_Page-model_MessageSearchResult": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Message"
-                    }
+                    "$ref": "#/definitions/model.Page-model_MessageSearchResult"
                 },
                 "errors": {
                     "description": "Array of errors with code and message",
@@ -770,17 +3948,14 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Response-array_model_User": {
+        "model.Response-model_User": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.User"
-                    }
+                    "$ref": "#/definitions/model.User"
                 },
                 "errors": {
                     "description": "Array of errors with code and message",
@@ -794,14 +3969,14 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Response-endpoint_LoginResponse": {
+        "model.Response-model_UserBlock": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
                 "data": {
-                    "$ref": "#/definitions/endpoint.LoginResponse"
+                    "$ref": "#/definitions/model.UserBlock"
                 },
                 "errors": {
                     "description": "Array of errors with code and message",
@@ -815,14 +3990,14 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Response-model_Conversation": {
+        "model.Response-model_UserPresence": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
                 "data": {
-                    "$ref": "#/definitions/model.Conversation"
+                    "$ref": "#/definitions/model.UserPresence"
                 },
                 "errors": {
                     "description": "Array of errors with code and message",
@@ -836,14 +4011,14 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Response-model_Message": {
+        "model.Response-model_UserProfile": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
                 "data": {
-                    "$ref": "#/definitions/model.Message"
+                    "$ref": "#/definitions/model.UserProfile"
                 },
                 "errors": {
                     "description": "Array of errors with code and message",
@@ -857,14 +4032,14 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Response-model_User": {
+        "model.Response-model_UserSearchPage": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
                 "data": {
-                    "$ref": "#/definitions/model.User"
+                    "$ref": "#/definitions/model.UserSearchPage"
                 },
                 "errors": {
                     "description": "Array of errors with code and message",
@@ -899,15 +4074,59 @@ const docTemplate = `{
                 }
             }
         },
+        "model.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "model.User": {
             "type": "object",
             "properties": {
+                "avatar_attachment_id": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "display_name": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "last_seen_at": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -915,6 +4134,82 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "model.UserBlock": {
+            "type": "object",
+            "properties": {
+                "blocked_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UserPresence": {
+            "type": "object",
+            "properties": {
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "online": {
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.UserProfile": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.UserStatus"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UserSearchPage": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UserProfile"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UserStatus": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -923,6 +4218,12 @@ const docTemplate = `{
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
+        },
+        "ServiceAuth": {
+            "description": "Shared INTERNAL_SERVICE_KEY of the deployment, for internal endpoints.",
+            "type": "apiKey",
+            "name": "X-Service-Key",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,50 @@
+package endpoint
+
+import (
+	"io"
+	"local/model"
+	"local/service/attachment"
+	"local/service/initial"
+	"local/util/logger"
+)
+
+type AttachmentEndpoints struct {
+	attachmentSvc attachment.AttachmentService
+}
+
+type UploadAttachmentRequest struct {
+	ConversationID uint
+	FileName       string
+	Size           int64
+	Body           io.Reader
+}
+
+func (e *AttachmentEndpoints) Upload(reqCtx *model.RequestContext, request UploadAttachmentRequest) model.Response[*model.Attachment] {
+	logger.Info(reqCtx, "AttachmentEndpoints.Upload called", map[string]interface{}{
+		"conversation_id": request.ConversationID,
+		"size":            request.Size,
+	})
+	return e.attachmentSvc.Upload(reqCtx, request.ConversationID, reqCtx.UserID, request.FileName, request.Size, request.Body)
+}
+
+type UploadAvatarRequest struct {
+	FileName string
+	Size     int64
+	Body     io.Reader
+}
+
+func (e *AttachmentEndpoints) UploadAvatar(reqCtx *model.RequestContext, request UploadAvatarRequest) model.Response[*model.Attachment] {
+	logger.Info(reqCtx, "AttachmentEndpoints.UploadAvatar called", map[string]interface{}{"size": request.Size})
+	return e.attachmentSvc.UploadAvatar(reqCtx, reqCtx.UserID, request.FileName, request.Size, request.Body)
+}
+
+func (e *AttachmentEndpoints) Download(reqCtx *model.RequestContext, attachmentID uint) model.Response[*model.AttachmentContent] {
+	logger.Info(reqCtx, "AttachmentEndpoints.Download called", map[string]interface{}{"attachment_id": attachmentID})
+	return e.attachmentSvc.Download(reqCtx, attachmentID, reqCtx.UserID)
+}
+
+func NewAttachmentEndpoints(params *initial.Service) *AttachmentEndpoints {
+	return &AttachmentEndpoints{
+		attachmentSvc: params.AttachmentSvc,
+	}
+}
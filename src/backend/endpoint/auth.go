@@ -1,10 +1,12 @@
 package endpoint
 
 import (
+	"local/infra/provider/signing"
 	"local/model"
 	"local/service/auth"
 	"local/service/initial"
 	"local/util/logger"
+	"time"
 )
 
 type AuthEndpoints struct {
@@ -21,14 +23,65 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// LoginResponse carries the tokens, or with mfa_required set only the mfa_token
+// challenge that POST /login/mfa exchanges for them
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	MFARequired  bool      `json:"mfa_required,omitempty"`
+	MFAToken     string    `json:"mfa_token,omitempty"`
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type OIDCProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCAuthorizationResponse is where to send the user; the client keeps state to
+// compare with the one the provider returns to its redirect page
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+type OIDCCallbackRequest struct {
+	State string `json:"state" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 type LogoutRequest struct {
 	Token string `json:"token"`
 }
 
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
 type GetMeRequest struct {
 	Token string `json:"token"`
 }
@@ -38,6 +91,11 @@ func (e *AuthEndpoints) Authenticate(reqCtx *model.RequestContext) model.Respons
 	return e.authService.Authenticate(reqCtx)
 }
 
+// VerifyToken checks a bearer token and returns its claims; used once per request by the auth middleware
+func (e *AuthEndpoints) VerifyToken(reqCtx *model.RequestContext, token string) model.Response[*auth.JWTClaims] {
+	return e.authService.ParseToken(token)
+}
+
 func (e *AuthEndpoints) GetMe(reqCtx *model.RequestContext) model.Response[*model.User] {
 	logger.Info(reqCtx, "AuthEndpoints.GetMe called")
 	return e.authService.GetMe(reqCtx)
@@ -58,8 +116,100 @@ func (e *AuthEndpoints) Login(reqCtx *model.RequestContext, request interface{})
 		return model.ErrorArray[LoginResponse](tokenResponse.Code, tokenResponse.Message, tokenResponse.Errors)
 	}
 
-	response := LoginResponse{Token: tokenResponse.Data}
-	return model.SuccessResponse(response, "Login successful")
+	return model.SuccessResponse(newLoginResponse(tokenResponse.Data), tokenResponse.Message)
+}
+
+func (e *AuthEndpoints) VerifyMFA(reqCtx *model.RequestContext, req VerifyMFARequest) model.Response[LoginResponse] {
+	logger.Info(reqCtx, "AuthEndpoints.VerifyMFA called")
+
+	tokenResponse := e.authService.VerifyMFA(reqCtx, req.MFAToken, req.Code)
+	if !tokenResponse.OK() {
+		return model.ErrorArray[LoginResponse](tokenResponse.Code, tokenResponse.Message, tokenResponse.Errors)
+	}
+
+	return model.SuccessResponse(newLoginResponse(tokenResponse.Data), tokenResponse.Message)
+}
+
+func (e *AuthEndpoints) OIDCProviders(reqCtx *model.RequestContext) model.Response[[]OIDCProviderResponse] {
+	logger.Info(reqCtx, "AuthEndpoints.OIDCProviders called")
+	response := e.authService.OIDCProviders(reqCtx)
+	if !response.OK() {
+		return model.ErrorArray[[]OIDCProviderResponse](response.Code, response.Message, response.Errors)
+	}
+	providers := make([]OIDCProviderResponse, 0, len(response.Data))
+	for _, provider := range response.Data {
+		providers = append(providers, OIDCProviderResponse{Name: provider.Name, DisplayName: provider.DisplayName})
+	}
+	return model.SuccessResponse(providers, response.Message)
+}
+
+func (e *AuthEndpoints) StartOIDCLogin(reqCtx *model.RequestContext, provider string) model.Response[OIDCAuthorizationResponse] {
+	logger.Info(reqCtx, "AuthEndpoints.StartOIDCLogin called", map[string]interface{}{"provider": provider})
+	response := e.authService.StartOIDCLogin(reqCtx, provider)
+	if !response.OK() {
+		return model.ErrorArray[OIDCAuthorizationResponse](response.Code, response.Message, response.Errors)
+	}
+	return model.SuccessResponse(OIDCAuthorizationResponse{
+		AuthorizationURL: response.Data.URL,
+		State:            response.Data.State,
+		ExpiresAt:        response.Data.ExpiresAt,
+	}, response.Message)
+}
+
+func (e *AuthEndpoints) CompleteOIDCLogin(reqCtx *model.RequestContext, provider string, req OIDCCallbackRequest) model.Response[LoginResponse] {
+	logger.Info(reqCtx, "AuthEndpoints.CompleteOIDCLogin called", map[string]interface{}{"provider": provider})
+
+	tokenResponse := e.authService.CompleteOIDCLogin(reqCtx, provider, req.State, req.Code)
+	if !tokenResponse.OK() {
+		return model.ErrorArray[LoginResponse](tokenResponse.Code, tokenResponse.Message, tokenResponse.Errors)
+	}
+
+	return model.SuccessResponse(newLoginResponse(tokenResponse.Data), tokenResponse.Message)
+}
+
+func (e *AuthEndpoints) EnrollMFA(reqCtx *model.RequestContext) model.Response[MFAEnrollmentResponse] {
+	logger.Info(reqCtx, "AuthEndpoints.EnrollMFA called")
+	response := e.authService.EnrollMFA(reqCtx, reqCtx.UserID)
+	if !response.OK() {
+		return model.ErrorArray[MFAEnrollmentResponse](response.Code, response.Message, response.Errors)
+	}
+	return model.SuccessResponse(MFAEnrollmentResponse{Secret: response.Data.Secret, OtpauthURI: response.Data.URI}, response.Message)
+}
+
+func (e *AuthEndpoints) ConfirmMFA(reqCtx *model.RequestContext, req MFACodeRequest) model.Response[MFARecoveryCodesResponse] {
+	logger.Info(reqCtx, "AuthEndpoints.ConfirmMFA called")
+	response := e.authService.ConfirmMFA(reqCtx, reqCtx.UserID, req.Code)
+	if !response.OK() {
+		return model.ErrorArray[MFARecoveryCodesResponse](response.Code, response.Message, response.Errors)
+	}
+	return model.SuccessResponse(MFARecoveryCodesResponse{RecoveryCodes: response.Data}, response.Message)
+}
+
+func (e *AuthEndpoints) DisableMFA(reqCtx *model.RequestContext, req MFACodeRequest) model.Response[string] {
+	logger.Info(reqCtx, "AuthEndpoints.DisableMFA called")
+	return e.authService.DisableMFA(reqCtx, reqCtx.UserID, req.Code)
+}
+
+func (e *AuthEndpoints) Refresh(reqCtx *model.RequestContext, request interface{}) model.Response[LoginResponse] {
+	req := request.(RefreshTokenRequest)
+	logger.Info(reqCtx, "AuthEndpoints.Refresh called")
+
+	tokenResponse := e.authService.Refresh(reqCtx, req.RefreshToken)
+	if !tokenResponse.OK() {
+		return model.ErrorArray[LoginResponse](tokenResponse.Code, tokenResponse.Message, tokenResponse.Errors)
+	}
+
+	return model.SuccessResponse(newLoginResponse(tokenResponse.Data), "Token refreshed successfully")
+}
+
+func newLoginResponse(tokens *auth.TokenPair) LoginResponse {
+	return LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		MFARequired:  tokens.MFAToken != "",
+		MFAToken:     tokens.MFAToken,
+	}
 }
 
 func (e *AuthEndpoints) Logout(reqCtx *model.RequestContext, request interface{}) model.Response[string] {
@@ -68,9 +218,28 @@ func (e *AuthEndpoints) Logout(reqCtx *model.RequestContext, request interface{}
 	return e.authService.Logout(reqCtx, req.Token)
 }
 
-func (e *AuthEndpoints) GetUsers(reqCtx *model.RequestContext) model.Response[[]*model.User] {
-	logger.Info(reqCtx, "AuthEndpoints.GetUsers called")
-	return e.authService.GetUsers(reqCtx)
+func (e *AuthEndpoints) LogoutAll(reqCtx *model.RequestContext) model.Response[string] {
+	logger.Info(reqCtx, "AuthEndpoints.LogoutAll called")
+	return e.authService.LogoutAll(reqCtx, reqCtx.UserID)
+}
+
+func (e *AuthEndpoints) ListSessions(reqCtx *model.RequestContext) model.Response[[]*model.Session] {
+	logger.Info(reqCtx, "AuthEndpoints.ListSessions called")
+	return e.authService.ListSessions(reqCtx, reqCtx.UserID)
+}
+
+func (e *AuthEndpoints) DeleteSession(reqCtx *model.RequestContext, sessionID string) model.Response[string] {
+	logger.Info(reqCtx, "AuthEndpoints.DeleteSession called", map[string]interface{}{"session_id": sessionID})
+	return e.authService.DeleteSession(reqCtx, reqCtx.UserID, sessionID)
+}
+
+func (e *AuthEndpoints) ChangePassword(reqCtx *model.RequestContext, req ChangePasswordRequest) model.Response[string] {
+	logger.Info(reqCtx, "AuthEndpoints.ChangePassword called")
+	return e.authService.ChangePassword(reqCtx, reqCtx.UserID, req.OldPassword, req.NewPassword)
+}
+
+func (e *AuthEndpoints) JWKS(reqCtx *model.RequestContext) model.Response[*signing.JWKS] {
+	return e.authService.JWKS(reqCtx)
 }
 
 func NewAuthEndpoints(params *initial.Service) *AuthEndpoints {
@@ -6,6 +6,7 @@ import (
 	"local/service/conversation"
 	"local/service/initial"
 	"local/util/logger"
+	"time"
 )
 
 type ConversationEndpoints struct {
@@ -21,6 +22,24 @@ type GetUserConversationsRequest struct {
 	UserID uint `json:"user_id"`
 }
 
+type CreateGroupConversationRequest struct {
+	Name    string `json:"name"`
+	UserIDs []uint `json:"user_ids"`
+}
+
+type AddParticipantsRequest struct {
+	UserIDs []uint `json:"user_ids"`
+}
+
+type MarkAsReadRequest struct {
+	MessageID uint `json:"message_id"`
+}
+
+// MuteConversationRequest mutes until the given time, or indefinitely without one
+type MuteConversationRequest struct {
+	Until *time.Time `json:"until"`
+}
+
 
 func (e *ConversationEndpoints) CreateConversation(reqCtx *model.RequestContext, userIDs []uint) model.Response[*model.Conversation] {
 	logger.Info(reqCtx, "ConversationEndpoints.CreateConversation called", map[string]interface{}{"user_ids": userIDs})
@@ -32,9 +51,60 @@ func (e *ConversationEndpoints) GetConversationByUserIDs(reqCtx *model.RequestCo
 	return e.cvsSvc.GetConversationByUserIDs(reqCtx, userIDs)
 }
 
-func (e *ConversationEndpoints) GetUserConversations(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Conversation] {
+func (e *ConversationEndpoints) GetUserConversations(reqCtx *model.RequestContext, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Conversation]] {
 	logger.Info(reqCtx, "ConversationEndpoints.GetUserConversations called", map[string]interface{}{"user_id": userID})
-	return e.cvsSvc.GetUserConversations(reqCtx, userID)
+	return e.cvsSvc.GetUserConversations(reqCtx, userID, cursor)
+}
+
+func (e *ConversationEndpoints) CreateGroupConversation(reqCtx *model.RequestContext, request CreateGroupConversationRequest) model.Response[*model.Conversation] {
+	logger.Info(reqCtx, "ConversationEndpoints.CreateGroupConversation called", map[string]interface{}{"name": request.Name, "user_ids": request.UserIDs})
+	return e.cvsSvc.CreateGroupConversation(reqCtx, reqCtx.UserID, request.Name, request.UserIDs)
+}
+
+func (e *ConversationEndpoints) GetParticipants(reqCtx *model.RequestContext, conversationID uint) model.Response[[]*model.ConversationParticipant] {
+	logger.Info(reqCtx, "ConversationEndpoints.GetParticipants called", map[string]interface{}{"conversation_id": conversationID})
+	return e.cvsSvc.GetParticipants(reqCtx, conversationID, reqCtx.UserID)
+}
+
+// GetParticipantsForMember lists the participants on behalf of userID, for internal callers
+func (e *ConversationEndpoints) GetParticipantsForMember(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[[]*model.ConversationParticipant] {
+	logger.Info(reqCtx, "ConversationEndpoints.GetParticipantsForMember called", map[string]interface{}{"conversation_id": conversationID, "user_id": userID})
+	return e.cvsSvc.GetParticipants(reqCtx, conversationID, userID)
+}
+
+func (e *ConversationEndpoints) AddParticipants(reqCtx *model.RequestContext, conversationID uint, request AddParticipantsRequest) model.Response[*model.Conversation] {
+	logger.Info(reqCtx, "ConversationEndpoints.AddParticipants called", map[string]interface{}{"conversation_id": conversationID, "user_ids": request.UserIDs})
+	return e.cvsSvc.AddParticipants(reqCtx, conversationID, reqCtx.UserID, request.UserIDs)
+}
+
+func (e *ConversationEndpoints) RemoveParticipant(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[string] {
+	logger.Info(reqCtx, "ConversationEndpoints.RemoveParticipant called", map[string]interface{}{"conversation_id": conversationID, "user_id": userID})
+	return e.cvsSvc.RemoveParticipant(reqCtx, conversationID, reqCtx.UserID, userID)
+}
+
+func (e *ConversationEndpoints) PromoteParticipant(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "ConversationEndpoints.PromoteParticipant called", map[string]interface{}{"conversation_id": conversationID, "user_id": userID})
+	return e.cvsSvc.PromoteParticipant(reqCtx, conversationID, reqCtx.UserID, userID)
+}
+
+func (e *ConversationEndpoints) LeaveConversation(reqCtx *model.RequestContext, conversationID uint) model.Response[string] {
+	logger.Info(reqCtx, "ConversationEndpoints.LeaveConversation called", map[string]interface{}{"conversation_id": conversationID})
+	return e.cvsSvc.LeaveConversation(reqCtx, conversationID, reqCtx.UserID)
+}
+
+func (e *ConversationEndpoints) MarkAsRead(reqCtx *model.RequestContext, conversationID uint, request MarkAsReadRequest) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "ConversationEndpoints.MarkAsRead called", map[string]interface{}{"conversation_id": conversationID, "message_id": request.MessageID})
+	return e.cvsSvc.MarkAsRead(reqCtx, conversationID, reqCtx.UserID, request.MessageID)
+}
+
+func (e *ConversationEndpoints) MuteConversation(reqCtx *model.RequestContext, conversationID uint, request MuteConversationRequest) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "ConversationEndpoints.MuteConversation called", map[string]interface{}{"conversation_id": conversationID, "until": request.Until})
+	return e.cvsSvc.MuteConversation(reqCtx, conversationID, reqCtx.UserID, request.Until)
+}
+
+func (e *ConversationEndpoints) UnmuteConversation(reqCtx *model.RequestContext, conversationID uint) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "ConversationEndpoints.UnmuteConversation called", map[string]interface{}{"conversation_id": conversationID})
+	return e.cvsSvc.UnmuteConversation(reqCtx, conversationID, reqCtx.UserID)
 }
 
 func NewConversationEndpoints(params *initial.Service) *ConversationEndpoints {
@@ -7,15 +7,21 @@ type Endpoints struct {
 	Auth *AuthEndpoints
 	Conversation *ConversationEndpoints
 	Message *MessageEndpoints
+	User *UserEndpoints
+	Attachment *AttachmentEndpoints
 }
 
 func NewEndpoints(params *initial.Service) *Endpoints {
 	auth := NewAuthEndpoints(params)
 	conversation := NewConversationEndpoints(params)
 	message := NewMessageEndpoints(params)
+	user := NewUserEndpoints(params)
+	attachment := NewAttachmentEndpoints(params)
 	return &Endpoints{
 		Auth: auth,
 		Conversation: conversation,
 		Message: message,
+		User: user,
+		Attachment: attachment,
 	}
 }
\ No newline at end of file
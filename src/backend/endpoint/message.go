@@ -18,6 +18,18 @@ type CreateMessageRequest struct {
 	SenderID uint `json:"sender_id"`
 	Content string `json:"content"`
 	SessionID string `json:"session_id"`
+	MessageType string `json:"message_type,omitempty"`
+	AttachmentID *uint `json:"attachment_id,omitempty"`
+	ReplyToID *uint `json:"reply_to_id,omitempty"`
+	ThreadRootID *uint `json:"thread_root_id,omitempty"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" form:"emoji"`
+}
+
+type EditMessageRequest struct {
+	Content string `json:"content"`
 }
 
 func (e *MessageEndpoints) CreateMessage(reqCtx *model.RequestContext, request CreateMessageRequest) model.Response[*model.Message] {
@@ -30,12 +42,46 @@ func (e *MessageEndpoints) CreateMessage(reqCtx *model.RequestContext, request C
 		SenderID: request.SenderID,
 		Content: request.Content,
 		SessionID: request.SessionID,
+		MessageType: request.MessageType,
+		AttachmentID: request.AttachmentID,
+		ReplyToID: request.ReplyToID,
+		ThreadRootID: request.ThreadRootID,
 	})
 }
 
-func (e *MessageEndpoints) GetMessagesByConversationID(reqCtx *model.RequestContext, cvsID uint) model.Response[[]*model.Message] {
+func (e *MessageEndpoints) GetMessagesByConversationID(reqCtx *model.RequestContext, cvsID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
 	logger.Info(reqCtx, "MessageEndpoints.GetMessagesByConversationID called", map[string]interface{}{"conversation_id": cvsID})
-	return e.messageSvc.GetMessagesByConversationID(reqCtx, cvsID)
+	return e.messageSvc.GetMessagesByConversationID(reqCtx, cvsID, reqCtx.UserID, cursor)
+}
+
+func (e *MessageEndpoints) EditMessage(reqCtx *model.RequestContext, cvsID, messageID uint, request EditMessageRequest) model.Response[*model.Message] {
+	logger.Info(reqCtx, "MessageEndpoints.EditMessage called", map[string]interface{}{"conversation_id": cvsID, "message_id": messageID})
+	return e.messageSvc.EditMessage(reqCtx, cvsID, messageID, reqCtx.UserID, request.Content)
+}
+
+func (e *MessageEndpoints) DeleteMessage(reqCtx *model.RequestContext, cvsID, messageID uint) model.Response[*model.Message] {
+	logger.Info(reqCtx, "MessageEndpoints.DeleteMessage called", map[string]interface{}{"conversation_id": cvsID, "message_id": messageID})
+	return e.messageSvc.DeleteMessage(reqCtx, cvsID, messageID, reqCtx.UserID)
+}
+
+func (e *MessageEndpoints) GetThread(reqCtx *model.RequestContext, messageID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
+	logger.Info(reqCtx, "MessageEndpoints.GetThread called", map[string]interface{}{"message_id": messageID})
+	return e.messageSvc.GetThread(reqCtx, messageID, reqCtx.UserID, cursor)
+}
+
+func (e *MessageEndpoints) SearchMessages(reqCtx *model.RequestContext, params model.MessageSearchParams) model.Response[*model.Page[*model.MessageSearchResult]] {
+	logger.Info(reqCtx, "MessageEndpoints.SearchMessages called", map[string]interface{}{"conversation_id": params.ConversationID})
+	return e.messageSvc.SearchMessages(reqCtx, reqCtx.UserID, params)
+}
+
+func (e *MessageEndpoints) AddReaction(reqCtx *model.RequestContext, messageID uint, request ReactionRequest) model.Response[[]*model.ReactionSummary] {
+	logger.Info(reqCtx, "MessageEndpoints.AddReaction called", map[string]interface{}{"message_id": messageID})
+	return e.messageSvc.AddReaction(reqCtx, messageID, reqCtx.UserID, request.Emoji)
+}
+
+func (e *MessageEndpoints) RemoveReaction(reqCtx *model.RequestContext, messageID uint, request ReactionRequest) model.Response[[]*model.ReactionSummary] {
+	logger.Info(reqCtx, "MessageEndpoints.RemoveReaction called", map[string]interface{}{"message_id": messageID})
+	return e.messageSvc.RemoveReaction(reqCtx, messageID, reqCtx.UserID, request.Emoji)
 }
 
 func NewMessageEndpoints(params *initial.Service) *MessageEndpoints {
@@ -0,0 +1,85 @@
+package endpoint
+
+import (
+	"local/model"
+	"local/service/initial"
+	"local/service/user"
+	"local/util/logger"
+)
+
+type UserEndpoints struct {
+	userSvc user.UserService
+}
+
+type SetPresenceRequest struct {
+	Online bool `json:"online"`
+}
+
+func (e *UserEndpoints) SetPresence(reqCtx *model.RequestContext, userID uint, request SetPresenceRequest) model.Response[*model.UserPresence] {
+	logger.Info(reqCtx, "UserEndpoints.SetPresence called", map[string]interface{}{"user_id": userID, "online": request.Online})
+	return e.userSvc.SetPresence(reqCtx, userID, request.Online)
+}
+
+func (e *UserEndpoints) ResetPresence(reqCtx *model.RequestContext) model.Response[int64] {
+	logger.Info(reqCtx, "UserEndpoints.ResetPresence called")
+	return e.userSvc.ResetPresence(reqCtx)
+}
+
+func (e *UserEndpoints) GetPresence(reqCtx *model.RequestContext, userIDs []uint) model.Response[[]*model.UserPresence] {
+	logger.Info(reqCtx, "UserEndpoints.GetPresence called", map[string]interface{}{"user_ids": userIDs})
+	return e.userSvc.GetPresence(reqCtx, userIDs)
+}
+
+// UpdateProfileRequest changes the fields that are present. An avatar_attachment_id of 0
+// removes the avatar and a status with an empty text clears the status
+type UpdateProfileRequest struct {
+	DisplayName        *string           `json:"display_name"`
+	Bio                *string           `json:"bio"`
+	AvatarAttachmentID *uint             `json:"avatar_attachment_id"`
+	Status             *model.UserStatus `json:"status"`
+}
+
+func (e *UserEndpoints) GetProfile(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserProfile] {
+	logger.Info(reqCtx, "UserEndpoints.GetProfile called", map[string]interface{}{"user_id": userID})
+	return e.userSvc.GetProfile(reqCtx, userID)
+}
+
+func (e *UserEndpoints) UpdateProfile(reqCtx *model.RequestContext, request UpdateProfileRequest) model.Response[*model.UserProfile] {
+	logger.Info(reqCtx, "UserEndpoints.UpdateProfile called")
+	return e.userSvc.UpdateProfile(reqCtx, reqCtx.UserID, &user.ProfileUpdate{
+		DisplayName:        request.DisplayName,
+		Bio:                request.Bio,
+		AvatarAttachmentID: request.AvatarAttachmentID,
+		Status:             request.Status,
+	})
+}
+
+func (e *UserEndpoints) SearchUsers(reqCtx *model.RequestContext, params model.UserSearchParams) model.Response[*model.UserSearchPage] {
+	logger.Info(reqCtx, "UserEndpoints.SearchUsers called", map[string]interface{}{"query": params.Query})
+	return e.userSvc.SearchUsers(reqCtx, reqCtx.UserID, params)
+}
+
+type BlockUserRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+func (e *UserEndpoints) ListBlocks(reqCtx *model.RequestContext) model.Response[[]*model.UserBlock] {
+	logger.Info(reqCtx, "UserEndpoints.ListBlocks called")
+	return e.userSvc.ListBlocks(reqCtx, reqCtx.UserID)
+}
+
+func (e *UserEndpoints) Block(reqCtx *model.RequestContext, request BlockUserRequest) model.Response[*model.UserBlock] {
+	logger.Info(reqCtx, "UserEndpoints.Block called", map[string]interface{}{"blocked_id": request.UserID})
+	return e.userSvc.Block(reqCtx, reqCtx.UserID, request.UserID)
+}
+
+func (e *UserEndpoints) Unblock(reqCtx *model.RequestContext, userID uint) model.Response[bool] {
+	logger.Info(reqCtx, "UserEndpoints.Unblock called", map[string]interface{}{"blocked_id": userID})
+	return e.userSvc.Unblock(reqCtx, reqCtx.UserID, userID)
+}
+
+func NewUserEndpoints(params *initial.Service) *UserEndpoints {
+	return &UserEndpoints{
+		userSvc: params.UserSvc,
+	}
+}
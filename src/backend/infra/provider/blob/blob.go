@@ -0,0 +1,37 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"local/config"
+)
+
+// ErrNotFound is returned by Get when no object is stored under the key
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps attachment contents outside the database
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStore builds the store selected by BLOB_STORE ("local" or "s3")
+func NewBlobStore() (BlobStore, error) {
+	switch config.Config.BlobStore {
+	case "", "local":
+		return NewLocalStore(config.Config.BlobLocalDir)
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:  config.Config.S3Endpoint,
+			Bucket:    config.Config.S3Bucket,
+			Region:    config.Config.S3Region,
+			AccessKey: config.Config.S3AccessKey,
+			SecretKey: config.Config.S3SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", config.Config.BlobStore)
+	}
+}
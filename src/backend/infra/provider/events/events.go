@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"local/config"
+	"local/infra/provider/kafka"
+	"local/job/consumer"
+	"local/model"
+	"strconv"
+)
+
+// EventPublisher tells other services what happened to messages. Events are
+// keyed by conversation ID, so the events of one conversation arrive in order.
+type EventPublisher interface {
+	PublishMessageEvent(ctx context.Context, event consumer.MessageEvent) error
+}
+
+// NewEventPublisher builds the publisher selected by EVENT_PUBLISHER ("kafka" or "none").
+// The Kafka publisher needs kafka.InitProducers to have run.
+func NewEventPublisher() (EventPublisher, error) {
+	switch config.Config.EventPublisher {
+	case "", "none":
+		return NoopPublisher{}, nil
+	case "kafka":
+		if kafka.MessageProducer == nil {
+			return nil, fmt.Errorf("kafka producers are not initialized")
+		}
+		return NewKafkaPublisher(kafka.MessageProducer), nil
+	default:
+		return nil, fmt.Errorf("unknown event publisher %q", config.Config.EventPublisher)
+	}
+}
+
+// NewMessageEvent describes what happened to message; deleted messages carry no content
+func NewMessageEvent(eventType string, message *model.Message) consumer.MessageEvent {
+	event := consumer.MessageEvent{
+		Type:           eventType,
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		UserID:         message.SenderID,
+		Content:        message.Content,
+		CreatedAt:      message.CreatedAt,
+	}
+	if eventType == consumer.MessageEventDeleted {
+		event.Content = ""
+	}
+	return event
+}
+
+// MessageEventKey is the partition key of an event: its conversation ID
+func MessageEventKey(event consumer.MessageEvent) string {
+	return strconv.FormatUint(uint64(event.ConversationID), 10)
+}
+
+// NoopPublisher drops every event, for deployments without a message broker
+type NoopPublisher struct{}
+
+func (NoopPublisher) PublishMessageEvent(_ context.Context, _ consumer.MessageEvent) error {
+	return nil
+}
+
+// KafkaPublisher publishes message events to the message topic
+type KafkaPublisher struct {
+	producer *kafka.Producer
+}
+
+func NewKafkaPublisher(producer *kafka.Producer) *KafkaPublisher {
+	return &KafkaPublisher{producer: producer}
+}
+
+func (p *KafkaPublisher) PublishMessageEvent(ctx context.Context, event consumer.MessageEvent) error {
+	return p.producer.ProduceMessage(ctx, MessageEventKey(event), event)
+}
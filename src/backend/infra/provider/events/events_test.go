@@ -0,0 +1,44 @@
+package events
+
+import (
+	"context"
+	"local/job/consumer"
+	"local/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessageEvent(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	message := &model.Message{ID: 7, ConversationID: 3, SenderID: 5, Content: "hello", CreatedAt: createdAt}
+
+	assert.Equal(t, consumer.MessageEvent{
+		Type:           consumer.MessageEventCreated,
+		MessageID:      7,
+		ConversationID: 3,
+		UserID:         5,
+		Content:        "hello",
+		CreatedAt:      createdAt,
+	}, NewMessageEvent(consumer.MessageEventCreated, message))
+	assert.Empty(t, NewMessageEvent(consumer.MessageEventDeleted, message).Content)
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := NewMemoryPublisher()
+	ctx := context.Background()
+
+	assert.NoError(t, publisher.PublishMessageEvent(ctx, consumer.MessageEvent{Type: consumer.MessageEventCreated, MessageID: 1, ConversationID: 12}))
+	assert.NoError(t, publisher.PublishMessageEvent(ctx, consumer.MessageEvent{Type: consumer.MessageEventDeleted, MessageID: 1, ConversationID: 12}))
+
+	published := publisher.Events()
+	assert.Len(t, published, 2)
+	assert.Equal(t, "12", published[0].Key)
+	assert.Equal(t, consumer.MessageEventCreated, published[0].Event.Type)
+	assert.Equal(t, consumer.MessageEventDeleted, published[1].Event.Type)
+
+	// Callers get a copy
+	published[0].Key = "changed"
+	assert.Equal(t, "12", publisher.Events()[0].Key)
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"local/job/consumer"
+	"sync"
+)
+
+// PublishedEvent is an event a MemoryPublisher received, with its partition key
+type PublishedEvent struct {
+	Key   string
+	Event consumer.MessageEvent
+}
+
+// MemoryPublisher keeps published events in process memory so tests can inspect them
+type MemoryPublisher struct {
+	lock   sync.Mutex
+	events []PublishedEvent
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) PublishMessageEvent(_ context.Context, event consumer.MessageEvent) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.events = append(p.events, PublishedEvent{Key: MessageEventKey(event), Event: event})
+	return nil
+}
+
+// Events returns the events published so far, oldest first
+func (p *MemoryPublisher) Events() []PublishedEvent {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return append([]PublishedEvent{}, p.events...)
+}
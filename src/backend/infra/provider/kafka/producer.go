@@ -15,12 +15,13 @@ type Producer struct {
 	writer *kafka.Writer
 }
 
-// NewProducer creates a new Kafka producer
+// NewProducer creates a new Kafka producer. Messages with the same key go to the
+// same partition, so they are consumed in the order they were produced
 func NewProducer(brokers []string, topic string) *Producer {
 	writer := &kafka.Writer{
 		Addr:     kafka.TCP(brokers...),
 		Topic:    topic,
-		Balancer: &kafka.LeastBytes{},
+		Balancer: &kafka.Hash{},
 	}
 
 	logger.Info(nil, "Kafka producer initialized", map[string]interface{}{
@@ -0,0 +1,139 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"local/config"
+	"local/infra/repo"
+	"strings"
+	"time"
+)
+
+// resetAfter is how long a key must go without a failure before its count starts over
+const resetAfter = 24 * time.Hour
+
+// Attempts is the failure history of one key
+type Attempts struct {
+	Failures      int
+	LastFailureAt time.Time
+}
+
+// Store keeps failed login counters by key. Another backend such as Redis only
+// has to implement this interface.
+type Store interface {
+	Get(ctx context.Context, key string) (Attempts, error)
+	// RecordFailure counts a failure at now, forgetting failures older than resetAfter
+	RecordFailure(ctx context.Context, key string, now time.Time, resetAfter time.Duration) (Attempts, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// NewStore builds the store selected by LOGIN_LOCKOUT_STORE ("mysql" or "memory")
+func NewStore(attempts repo.LoginAttemptRepo) (Store, error) {
+	switch config.Config.LoginLockoutStore {
+	case "", "mysql":
+		return NewSQLStore(attempts), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown login lockout store %q", config.Config.LoginLockoutStore)
+	}
+}
+
+// Policy locks a key once it reaches MaxFailures consecutive failures: for Base
+// at first, doubling with each further failure up to Max. MaxFailures 0 disables it
+type Policy struct {
+	MaxFailures int
+	Base        time.Duration
+	Max         time.Duration
+}
+
+// lockedUntil is when a key with these attempts may try again
+func (p Policy) lockedUntil(attempts Attempts) time.Time {
+	if p.MaxFailures <= 0 || attempts.Failures < p.MaxFailures {
+		return time.Time{}
+	}
+	delay := p.Base
+	for i := p.MaxFailures; i < attempts.Failures && delay < p.Max; i++ {
+		delay *= 2
+	}
+	if delay > p.Max {
+		delay = p.Max
+	}
+	return attempts.LastFailureAt.Add(delay)
+}
+
+// Guard throttles password guessing against one account and from one address.
+// Failures count against both; a success only clears the account, so logging in
+// to an account you own does not buy more guesses at others.
+type Guard struct {
+	store   Store
+	account Policy
+	ip      Policy
+	now     func() time.Time
+}
+
+func NewGuard(store Store, account, ip Policy) *Guard {
+	return &Guard{store: store, account: account, ip: ip, now: time.Now}
+}
+
+// NewGuardFromConfig applies the LOGIN_* lockout settings to the store
+func NewGuardFromConfig(store Store) *Guard {
+	return NewGuard(store,
+		Policy{MaxFailures: config.Config.LoginAccountMaxFailures, Base: config.Config.LoginLockoutBase, Max: config.Config.LoginLockoutMax},
+		Policy{MaxFailures: config.Config.LoginIPMaxFailures, Base: config.Config.LoginLockoutBase, Max: config.Config.LoginLockoutMax},
+	)
+}
+
+// Locked returns how long the account or address must wait before trying again,
+// or zero when neither is locked
+func (g *Guard) Locked(ctx context.Context, userName, clientIP string) (time.Duration, error) {
+	now := g.now()
+	var wait time.Duration
+	for _, k := range g.keys(userName, clientIP) {
+		attempts, err := g.store.Get(ctx, k.key)
+		if err != nil {
+			return 0, err
+		}
+		if now.Sub(attempts.LastFailureAt) > resetAfter {
+			continue
+		}
+		if until := k.policy.lockedUntil(attempts); until.Sub(now) > wait {
+			wait = until.Sub(now)
+		}
+	}
+	return wait, nil
+}
+
+// Fail records a failed attempt against the account and the address
+func (g *Guard) Fail(ctx context.Context, userName, clientIP string) error {
+	now := g.now()
+	for _, k := range g.keys(userName, clientIP) {
+		if _, err := g.store.RecordFailure(ctx, k.key, now, resetAfter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Succeed clears the account's failures after a correct password
+func (g *Guard) Succeed(ctx context.Context, userName string) error {
+	return g.store.Reset(ctx, accountKey(userName))
+}
+
+type guardKey struct {
+	key    string
+	policy Policy
+}
+
+func (g *Guard) keys(userName, clientIP string) []guardKey {
+	keys := []guardKey{{key: accountKey(userName), policy: g.account}}
+	if clientIP != "" {
+		keys = append(keys, guardKey{key: "ip:" + clientIP, policy: g.ip})
+	}
+	return keys
+}
+
+// accountKey folds case, as MySQL's default collation matches usernames case-insensitively
+func accountKey(userName string) string {
+	return "user:" + strings.ToLower(userName)
+}
@@ -0,0 +1,111 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clock is a settable time source for the guard
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestGuard() (*Guard, *clock) {
+	c := &clock{now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+	guard := NewGuard(NewMemoryStore(),
+		Policy{MaxFailures: 3, Base: 30 * time.Second, Max: 2 * time.Minute},
+		Policy{MaxFailures: 5, Base: 30 * time.Second, Max: 2 * time.Minute},
+	)
+	guard.now = c.Now
+	return guard, c
+}
+
+func TestGuard_ExponentialAccountLockout(t *testing.T) {
+	guard, c := newTestGuard()
+	ctx := context.Background()
+
+	fail := func() time.Duration {
+		assert.NoError(t, guard.Fail(ctx, "alice", ""))
+		wait, err := guard.Locked(ctx, "alice", "")
+		assert.NoError(t, err)
+		return wait
+	}
+
+	assert.Zero(t, fail())
+	assert.Zero(t, fail())
+	assert.Equal(t, 30*time.Second, fail())
+
+	c.now = c.now.Add(30 * time.Second)
+	assert.Equal(t, time.Minute, fail())
+
+	c.now = c.now.Add(time.Minute)
+	assert.Equal(t, 2*time.Minute, fail())
+
+	// Capped at Max
+	c.now = c.now.Add(2 * time.Minute)
+	assert.Equal(t, 2*time.Minute, fail())
+
+	// Usernames differing only in case share a counter
+	wait, err := guard.Locked(ctx, "ALICE", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, wait)
+
+	// The lock runs out on its own
+	c.now = c.now.Add(2 * time.Minute)
+	wait, err = guard.Locked(ctx, "alice", "")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// A correct password clears the account
+	assert.NoError(t, guard.Succeed(ctx, "alice"))
+	assert.Zero(t, fail())
+}
+
+func TestGuard_FailuresExpire(t *testing.T) {
+	guard, c := newTestGuard()
+	ctx := context.Background()
+
+	assert.NoError(t, guard.Fail(ctx, "alice", ""))
+	assert.NoError(t, guard.Fail(ctx, "alice", ""))
+
+	// A day later the earlier failures no longer count towards a lock
+	c.now = c.now.Add(resetAfter + time.Second)
+	assert.NoError(t, guard.Fail(ctx, "alice", ""))
+	wait, err := guard.Locked(ctx, "alice", "")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+}
+
+func TestGuard_PerIPLockout(t *testing.T) {
+	guard, _ := newTestGuard()
+	ctx := context.Background()
+
+	// Spraying one guess at many accounts trips the address limit
+	for _, user := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, guard.Fail(ctx, user, "203.0.113.7"))
+	}
+	wait, err := guard.Locked(ctx, "f", "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, wait)
+
+	// Other addresses are unaffected, and a success does not clear the address
+	wait, err = guard.Locked(ctx, "f", "198.51.100.1")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.NoError(t, guard.Succeed(ctx, "a"))
+	wait, err = guard.Locked(ctx, "a", "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, wait)
+}
+
+func TestPolicy_Disabled(t *testing.T) {
+	policy := Policy{MaxFailures: 0, Base: time.Second, Max: time.Minute}
+	assert.True(t, policy.lockedUntil(Attempts{Failures: 100, LastFailureAt: time.Now()}).IsZero())
+}
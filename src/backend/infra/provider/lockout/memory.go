@@ -0,0 +1,50 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps failure counters in process memory. It suits a single
+// instance and tests; counters are lost on restart.
+type MemoryStore struct {
+	lock     sync.Mutex
+	attempts map[string]Attempts
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: make(map[string]Attempts)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Attempts, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *MemoryStore) RecordFailure(_ context.Context, key string, now time.Time, resetAfter time.Duration) (Attempts, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// Forget keys that have been quiet long enough to start over
+	for k, attempts := range s.attempts {
+		if now.Sub(attempts.LastFailureAt) > resetAfter {
+			delete(s.attempts, k)
+		}
+	}
+	attempts := s.attempts[key]
+	attempts.Failures++
+	attempts.LastFailureAt = now
+	s.attempts[key] = attempts
+	return attempts, nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
@@ -0,0 +1,45 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"local/infra/repo"
+	"local/model"
+	"time"
+)
+
+// SQLStore persists failure counters through the repository so every instance shares them
+type SQLStore struct {
+	repo repo.LoginAttemptRepo
+}
+
+func NewSQLStore(attempts repo.LoginAttemptRepo) *SQLStore {
+	return &SQLStore{repo: attempts}
+}
+
+func (s *SQLStore) Get(ctx context.Context, key string) (Attempts, error) {
+	response := s.repo.Get(model.NewRequestContext(ctx), key)
+	if response.Code == model.CodeNotFound {
+		return Attempts{}, nil
+	}
+	if !response.OK() {
+		return Attempts{}, errors.New(response.ErrorString())
+	}
+	return Attempts{Failures: response.Data.Failures, LastFailureAt: response.Data.LastFailureAt}, nil
+}
+
+func (s *SQLStore) RecordFailure(ctx context.Context, key string, now time.Time, resetAfter time.Duration) (Attempts, error) {
+	response := s.repo.RecordFailure(model.NewRequestContext(ctx), key, now, resetAfter)
+	if !response.OK() {
+		return Attempts{}, errors.New(response.ErrorString())
+	}
+	return Attempts{Failures: response.Data.Failures, LastFailureAt: response.Data.LastFailureAt}, nil
+}
+
+func (s *SQLStore) Reset(ctx context.Context, key string) error {
+	response := s.repo.Reset(model.NewRequestContext(ctx), key)
+	if !response.OK() {
+		return errors.New(response.ErrorString())
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package oidc
+
+import "time"
+
+// ExpireKeys lets tests skip the wait before the provider's keys may be fetched again
+func (p *Provider) ExpireKeys() {
+	p.mu.Lock()
+	p.fetchedAt = time.Time{}
+	p.mu.Unlock()
+}
@@ -0,0 +1,159 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// refetchInterval is the least time between two downloads of a provider's keys
+const refetchInterval = time.Minute
+
+// jwk is a public key as published in a provider's JWKS
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC and OKP keys
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+type publicKey struct {
+	kty string
+	alg string
+	key interface{}
+}
+
+// keySet is a provider's signing keys by kid
+type keySet struct {
+	keys map[string]publicKey
+}
+
+// parseKeySet keeps the signing keys it understands and skips the rest, so one
+// key of an unsupported type does not stop logins
+func parseKeySet(document jwksDocument) *keySet {
+	set := &keySet{keys: map[string]publicKey{}}
+	for _, k := range document.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		set.keys[k.Kid] = publicKey{kty: k.Kty, alg: k.Alg, key: key}
+	}
+	return set
+}
+
+// find returns the key named by kid, or the only key when the token names none.
+// The key's type must suit the token's algorithm
+func (s *keySet) find(kid, alg string) (interface{}, error) {
+	key, ok := s.keys[kid]
+	if !ok && kid == "" && len(s.keys) == 1 {
+		for _, only := range s.keys {
+			key, ok = only, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if key.alg != "" && key.alg != alg {
+		return nil, fmt.Errorf("signing key %q is for %s, not %s", kid, key.alg, alg)
+	}
+	if keyType(alg) != key.kty {
+		return nil, fmt.Errorf("signing key %q cannot verify %s", kid, alg)
+	}
+	return key.key, nil
+}
+
+// keyType is the JWK kty that verifies an algorithm
+func keyType(alg string) string {
+	switch {
+	case strings.HasPrefix(alg, "RS"), strings.HasPrefix(alg, "PS"):
+		return "RSA"
+	case strings.HasPrefix(alg, "ES"):
+		return "EC"
+	case alg == "EdDSA":
+		return "OKP"
+	default:
+		return ""
+	}
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("jwk %s: exponent out of range", k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk %s: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("jwk %s: point is not on the curve", k.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk %s: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk %s: invalid Ed25519 key", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("jwk %s: unsupported key type %q", k.Kid, k.Kty)
+	}
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid jwk number")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,311 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"local/config"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// maxResponseBytes caps what is read from the provider's endpoints
+	maxResponseBytes = 1 << 20
+	// clockSkew is how far the provider's clock may be from ours
+	clockSkew = time.Minute
+)
+
+// idTokenMethods are the asymmetric algorithms ID tokens may be signed with; the
+// shared-secret HS* family and "none" are never accepted
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// Claims are the ID token claims a login uses. Subject is the provider's stable
+// id for the account; the rest is profile data the provider may leave out
+type Claims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	AuthorizedParty   string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// discovery is the part of the provider's metadata document a login needs
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is an OpenID Connect identity provider used with the authorization
+// code flow and PKCE. Its metadata and signing keys are fetched on first use, so
+// the service starts even while the provider is unreachable
+type Provider struct {
+	cfg    config.OIDCProviderConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	metadata  *discovery
+	keys      *keySet
+	fetchedAt time.Time
+}
+
+// NewProvider checks the configuration of one provider. A nil client uses a
+// client with a short timeout
+func NewProvider(cfg config.OIDCProviderConfig, client *http.Client) (*Provider, error) {
+	if !namePattern.MatchString(cfg.Name) {
+		return nil, fmt.Errorf("oidc provider %q: names are lower case letters, digits and dashes", cfg.Name)
+	}
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("oidc provider %s: issuer, client id and redirect url are required", cfg.Name)
+	}
+	if _, err := url.Parse(cfg.RedirectURL); err != nil {
+		return nil, fmt.Errorf("oidc provider %s: invalid redirect url: %w", cfg.Name, err)
+	}
+	if cfg.DisplayName == "" {
+		cfg.DisplayName = cfg.Name
+	}
+	if !contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client}, nil
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *Provider) DisplayName() string {
+	return p.cfg.DisplayName
+}
+
+// AuthCodeURL is where the user is sent to sign in. The provider hands state back
+// unchanged and puts nonce into the ID token; only the S256 challenge of the
+// verifier leaves the server
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("oidc provider %s: invalid authorization endpoint: %w", p.cfg.Name, err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+// Exchange redeems an authorization code and returns the raw ID token, which
+// must still be checked with VerifyIDToken
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", verifier)
+	if p.cfg.ClientSecret == "" {
+		// Public clients identify themselves in the body
+		form.Set("client_id", p.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.do(req, &body)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("oidc provider %s: token endpoint returned %d: %s %s", p.cfg.Name, status, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("oidc provider %s: token response has no id_token", p.cfg.Name)
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken checks the ID token's signature against the provider's keys, that
+// it was issued by the provider for this client and has not expired, and that it
+// carries the nonce of the login it answers
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			return p.verificationKey(ctx, token)
+		},
+		jwt.WithValidMethods(idTokenMethods),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc provider %s: invalid id token: %w", p.cfg.Name, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("oidc provider %s: id token has no subject", p.cfg.Name)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("oidc provider %s: id token nonce does not match", p.cfg.Name)
+	}
+	// A token meant for several clients must name us as the one it was issued to
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("oidc provider %s: id token was issued to %q", p.cfg.Name, claims.AuthorizedParty)
+	}
+	return claims, nil
+}
+
+// discover loads the provider's metadata once; failures are retried on the next call
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+	var metadata discovery
+	status, err := p.do(req, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc provider %s: discovery returned %d", p.cfg.Name, status)
+	}
+	// The issuer must be exactly the configured one, or tokens from another tenant could pass
+	if metadata.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc provider %s: discovery names issuer %q, expected %q", p.cfg.Name, metadata.Issuer, p.cfg.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc provider %s: discovery document is incomplete", p.cfg.Name)
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// verificationKey finds the key a token names. An unknown kid refetches the
+// provider's keys, since that is how a rotation shows up, but at most once per
+// refetchInterval so forged kids cannot make us hammer the provider
+func (p *Provider) verificationKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys != nil {
+		if key, err := p.keys.find(kid, token.Method.Alg()); err == nil {
+			return key, nil
+		}
+		if time.Since(p.fetchedAt) < refetchInterval {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.metadata.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var document jwksDocument
+	status, err := p.do(req, &document)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc provider %s: jwks returned %d", p.cfg.Name, status)
+	}
+	p.keys = parseKeySet(document)
+	p.fetchedAt = time.Now()
+	return p.keys.find(kid, token.Method.Alg())
+}
+
+// do sends the request and decodes a JSON body, error responses included
+func (p *Provider) do(req *http.Request, into interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("oidc provider %s: %w", p.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return 0, fmt.Errorf("oidc provider %s: %w", p.cfg.Name, err)
+	}
+	if err := json.Unmarshal(data, into); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("oidc provider %s: invalid response from %s: %w", p.cfg.Name, req.URL.Path, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// RandomString returns 256 random bits, URL safe. It serves as state, nonce and
+// PKCE verifier alike; the alphabet is within what RFC 7636 allows for verifiers
+func RandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge is the RFC 7636 S256 challenge for a verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package oidc_test
+
+import (
+	"context"
+	"local/config"
+	"local/infra/provider/oidc"
+	"local/infra/provider/oidc/oidctest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost:5173/auth/callback"
+
+var alice = oidctest.User{
+	Subject:           "alice-subject",
+	Email:             "alice@example.com",
+	EmailVerified:     true,
+	Name:              "Alice",
+	PreferredUsername: "alice",
+}
+
+func newTestProvider(t *testing.T, clientSecret string) (*oidctest.Server, *oidc.Provider) {
+	server, err := oidctest.NewServer("simple-chat", clientSecret)
+	assert.NoError(t, err)
+	t.Cleanup(server.Close)
+	provider, err := oidc.NewProvider(server.ProviderConfig("corp", redirectURL), nil)
+	assert.NoError(t, err)
+	return server, provider
+}
+
+// signIn runs the browser half of a login and returns the authorization code
+func signIn(t *testing.T, server *oidctest.Server, provider *oidc.Provider, state, nonce, verifier string) string {
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+	assert.NoError(t, err)
+	callback, err := server.SignIn(authURL, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, state, callback.Query().Get("state"))
+	assert.Empty(t, callback.Query().Get("error"))
+	return callback.Query().Get("code")
+}
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	for name, secret := range map[string]string{"confidential client": "s3cret/+", "public client": ""} {
+		t.Run(name, func(t *testing.T) {
+			server, provider := newTestProvider(t, secret)
+			ctx := context.Background()
+			verifier, err := oidc.RandomString()
+			assert.NoError(t, err)
+
+			authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+			assert.NoError(t, err)
+			parsed, err := url.Parse(authURL)
+			assert.NoError(t, err)
+			query := parsed.Query()
+			assert.Equal(t, "code", query.Get("response_type"))
+			assert.Equal(t, "openid profile email", query.Get("scope"))
+			assert.Equal(t, "S256", query.Get("code_challenge_method"))
+			assert.Equal(t, oidc.CodeChallenge(verifier), query.Get("code_challenge"))
+			// The verifier itself never goes through the browser
+			assert.NotContains(t, authURL, verifier)
+
+			code := signIn(t, server, provider, "state-1", "nonce-1", verifier)
+			idToken, err := provider.Exchange(ctx, code, verifier)
+			assert.NoError(t, err)
+
+			claims, err := provider.VerifyIDToken(ctx, idToken, "nonce-1")
+			assert.NoError(t, err)
+			assert.Equal(t, "alice-subject", claims.Subject)
+			assert.Equal(t, "alice@example.com", claims.Email)
+			assert.True(t, claims.EmailVerified)
+			assert.Equal(t, "alice", claims.PreferredUsername)
+
+			// Codes work once
+			_, err = provider.Exchange(ctx, code, verifier)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestProvider_ExchangeRequiresTheVerifier(t *testing.T) {
+	server, provider := newTestProvider(t, "secret")
+	verifier, _ := oidc.RandomString()
+	other, _ := oidc.RandomString()
+
+	code := signIn(t, server, provider, "state", "nonce", verifier)
+	_, err := provider.Exchange(context.Background(), code, other)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestProvider_VerifyIDTokenRejects(t *testing.T) {
+	server, provider := newTestProvider(t, "secret")
+	ctx := context.Background()
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   server.Issuer(),
+			"sub":   "alice-subject",
+			"aud":   "simple-chat",
+			"exp":   now.Add(5 * time.Minute).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "nonce",
+		}
+	}
+	token, err := server.SignIDToken(valid())
+	assert.NoError(t, err)
+	_, err = provider.VerifyIDToken(ctx, token, "nonce")
+	assert.NoError(t, err)
+
+	cases := map[string]func(jwt.MapClaims){
+		"another issuer":          func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"another client":          func(c jwt.MapClaims) { c["aud"] = "other-client" },
+		"expired":                 func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() },
+		"no expiry":               func(c jwt.MapClaims) { delete(c, "exp") },
+		"no subject":              func(c jwt.MapClaims) { delete(c, "sub") },
+		"another login's nonce":   func(c jwt.MapClaims) { c["nonce"] = "other" },
+		"shared without azp":      func(c jwt.MapClaims) { c["aud"] = []string{"simple-chat", "other-client"} },
+		"shared with another azp": func(c jwt.MapClaims) { c["aud"] = []string{"simple-chat", "other-client"}; c["azp"] = "other-client" },
+	}
+	for name, edit := range cases {
+		t.Run(name, func(t *testing.T) {
+			claims := valid()
+			edit(claims)
+			token, err := server.SignIDToken(claims)
+			assert.NoError(t, err)
+			_, err = provider.VerifyIDToken(ctx, token, "nonce")
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		assert.NoError(t, err)
+		_, err = provider.VerifyIDToken(ctx, token, "nonce")
+		assert.Error(t, err)
+	})
+
+	t.Run("signed with a shared secret", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, valid()).SignedString([]byte("secret"))
+		assert.NoError(t, err)
+		_, err = provider.VerifyIDToken(ctx, token, "nonce")
+		assert.Error(t, err)
+	})
+}
+
+func TestProvider_KeyRotation(t *testing.T) {
+	server, provider := newTestProvider(t, "secret")
+	ctx := context.Background()
+	claims := jwt.MapClaims{
+		"iss":   server.Issuer(),
+		"sub":   "alice-subject",
+		"aud":   "simple-chat",
+		"exp":   time.Now().Add(5 * time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": "nonce",
+	}
+	token, _ := server.SignIDToken(claims)
+	_, err := provider.VerifyIDToken(ctx, token, "nonce")
+	assert.NoError(t, err)
+
+	assert.NoError(t, server.RotateKeys())
+	rotated, _ := server.SignIDToken(claims)
+
+	// Keys were fetched moments ago, so an unknown kid does not fetch them again
+	_, err = provider.VerifyIDToken(ctx, rotated, "nonce")
+	assert.Error(t, err)
+
+	provider.ExpireKeys()
+	_, err = provider.VerifyIDToken(ctx, rotated, "nonce")
+	assert.NoError(t, err)
+}
+
+func TestProvider_DiscoveryIssuerMustMatch(t *testing.T) {
+	server, err := oidctest.NewServer("simple-chat", "secret")
+	assert.NoError(t, err)
+	defer server.Close()
+
+	cfg := server.ProviderConfig("corp", redirectURL)
+	cfg.Issuer += "/"
+	provider, err := oidc.NewProvider(cfg, nil)
+	assert.NoError(t, err)
+	_, err = provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	assert.ErrorContains(t, err, "discovery names issuer")
+}
+
+func TestNewRegistryFromConfig(t *testing.T) {
+	saved := config.Config.OIDCProviders
+	defer func() { config.Config.OIDCProviders = saved }()
+
+	corp := config.OIDCProviderConfig{Name: "corp", Issuer: "https://idp.example.com", ClientID: "chat", RedirectURL: redirectURL}
+	partner := config.OIDCProviderConfig{Name: "partner-idp", DisplayName: "Partner", Issuer: "https://partner.example.com", ClientID: "chat", RedirectURL: redirectURL}
+	config.Config.OIDCProviders = []config.OIDCProviderConfig{corp, partner}
+	registry, err := oidc.NewRegistryFromConfig()
+	assert.NoError(t, err)
+	providers := registry.List()
+	assert.Len(t, providers, 2)
+	assert.Equal(t, "corp", providers[0].Name())
+	assert.Equal(t, "corp", providers[0].DisplayName())
+	assert.Equal(t, "Partner", providers[1].DisplayName())
+	_, err = registry.Get("missing")
+	assert.ErrorIs(t, err, oidc.ErrUnknownProvider)
+
+	config.Config.OIDCProviders = []config.OIDCProviderConfig{corp, corp}
+	_, err = oidc.NewRegistryFromConfig()
+	assert.Error(t, err)
+
+	config.Config.OIDCProviders = []config.OIDCProviderConfig{{Name: "Corp", Issuer: "https://idp.example.com", ClientID: "chat", RedirectURL: redirectURL}}
+	_, err = oidc.NewRegistryFromConfig()
+	assert.Error(t, err)
+
+	config.Config.OIDCProviders = []config.OIDCProviderConfig{{Name: "corp", ClientID: "chat", RedirectURL: redirectURL}}
+	_, err = oidc.NewRegistryFromConfig()
+	assert.Error(t, err)
+}
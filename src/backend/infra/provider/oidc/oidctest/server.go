@@ -0,0 +1,266 @@
+// Package oidctest runs an OpenID Connect provider in process, for testing logins
+// without a real identity provider
+package oidctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"local/config"
+	"local/infra/provider/oidc"
+	"local/infra/provider/signing"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// User is the account that signs in at the provider
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// grant is an issued authorization code waiting to be redeemed
+type grant struct {
+	user          User
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+}
+
+// Server implements discovery, the authorization endpoint with PKCE, the token
+// endpoint and the JWKS. Authorization codes work once
+type Server struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+	// EditClaims, when set, changes every ID token's claims before it is signed
+	EditClaims func(claims jwt.MapClaims)
+
+	mu     sync.Mutex
+	keys   *signing.KeySet
+	user   User
+	grants map[string]*grant
+}
+
+// NewServer starts a provider for one client; an empty secret makes it a public client
+func NewServer(clientID, clientSecret string) (*Server, error) {
+	keys, err := signing.Generate()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		keys:         keys,
+		grants:       map[string]*grant{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+	return s, nil
+}
+
+// Issuer is the provider's issuer identifier
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// ProviderConfig configures a client of this server under the given name
+func (s *Server) ProviderConfig(name, redirectURL string) config.OIDCProviderConfig {
+	return config.OIDCProviderConfig{
+		Name:         name,
+		DisplayName:  name,
+		Issuer:       s.Issuer(),
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "profile", "email"},
+	}
+}
+
+// RotateKeys replaces the signing key, as a provider rotating its keys would
+func (s *Server) RotateKeys() error {
+	keys, err := signing.Generate()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// SignIn follows an authorization URL as the user would in a browser and returns
+// the redirect back to the client, carrying the code and state or an error
+func (s *Server) SignIn(authURL string, user User) (*url.URL, error) {
+	s.mu.Lock()
+	s.user = user
+	s.mu.Unlock()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return nil, fmt.Errorf("authorization endpoint returned %d", resp.StatusCode)
+	}
+	return url.Parse(resp.Header.Get("Location"))
+}
+
+// SignIDToken signs arbitrary claims with the server's current key
+func (s *Server) SignIDToken(claims jwt.MapClaims) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys.Sign(claims)
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.Issuer(),
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{jwt.SigningMethodEdDSA.Alg()},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != s.ClientID {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("redirect_uri") == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	back := redirectURI.Query()
+	back.Set("state", query.Get("state"))
+	switch {
+	case query.Get("response_type") != "code":
+		back.Set("error", "unsupported_response_type")
+	case query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256":
+		back.Set("error", "invalid_request")
+	default:
+		code, err := oidc.RandomString()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.mu.Lock()
+		s.grants[code] = &grant{
+			user:          s.user,
+			redirectURI:   query.Get("redirect_uri"),
+			nonce:         query.Get("nonce"),
+			codeChallenge: query.Get("code_challenge"),
+		}
+		s.mu.Unlock()
+		back.Set("code", code)
+	}
+	redirectURI.RawQuery = back.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if !s.authenticateClient(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                s.Issuer(),
+		"sub":                g.user.Subject,
+		"aud":                s.ClientID,
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"iat":                now.Unix(),
+		"nonce":              g.nonce,
+		"email":              g.user.Email,
+		"email_verified":     g.user.EmailVerified,
+		"name":               g.user.Name,
+		"preferred_username": g.user.PreferredUsername,
+	}
+	if s.EditClaims != nil {
+		s.EditClaims(claims)
+	}
+	idToken, err := s.SignIDToken(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	accessToken, _ := oidc.RandomString()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// authenticateClient accepts client_secret_basic for confidential clients and a
+// bare client_id for public ones
+func (s *Server) authenticateClient(r *http.Request) bool {
+	if s.ClientSecret == "" {
+		return r.PostForm.Get("client_id") == s.ClientID
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	id, _ = url.QueryUnescape(id)
+	secret, _ = url.QueryUnescape(secret)
+	return id == s.ClientID && secret == s.ClientSecret
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	set := s.keys.JWKS()
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, set)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
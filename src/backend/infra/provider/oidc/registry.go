@@ -0,0 +1,62 @@
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"local/config"
+)
+
+// ErrUnknownProvider is returned for a provider name that is not configured
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+// Registry holds the configured providers in configuration order
+type Registry struct {
+	providers map[string]*Provider
+	order     []string
+}
+
+func NewRegistry(providers ...*Provider) *Registry {
+	registry := &Registry{providers: make(map[string]*Provider, len(providers))}
+	for _, provider := range providers {
+		if _, ok := registry.providers[provider.Name()]; !ok {
+			registry.order = append(registry.order, provider.Name())
+		}
+		registry.providers[provider.Name()] = provider
+	}
+	return registry
+}
+
+// NewRegistryFromConfig builds the providers listed in OIDC_PROVIDERS; none
+// configured leaves single sign-on switched off
+func NewRegistryFromConfig() (*Registry, error) {
+	providers := make([]*Provider, 0, len(config.Config.OIDCProviders))
+	seen := map[string]bool{}
+	for _, cfg := range config.Config.OIDCProviders {
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("oidc provider %s is configured twice", cfg.Name)
+		}
+		seen[cfg.Name] = true
+		provider, err := NewProvider(cfg, nil)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	return NewRegistry(providers...), nil
+}
+
+func (r *Registry) Get(name string) (*Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+func (r *Registry) List() []*Provider {
+	providers := make([]*Provider, 0, len(r.order))
+	for _, name := range r.order {
+		providers = append(providers, r.providers[name])
+	}
+	return providers
+}
@@ -0,0 +1,90 @@
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"local/config"
+	"local/model"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxBytes is where bcrypt stops reading; longer passwords are refused rather than silently cut
+const maxBytes = 72
+
+// DefaultMinLength applies when no minimum is configured
+const DefaultMinLength = 8
+
+// Policy decides which passwords may be set
+type Policy struct {
+	minLength int
+	breached  map[string]struct{}
+}
+
+// NewPolicy builds a policy; breached lists passwords known from data breaches
+func NewPolicy(minLength int, breached []string) *Policy {
+	if minLength <= 0 {
+		minLength = DefaultMinLength
+	}
+	policy := &Policy{minLength: minLength, breached: make(map[string]struct{}, len(breached))}
+	for _, password := range breached {
+		policy.breached[strings.ToLower(password)] = struct{}{}
+	}
+	return policy
+}
+
+// LoadPolicy applies PASSWORD_MIN_LENGTH and the breached list in PASSWORD_BREACHED_LIST_FILE
+func LoadPolicy() (*Policy, error) {
+	var breached []string
+	if path := config.Config.PasswordBreachedListFile; path != "" {
+		var err error
+		if breached, err = readList(path); err != nil {
+			return nil, fmt.Errorf("read breached password list: %w", err)
+		}
+	}
+	return NewPolicy(config.Config.PasswordMinLength, breached), nil
+}
+
+// readList reads one password per line, skipping blank lines and # comments
+func readList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+	return passwords, scanner.Err()
+}
+
+// Validate lists every rule the password breaks, or nothing when it is acceptable
+func (p *Policy) Validate(userName, password string) []model.Error {
+	var problems []string
+	if utf8.RuneCountInString(password) < p.minLength {
+		problems = append(problems, fmt.Sprintf("Password must be at least %d characters", p.minLength))
+	}
+	if len(password) > maxBytes {
+		problems = append(problems, fmt.Sprintf("Password must be at most %d bytes", maxBytes))
+	}
+	if userName != "" && strings.EqualFold(password, userName) {
+		problems = append(problems, "Password must not match the username")
+	}
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		problems = append(problems, "Password is too common or has appeared in a data breach")
+	}
+
+	errors := make([]model.Error, 0, len(problems))
+	for _, problem := range problems {
+		errors = append(errors, model.Error{Code: model.CodeValidation, Message: problem})
+	}
+	return errors
+}
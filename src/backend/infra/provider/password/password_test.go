@@ -0,0 +1,62 @@
+package password
+
+import (
+	"local/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func messages(policy *Policy, userName, password string) []string {
+	var result []string
+	for _, problem := range policy.Validate(userName, password) {
+		result = append(result, problem.Message)
+	}
+	return result
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	policy := NewPolicy(10, []string{"Password123456"})
+
+	assert.Empty(t, messages(policy, "alice", "correct horse battery"))
+	assert.Equal(t, []string{"Password must be at least 10 characters"}, messages(policy, "alice", "short"))
+	// Length counts characters, not bytes
+	assert.Empty(t, messages(policy, "alice", "ĉĉĉĉĉĉĉĉĉĉ"))
+	assert.Equal(t, []string{"Password must be at most 72 bytes"}, messages(policy, "alice", strings.Repeat("a", 73)))
+	assert.Equal(t, []string{"Password must not match the username"}, messages(policy, "Alice.Smith1", "alice.smith1"))
+	assert.Equal(t, []string{"Password is too common or has appeared in a data breach"}, messages(policy, "alice", "PASSWORD123456"))
+}
+
+func TestLoadPolicy(t *testing.T) {
+	previous := config.Config
+	defer func() { config.Config = previous }()
+
+	list := filepath.Join(t.TempDir(), "breached.txt")
+	assert.NoError(t, os.WriteFile(list, []byte("# common passwords\n\nletmein1234\n  qwertyuiop  \n"), 0o600))
+	config.Config.PasswordMinLength = 0
+	config.Config.PasswordBreachedListFile = list
+
+	policy, err := LoadPolicy()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, policy.Validate("alice", "qwertyuiop"))
+	assert.NotEmpty(t, policy.Validate("alice", "letmein1234"))
+	assert.NotEmpty(t, policy.Validate("alice", "seven77"))
+	assert.Empty(t, policy.Validate("alice", "# common passwords"))
+
+	config.Config.PasswordBreachedListFile = filepath.Join(t.TempDir(), "missing.txt")
+	_, err = LoadPolicy()
+	assert.Error(t, err)
+}
+
+// The bundled list loads and covers the obvious choices
+func TestBundledList(t *testing.T) {
+	passwords, err := readList("../../../config/breached-passwords.txt")
+	assert.NoError(t, err)
+	policy := NewPolicy(DefaultMinLength, passwords)
+	for _, password := range []string{"password", "password123", "12345678", "qwerty123", "iloveyou"} {
+		assert.NotEmpty(t, policy.Validate("", password), password)
+	}
+}
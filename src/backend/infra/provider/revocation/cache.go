@@ -0,0 +1,83 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxCachedLookups bounds the lookup cache; it is emptied when it grows past this
+const maxCachedLookups = 10000
+
+type cachedLookup struct {
+	revoked bool
+	expires time.Time
+}
+
+// CachedStore answers repeated lookups from memory so that authenticating a
+// request does not cost a database round trip every time. Revocations made
+// through this instance apply immediately; ones made by other instances are
+// picked up once the cached answer is older than the TTL.
+type CachedStore struct {
+	next    Store
+	ttl     time.Duration
+	lock    sync.Mutex
+	lookups map[string]cachedLookup
+}
+
+func NewCachedStore(next Store, ttl time.Duration) *CachedStore {
+	return &CachedStore{
+		next:    next,
+		ttl:     ttl,
+		lookups: make(map[string]cachedLookup),
+	}
+}
+
+func (s *CachedStore) RevokeSession(ctx context.Context, userID uint, sessionID string, expiresAt time.Time) error {
+	if err := s.next.RevokeSession(ctx, userID, sessionID, expiresAt); err != nil {
+		return err
+	}
+	s.reset()
+	return nil
+}
+
+func (s *CachedStore) RevokeUser(ctx context.Context, userID uint, before time.Time) error {
+	if err := s.next.RevokeUser(ctx, userID, before); err != nil {
+		return err
+	}
+	s.reset()
+	return nil
+}
+
+func (s *CachedStore) IsRevoked(ctx context.Context, userID uint, sessionID string, issuedAt time.Time) (bool, error) {
+	key := fmt.Sprintf("%d:%s:%d", userID, sessionID, issuedAt.UnixMilli())
+	now := time.Now()
+
+	s.lock.Lock()
+	lookup, ok := s.lookups[key]
+	s.lock.Unlock()
+	if ok && now.Before(lookup.expires) {
+		return lookup.revoked, nil
+	}
+
+	revoked, err := s.next.IsRevoked(ctx, userID, sessionID, issuedAt)
+	if err != nil {
+		return false, err
+	}
+
+	s.lock.Lock()
+	if len(s.lookups) >= maxCachedLookups {
+		s.lookups = make(map[string]cachedLookup)
+	}
+	s.lookups[key] = cachedLookup{revoked: revoked, expires: now.Add(s.ttl)}
+	s.lock.Unlock()
+	return revoked, nil
+}
+
+// reset drops every cached answer; revocations are rare enough that this is cheaper than indexing
+func (s *CachedStore) reset() {
+	s.lock.Lock()
+	s.lookups = make(map[string]cachedLookup)
+	s.lock.Unlock()
+}
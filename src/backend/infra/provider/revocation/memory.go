@@ -0,0 +1,55 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps revocations in process memory. It suits a single instance
+// and tests; revocations are lost on restart.
+type MemoryStore struct {
+	lock     sync.RWMutex
+	sessions map[string]time.Time
+	cutoffs  map[uint]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]time.Time),
+		cutoffs:  make(map[uint]time.Time),
+	}
+}
+
+func (s *MemoryStore) RevokeSession(_ context.Context, _ uint, sessionID string, expiresAt time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// Forget sessions whose tokens have expired on their own
+	now := time.Now()
+	for id, expiry := range s.sessions {
+		if expiry.Before(now) {
+			delete(s.sessions, id)
+		}
+	}
+	s.sessions[sessionID] = expiresAt
+	return nil
+}
+
+func (s *MemoryStore) RevokeUser(_ context.Context, userID uint, before time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.cutoffs[userID] = before
+	return nil
+}
+
+func (s *MemoryStore) IsRevoked(_ context.Context, userID uint, sessionID string, issuedAt time.Time) (bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if _, ok := s.sessions[sessionID]; ok {
+		return true, nil
+	}
+	return revokedBy(s.cutoffs[userID], issuedAt), nil
+}
@@ -0,0 +1,36 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"local/config"
+	"local/infra/repo"
+	"time"
+)
+
+// Store records which login sessions may no longer authenticate. A session is
+// revoked on its own (logout) or through a per-user cutoff that kills every
+// token issued before it (log out all sessions). Another backend such as Redis
+// only has to implement this interface.
+type Store interface {
+	RevokeSession(ctx context.Context, userID uint, sessionID string, expiresAt time.Time) error
+	RevokeUser(ctx context.Context, userID uint, before time.Time) error
+	IsRevoked(ctx context.Context, userID uint, sessionID string, issuedAt time.Time) (bool, error)
+}
+
+// NewStore builds the store selected by REVOCATION_STORE ("mysql" or "memory")
+func NewStore(revocations repo.RevocationRepo) (Store, error) {
+	switch config.Config.RevocationStore {
+	case "", "mysql":
+		return NewCachedStore(NewSQLStore(revocations), config.Config.RevocationCacheTTL), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown revocation store %q", config.Config.RevocationStore)
+	}
+}
+
+// revokedBy reports whether a token issued at issuedAt falls before the user's cutoff
+func revokedBy(cutoff, issuedAt time.Time) bool {
+	return !cutoff.IsZero() && issuedAt.Before(cutoff)
+}
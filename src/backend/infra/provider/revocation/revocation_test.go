@@ -0,0 +1,111 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingStore records how often lookups reach the backing store
+type countingStore struct {
+	*MemoryStore
+	lookups int
+	err     error
+}
+
+func (s *countingStore) IsRevoked(ctx context.Context, userID uint, sessionID string, issuedAt time.Time) (bool, error) {
+	s.lookups++
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.MemoryStore.IsRevoked(ctx, userID, sessionID, issuedAt)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	issuedAt := time.Now()
+
+	revoked, err := store.IsRevoked(ctx, 1, "s1", issuedAt)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	assert.NoError(t, store.RevokeSession(ctx, 1, "s1", issuedAt.Add(time.Hour)))
+	revoked, _ = store.IsRevoked(ctx, 1, "s1", issuedAt)
+	assert.True(t, revoked)
+	revoked, _ = store.IsRevoked(ctx, 1, "s2", issuedAt)
+	assert.False(t, revoked)
+
+	// The cutoff only reaches tokens issued before it
+	assert.NoError(t, store.RevokeUser(ctx, 1, issuedAt.Add(time.Millisecond)))
+	revoked, _ = store.IsRevoked(ctx, 1, "s2", issuedAt)
+	assert.True(t, revoked)
+	revoked, _ = store.IsRevoked(ctx, 1, "s3", issuedAt.Add(time.Second))
+	assert.False(t, revoked)
+	revoked, _ = store.IsRevoked(ctx, 2, "s4", issuedAt)
+	assert.False(t, revoked)
+}
+
+func TestCachedStore(t *testing.T) {
+	ctx := context.Background()
+	issuedAt := time.Now()
+
+	t.Run("answers repeated lookups from memory", func(t *testing.T) {
+		backing := &countingStore{MemoryStore: NewMemoryStore()}
+		store := NewCachedStore(backing, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			revoked, err := store.IsRevoked(ctx, 1, "s1", issuedAt)
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+		}
+		assert.Equal(t, 1, backing.lookups)
+	})
+
+	t.Run("revocations through the cache apply immediately", func(t *testing.T) {
+		backing := &countingStore{MemoryStore: NewMemoryStore()}
+		store := NewCachedStore(backing, time.Minute)
+
+		revoked, _ := store.IsRevoked(ctx, 1, "s1", issuedAt)
+		assert.False(t, revoked)
+
+		assert.NoError(t, store.RevokeSession(ctx, 1, "s1", issuedAt.Add(time.Hour)))
+		revoked, _ = store.IsRevoked(ctx, 1, "s1", issuedAt)
+		assert.True(t, revoked)
+
+		revoked, _ = store.IsRevoked(ctx, 1, "s2", issuedAt)
+		assert.False(t, revoked)
+		assert.NoError(t, store.RevokeUser(ctx, 1, issuedAt.Add(time.Millisecond)))
+		revoked, _ = store.IsRevoked(ctx, 1, "s2", issuedAt)
+		assert.True(t, revoked)
+	})
+
+	t.Run("revocations elsewhere show up after the TTL", func(t *testing.T) {
+		backing := &countingStore{MemoryStore: NewMemoryStore()}
+		store := NewCachedStore(backing, 10*time.Millisecond)
+
+		revoked, _ := store.IsRevoked(ctx, 1, "s1", issuedAt)
+		assert.False(t, revoked)
+
+		// Another instance revokes the session directly in the shared store
+		assert.NoError(t, backing.RevokeSession(ctx, 1, "s1", issuedAt.Add(time.Hour)))
+		time.Sleep(20 * time.Millisecond)
+
+		revoked, _ = store.IsRevoked(ctx, 1, "s1", issuedAt)
+		assert.True(t, revoked)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		backing := &countingStore{MemoryStore: NewMemoryStore(), err: errors.New("database down")}
+		store := NewCachedStore(backing, time.Minute)
+
+		_, err := store.IsRevoked(ctx, 1, "s1", issuedAt)
+		assert.Error(t, err)
+		_, err = store.IsRevoked(ctx, 1, "s1", issuedAt)
+		assert.Error(t, err)
+		assert.Equal(t, 2, backing.lookups)
+	})
+}
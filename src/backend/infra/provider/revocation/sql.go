@@ -0,0 +1,58 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"local/infra/repo"
+	"local/model"
+	"time"
+)
+
+// SQLStore persists revocations through the repository so every instance sees them
+type SQLStore struct {
+	repo repo.RevocationRepo
+}
+
+func NewSQLStore(revocations repo.RevocationRepo) *SQLStore {
+	return &SQLStore{repo: revocations}
+}
+
+func (s *SQLStore) RevokeSession(ctx context.Context, userID uint, sessionID string, expiresAt time.Time) error {
+	response := s.repo.RevokeSession(model.NewRequestContext(ctx), &model.RevokedSession{
+		SessionID: sessionID,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	})
+	if !response.OK() {
+		return errors.New(response.ErrorString())
+	}
+	return nil
+}
+
+func (s *SQLStore) RevokeUser(ctx context.Context, userID uint, before time.Time) error {
+	response := s.repo.RevokeUser(model.NewRequestContext(ctx), userID, before)
+	if !response.OK() {
+		return errors.New(response.ErrorString())
+	}
+	return nil
+}
+
+func (s *SQLStore) IsRevoked(ctx context.Context, userID uint, sessionID string, issuedAt time.Time) (bool, error) {
+	reqCtx := model.NewRequestContext(ctx)
+	sessionResponse := s.repo.IsSessionRevoked(reqCtx, sessionID)
+	if !sessionResponse.OK() {
+		return false, errors.New(sessionResponse.ErrorString())
+	}
+	if sessionResponse.Data {
+		return true, nil
+	}
+
+	cutoffResponse := s.repo.GetUserCutoff(reqCtx, userID)
+	if cutoffResponse.Code == model.CodeNotFound {
+		return false, nil
+	}
+	if !cutoffResponse.OK() {
+		return false, errors.New(cutoffResponse.ErrorString())
+	}
+	return revokedBy(cutoffResponse.Data.RevokedBefore, issuedAt), nil
+}
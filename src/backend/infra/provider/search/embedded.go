@@ -0,0 +1,233 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"local/model"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	opIndex  = "index"
+	opRemove = "remove"
+)
+
+// logEntry is one change to the embedded index, stored as a line of JSON
+type logEntry struct {
+	Op        string    `json:"op"`
+	Document  *Document `json:"document,omitempty"`
+	MessageID uint      `json:"message_id,omitempty"`
+}
+
+// indexedMessage is what the index keeps of a message: its metadata and words, not its content
+type indexedMessage struct {
+	doc   Document
+	terms []string
+}
+
+// EmbeddedIndex is an inverted index from words to messages held in process
+// memory. Opened on a path, every change is appended to a log file there and read
+// back from it, so the consumer that writes the index and the servers that search
+// it share it through the file, and it survives restarts. Without a path it
+// suits a single process and tests.
+type EmbeddedIndex struct {
+	lock     sync.RWMutex
+	messages map[uint]*indexedMessage
+	postings map[string]map[uint]struct{}
+
+	path   string
+	offset int64
+}
+
+func NewEmbeddedIndex() *EmbeddedIndex {
+	return &EmbeddedIndex{
+		messages: make(map[uint]*indexedMessage),
+		postings: make(map[string]map[uint]struct{}),
+	}
+}
+
+// OpenEmbeddedIndex loads the index logged at path, creating its directory if needed
+func OpenEmbeddedIndex(path string) (*EmbeddedIndex, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create search index directory: %w", err)
+	}
+	x := NewEmbeddedIndex()
+	x.path = path
+	if err := x.refresh(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *EmbeddedIndex) Index(_ context.Context, doc Document) error {
+	return x.write(logEntry{Op: opIndex, Document: &doc})
+}
+
+func (x *EmbeddedIndex) Remove(_ context.Context, messageID uint) error {
+	return x.write(logEntry{Op: opRemove, MessageID: messageID})
+}
+
+func (x *EmbeddedIndex) Search(_ context.Context, filter *model.MessageSearchFilter) ([]uint, error) {
+	if err := x.refresh(); err != nil {
+		return nil, err
+	}
+
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	ids := []uint{}
+	if len(filter.Terms) == 0 {
+		return ids, nil
+	}
+	var matches map[uint]struct{}
+	for _, term := range filter.Terms {
+		found := map[uint]struct{}{}
+		for word, messageIDs := range x.postings {
+			if !strings.HasPrefix(word, term) {
+				continue
+			}
+			for id := range messageIDs {
+				if _, ok := matches[id]; matches == nil || ok {
+					found[id] = struct{}{}
+				}
+			}
+		}
+		if len(found) == 0 {
+			return ids, nil
+		}
+		matches = found
+	}
+
+	conversations := make(map[uint]bool, len(filter.ConversationIDs))
+	for _, id := range filter.ConversationIDs {
+		conversations[id] = true
+	}
+	for id := range matches {
+		doc := x.messages[id].doc
+		switch {
+		case !conversations[doc.ConversationID],
+			filter.SenderID != 0 && doc.SenderID != filter.SenderID,
+			filter.From != nil && doc.CreatedAt.Before(*filter.From),
+			filter.To != nil && doc.CreatedAt.After(*filter.To),
+			filter.BeforeID != 0 && id >= filter.BeforeID:
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+	if filter.Limit > 0 && len(ids) > filter.Limit {
+		ids = ids[:filter.Limit]
+	}
+	return ids, nil
+}
+
+// write applies a change, going through the log when the index has one
+func (x *EmbeddedIndex) write(entry logEntry) error {
+	if x.path == "" {
+		x.lock.Lock()
+		defer x.lock.Unlock()
+		x.apply(entry)
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index entry: %w", err)
+	}
+	file, err := os.OpenFile(x.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open search index: %w", err)
+	}
+	// One write per line, so readers never see half of an entry followed by another
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return x.refresh()
+}
+
+// refresh applies the entries appended to the log since it was last read
+func (x *EmbeddedIndex) refresh() error {
+	if x.path == "" {
+		return nil
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	file, err := os.Open(x.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open search index: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(x.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read search index: %w", err)
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line still being written is read on the next refresh
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read search index: %w", err)
+		}
+		x.offset += int64(len(line))
+
+		var entry logEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("corrupt search index entry at offset %d: %w", x.offset-int64(len(line)), err)
+		}
+		x.apply(entry)
+	}
+}
+
+// apply changes the in-memory index; callers hold the write lock
+func (x *EmbeddedIndex) apply(entry logEntry) {
+	switch entry.Op {
+	case opIndex:
+		doc := *entry.Document
+		x.remove(doc.MessageID)
+		terms := Terms(doc.Content)
+		doc.Content = ""
+		x.messages[doc.MessageID] = &indexedMessage{doc: doc, terms: terms}
+		for _, term := range terms {
+			if x.postings[term] == nil {
+				x.postings[term] = make(map[uint]struct{})
+			}
+			x.postings[term][doc.MessageID] = struct{}{}
+		}
+	case opRemove:
+		x.remove(entry.MessageID)
+	}
+}
+
+func (x *EmbeddedIndex) remove(messageID uint) {
+	message, ok := x.messages[messageID]
+	if !ok {
+		return
+	}
+	for _, term := range message.terms {
+		delete(x.postings[term], messageID)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.messages, messageID)
+}
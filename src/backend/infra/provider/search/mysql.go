@@ -0,0 +1,34 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"local/infra/repo"
+	"local/model"
+)
+
+// MySQLIndex searches the messages table through its FULLTEXT index, which MySQL
+// keeps current on every write, so indexing and removing have nothing to do
+type MySQLIndex struct {
+	repo repo.MessageRepo
+}
+
+func NewMySQLIndex(messages repo.MessageRepo) *MySQLIndex {
+	return &MySQLIndex{repo: messages}
+}
+
+func (x *MySQLIndex) Index(_ context.Context, _ Document) error {
+	return nil
+}
+
+func (x *MySQLIndex) Remove(_ context.Context, _ uint) error {
+	return nil
+}
+
+func (x *MySQLIndex) Search(ctx context.Context, filter *model.MessageSearchFilter) ([]uint, error) {
+	response := x.repo.Search(model.NewRequestContext(ctx), filter)
+	if !response.OK() {
+		return nil, errors.New(response.ErrorString())
+	}
+	return response.Data, nil
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"local/config"
+	"local/infra/repo"
+	"local/model"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// snippetLength is how many characters of a long message a highlight shows
+	snippetLength = 160
+	// snippetLead is how many characters before the first match a highlight keeps
+	snippetLead = 40
+)
+
+// Document is the searchable part of a message
+type Document struct {
+	MessageID      uint      `json:"message_id"`
+	ConversationID uint      `json:"conversation_id"`
+	SenderID       uint      `json:"sender_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SearchIndex finds messages by the words they contain. The message consumer
+// keeps it current; another engine only has to implement this interface.
+type SearchIndex interface {
+	// Index adds a message, replacing what was indexed for it before
+	Index(ctx context.Context, doc Document) error
+	Remove(ctx context.Context, messageID uint) error
+	// Search returns the IDs of the matching messages, newest first
+	Search(ctx context.Context, filter *model.MessageSearchFilter) ([]uint, error)
+}
+
+// NewSearchIndex builds the index selected by SEARCH_INDEX ("mysql" or "embedded")
+func NewSearchIndex(messages repo.MessageRepo) (SearchIndex, error) {
+	switch config.Config.SearchIndex {
+	case "", "mysql":
+		return NewMySQLIndex(messages), nil
+	case "embedded":
+		return OpenEmbeddedIndex(config.Config.SearchIndexPath)
+	default:
+		return nil, fmt.Errorf("unknown search index %q", config.Config.SearchIndex)
+	}
+}
+
+// Terms splits text into the lowercase words a search looks for, without repeats
+func Terms(text string) []string {
+	terms := []string{}
+	seen := map[string]bool{}
+	for _, word := range strings.FieldsFunc(text, isSeparator) {
+		term := strings.ToLower(word)
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Highlight HTML-escapes content and wraps each word starting with one of terms
+// in <mark>. Long content is cut to an excerpt around the first match
+func Highlight(content string, terms []string) string {
+	runes := []rune(content)
+	type span struct{ start, end int }
+	var matches []span
+	for i := 0; i < len(runes); {
+		if isSeparator(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && !isSeparator(runes[j]) {
+			j++
+		}
+		word := strings.ToLower(string(runes[i:j]))
+		for _, term := range terms {
+			if strings.HasPrefix(word, term) {
+				matches = append(matches, span{i, j})
+				break
+			}
+		}
+		i = j
+	}
+
+	start, end := 0, len(runes)
+	if len(runes) > snippetLength {
+		if len(matches) > 0 && matches[0].start > snippetLead {
+			start = min(matches[0].start-snippetLead, len(runes)-snippetLength)
+		}
+		end = start + snippetLength
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, match := range matches {
+		if match.start < start || match.start >= end {
+			continue
+		}
+		matchEnd := min(match.end, end)
+		b.WriteString(html.EscapeString(string(runes[pos:match.start])))
+		b.WriteString("<mark>" + html.EscapeString(string(runes[match.start:matchEnd])) + "</mark>")
+		pos = matchEnd
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// isSeparator tells words apart: anything but a letter or a digit ends a word
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,128 @@
+package search
+
+import (
+	"context"
+	"local/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"deploy", "on", "friday", "at", "5pm"}, Terms("Deploy on-Friday? friday at 5pm!"))
+	assert.Equal(t, []string{"xin", "chào"}, Terms("Xin CHÀO"))
+	assert.Empty(t, Terms(" +*-\"() "))
+}
+
+func TestHighlight(t *testing.T) {
+	t.Run("marks words starting with a term", func(t *testing.T) {
+		assert.Equal(t, "<mark>Deploying</mark> on <mark>friday</mark>", Highlight("Deploying on friday", []string{"deploy", "fri"}))
+	})
+
+	t.Run("escapes HTML", func(t *testing.T) {
+		assert.Equal(t, "&lt;b&gt;<mark>bold</mark>&lt;/b&gt; &amp; more", Highlight("<b>bold</b> & more", []string{"bold"}))
+	})
+
+	t.Run("cuts long content around the first match", func(t *testing.T) {
+		content := strings.Repeat("filler ", 50) + "needle " + strings.Repeat("filler ", 50)
+		highlight := Highlight(content, []string{"needle"})
+		assert.True(t, strings.HasPrefix(highlight, "…"))
+		assert.True(t, strings.HasSuffix(highlight, "…"))
+		assert.Contains(t, highlight, "<mark>needle</mark>")
+		assert.Equal(t, snippetLength+2, len([]rune(strings.NewReplacer("<mark>", "", "</mark>", "").Replace(highlight))))
+	})
+}
+
+func indexMessages(t *testing.T, index SearchIndex, createdAt time.Time) {
+	for _, doc := range []Document{
+		{MessageID: 1, ConversationID: 1, SenderID: 1, Content: "Deploy the release on Friday", CreatedAt: createdAt},
+		{MessageID: 2, ConversationID: 1, SenderID: 2, Content: "the deployment failed", CreatedAt: createdAt.Add(time.Hour)},
+		{MessageID: 3, ConversationID: 2, SenderID: 1, Content: "deploy notes", CreatedAt: createdAt.Add(2 * time.Hour)},
+		{MessageID: 4, ConversationID: 1, SenderID: 1, Content: "lunch?", CreatedAt: createdAt.Add(3 * time.Hour)},
+	} {
+		assert.NoError(t, index.Index(context.Background(), doc))
+	}
+}
+
+func TestEmbeddedIndex(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	index := NewEmbeddedIndex()
+	indexMessages(t, index, createdAt)
+
+	search := func(filter model.MessageSearchFilter) []uint {
+		if filter.ConversationIDs == nil {
+			filter.ConversationIDs = []uint{1, 2}
+		}
+		ids, err := index.Search(ctx, &filter)
+		assert.NoError(t, err)
+		return ids
+	}
+
+	t.Run("matches word prefixes, newest first", func(t *testing.T) {
+		assert.Equal(t, []uint{3, 2, 1}, search(model.MessageSearchFilter{Terms: []string{"deploy"}}))
+	})
+
+	t.Run("every term must match", func(t *testing.T) {
+		assert.Equal(t, []uint{1}, search(model.MessageSearchFilter{Terms: []string{"deploy", "fri"}}))
+		assert.Empty(t, search(model.MessageSearchFilter{Terms: []string{"deploy", "lunch"}}))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		terms := []string{"deploy"}
+		from, to := createdAt.Add(30*time.Minute), createdAt.Add(90*time.Minute)
+		assert.Equal(t, []uint{2, 1}, search(model.MessageSearchFilter{Terms: terms, ConversationIDs: []uint{1}}))
+		assert.Equal(t, []uint{3, 1}, search(model.MessageSearchFilter{Terms: terms, SenderID: 1}))
+		assert.Equal(t, []uint{2}, search(model.MessageSearchFilter{Terms: terms, From: &from, To: &to}))
+		assert.Equal(t, []uint{2}, search(model.MessageSearchFilter{Terms: terms, BeforeID: 3, Limit: 1}))
+	})
+
+	t.Run("reindexing replaces and removing drops", func(t *testing.T) {
+		assert.NoError(t, index.Index(ctx, Document{MessageID: 1, ConversationID: 1, SenderID: 1, Content: "rescheduled", CreatedAt: createdAt}))
+		assert.NoError(t, index.Remove(ctx, 3))
+		assert.Equal(t, []uint{2}, search(model.MessageSearchFilter{Terms: []string{"deploy"}}))
+		assert.Equal(t, []uint{1}, search(model.MessageSearchFilter{Terms: []string{"resched"}}))
+	})
+}
+
+func TestEmbeddedIndex_SharedThroughLog(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "search", "messages.log")
+	writer, err := OpenEmbeddedIndex(path)
+	assert.NoError(t, err)
+	reader, err := OpenEmbeddedIndex(path)
+	assert.NoError(t, err)
+	filter := &model.MessageSearchFilter{Terms: []string{"deploy"}, ConversationIDs: []uint{1, 2}}
+
+	// The reader picks up what the writer appended
+	indexMessages(t, writer, time.Now())
+	ids, err := reader.Search(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3, 2, 1}, ids)
+
+	// A half-written line waits until it is complete
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"op":"remove",`)
+	assert.NoError(t, err)
+	ids, err = reader.Search(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3, 2, 1}, ids)
+	_, err = file.WriteString(`"message_id":2}` + "\n")
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	ids, err = reader.Search(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3, 1}, ids)
+
+	// Reopening replays the whole log
+	reopened, err := OpenEmbeddedIndex(path)
+	assert.NoError(t, err)
+	ids, err = reopened.Search(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3, 1}, ids)
+}
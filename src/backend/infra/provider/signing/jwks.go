@@ -0,0 +1,53 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK is the public half of a signing key in RFC 7517 form
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 (OKP) keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publishes every key in the set, retiring ones included, so verifiers keep
+// accepting tokens that were signed before a rotation
+func (s *KeySet) JWKS() *JWKS {
+	set := &JWKS{Keys: make([]JWK, 0, len(s.order))}
+	for _, id := range s.order {
+		set.Keys = append(set.Keys, s.keys[id].JWK())
+	}
+	return set
+}
+
+// JWK describes the key's public half
+func (k *Key) JWK() JWK {
+	jwk := JWK{Use: "sig", Alg: k.Method.Alg(), Kid: k.ID}
+	switch public := k.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
@@ -0,0 +1,261 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"local/config"
+	"local/util/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing
+const minRSABits = 2048
+
+// Key is one asymmetric signing key, identified in token headers by its kid
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+// NewKey wraps an RSA (RS256) or Ed25519 (EdDSA) private key
+func NewKey(id string, private crypto.Signer) (*Key, error) {
+	if id == "" {
+		return nil, fmt.Errorf("signing key needs an id")
+	}
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("signing key %s: RSA keys must be at least %d bits", id, minRSABits)
+		}
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, private: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, private: k}, nil
+	default:
+		return nil, fmt.Errorf("signing key %s: unsupported key type %T", id, private)
+	}
+}
+
+// GenerateKey creates a fresh key for the algorithm ("EdDSA" or "RS256")
+func GenerateKey(id, alg string) (*Key, error) {
+	switch alg {
+	case "", jwt.SigningMethodEdDSA.Alg():
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return NewKey(id, private)
+	case jwt.SigningMethodRS256.Alg():
+		private, err := rsa.GenerateKey(rand.Reader, minRSABits)
+		if err != nil {
+			return nil, err
+		}
+		return NewKey(id, private)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+}
+
+// ParseKey reads a PEM encoded PKCS#8 or PKCS#1 private key
+func ParseKey(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s: no PEM block found", id)
+	}
+	var private any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("signing key %s: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", id, err)
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s: unsupported key type %T", id, private)
+	}
+	return NewKey(id, signer)
+}
+
+// MarshalPEM encodes the private key as PKCS#8 PEM
+func (k *Key) MarshalPEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// Public returns the verification half of the key
+func (k *Key) Public() crypto.PublicKey {
+	return k.private.Public()
+}
+
+// KeySet signs with one active key and verifies with every key it holds, so a
+// new key can take over signing while tokens from the previous one stay valid
+// until they expire and the old key is removed
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+	order  []string
+}
+
+// NewKeySet builds a set signing with activeID, or with the last key when activeID is empty
+func NewKeySet(activeID string, keys ...*Key) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys")
+	}
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		if _, exists := set.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate signing key id %q", key.ID)
+		}
+		set.keys[key.ID] = key
+		set.order = append(set.order, key.ID)
+	}
+	if activeID == "" {
+		activeID = keys[len(keys)-1].ID
+	}
+	active, ok := set.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q not found", activeID)
+	}
+	set.active = active
+	return set, nil
+}
+
+// Generate returns a set with a single new Ed25519 key. Tokens it signs cannot be
+// verified after a restart or by another instance, so it suits tests and local runs
+func Generate() (*KeySet, error) {
+	key, err := GenerateKey(newKeyID(), jwt.SigningMethodEdDSA.Alg())
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySet(key.ID, key)
+}
+
+// ActiveKeyID is the kid new tokens are signed with
+func (s *KeySet) ActiveKeyID() string {
+	return s.active.ID
+}
+
+// Sign issues a token for the claims with the active key and its kid in the header
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.private)
+}
+
+// Keyfunc resolves the verification key named by a token's kid for jwt.Parse. The
+// token's alg must match the key, so an RSA public key can never be used as an HMAC secret
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.Public(), nil
+}
+
+// Methods lists the algorithms of the keys in the set, for jwt.WithValidMethods
+func (s *KeySet) Methods() []string {
+	seen := map[string]bool{}
+	methods := []string{}
+	for _, id := range s.order {
+		alg := s.keys[id].Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// LoadKeySet loads the keys configured by JWT_KEYS_DIR. Every *.pem file in the
+// directory is a private key named by its file name; JWT_ACTIVE_KEY_ID picks the
+// signing key and defaults to the last name in lexical order, so date-named keys
+// rotate by dropping in a new file. An empty directory gets a generated key; with
+// no directory at all the keys only live as long as the process
+func LoadKeySet() (*KeySet, error) {
+	dir := config.Config.JwtKeysDir
+	if dir == "" {
+		logger.Warn(nil, "JWT_KEYS_DIR is not set; signing with a temporary key that is lost on restart")
+		return Generate()
+	}
+
+	keys, err := loadKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		key, err := GenerateKey(time.Now().UTC().Format("20060102-150405"), config.Config.JwtKeyAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeKey(dir, key); err != nil {
+			return nil, err
+		}
+		logger.Warn(nil, "Generated a new JWT signing key", map[string]interface{}{"kid": key.ID, "dir": dir})
+		keys = append(keys, key)
+	}
+	return NewKeySet(config.Config.JwtActiveKeyID, keys...)
+}
+
+func loadKeys(dir string) ([]*Key, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseKey(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func writeKey(dir string, key *Key) error {
+	data, err := key.MarshalPEM()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, key.ID+".pem"), data, 0o600)
+}
+
+func newKeyID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
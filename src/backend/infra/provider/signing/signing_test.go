@@ -0,0 +1,173 @@
+package signing
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"local/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func testClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func parse(set *KeySet, token string) error {
+	_, err := jwt.Parse(token, set.Keyfunc, jwt.WithValidMethods(set.Methods()))
+	return err
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, err := GenerateKey("2026-01", "EdDSA")
+	assert.NoError(t, err)
+	newKey, err := GenerateKey("2026-10", "RS256")
+	assert.NoError(t, err)
+
+	before, err := NewKeySet("", oldKey)
+	assert.NoError(t, err)
+	oldToken, err := before.Sign(testClaims())
+	assert.NoError(t, err)
+
+	// The new key signs while the old one still verifies what it issued
+	after, err := NewKeySet("", oldKey, newKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10", after.ActiveKeyID())
+	assert.ElementsMatch(t, []string{"EdDSA", "RS256"}, after.Methods())
+	assert.NoError(t, parse(after, oldToken))
+
+	newToken, err := after.Sign(testClaims())
+	assert.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10", parsed.Header["kid"])
+	assert.Equal(t, "RS256", parsed.Header["alg"])
+	assert.NoError(t, parse(after, newToken))
+
+	// Once the old key is dropped its tokens stop verifying
+	retired, err := NewKeySet("", newKey)
+	assert.NoError(t, err)
+	assert.Error(t, parse(retired, oldToken))
+	assert.Error(t, parse(before, newToken))
+
+	_, err = NewKeySet("missing", oldKey)
+	assert.Error(t, err)
+	_, err = NewKeySet("", oldKey, oldKey)
+	assert.Error(t, err)
+}
+
+func TestKeySet_RejectsForgedTokens(t *testing.T) {
+	key, err := GenerateKey("rsa-1", "RS256")
+	assert.NoError(t, err)
+	set, err := NewKeySet("", key)
+	assert.NoError(t, err)
+
+	t.Run("HMAC keyed with the public key", func(t *testing.T) {
+		publicDER, err := x509.MarshalPKIXPublicKey(key.Public())
+		assert.NoError(t, err)
+		publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+		token.Header["kid"] = "rsa-1"
+		forged, err := token.SignedString(publicPEM)
+		assert.NoError(t, err)
+		assert.Error(t, parse(set, forged))
+	})
+
+	t.Run("unknown kid", func(t *testing.T) {
+		other, err := Generate()
+		assert.NoError(t, err)
+		token, err := other.Sign(testClaims())
+		assert.NoError(t, err)
+		assert.Error(t, parse(set, token))
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, testClaims())
+		token.Header["kid"] = "rsa-1"
+		forged, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		assert.NoError(t, err)
+		assert.Error(t, parse(set, forged))
+	})
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, err := GenerateKey("rsa-1", "RS256")
+	assert.NoError(t, err)
+	edKey, err := GenerateKey("ed-1", "EdDSA")
+	assert.NoError(t, err)
+	set, err := NewKeySet("ed-1", rsaKey, edKey)
+	assert.NoError(t, err)
+
+	jwks := set.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+
+	rsaJWK := jwks.Keys[0]
+	assert.Equal(t, "RSA", rsaJWK.Kty)
+	assert.Equal(t, "RS256", rsaJWK.Alg)
+	assert.Equal(t, "rsa-1", rsaJWK.Kid)
+	assert.Equal(t, "sig", rsaJWK.Use)
+	assert.Equal(t, "AQAB", rsaJWK.E)
+	assert.Len(t, rsaJWK.N, 342) // 2048 bits, base64url without padding
+
+	edJWK := jwks.Keys[1]
+	assert.Equal(t, "OKP", edJWK.Kty)
+	assert.Equal(t, "Ed25519", edJWK.Crv)
+	assert.Equal(t, "EdDSA", edJWK.Alg)
+	assert.Len(t, edJWK.X, 43)
+	assert.Empty(t, edJWK.N)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	previous := config.Config
+	defer func() { config.Config = previous }()
+	config.Config.JwtKeysDir = dir
+	config.Config.JwtActiveKeyID = ""
+	config.Config.JwtKeyAlgorithm = "EdDSA"
+
+	// An empty directory gets a key that survives the next load
+	first, err := LoadKeySet()
+	assert.NoError(t, err)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.pem"))
+	assert.Len(t, files, 1)
+
+	again, err := LoadKeySet()
+	assert.NoError(t, err)
+	assert.Equal(t, first.ActiveKeyID(), again.ActiveKeyID())
+	token, err := first.Sign(testClaims())
+	assert.NoError(t, err)
+	assert.NoError(t, parse(again, token))
+
+	// A later-named key takes over signing, PKCS#1 RSA files included
+	rsaKey, err := GenerateKey("x", "RS256")
+	assert.NoError(t, err)
+	pkcs1 := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKey.private.(*rsa.PrivateKey)),
+	})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "99999999-rotated.pem"), pkcs1, 0o600))
+
+	rotated, err := LoadKeySet()
+	assert.NoError(t, err)
+	assert.Equal(t, "99999999-rotated", rotated.ActiveKeyID())
+	assert.NoError(t, parse(rotated, token))
+
+	// Unless the active key is pinned
+	config.Config.JwtActiveKeyID = first.ActiveKeyID()
+	pinned, err := LoadKeySet()
+	assert.NoError(t, err)
+	assert.Equal(t, first.ActiveKeyID(), pinned.ActiveKeyID())
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600))
+	_, err = LoadKeySet()
+	assert.Error(t, err)
+}
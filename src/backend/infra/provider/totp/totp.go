@@ -0,0 +1,109 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits, Period and the SHA-1 HMAC are the RFC 6238 defaults every authenticator app supports
+	Digits = 6
+	Period = 30 * time.Second
+	// skew accepts codes from one step either side of now, for clocks that drift
+	skew = 1
+	// secretSize is the 160-bit key length RFC 4226 recommends
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random key, base32 encoded as authenticator apps expect
+func GenerateSecret() (string, error) {
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(buf), nil
+}
+
+// URI is the otpauth:// link an authenticator app enrolls from, usually shown as a QR code
+func URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period/time.Second)))
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Step is the time step a moment falls in
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code for the secret at time t
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, Step(t)), nil
+}
+
+// Validate checks a code against the steps around now and returns the step it
+// matched. Steps up to and including lastStep are refused, so a code that was
+// already accepted cannot be replayed
+func Validate(secret, input string, now time.Time, lastStep int64) (int64, bool, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false, err
+	}
+	input = strings.ReplaceAll(input, " ", "")
+	if len(input) != Digits {
+		return 0, false, nil
+	}
+	current := Step(now)
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if hmac.Equal([]byte(code(key, step)), []byte(input)) {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	return key, nil
+}
+
+// code is the RFC 4226 HOTP value for a counter, truncated to Digits
+func code(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < Digits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%modulo)
+}
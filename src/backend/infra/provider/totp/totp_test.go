@@ -0,0 +1,82 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcSecret is the SHA-1 key from the RFC 6238 appendix B test vectors
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; these are their last 6 digits
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := Code(rfcSecret, time.Unix(unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := Step(now)
+
+	matched, ok, err := Validate(rfcSecret, "005924", now, 0)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	// One step of drift either way is tolerated, two is not
+	previous, _ := Code(rfcSecret, now.Add(-Period))
+	_, ok, _ = Validate(rfcSecret, previous, now, 0)
+	assert.True(t, ok)
+	next, _ := Code(rfcSecret, now.Add(Period))
+	_, ok, _ = Validate(rfcSecret, next, now, 0)
+	assert.True(t, ok)
+	stale, _ := Code(rfcSecret, now.Add(-2*Period))
+	_, ok, _ = Validate(rfcSecret, stale, now, 0)
+	assert.False(t, ok)
+
+	// A code cannot be used again once its step is spent
+	_, ok, _ = Validate(rfcSecret, "005924", now, step)
+	assert.False(t, ok)
+
+	_, ok, _ = Validate(rfcSecret, "005 924", now, 0)
+	assert.True(t, ok)
+	_, ok, _ = Validate(rfcSecret, "05924", now, 0)
+	assert.False(t, ok)
+	_, _, err = Validate("not base32!", "005924", now, 0)
+	assert.Error(t, err)
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	other, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+
+	uri, err := url.Parse(URI("Simple Chat", "alice", secret))
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Simple Chat:alice", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Simple Chat", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"local/model"
+	"local/util/logger"
+
+	"gorm.io/gorm"
+)
+
+type AttachmentRepo interface {
+	Create(reqCtx *model.RequestContext, attachment *model.Attachment) model.Response[*model.Attachment]
+	GetByID(reqCtx *model.RequestContext, id uint) model.Response[*model.Attachment]
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func (r *attachmentRepository) Create(reqCtx *model.RequestContext, attachment *model.Attachment) model.Response[*model.Attachment] {
+	logger.Info(reqCtx, "AttachmentRepo.Create called", map[string]interface{}{
+		"conversation_id": attachment.ConversationID,
+		"uploader_id":     attachment.UploaderID,
+	})
+	if err := r.db.WithContext(reqCtx.Context()).Create(attachment).Error; err != nil {
+		return model.InternalError[*model.Attachment]("Failed to save attachment")
+	}
+	attachment.URL = attachment.DownloadPath()
+	return model.SuccessResponse(attachment, "Attachment uploaded successfully")
+}
+
+func (r *attachmentRepository) GetByID(reqCtx *model.RequestContext, id uint) model.Response[*model.Attachment] {
+	logger.Info(reqCtx, "AttachmentRepo.GetByID called", map[string]interface{}{"attachment_id": id})
+	var attachment model.Attachment
+	if err := r.db.WithContext(reqCtx.Context()).First(&attachment, id).Error; err != nil {
+		return model.NotFound[*model.Attachment]("Attachment not found")
+	}
+	attachment.URL = attachment.DownloadPath()
+	return model.SuccessResponse(&attachment, "Attachment retrieved successfully")
+}
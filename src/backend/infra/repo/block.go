@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"local/model"
+	"local/util/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BlockRepo interface {
+	// Block records the block; blocking a user twice keeps the first record
+	Block(reqCtx *model.RequestContext, blockerID, blockedID uint) model.Response[*model.UserBlock]
+	Unblock(reqCtx *model.RequestContext, blockerID, blockedID uint) model.Response[bool]
+	ListBlocked(reqCtx *model.RequestContext, blockerID uint) model.Response[[]*model.UserBlock]
+	// IsBlockedBetween reports whether either user blocked the other
+	IsBlockedBetween(reqCtx *model.RequestContext, userID, otherID uint) model.Response[bool]
+}
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func (r *blockRepository) Block(reqCtx *model.RequestContext, blockerID, blockedID uint) model.Response[*model.UserBlock] {
+	logger.Info(reqCtx, "BlockRepo.Block called", map[string]interface{}{"blocker_id": blockerID, "blocked_id": blockedID})
+	block := &model.UserBlock{BlockerID: blockerID, BlockedID: blockedID}
+	err := r.db.WithContext(reqCtx.Context()).Clauses(clause.OnConflict{DoNothing: true}).Create(block).Error
+	if err != nil {
+		return model.InternalError[*model.UserBlock]("Failed to block user")
+	}
+	if err := r.db.WithContext(reqCtx.Context()).Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).First(block).Error; err != nil {
+		return model.InternalError[*model.UserBlock]("Failed to block user")
+	}
+	return model.SuccessResponse(block, "User blocked successfully")
+}
+
+func (r *blockRepository) Unblock(reqCtx *model.RequestContext, blockerID, blockedID uint) model.Response[bool] {
+	logger.Info(reqCtx, "BlockRepo.Unblock called", map[string]interface{}{"blocker_id": blockerID, "blocked_id": blockedID})
+	result := r.db.WithContext(reqCtx.Context()).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&model.UserBlock{})
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to unblock user")
+	}
+	if result.RowsAffected == 0 {
+		return model.NotFound[bool]("User is not blocked")
+	}
+	return model.SuccessResponse(true, "User unblocked successfully")
+}
+
+func (r *blockRepository) ListBlocked(reqCtx *model.RequestContext, blockerID uint) model.Response[[]*model.UserBlock] {
+	logger.Info(reqCtx, "BlockRepo.ListBlocked called", map[string]interface{}{"blocker_id": blockerID})
+	blocks := []*model.UserBlock{}
+	err := r.db.WithContext(reqCtx.Context()).
+		Where("blocker_id = ?", blockerID).
+		Order("created_at DESC").
+		Find(&blocks).Error
+	if err != nil {
+		return model.InternalError[[]*model.UserBlock]("Failed to list blocked users")
+	}
+	return model.SuccessResponse(blocks, "Blocked users retrieved successfully")
+}
+
+func (r *blockRepository) IsBlockedBetween(reqCtx *model.RequestContext, userID, otherID uint) model.Response[bool] {
+	var count int64
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error
+	if err != nil {
+		return model.InternalError[bool]("Failed to check blocked users")
+	}
+	return model.SuccessResponse(count > 0, "Block checked successfully")
+}
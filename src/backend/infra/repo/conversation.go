@@ -5,6 +5,7 @@ import (
 	"local/util/logger"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ConversationRepo interface {
@@ -13,8 +14,12 @@ type ConversationRepo interface {
 	Create(reqCtx *model.RequestContext, conversation *model.Conversation) model.Response[*model.Conversation]
 	Update(reqCtx *model.RequestContext, conversation *model.Conversation) model.Response[*model.Conversation]
 	Delete(reqCtx *model.RequestContext, conversation *model.Conversation) model.Response[*model.Conversation]
-	GetByParticipant(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Conversation]
+	GetByParticipant(reqCtx *model.RequestContext, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Conversation]]
 	GetByEntityJoined(reqCtx *model.RequestContext, entityJoined string) model.Response[*model.Conversation]
+	// GetOrCreatePrivate creates a private conversation unless its pair of users
+	// already has one, which is then loaded into conversation. It reports whether
+	// the conversation was created.
+	GetOrCreatePrivate(reqCtx *model.RequestContext, conversation *model.Conversation) model.Response[bool]
 	Count(reqCtx *model.RequestContext) (int64, error)
 }
 
@@ -61,9 +66,33 @@ func (r *conversationRepository) Create(reqCtx *model.RequestContext, conversati
 	return model.SuccessResponse(conversation, "Conversation created successfully")
 }
 
+func (r *conversationRepository) GetOrCreatePrivate(reqCtx *model.RequestContext, conversation *model.Conversation) model.Response[bool] {
+	logger.Info(reqCtx, "ConversationRepo.GetOrCreatePrivate called", map[string]interface{}{"entity_joined": conversation.EntityJoined})
+	if conversation.EntityJoined == nil {
+		return model.BadRequest[bool]("Private conversations need a pair of users")
+	}
+	// The unique entity_joined index settles concurrent creates: the loser inserts
+	// nothing and loads the winner's conversation
+	result := r.db.WithContext(reqCtx.Context()).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "entity_joined"}}, DoNothing: true}).
+		Create(conversation)
+	if result.Error != nil {
+		return model.BadRequest[bool]("Failed to create conversation")
+	}
+	if result.RowsAffected > 0 {
+		return model.SuccessResponse(true, "Conversation created successfully")
+	}
+	var existing model.Conversation
+	if err := r.db.WithContext(reqCtx.Context()).Where("entity_joined = ?", *conversation.EntityJoined).First(&existing).Error; err != nil {
+		return model.InternalError[bool]("Failed to load conversation")
+	}
+	*conversation = existing
+	return model.SuccessResponse(false, "Conversation already exists")
+}
+
 func (r *conversationRepository) Update(reqCtx *model.RequestContext, conversation *model.Conversation) model.Response[*model.Conversation] {
 	logger.Info(reqCtx, "ConversationRepo.Update called", map[string]interface{}{"conversation_id": conversation.ID})
-	err := r.db.WithContext(reqCtx.Context()).Save(conversation).Error
+	err := r.db.WithContext(reqCtx.Context()).Omit(clause.Associations).Save(conversation).Error
 	if err != nil {
 		return model.BadRequest[*model.Conversation]("Failed to update conversation")
 	}
@@ -79,19 +108,52 @@ func (r *conversationRepository) Delete(reqCtx *model.RequestContext, conversati
 	return model.SuccessResponse(conversation, "Conversation deleted successfully")
 }
 
-func (r *conversationRepository) GetByParticipant(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Conversation] {
-	logger.Info(reqCtx, "ConversationRepo.GetByParticipant called", map[string]interface{}{"user_id": userID})
+// GetByParticipant pages through a user's conversations by recent activity.
+// Cursors are conversation IDs; ties on last_message_id are broken by conversation ID.
+func (r *conversationRepository) GetByParticipant(reqCtx *model.RequestContext, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Conversation]] {
+	cursor = cursor.Normalize()
+	logger.Info(reqCtx, "ConversationRepo.GetByParticipant called", map[string]interface{}{
+		"user_id":   userID,
+		"before_id": cursor.BeforeID,
+		"after_id":  cursor.AfterID,
+		"limit":     cursor.Limit,
+	})
 	var conversations []*model.Conversation
-	err := r.db.WithContext(reqCtx.Context()).
+	query := r.db.WithContext(reqCtx.Context()).
 		Preload("Participants.User").
 		Joins("JOIN conversation_participants ON conversations.id = conversation_participants.conversation_id").
-		Where("conversation_participants.user_id = ?", userID).
-		Order("last_message_id DESC").
-		Find(&conversations).Error
+		Where("conversation_participants.user_id = ?", userID)
+
+	cursorID := cursor.BeforeID
+	if cursor.AfterID != 0 {
+		cursorID = cursor.AfterID
+	}
+	if cursorID != 0 {
+		var anchor model.Conversation
+		if err := r.db.WithContext(reqCtx.Context()).Select("id", "last_message_id").First(&anchor, cursorID).Error; err != nil {
+			return model.BadRequest[*model.Page[*model.Conversation]]("Invalid cursor")
+		}
+		if cursor.AfterID != 0 {
+			query = query.Where("(conversations.last_message_id > ? OR (conversations.last_message_id = ? AND conversations.id > ?))",
+				anchor.LastMessageID, anchor.LastMessageID, anchor.ID)
+		} else {
+			query = query.Where("(conversations.last_message_id < ? OR (conversations.last_message_id = ? AND conversations.id < ?))",
+				anchor.LastMessageID, anchor.LastMessageID, anchor.ID)
+		}
+	}
+
+	if cursor.AfterID != 0 {
+		query = query.Order("conversations.last_message_id ASC").Order("conversations.id ASC")
+	} else {
+		query = query.Order("conversations.last_message_id DESC").Order("conversations.id DESC")
+	}
+
+	err := query.Limit(cursor.Limit + 1).Find(&conversations).Error
 	if err != nil {
-		return model.InternalError[[]*model.Conversation]("Failed to get conversations")
+		return model.InternalError[*model.Page[*model.Conversation]]("Failed to get conversations")
 	}
-	return model.SuccessResponse(conversations, "Conversations retrieved successfully")
+	page := model.NewPage(conversations, cursor, func(c *model.Conversation) uint { return c.ID })
+	return model.SuccessResponse(page, "Conversations retrieved successfully")
 }
 
 func (r *conversationRepository) Count(reqCtx *model.RequestContext) (int64, error) {
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"errors"
+	"local/model"
+	"local/util/logger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errIdentityTaken aborts provisioning when the username or the identity was claimed first
+var errIdentityTaken = errors.New("identity or username taken")
+
+type IdentityRepo interface {
+	Get(reqCtx *model.RequestContext, provider, subject string) model.Response[*model.UserIdentity]
+	// CreateWithUser provisions a new user together with its first identity. It fails
+	// with Conflict when the username is taken or the identity was linked meanwhile
+	CreateWithUser(reqCtx *model.RequestContext, user *model.User, identity *model.UserIdentity) model.Response[*model.UserIdentity]
+	// RecordLogin stamps the identity's last login and refreshes its email
+	RecordLogin(reqCtx *model.RequestContext, id uint, email string, at time.Time) model.Response[bool]
+	// SaveLoginState stores a login in flight, dropping ones that have expired
+	SaveLoginState(reqCtx *model.RequestContext, state *model.OIDCLoginState) model.Response[*model.OIDCLoginState]
+	// ConsumeLoginState removes and returns a login in flight; it fails with NotFound
+	// when the state is unknown, already used or expired
+	ConsumeLoginState(reqCtx *model.RequestContext, stateHash string, now time.Time) model.Response[*model.OIDCLoginState]
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func (r *identityRepository) Get(reqCtx *model.RequestContext, provider, subject string) model.Response[*model.UserIdentity] {
+	var identity model.UserIdentity
+	err := r.db.WithContext(reqCtx.Context()).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotFound[*model.UserIdentity]("Identity not found")
+	}
+	if err != nil {
+		return model.InternalError[*model.UserIdentity]("Failed to load identity")
+	}
+	return model.SuccessResponse(&identity, "Identity retrieved successfully")
+}
+
+func (r *identityRepository) CreateWithUser(reqCtx *model.RequestContext, user *model.User, identity *model.UserIdentity) model.Response[*model.UserIdentity] {
+	logger.Info(reqCtx, "IdentityRepo.CreateWithUser called", map[string]interface{}{"username": user.UserName, "provider": identity.Provider})
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errIdentityTaken
+		}
+		identity.UserID = user.ID
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(identity)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errIdentityTaken
+		}
+		return nil
+	})
+	if errors.Is(err, errIdentityTaken) {
+		return model.Conflict[*model.UserIdentity]("Username or identity already exists")
+	}
+	if err != nil {
+		return model.InternalError[*model.UserIdentity]("Failed to create user")
+	}
+	return model.SuccessResponse(identity, "User created successfully")
+}
+
+func (r *identityRepository) RecordLogin(reqCtx *model.RequestContext, id uint, email string, at time.Time) model.Response[bool] {
+	err := r.db.WithContext(reqCtx.Context()).Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"email": email, "last_login_at": at}).Error
+	if err != nil {
+		return model.InternalError[bool]("Failed to record login")
+	}
+	return model.SuccessResponse(true, "Login recorded successfully")
+}
+
+func (r *identityRepository) SaveLoginState(reqCtx *model.RequestContext, state *model.OIDCLoginState) model.Response[*model.OIDCLoginState] {
+	logger.Info(reqCtx, "IdentityRepo.SaveLoginState called", map[string]interface{}{"provider": state.Provider})
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		// Logins that were abandoned at the provider are never consumed
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.OIDCLoginState{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(state).Error
+	})
+	if err != nil {
+		return model.InternalError[*model.OIDCLoginState]("Failed to save login state")
+	}
+	return model.SuccessResponse(state, "Login state saved successfully")
+}
+
+func (r *identityRepository) ConsumeLoginState(reqCtx *model.RequestContext, stateHash string, now time.Time) model.Response[*model.OIDCLoginState] {
+	logger.Info(reqCtx, "IdentityRepo.ConsumeLoginState called")
+	var state model.OIDCLoginState
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+			return err
+		}
+		// Only the request that deletes the row may use it
+		result := tx.Where("state_hash = ?", stateHash).Delete(&model.OIDCLoginState{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !now.Before(state.ExpiresAt)) {
+		return model.NotFound[*model.OIDCLoginState]("Login state not found")
+	}
+	if err != nil {
+		return model.InternalError[*model.OIDCLoginState]("Failed to load login state")
+	}
+	return model.SuccessResponse(&state, "Login state retrieved successfully")
+}
@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"errors"
+	"local/model"
+	"local/util/logger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepo interface {
+	Get(reqCtx *model.RequestContext, key string) model.Response[*model.LoginAttempt]
+	RecordFailure(reqCtx *model.RequestContext, key string, now time.Time, resetAfter time.Duration) model.Response[*model.LoginAttempt]
+	Reset(reqCtx *model.RequestContext, key string) model.Response[bool]
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func (r *loginAttemptRepository) Get(reqCtx *model.RequestContext, key string) model.Response[*model.LoginAttempt] {
+	var attempt model.LoginAttempt
+	err := r.db.WithContext(reqCtx.Context()).Where("`key` = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotFound[*model.LoginAttempt]("No failed logins")
+	}
+	if err != nil {
+		return model.InternalError[*model.LoginAttempt]("Failed to load login attempts")
+	}
+	return model.SuccessResponse(&attempt, "Login attempts retrieved successfully")
+}
+
+// RecordFailure adds one failure to the key, starting over when the previous one
+// is older than resetAfter. The row is locked so concurrent failures all count
+func (r *loginAttemptRepository) RecordFailure(reqCtx *model.RequestContext, key string, now time.Time, resetAfter time.Duration) model.Response[*model.LoginAttempt] {
+	logger.Info(reqCtx, "LoginAttemptRepo.RecordFailure called", map[string]interface{}{"key": key})
+	var attempt model.LoginAttempt
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginAttempt{Key: key, LastFailureAt: now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("`key` = ?", key).
+			First(&attempt).Error; err != nil {
+			return err
+		}
+		if now.Sub(attempt.LastFailureAt) > resetAfter {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		return tx.Model(&attempt).Updates(map[string]interface{}{
+			"failures":        attempt.Failures,
+			"last_failure_at": attempt.LastFailureAt,
+		}).Error
+	})
+	if err != nil {
+		return model.InternalError[*model.LoginAttempt]("Failed to record login attempt")
+	}
+	return model.SuccessResponse(&attempt, "Login attempt recorded successfully")
+}
+
+func (r *loginAttemptRepository) Reset(reqCtx *model.RequestContext, key string) model.Response[bool] {
+	result := r.db.WithContext(reqCtx.Context()).Where("`key` = ?", key).Delete(&model.LoginAttempt{})
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to reset login attempts")
+	}
+	return model.SuccessResponse(result.RowsAffected > 0, "Login attempts reset successfully")
+}
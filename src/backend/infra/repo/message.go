@@ -1,44 +1,352 @@
 package repo
 
 import (
+	"errors"
 	"local/model"
 	"local/util/logger"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type MessageRepo interface {
-	Create(reqCtx *model.RequestContext, message *model.Message) model.Response[*model.Message]
-	GetByConversationID(reqCtx *model.RequestContext, conversationID uint) model.Response[[]*model.Message]
+	// Create saves a message as the last of its conversation, binds its attachment
+	// and stores the deliveries built by outbox, all in one transaction
+	Create(reqCtx *model.RequestContext, message *model.Message, outbox OutboxBuilder) model.Response[*model.Message]
+	GetByID(reqCtx *model.RequestContext, id uint) model.Response[*model.Message]
+	// GetByIDs loads messages newest first, with their reactions flagged for viewerID
+	GetByIDs(reqCtx *model.RequestContext, ids []uint, viewerID uint) model.Response[[]*model.Message]
+	GetByConversationID(reqCtx *model.RequestContext, conversationID, viewerID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]]
+	GetThread(reqCtx *model.RequestContext, rootID, viewerID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]]
+	CountUnread(reqCtx *model.RequestContext, userID uint, conversationIDs []uint) model.Response[map[uint]int64]
+	Edit(reqCtx *model.RequestContext, message *model.Message, editorID uint, content string, outbox OutboxBuilder) model.Response[*model.Message]
+	SoftDelete(reqCtx *model.RequestContext, message *model.Message, outbox OutboxBuilder) model.Response[*model.Message]
 	Count(reqCtx *model.RequestContext) (int64, error)
+	// Search finds message IDs through the FULLTEXT index on content; MySQL only
+	Search(reqCtx *model.RequestContext, filter *model.MessageSearchFilter) model.Response[[]uint]
 }
 
 type messageRepository struct {
 	db *gorm.DB
 }
 
-func (r *messageRepository) Create(reqCtx *model.RequestContext, message *model.Message) model.Response[*model.Message] {
+var errAttachmentSent = errors.New("attachment has already been sent")
+
+func (r *messageRepository) Create(reqCtx *model.RequestContext, message *model.Message, outbox OutboxBuilder) model.Response[*model.Message] {
 	logger.Info(reqCtx, "MessageRepo.Create called", map[string]interface{}{
 		"conversation_id": message.ConversationID,
 		"sender_id": message.SenderID,
 	})
-	err := r.db.WithContext(reqCtx.Context()).Create(message).Error
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		if message.AttachmentID != nil {
+			result := tx.Model(&model.Attachment{}).
+				Where("id = ? AND message_id IS NULL", *message.AttachmentID).
+				Update("message_id", message.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errAttachmentSent
+			}
+			var attachment model.Attachment
+			if err := tx.First(&attachment, *message.AttachmentID).Error; err != nil {
+				return err
+			}
+			attachment.URL = attachment.DownloadPath()
+			message.Attachment = &attachment
+		}
+		// Concurrent messages only ever move the marker forward
+		if err := tx.Model(&model.Conversation{}).
+			Where("id = ? AND last_message_id < ?", message.ConversationID, message.ID).
+			Update("last_message_id", message.ID).Error; err != nil {
+			return err
+		}
+		return saveOutbox(tx, message, outbox)
+	})
+	if errors.Is(err, errAttachmentSent) {
+		return model.BadRequest[*model.Message]("Attachment has already been sent")
+	}
 	if err != nil {
 		return model.BadRequest[*model.Message]("Failed to create message")
 	}
 	return model.SuccessResponse(message, "Message created successfully")
 }
 
-func (r *messageRepository) GetByConversationID(reqCtx *model.RequestContext, conversationID uint) model.Response[[]*model.Message] {
-	logger.Info(reqCtx, "MessageRepo.GetByConversationID called", map[string]interface{}{"conversation_id": conversationID})
-	var messages []*model.Message
-	err := r.db.WithContext(reqCtx.Context()).Where("conversation_id = ?", conversationID).Order("id DESC").Find(&messages).Error
+func (r *messageRepository) GetByID(reqCtx *model.RequestContext, id uint) model.Response[*model.Message] {
+	logger.Info(reqCtx, "MessageRepo.GetByID called", map[string]interface{}{"message_id": id})
+	var message model.Message
+	err := r.db.WithContext(reqCtx.Context()).First(&message, id).Error
 	if err != nil {
+		return model.NotFound[*model.Message]("Message not found")
+	}
+	return model.SuccessResponse(&message, "Message retrieved successfully")
+}
+
+func (r *messageRepository) GetByIDs(reqCtx *model.RequestContext, ids []uint, viewerID uint) model.Response[[]*model.Message] {
+	logger.Info(reqCtx, "MessageRepo.GetByIDs called", map[string]interface{}{"message_ids": ids})
+	messages := []*model.Message{}
+	if len(ids) == 0 {
+		return model.SuccessResponse(messages, "Messages retrieved successfully")
+	}
+	err := r.db.WithContext(reqCtx.Context()).Where("id IN ?", ids).Order("id DESC").Find(&messages).Error
+	if err != nil {
+		return model.InternalError[[]*model.Message]("Failed to get messages")
+	}
+	if err := r.attachReplies(reqCtx, messages); err != nil {
+		return model.InternalError[[]*model.Message]("Failed to get messages")
+	}
+	if err := r.attachReactions(reqCtx, messages, viewerID); err != nil {
+		return model.InternalError[[]*model.Message]("Failed to get messages")
+	}
+	if err := r.attachFiles(reqCtx, messages); err != nil {
 		return model.InternalError[[]*model.Message]("Failed to get messages")
 	}
 	return model.SuccessResponse(messages, "Messages retrieved successfully")
 }
 
+// GetByConversationID lists a page of the conversation's messages; reactions are
+// flagged as reacted_by_me for viewerID.
+func (r *messageRepository) GetByConversationID(reqCtx *model.RequestContext, conversationID, viewerID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
+	cursor = cursor.Normalize()
+	logger.Info(reqCtx, "MessageRepo.GetByConversationID called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"before_id":       cursor.BeforeID,
+		"after_id":        cursor.AfterID,
+		"limit":           cursor.Limit,
+	})
+	query := r.db.WithContext(reqCtx.Context()).Where("conversation_id = ?", conversationID)
+	return r.page(reqCtx, query, viewerID, cursor)
+}
+
+// GetThread lists the replies posted in the thread started by rootID
+func (r *messageRepository) GetThread(reqCtx *model.RequestContext, rootID, viewerID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
+	cursor = cursor.Normalize()
+	logger.Info(reqCtx, "MessageRepo.GetThread called", map[string]interface{}{
+		"root_id":   rootID,
+		"before_id": cursor.BeforeID,
+		"after_id":  cursor.AfterID,
+		"limit":     cursor.Limit,
+	})
+	query := r.db.WithContext(reqCtx.Context()).Where("thread_root_id = ?", rootID)
+	return r.page(reqCtx, query, viewerID, cursor)
+}
+
+// page runs a keyset-paginated message query and fills in reply counts, quote previews and reactions
+func (r *messageRepository) page(reqCtx *model.RequestContext, query *gorm.DB, viewerID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
+	var messages []*model.Message
+	switch {
+	case cursor.AfterID != 0:
+		query = query.Where("id > ?", cursor.AfterID).Order("id ASC")
+	case cursor.BeforeID != 0:
+		query = query.Where("id < ?", cursor.BeforeID).Order("id DESC")
+	default:
+		query = query.Order("id DESC")
+	}
+	err := query.Limit(cursor.Limit + 1).Find(&messages).Error
+	if err != nil {
+		return model.InternalError[*model.Page[*model.Message]]("Failed to get messages")
+	}
+	page := model.NewPage(messages, cursor, func(m *model.Message) uint { return m.ID })
+	if err := r.attachReplies(reqCtx, page.Items); err != nil {
+		return model.InternalError[*model.Page[*model.Message]]("Failed to get messages")
+	}
+	if err := r.attachReactions(reqCtx, page.Items, viewerID); err != nil {
+		return model.InternalError[*model.Page[*model.Message]]("Failed to get messages")
+	}
+	if err := r.attachFiles(reqCtx, page.Items); err != nil {
+		return model.InternalError[*model.Page[*model.Message]]("Failed to get messages")
+	}
+	return model.SuccessResponse(page, "Messages retrieved successfully")
+}
+
+func (r *messageRepository) attachReactions(reqCtx *model.RequestContext, messages []*model.Message, viewerID uint) error {
+	ids := make([]uint, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	summaries, err := summarizeReactions(r.db.WithContext(reqCtx.Context()), ids, viewerID)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		message.Reactions = summaries[message.ID]
+	}
+	return nil
+}
+
+// attachFiles embeds the attachment metadata of attachment messages
+func (r *messageRepository) attachFiles(reqCtx *model.RequestContext, messages []*model.Message) error {
+	ids := []uint{}
+	for _, message := range messages {
+		// Tombstones keep no content, and that includes the file
+		if message.AttachmentID != nil && !message.IsDeleted() {
+			ids = append(ids, *message.AttachmentID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var attachments []*model.Attachment
+	if err := r.db.WithContext(reqCtx.Context()).Where("id IN ?", ids).Find(&attachments).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]*model.Attachment, len(attachments))
+	for _, attachment := range attachments {
+		attachment.URL = attachment.DownloadPath()
+		byID[attachment.ID] = attachment
+	}
+	for _, message := range messages {
+		if message.AttachmentID != nil && !message.IsDeleted() {
+			message.Attachment = byID[*message.AttachmentID]
+		}
+	}
+	return nil
+}
+
+// attachReplies sets the reply count of thread roots and the preview of quoted messages
+func (r *messageRepository) attachReplies(reqCtx *model.RequestContext, messages []*model.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(messages))
+	quotedIDs := []uint{}
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+		if message.ReplyToID != nil {
+			quotedIDs = append(quotedIDs, *message.ReplyToID)
+		}
+	}
+
+	var counts []struct {
+		ThreadRootID uint
+		Replies      int64
+	}
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.Message{}).
+		Select("thread_root_id, COUNT(*) AS replies").
+		Where("thread_root_id IN ?", ids).
+		Where("deleted_at IS NULL").
+		Group("thread_root_id").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+	replies := make(map[uint]int64, len(counts))
+	for _, count := range counts {
+		replies[count.ThreadRootID] = count.Replies
+	}
+
+	previews := map[uint]*model.MessagePreview{}
+	if len(quotedIDs) > 0 {
+		var quoted []*model.Message
+		if err := r.db.WithContext(reqCtx.Context()).Where("id IN ?", quotedIDs).Find(&quoted).Error; err != nil {
+			return err
+		}
+		for _, message := range quoted {
+			previews[message.ID] = model.NewMessagePreview(message)
+		}
+	}
+
+	for _, message := range messages {
+		message.ReplyCount = replies[message.ID]
+		if message.ReplyToID != nil {
+			message.ReplyTo = previews[*message.ReplyToID]
+		}
+	}
+	return nil
+}
+
+// CountUnread counts, per conversation, the messages from other users that arrived
+// after the user's last read marker.
+func (r *messageRepository) CountUnread(reqCtx *model.RequestContext, userID uint, conversationIDs []uint) model.Response[map[uint]int64] {
+	logger.Info(reqCtx, "MessageRepo.CountUnread called", map[string]interface{}{
+		"user_id":          userID,
+		"conversation_ids": conversationIDs,
+	})
+	counts := map[uint]int64{}
+	if len(conversationIDs) == 0 {
+		return model.SuccessResponse(counts, "Unread counts retrieved successfully")
+	}
+
+	var rows []struct {
+		ConversationID uint
+		Unread         int64
+	}
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.Message{}).
+		Select("messages.conversation_id AS conversation_id, COUNT(*) AS unread").
+		Joins("JOIN conversation_participants ON conversation_participants.conversation_id = messages.conversation_id AND conversation_participants.user_id = ?", userID).
+		Where("messages.conversation_id IN ?", conversationIDs).
+		Where("messages.id > conversation_participants.last_read_message_id").
+		Where("messages.sender_id <> ?", userID).
+		Where("messages.deleted_at IS NULL").
+		Group("messages.conversation_id").
+		Scan(&rows).Error
+	if err != nil {
+		return model.InternalError[map[uint]int64]("Failed to count unread messages")
+	}
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Unread
+	}
+	return model.SuccessResponse(counts, "Unread counts retrieved successfully")
+}
+
+// Edit replaces the message content and records the previous content in message_edits
+func (r *messageRepository) Edit(reqCtx *model.RequestContext, message *model.Message, editorID uint, content string, outbox OutboxBuilder) model.Response[*model.Message] {
+	logger.Info(reqCtx, "MessageRepo.Edit called", map[string]interface{}{
+		"message_id": message.ID,
+		"editor_id":  editorID,
+	})
+	editedAt := time.Now()
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.MessageEdit{
+			MessageID:       message.ID,
+			EditorID:        editorID,
+			PreviousContent: message.Content,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(message).Updates(map[string]interface{}{
+			"content":   content,
+			"edited_at": editedAt,
+		}).Error; err != nil {
+			return err
+		}
+		message.Content = content
+		message.EditedAt = &editedAt
+		return saveOutbox(tx, message, outbox)
+	})
+	if err != nil {
+		return model.InternalError[*model.Message]("Failed to edit message")
+	}
+	return model.SuccessResponse(message, "Message edited successfully")
+}
+
+// SoftDelete blanks the message content and stamps deleted_at so it renders as a tombstone
+func (r *messageRepository) SoftDelete(reqCtx *model.RequestContext, message *model.Message, outbox OutboxBuilder) model.Response[*model.Message] {
+	logger.Info(reqCtx, "MessageRepo.SoftDelete called", map[string]interface{}{"message_id": message.ID})
+	deletedAt := time.Now()
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(message).Updates(map[string]interface{}{
+			"content":    "",
+			"deleted_at": deletedAt,
+		}).Error; err != nil {
+			return err
+		}
+		message.Content = ""
+		message.DeletedAt = &deletedAt
+		return saveOutbox(tx, message, outbox)
+	})
+	if err != nil {
+		return model.InternalError[*model.Message]("Failed to delete message")
+	}
+	return model.SuccessResponse(message, "Message deleted successfully")
+}
+
 func (r *messageRepository) Count(reqCtx *model.RequestContext) (int64, error) {
 	logger.Info(reqCtx, "MessageRepo.Count called")
 	var count int64
@@ -49,6 +357,46 @@ func (r *messageRepository) Count(reqCtx *model.RequestContext) (int64, error) {
 	return count, nil
 }
 
+// Search requires every term as a word prefix in boolean mode. Words shorter than
+// innodb_ft_min_token_size and stopwords are not in the index and so never match
+func (r *messageRepository) Search(reqCtx *model.RequestContext, filter *model.MessageSearchFilter) model.Response[[]uint] {
+	logger.Info(reqCtx, "MessageRepo.Search called", map[string]interface{}{
+		"terms":            filter.Terms,
+		"conversation_ids": filter.ConversationIDs,
+		"before_id":        filter.BeforeID,
+		"limit":            filter.Limit,
+	})
+	ids := []uint{}
+	if len(filter.Terms) == 0 || len(filter.ConversationIDs) == 0 {
+		return model.SuccessResponse(ids, "Messages searched successfully")
+	}
+	// Terms hold only letters and digits, so none of them is read as an operator
+	against := make([]string, 0, len(filter.Terms))
+	for _, term := range filter.Terms {
+		against = append(against, "+"+term+"*")
+	}
+	query := r.db.WithContext(reqCtx.Context()).Model(&model.Message{}).
+		Where("MATCH (content) AGAINST (? IN BOOLEAN MODE)", strings.Join(against, " ")).
+		Where("conversation_id IN ?", filter.ConversationIDs).
+		Where("deleted_at IS NULL")
+	if filter.SenderID != 0 {
+		query = query.Where("sender_id = ?", filter.SenderID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+	if err := query.Order("id DESC").Limit(filter.Limit).Pluck("id", &ids).Error; err != nil {
+		return model.InternalError[[]uint]("Failed to search messages")
+	}
+	return model.SuccessResponse(ids, "Messages searched successfully")
+}
+
 func NewMessageRepository(db *gorm.DB) (MessageRepo, error) {
 	return &messageRepository{db: db}, nil
 }
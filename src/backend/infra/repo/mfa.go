@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"errors"
+	"local/model"
+	"local/util/logger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errMFAAlreadyEnabled aborts confirming an enrollment that another request confirmed first
+var errMFAAlreadyEnabled = errors.New("mfa already enabled")
+
+type MFARepo interface {
+	Get(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserMFA]
+	// SavePending starts an enrollment, replacing any earlier one that was never confirmed
+	SavePending(reqCtx *model.RequestContext, userID uint, secret string) model.Response[*model.UserMFA]
+	// Enable confirms the enrollment with the step of its first code and stores the recovery code hashes
+	Enable(reqCtx *model.RequestContext, userID uint, step int64, enabledAt time.Time, codeHashes []string) model.Response[*model.UserMFA]
+	// UseStep spends a time step; it fails with Conflict when that step or a later one was already used
+	UseStep(reqCtx *model.RequestContext, userID uint, step int64) model.Response[bool]
+	// UseRecoveryCode spends a recovery code; it fails with NotFound when the code is unknown or used
+	UseRecoveryCode(reqCtx *model.RequestContext, userID uint, codeHash string, usedAt time.Time) model.Response[bool]
+	Delete(reqCtx *model.RequestContext, userID uint) model.Response[bool]
+}
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func (r *mfaRepository) Get(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserMFA] {
+	var mfa model.UserMFA
+	err := r.db.WithContext(reqCtx.Context()).Where("user_id = ?", userID).First(&mfa).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotFound[*model.UserMFA]("Two-factor authentication is not set up")
+	}
+	if err != nil {
+		return model.InternalError[*model.UserMFA]("Failed to load two-factor authentication")
+	}
+	return model.SuccessResponse(&mfa, "Two-factor authentication retrieved successfully")
+}
+
+func (r *mfaRepository) SavePending(reqCtx *model.RequestContext, userID uint, secret string) model.Response[*model.UserMFA] {
+	logger.Info(reqCtx, "MFARepo.SavePending called", map[string]interface{}{"user_id": userID})
+	mfa := &model.UserMFA{UserID: userID, Secret: secret}
+	result := r.db.WithContext(reqCtx.Context()).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		// An enabled secret is never overwritten; disable it first
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_mfa.enabled_at IS NULL"}}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"secret": secret, "last_used_step": 0, "updated_at": time.Now()}),
+	}).Create(mfa)
+	if result.Error != nil {
+		return model.InternalError[*model.UserMFA]("Failed to save two-factor authentication")
+	}
+	if result.RowsAffected == 0 {
+		return model.Conflict[*model.UserMFA]("Two-factor authentication is already enabled")
+	}
+	return model.SuccessResponse(mfa, "Two-factor authentication enrollment started")
+}
+
+func (r *mfaRepository) Enable(reqCtx *model.RequestContext, userID uint, step int64, enabledAt time.Time, codeHashes []string) model.Response[*model.UserMFA] {
+	logger.Info(reqCtx, "MFARepo.Enable called", map[string]interface{}{"user_id": userID})
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.UserMFA{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": enabledAt, "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errMFAAlreadyEnabled
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]*model.MFARecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, &model.MFARecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+	if errors.Is(err, errMFAAlreadyEnabled) {
+		return model.Conflict[*model.UserMFA]("Two-factor authentication is already enabled")
+	}
+	if err != nil {
+		return model.InternalError[*model.UserMFA]("Failed to enable two-factor authentication")
+	}
+	return r.Get(reqCtx, userID)
+}
+
+func (r *mfaRepository) UseStep(reqCtx *model.RequestContext, userID uint, step int64) model.Response[bool] {
+	result := r.db.WithContext(reqCtx.Context()).Model(&model.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to record two-factor code")
+	}
+	if result.RowsAffected == 0 {
+		return model.Conflict[bool]("Code has already been used")
+	}
+	return model.SuccessResponse(true, "Two-factor code recorded successfully")
+}
+
+func (r *mfaRepository) UseRecoveryCode(reqCtx *model.RequestContext, userID uint, codeHash string, usedAt time.Time) model.Response[bool] {
+	logger.Info(reqCtx, "MFARepo.UseRecoveryCode called", map[string]interface{}{"user_id": userID})
+	result := r.db.WithContext(reqCtx.Context()).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to use recovery code")
+	}
+	if result.RowsAffected == 0 {
+		return model.NotFound[bool]("Recovery code not found")
+	}
+	return model.SuccessResponse(true, "Recovery code used successfully")
+}
+
+func (r *mfaRepository) Delete(reqCtx *model.RequestContext, userID uint) model.Response[bool] {
+	logger.Info(reqCtx, "MFARepo.Delete called", map[string]interface{}{"user_id": userID})
+	var deleted int64
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("user_id = ?", userID).Delete(&model.UserMFA{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return model.InternalError[bool]("Failed to disable two-factor authentication")
+	}
+	return model.SuccessResponse(deleted > 0, "Two-factor authentication disabled successfully")
+}
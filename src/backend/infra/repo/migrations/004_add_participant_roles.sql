@@ -0,0 +1,5 @@
+-- Migration: Add group conversation roles to conversation_participants
+-- Date: 2026-10-17
+
+ALTER TABLE `conversation_participants`
+  ADD COLUMN `role` varchar(32) NOT NULL DEFAULT 'member' COMMENT 'owner, admin, member' AFTER `user_id`;
@@ -0,0 +1,4 @@
+-- Migration: Composite index backing cursor pagination of message history
+-- Date: 2026-10-17
+
+CREATE INDEX `idx_messages_conversation_id_id` ON `messages` (`conversation_id`, `id`);
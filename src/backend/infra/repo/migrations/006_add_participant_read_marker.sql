@@ -0,0 +1,5 @@
+-- Migration: Track the last message each participant has read
+-- Date: 2026-10-17
+
+ALTER TABLE `conversation_participants`
+  ADD COLUMN `last_read_message_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER `joined_at`;
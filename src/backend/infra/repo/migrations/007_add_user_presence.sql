@@ -0,0 +1,6 @@
+-- Migration: Persist online presence and last-seen time on users
+-- Date: 2026-10-17
+
+ALTER TABLE `users`
+  ADD COLUMN `online` tinyint(1) NOT NULL DEFAULT 0 AFTER `password`,
+  ADD COLUMN `last_seen_at` datetime(3) NULL DEFAULT NULL AFTER `online`;
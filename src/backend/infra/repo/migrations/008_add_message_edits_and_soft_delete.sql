@@ -0,0 +1,16 @@
+-- Migration: Message editing history and soft deletion
+-- Date: 2026-10-17
+
+ALTER TABLE `messages`
+  ADD COLUMN `edited_at` datetime(3) NULL DEFAULT NULL AFTER `updated_at`,
+  ADD COLUMN `deleted_at` datetime(3) NULL DEFAULT NULL AFTER `edited_at`;
+
+CREATE TABLE IF NOT EXISTS `message_edits` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `message_id` bigint unsigned NOT NULL,
+  `editor_id` bigint unsigned NOT NULL,
+  `previous_content` text NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_message_edits_message_id` (`message_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,7 @@
+-- Migration: Threaded replies and quoted messages
+-- Date: 2026-10-17
+
+ALTER TABLE `messages`
+  ADD COLUMN `reply_to_id` bigint unsigned NULL DEFAULT NULL AFTER `deleted_at`,
+  ADD COLUMN `thread_root_id` bigint unsigned NULL DEFAULT NULL AFTER `reply_to_id`,
+  ADD KEY `idx_messages_thread_root_id` (`thread_root_id`);
@@ -0,0 +1,12 @@
+-- Migration: Emoji reactions on messages
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `message_reactions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `message_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `emoji` varchar(32) NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_message_reactions_message_user_emoji` (`message_id`, `user_id`, `emoji`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,20 @@
+-- Migration: File and image attachments
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `attachments` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `conversation_id` bigint unsigned NOT NULL,
+  `uploader_id` bigint unsigned NOT NULL,
+  `message_id` bigint unsigned NULL DEFAULT NULL,
+  `file_name` varchar(255) NOT NULL,
+  `content_type` varchar(255) NOT NULL,
+  `size` bigint NOT NULL,
+  `storage_key` varchar(255) NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_attachments_conversation_id` (`conversation_id`),
+  KEY `idx_attachments_message_id` (`message_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `messages`
+  ADD COLUMN `attachment_id` bigint unsigned NULL DEFAULT NULL AFTER `thread_root_id`;
@@ -0,0 +1,19 @@
+-- Migration: Token revocation for logout and "log out all sessions"
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `revoked_sessions` (
+  `session_id` varchar(64) NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`session_id`),
+  KEY `idx_revoked_sessions_user_id` (`user_id`),
+  KEY `idx_revoked_sessions_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `user_token_cutoffs` (
+  `user_id` bigint unsigned NOT NULL,
+  `revoked_before` datetime(3) NOT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
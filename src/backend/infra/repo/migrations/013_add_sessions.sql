@@ -0,0 +1,24 @@
+-- Migration: Login sessions with rotating refresh tokens
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `sessions` (
+  `id` varchar(64) NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `refresh_token_hash` varchar(64) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `revoked_at` datetime(3) NULL DEFAULT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_sessions_refresh_token_hash` (`refresh_token_hash`),
+  KEY `idx_sessions_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `rotated_refresh_tokens` (
+  `token_hash` varchar(64) NOT NULL,
+  `session_id` varchar(64) NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `rotated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`token_hash`),
+  KEY `idx_rotated_refresh_tokens_session_id` (`session_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
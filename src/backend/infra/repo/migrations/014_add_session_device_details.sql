@@ -0,0 +1,7 @@
+-- Migration: Device details for session management
+-- Date: 2026-10-17
+
+ALTER TABLE `sessions`
+  ADD COLUMN `user_agent` varchar(512) NULL DEFAULT NULL AFTER `refresh_token_hash`,
+  ADD COLUMN `ip_address` varchar(64) NULL DEFAULT NULL AFTER `user_agent`,
+  ADD COLUMN `last_used_at` datetime(3) NULL DEFAULT NULL AFTER `ip_address`;
@@ -0,0 +1,11 @@
+-- Migration: Failed login counters for account and address lockout
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `login_attempts` (
+  `key` varchar(191) NOT NULL,
+  `failures` bigint NOT NULL DEFAULT 0,
+  `last_failure_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`key`),
+  KEY `idx_login_attempts_last_failure_at` (`last_failure_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,23 @@
+-- Migration: TOTP two-factor authentication and recovery codes
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `user_mfa` (
+  `user_id` bigint unsigned NOT NULL,
+  `secret` varchar(64) NOT NULL,
+  `enabled_at` datetime(3) NULL DEFAULT NULL,
+  `last_used_step` bigint NOT NULL DEFAULT 0,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `mfa_recovery_codes` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `code_hash` varchar(64) NOT NULL,
+  `used_at` datetime(3) NULL DEFAULT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_mfa_recovery_codes_code_hash` (`code_hash`),
+  KEY `idx_mfa_recovery_codes_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,27 @@
+-- Migration: External identities for OpenID Connect single sign-on
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `user_identities` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `provider` varchar(64) NOT NULL,
+  `subject` varchar(255) NOT NULL,
+  `email` varchar(255) DEFAULT NULL,
+  `last_login_at` datetime(3) NULL DEFAULT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_identities_provider_subject` (`provider`, `subject`),
+  KEY `idx_user_identities_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `oidc_login_states` (
+  `state_hash` varchar(64) NOT NULL,
+  `provider` varchar(64) NOT NULL,
+  `code_verifier` varchar(128) NOT NULL,
+  `nonce` varchar(128) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`state_hash`),
+  KEY `idx_oidc_login_states_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
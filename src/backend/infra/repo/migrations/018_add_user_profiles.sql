@@ -0,0 +1,9 @@
+-- Migration: User profiles with display name, avatar, bio and custom status
+-- Date: 2026-10-17
+
+ALTER TABLE `users`
+  ADD COLUMN `display_name` varchar(64) NOT NULL DEFAULT '' AFTER `password`,
+  ADD COLUMN `avatar_attachment_id` bigint unsigned NULL DEFAULT NULL AFTER `display_name`,
+  ADD COLUMN `bio` varchar(500) NOT NULL DEFAULT '' AFTER `avatar_attachment_id`,
+  ADD COLUMN `status_text` varchar(100) NOT NULL DEFAULT '' AFTER `bio`,
+  ADD COLUMN `status_expires_at` datetime(3) NULL DEFAULT NULL AFTER `status_text`;
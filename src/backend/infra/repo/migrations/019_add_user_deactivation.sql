@@ -0,0 +1,6 @@
+-- Migration: Deactivated users, hidden from the user directory
+-- Date: 2026-10-17
+
+ALTER TABLE `users`
+  ADD COLUMN `deactivated_at` datetime(3) NULL DEFAULT NULL AFTER `status_expires_at`,
+  ADD KEY `idx_users_deactivated_at` (`deactivated_at`);
@@ -0,0 +1,13 @@
+-- Migration: Blocked users and muted conversations
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `user_blocks` (
+  `blocker_id` bigint unsigned NOT NULL,
+  `blocked_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`blocker_id`, `blocked_id`),
+  KEY `idx_user_blocks_blocked_id` (`blocked_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `conversation_participants`
+  ADD COLUMN `muted_until` datetime(3) NULL DEFAULT NULL AFTER `last_read_message_id`;
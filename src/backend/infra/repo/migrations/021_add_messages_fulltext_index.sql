@@ -0,0 +1,7 @@
+-- Migration: Full-text index for message search
+-- Date: 2026-10-17
+
+-- Used when SEARCH_INDEX=mysql. Words shorter than innodb_ft_min_token_size (3 by
+-- default) are not indexed
+ALTER TABLE `messages`
+  ADD FULLTEXT INDEX `idx_messages_content_fulltext` (`content`);
@@ -0,0 +1,17 @@
+-- Migration: Transactional outbox for message events and socket broadcasts
+-- Date: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS `outbox` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `destination` varchar(16) NOT NULL,
+  `event_key` varchar(64) NOT NULL,
+  `payload` mediumtext NOT NULL,
+  `attempts` bigint NOT NULL DEFAULT 0,
+  `next_attempt_at` datetime(3) NOT NULL,
+  `delivered_at` datetime(3) NULL DEFAULT NULL,
+  `failed_at` datetime(3) NULL DEFAULT NULL,
+  `last_error` varchar(512) DEFAULT NULL,
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_outbox_due` (`delivered_at`, `failed_at`, `next_attempt_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,21 @@
+-- Migration: One private conversation per pair of users
+-- Date: 2026-10-17
+
+-- Group conversations have no pair
+UPDATE `conversations` SET `entity_joined` = NULL WHERE `entity_joined` = '';
+
+-- Concurrent creates may have left a pair with several conversations. The oldest
+-- keeps the pair; the others stay reachable through their participants
+UPDATE `conversations` c
+JOIN (
+  SELECT `entity_joined`, MIN(`id`) AS `keep_id`
+  FROM `conversations`
+  WHERE `entity_joined` IS NOT NULL
+  GROUP BY `entity_joined`
+  HAVING COUNT(*) > 1
+) d ON c.`entity_joined` = d.`entity_joined` AND c.`id` <> d.`keep_id`
+SET c.`entity_joined` = NULL;
+
+ALTER TABLE `conversations`
+  MODIFY `entity_joined` varchar(64) DEFAULT NULL,
+  ADD UNIQUE KEY `idx_conversations_entity_joined` (`entity_joined`);
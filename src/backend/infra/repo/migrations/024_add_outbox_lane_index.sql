@@ -0,0 +1,5 @@
+-- Migration: Look up the pending deliveries of one conversation in the outbox
+-- Date: 2026-10-17
+
+ALTER TABLE `outbox`
+  ADD KEY `idx_outbox_lane` (`destination`, `event_key`);
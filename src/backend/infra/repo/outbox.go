@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"local/model"
+	"local/util/logger"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxBuilder builds the deliveries that announce a saved message. Repositories
+// call it inside the transaction that saves the message and store its result there.
+type OutboxBuilder func(message *model.Message) ([]*model.OutboxEvent, error)
+
+type OutboxRepo interface {
+	// ListDue returns the pending deliveries whose next attempt is due, oldest first
+	ListDue(reqCtx *model.RequestContext, now time.Time, limit int) model.Response[[]*model.OutboxEvent]
+	// HasPendingBefore reports whether an older delivery with the same destination
+	// and key is still to be done, including one waiting out its retry delay
+	HasPendingBefore(reqCtx *model.RequestContext, event *model.OutboxEvent) model.Response[bool]
+	// Claim leases a delivery until leaseUntil; it fails when someone claimed it since it was read
+	Claim(reqCtx *model.RequestContext, event *model.OutboxEvent, leaseUntil time.Time) model.Response[bool]
+	MarkDelivered(reqCtx *model.RequestContext, id uint, deliveredAt time.Time) model.Response[bool]
+	// MarkRetry schedules another attempt after a failed one
+	MarkRetry(reqCtx *model.RequestContext, id uint, nextAttemptAt time.Time, lastError string) model.Response[bool]
+	// MarkFailed gives a delivery up for good
+	MarkFailed(reqCtx *model.RequestContext, id uint, failedAt time.Time, lastError string) model.Response[bool]
+	// PurgeDelivered deletes deliveries completed before the given time
+	PurgeDelivered(reqCtx *model.RequestContext, before time.Time) model.Response[int64]
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// maxOutboxErrorLength fits last_error
+const maxOutboxErrorLength = 512
+
+// saveOutbox stores the deliveries built for message within tx
+func saveOutbox(tx *gorm.DB, message *model.Message, outbox OutboxBuilder) error {
+	if outbox == nil {
+		return nil
+	}
+	events, err := outbox(message)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(&events).Error
+}
+
+func (r *outboxRepository) ListDue(reqCtx *model.RequestContext, now time.Time, limit int) model.Response[[]*model.OutboxEvent] {
+	logger.Info(reqCtx, "OutboxRepo.ListDue called", map[string]interface{}{"limit": limit})
+	events := []*model.OutboxEvent{}
+	err := r.db.WithContext(reqCtx.Context()).
+		Where("delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return model.InternalError[[]*model.OutboxEvent]("Failed to list outbox")
+	}
+	return model.SuccessResponse(events, "Outbox listed successfully")
+}
+
+func (r *outboxRepository) HasPendingBefore(reqCtx *model.RequestContext, event *model.OutboxEvent) model.Response[bool] {
+	logger.Info(reqCtx, "OutboxRepo.HasPendingBefore called", map[string]interface{}{"outbox_id": event.ID})
+	var count int64
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.OutboxEvent{}).
+		Where("destination = ? AND event_key = ? AND id < ?", event.Destination, event.Key, event.ID).
+		Where("delivered_at IS NULL AND failed_at IS NULL").
+		Count(&count).Error
+	if err != nil {
+		return model.InternalError[bool]("Failed to check outbox")
+	}
+	return model.SuccessResponse(count > 0, "Outbox checked successfully")
+}
+
+func (r *outboxRepository) Claim(reqCtx *model.RequestContext, event *model.OutboxEvent, leaseUntil time.Time) model.Response[bool] {
+	logger.Info(reqCtx, "OutboxRepo.Claim called", map[string]interface{}{"outbox_id": event.ID, "attempts": event.Attempts})
+	// Every claim bumps attempts, so the row always changes and RowsAffected tells
+	// whether this claim won
+	result := r.db.WithContext(reqCtx.Context()).
+		Model(&model.OutboxEvent{}).
+		Where("id = ? AND attempts = ? AND delivered_at IS NULL AND failed_at IS NULL", event.ID, event.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+		})
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to claim outbox event")
+	}
+	if result.RowsAffected == 0 {
+		return model.SuccessResponse(false, "Outbox event claimed elsewhere")
+	}
+	event.Attempts++
+	event.NextAttemptAt = leaseUntil
+	return model.SuccessResponse(true, "Outbox event claimed")
+}
+
+func (r *outboxRepository) MarkDelivered(reqCtx *model.RequestContext, id uint, deliveredAt time.Time) model.Response[bool] {
+	logger.Info(reqCtx, "OutboxRepo.MarkDelivered called", map[string]interface{}{"outbox_id": id})
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"delivered_at": deliveredAt, "last_error": ""}).Error
+	if err != nil {
+		return model.InternalError[bool]("Failed to update outbox event")
+	}
+	return model.SuccessResponse(true, "Outbox event delivered")
+}
+
+func (r *outboxRepository) MarkRetry(reqCtx *model.RequestContext, id uint, nextAttemptAt time.Time, lastError string) model.Response[bool] {
+	logger.Info(reqCtx, "OutboxRepo.MarkRetry called", map[string]interface{}{"outbox_id": id, "next_attempt_at": nextAttemptAt})
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"next_attempt_at": nextAttemptAt, "last_error": truncateOutboxError(lastError)}).Error
+	if err != nil {
+		return model.InternalError[bool]("Failed to update outbox event")
+	}
+	return model.SuccessResponse(true, "Outbox event rescheduled")
+}
+
+func (r *outboxRepository) MarkFailed(reqCtx *model.RequestContext, id uint, failedAt time.Time, lastError string) model.Response[bool] {
+	logger.Info(reqCtx, "OutboxRepo.MarkFailed called", map[string]interface{}{"outbox_id": id})
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"failed_at": failedAt, "last_error": truncateOutboxError(lastError)}).Error
+	if err != nil {
+		return model.InternalError[bool]("Failed to update outbox event")
+	}
+	return model.SuccessResponse(true, "Outbox event given up")
+}
+
+func (r *outboxRepository) PurgeDelivered(reqCtx *model.RequestContext, before time.Time) model.Response[int64] {
+	logger.Info(reqCtx, "OutboxRepo.PurgeDelivered called", map[string]interface{}{"before": before})
+	result := r.db.WithContext(reqCtx.Context()).
+		Where("delivered_at < ?", before).
+		Delete(&model.OutboxEvent{})
+	if result.Error != nil {
+		return model.InternalError[int64]("Failed to purge outbox")
+	}
+	return model.SuccessResponse(result.RowsAffected, "Outbox purged successfully")
+}
+
+func truncateOutboxError(message string) string {
+	if len(message) > maxOutboxErrorLength {
+		return strings.ToValidUTF8(message[:maxOutboxErrorLength], "")
+	}
+	return message
+}
@@ -3,8 +3,10 @@ package repo
 import (
 	"local/model"
 	"local/util/logger"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ParticipantRepo interface {
@@ -18,6 +20,10 @@ type ParticipantRepo interface {
 	GetByConversationAndUser(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant]
 	AddParticipantToConversation(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant]
 	RemoveParticipantFromConversation(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[string]
+	MarkRead(reqCtx *model.RequestContext, conversationID, userID, messageID uint) model.Response[*model.ConversationParticipant]
+	GetContactIDs(reqCtx *model.RequestContext, userID uint) model.Response[[]uint]
+	// SetMutedUntil mutes the conversation for the participant until the given time, or unmutes it when nil
+	SetMutedUntil(reqCtx *model.RequestContext, conversationID, userID uint, until *time.Time) model.Response[*model.ConversationParticipant]
 }
 
 type participantRepository struct {
@@ -61,7 +67,7 @@ func (r *participantRepository) Create(reqCtx *model.RequestContext, participant
 
 func (r *participantRepository) Update(reqCtx *model.RequestContext, participant *model.ConversationParticipant) model.Response[*model.ConversationParticipant] {
 	logger.Info(reqCtx, "ParticipantRepo.Update called", map[string]interface{}{"participant_id": participant.ID})
-	err := r.db.WithContext(reqCtx.Context()).Save(participant).Error
+	err := r.db.WithContext(reqCtx.Context()).Omit(clause.Associations).Save(participant).Error
 	if err != nil {
 		return model.BadRequest[*model.ConversationParticipant]("Failed to update participant")
 	}
@@ -155,9 +161,64 @@ func (r *participantRepository) RemoveParticipantFromConversation(reqCtx *model.
 	return model.SuccessResponse("", "Participant removed successfully")
 }
 
+// MarkRead advances the participant's read marker; it never moves backwards.
+func (r *participantRepository) MarkRead(reqCtx *model.RequestContext, conversationID, userID, messageID uint) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "ParticipantRepo.MarkRead called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+		"message_id":      messageID,
+	})
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND last_read_message_id < ?", conversationID, userID, messageID).
+		Update("last_read_message_id", messageID).Error
+	if err != nil {
+		return model.InternalError[*model.ConversationParticipant]("Failed to update read marker")
+	}
+
+	var participant model.ConversationParticipant
+	err = r.db.WithContext(reqCtx.Context()).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		First(&participant).Error
+	if err != nil {
+		return model.NotFound[*model.ConversationParticipant]("Participant not found")
+	}
+	return model.SuccessResponse(&participant, "Read marker updated successfully")
+}
+
+// GetContactIDs lists every other user who shares at least one conversation with userID
+func (r *participantRepository) GetContactIDs(reqCtx *model.RequestContext, userID uint) model.Response[[]uint] {
+	logger.Info(reqCtx, "ParticipantRepo.GetContactIDs called", map[string]interface{}{"user_id": userID})
+	var contactIDs []uint
+	err := r.db.WithContext(reqCtx.Context()).
+		Table("conversation_participants AS own").
+		Joins("JOIN conversation_participants AS other ON other.conversation_id = own.conversation_id").
+		Where("own.user_id = ? AND other.user_id <> ?", userID, userID).
+		Distinct("other.user_id").
+		Pluck("other.user_id", &contactIDs).Error
+	if err != nil {
+		return model.InternalError[[]uint]("Failed to get contacts")
+	}
+	return model.SuccessResponse(contactIDs, "Contacts retrieved successfully")
+}
+
+func (r *participantRepository) SetMutedUntil(reqCtx *model.RequestContext, conversationID, userID uint, until *time.Time) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "ParticipantRepo.SetMutedUntil called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+	})
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("muted_until", until).Error
+	if err != nil {
+		return model.InternalError[*model.ConversationParticipant]("Failed to update mute")
+	}
+	return r.GetByConversationAndUser(reqCtx, conversationID, userID)
+}
+
 func NewParticipantRepository(db *gorm.DB) (ParticipantRepo, error) {
 	return &participantRepository{
 		db: db,
 	}, nil
 }
-
@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"local/model"
+	"local/util/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionRepo interface {
+	Add(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[bool]
+	Remove(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[bool]
+	Summarize(reqCtx *model.RequestContext, messageIDs []uint, viewerID uint) model.Response[map[uint][]*model.ReactionSummary]
+}
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+// Add records the reaction and reports whether it was new; repeating a reaction is a no-op
+func (r *reactionRepository) Add(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[bool] {
+	logger.Info(reqCtx, "ReactionRepo.Add called", map[string]interface{}{
+		"message_id": messageID,
+		"user_id":    userID,
+		"emoji":      emoji,
+	})
+	result := r.db.WithContext(reqCtx.Context()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.MessageReaction{MessageID: messageID, UserID: userID, Emoji: emoji})
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to add reaction")
+	}
+	return model.SuccessResponse(result.RowsAffected > 0, "Reaction added successfully")
+}
+
+// Remove deletes the reaction and reports whether there was one to delete
+func (r *reactionRepository) Remove(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[bool] {
+	logger.Info(reqCtx, "ReactionRepo.Remove called", map[string]interface{}{
+		"message_id": messageID,
+		"user_id":    userID,
+		"emoji":      emoji,
+	})
+	result := r.db.WithContext(reqCtx.Context()).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Delete(&model.MessageReaction{})
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to remove reaction")
+	}
+	return model.SuccessResponse(result.RowsAffected > 0, "Reaction removed successfully")
+}
+
+// Summarize counts reactions per message and emoji, flagging the ones viewerID made
+func (r *reactionRepository) Summarize(reqCtx *model.RequestContext, messageIDs []uint, viewerID uint) model.Response[map[uint][]*model.ReactionSummary] {
+	logger.Info(reqCtx, "ReactionRepo.Summarize called", map[string]interface{}{
+		"message_ids": messageIDs,
+		"viewer_id":   viewerID,
+	})
+	summaries, err := summarizeReactions(r.db.WithContext(reqCtx.Context()), messageIDs, viewerID)
+	if err != nil {
+		return model.InternalError[map[uint][]*model.ReactionSummary]("Failed to summarize reactions")
+	}
+	return model.SuccessResponse(summaries, "Reactions retrieved successfully")
+}
+
+func summarizeReactions(db *gorm.DB, messageIDs []uint, viewerID uint) (map[uint][]*model.ReactionSummary, error) {
+	summaries := map[uint][]*model.ReactionSummary{}
+	if len(messageIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []struct {
+		MessageID uint
+		Emoji     string
+		Reactions int64
+		Mine      int64
+	}
+	err := db.Model(&model.MessageReaction{}).
+		Select("message_id, emoji, COUNT(*) AS reactions, SUM(CASE WHEN user_id = ? THEN 1 ELSE 0 END) AS mine", viewerID).
+		Where("message_id IN ?", messageIDs).
+		Group("message_id, emoji").
+		Order("MIN(id) ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		summaries[row.MessageID] = append(summaries[row.MessageID], &model.ReactionSummary{
+			Emoji:       row.Emoji,
+			Count:       row.Reactions,
+			ReactedByMe: row.Mine > 0,
+		})
+	}
+	return summaries, nil
+}
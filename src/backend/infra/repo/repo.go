@@ -1,19 +1,130 @@
 package repo
 
 import (
+	"errors"
 	"local/config"
 	"local/model"
+	"local/util/logger"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
+// RepositoryInterface exposes the individual repositories to the service layer
+// so that services can be tested against mocks
+type RepositoryInterface interface {
+	User() UserRepo
+	Conversation() ConversationRepo
+	Participant() ParticipantRepo
+	Message() MessageRepo
+	Reaction() ReactionRepo
+	Attachment() AttachmentRepo
+	Revocation() RevocationRepo
+	Session() SessionRepo
+	LoginAttempt() LoginAttemptRepo
+	MFA() MFARepo
+	Identity() IdentityRepo
+	Block() BlockRepo
+	Outbox() OutboxRepo
+	// WithTx runs fn as one unit of work: the repositories it is given share a
+	// transaction, committed when fn returns nil and rolled back otherwise
+	WithTx(reqCtx *model.RequestContext, fn func(tx RepositoryInterface) error) error
+}
+
 type Repository struct {
 	db              *gorm.DB
 	UserRepo        UserRepo
 	ConversationRepo ConversationRepo
 	ParticipantRepo  ParticipantRepo
 	MessageRepo      MessageRepo
+	ReactionRepo     ReactionRepo
+	AttachmentRepo   AttachmentRepo
+	RevocationRepo   RevocationRepo
+	SessionRepo      SessionRepo
+	LoginAttemptRepo LoginAttemptRepo
+	MFARepo          MFARepo
+	IdentityRepo     IdentityRepo
+	BlockRepo        BlockRepo
+	OutboxRepo       OutboxRepo
+}
+
+func (r *Repository) User() UserRepo {
+	return r.UserRepo
+}
+
+func (r *Repository) Conversation() ConversationRepo {
+	return r.ConversationRepo
+}
+
+func (r *Repository) Participant() ParticipantRepo {
+	return r.ParticipantRepo
+}
+
+func (r *Repository) Message() MessageRepo {
+	return r.MessageRepo
+}
+
+func (r *Repository) Reaction() ReactionRepo {
+	return r.ReactionRepo
+}
+
+func (r *Repository) Attachment() AttachmentRepo {
+	return r.AttachmentRepo
+}
+
+func (r *Repository) Revocation() RevocationRepo {
+	return r.RevocationRepo
+}
+
+func (r *Repository) Session() SessionRepo {
+	return r.SessionRepo
+}
+
+func (r *Repository) LoginAttempt() LoginAttemptRepo {
+	return r.LoginAttemptRepo
+}
+
+func (r *Repository) MFA() MFARepo {
+	return r.MFARepo
+}
+
+func (r *Repository) Identity() IdentityRepo {
+	return r.IdentityRepo
+}
+
+func (r *Repository) Block() BlockRepo {
+	return r.BlockRepo
+}
+
+func (r *Repository) Outbox() OutboxRepo {
+	return r.OutboxRepo
+}
+
+func (r *Repository) WithTx(reqCtx *model.RequestContext, fn func(tx RepositoryInterface) error) error {
+	return r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		return fn(newRepository(tx))
+	})
+}
+
+// errRolledBack rolls back a unit of work whose response failed
+var errRolledBack = errors.New("unit of work rolled back")
+
+// InTx runs fn as one unit of work and returns its response. A failed response
+// rolls everything fn did back; a successful one is committed.
+func InTx[T any](reqCtx *model.RequestContext, r RepositoryInterface, fn func(tx RepositoryInterface) model.Response[T]) model.Response[T] {
+	var response model.Response[T]
+	err := r.WithTx(reqCtx, func(tx RepositoryInterface) error {
+		response = fn(tx)
+		if !response.OK() {
+			return errRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRolledBack) {
+		logger.Error(reqCtx, "Failed to commit unit of work", err)
+		return model.InternalError[T]("Failed to save changes")
+	}
+	return response
 }
 
 // NewRepositoryWithDB creates a repository instance with the provided database
@@ -25,24 +136,46 @@ func NewRepositoryWithDB(db *gorm.DB) (*Repository, error) {
 		&model.Conversation{},
 		&model.ConversationParticipant{},
 		&model.Message{},
+		&model.MessageEdit{},
+		&model.MessageReaction{},
+		&model.Attachment{},
+		&model.RevokedSession{},
+		&model.UserTokenCutoff{},
+		&model.Session{},
+		&model.RotatedRefreshToken{},
+		&model.LoginAttempt{},
+		&model.UserMFA{},
+		&model.MFARecoveryCode{},
+		&model.UserIdentity{},
+		&model.OIDCLoginState{},
+		&model.UserBlock{},
+		&model.OutboxEvent{},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create repositories
-	userRepo := &userRepository{db: db}
-	conversationRepo := &conversationRepository{db: db}
-	participantRepo := &participantRepository{db: db}
-	messageRepo := &messageRepository{db: db}
+	return newRepository(db), nil
+}
 
+// newRepository creates the repositories on db, which may be a transaction
+func newRepository(db *gorm.DB) *Repository {
 	return &Repository{
-		db:              db,
-		UserRepo:        userRepo,
-		ConversationRepo: conversationRepo,
-		ParticipantRepo:  participantRepo,
-		MessageRepo:      messageRepo,
-	}, nil
+		db:               db,
+		UserRepo:         &userRepository{db: db},
+		ConversationRepo: &conversationRepository{db: db},
+		ParticipantRepo:  &participantRepository{db: db},
+		MessageRepo:      &messageRepository{db: db},
+		ReactionRepo:     &reactionRepository{db: db},
+		AttachmentRepo:   &attachmentRepository{db: db},
+		RevocationRepo:   &revocationRepository{db: db},
+		SessionRepo:      &sessionRepository{db: db},
+		LoginAttemptRepo: &loginAttemptRepository{db: db},
+		MFARepo:          &mfaRepository{db: db},
+		IdentityRepo:     &identityRepository{db: db},
+		BlockRepo:        &blockRepository{db: db},
+		OutboxRepo:       &outboxRepository{db: db},
+	}
 }
 
 // NewRepository creates a repository instance with MySQL connection from config
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"errors"
+	"local/model"
+	"local/util/logger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevocationRepo interface {
+	RevokeSession(reqCtx *model.RequestContext, session *model.RevokedSession) model.Response[bool]
+	RevokeUser(reqCtx *model.RequestContext, userID uint, before time.Time) model.Response[*model.UserTokenCutoff]
+	IsSessionRevoked(reqCtx *model.RequestContext, sessionID string) model.Response[bool]
+	GetUserCutoff(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserTokenCutoff]
+}
+
+type revocationRepository struct {
+	db *gorm.DB
+}
+
+// RevokeSession records the session as logged out and reports whether it was not already
+func (r *revocationRepository) RevokeSession(reqCtx *model.RequestContext, session *model.RevokedSession) model.Response[bool] {
+	logger.Info(reqCtx, "RevocationRepo.RevokeSession called", map[string]interface{}{
+		"user_id":    session.UserID,
+		"session_id": session.SessionID,
+	})
+	result := r.db.WithContext(reqCtx.Context()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(session)
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to revoke session")
+	}
+	return model.SuccessResponse(result.RowsAffected > 0, "Session revoked successfully")
+}
+
+// RevokeUser moves the user's cutoff so every token issued before it stops working
+func (r *revocationRepository) RevokeUser(reqCtx *model.RequestContext, userID uint, before time.Time) model.Response[*model.UserTokenCutoff] {
+	logger.Info(reqCtx, "RevocationRepo.RevokeUser called", map[string]interface{}{
+		"user_id": userID,
+		"before":  before,
+	})
+	cutoff := &model.UserTokenCutoff{UserID: userID, RevokedBefore: before}
+	err := r.db.WithContext(reqCtx.Context()).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "updated_at"}),
+		}).
+		Create(cutoff).Error
+	if err != nil {
+		return model.InternalError[*model.UserTokenCutoff]("Failed to revoke sessions")
+	}
+	return model.SuccessResponse(cutoff, "Sessions revoked successfully")
+}
+
+func (r *revocationRepository) IsSessionRevoked(reqCtx *model.RequestContext, sessionID string) model.Response[bool] {
+	var count int64
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.RevokedSession{}).
+		Where("session_id = ?", sessionID).
+		Count(&count).Error
+	if err != nil {
+		return model.InternalError[bool]("Failed to check session")
+	}
+	return model.SuccessResponse(count > 0, "Session checked successfully")
+}
+
+func (r *revocationRepository) GetUserCutoff(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserTokenCutoff] {
+	var cutoff model.UserTokenCutoff
+	err := r.db.WithContext(reqCtx.Context()).Where("user_id = ?", userID).First(&cutoff).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotFound[*model.UserTokenCutoff]("No session cutoff for user")
+	}
+	if err != nil {
+		return model.InternalError[*model.UserTokenCutoff]("Failed to load session cutoff")
+	}
+	return model.SuccessResponse(&cutoff, "Session cutoff retrieved successfully")
+}
@@ -0,0 +1,164 @@
+package repo
+
+import (
+	"errors"
+	"local/model"
+	"local/util/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errRefreshTokenUsed aborts a rotation that lost the race for the current refresh token
+var errRefreshTokenUsed = errors.New("refresh token already rotated")
+
+type SessionRepo interface {
+	Create(reqCtx *model.RequestContext, session *model.Session) model.Response[*model.Session]
+	GetByID(reqCtx *model.RequestContext, sessionID string) model.Response[*model.Session]
+	GetByRefreshTokenHash(reqCtx *model.RequestContext, tokenHash string) model.Response[*model.Session]
+	ListActiveByUser(reqCtx *model.RequestContext, userID uint, now time.Time) model.Response[[]*model.Session]
+	GetRotated(reqCtx *model.RequestContext, tokenHash string) model.Response[*model.RotatedRefreshToken]
+	Rotate(reqCtx *model.RequestContext, session *model.Session, tokenHash string, expiresAt time.Time) model.Response[*model.Session]
+	Revoke(reqCtx *model.RequestContext, sessionID string) model.Response[bool]
+	RevokeByUser(reqCtx *model.RequestContext, userID uint) model.Response[int64]
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func (r *sessionRepository) Create(reqCtx *model.RequestContext, session *model.Session) model.Response[*model.Session] {
+	logger.Info(reqCtx, "SessionRepo.Create called", map[string]interface{}{
+		"user_id":    session.UserID,
+		"session_id": session.ID,
+	})
+	if err := r.db.WithContext(reqCtx.Context()).Create(session).Error; err != nil {
+		return model.InternalError[*model.Session]("Failed to create session")
+	}
+	return model.SuccessResponse(session, "Session created successfully")
+}
+
+func (r *sessionRepository) GetByID(reqCtx *model.RequestContext, sessionID string) model.Response[*model.Session] {
+	logger.Info(reqCtx, "SessionRepo.GetByID called", map[string]interface{}{"session_id": sessionID})
+	var session model.Session
+	err := r.db.WithContext(reqCtx.Context()).Where("id = ?", sessionID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotFound[*model.Session]("Session not found")
+	}
+	if err != nil {
+		return model.InternalError[*model.Session]("Failed to load session")
+	}
+	return model.SuccessResponse(&session, "Session retrieved successfully")
+}
+
+// ListActiveByUser returns the user's sessions that can still be refreshed, most recently used first
+func (r *sessionRepository) ListActiveByUser(reqCtx *model.RequestContext, userID uint, now time.Time) model.Response[[]*model.Session] {
+	logger.Info(reqCtx, "SessionRepo.ListActiveByUser called", map[string]interface{}{"user_id": userID})
+	var sessions []*model.Session
+	err := r.db.WithContext(reqCtx.Context()).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return model.InternalError[[]*model.Session]("Failed to load sessions")
+	}
+	return model.SuccessResponse(sessions, "Sessions retrieved successfully")
+}
+
+func (r *sessionRepository) GetByRefreshTokenHash(reqCtx *model.RequestContext, tokenHash string) model.Response[*model.Session] {
+	logger.Info(reqCtx, "SessionRepo.GetByRefreshTokenHash called")
+	var session model.Session
+	err := r.db.WithContext(reqCtx.Context()).Where("refresh_token_hash = ?", tokenHash).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotFound[*model.Session]("Session not found")
+	}
+	if err != nil {
+		return model.InternalError[*model.Session]("Failed to load session")
+	}
+	return model.SuccessResponse(&session, "Session retrieved successfully")
+}
+
+func (r *sessionRepository) GetRotated(reqCtx *model.RequestContext, tokenHash string) model.Response[*model.RotatedRefreshToken] {
+	logger.Info(reqCtx, "SessionRepo.GetRotated called")
+	var rotated model.RotatedRefreshToken
+	err := r.db.WithContext(reqCtx.Context()).Where("token_hash = ?", tokenHash).First(&rotated).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotFound[*model.RotatedRefreshToken]("Refresh token not found")
+	}
+	if err != nil {
+		return model.InternalError[*model.RotatedRefreshToken]("Failed to load refresh token")
+	}
+	return model.SuccessResponse(&rotated, "Refresh token retrieved successfully")
+}
+
+// Rotate swaps the session's refresh token for a new one and remembers the old
+// hash, recording the session's current IP address as last used. Only the caller
+// holding the current token can win; a concurrent rotation of the same token gets
+// a conflict.
+func (r *sessionRepository) Rotate(reqCtx *model.RequestContext, session *model.Session, tokenHash string, expiresAt time.Time) model.Response[*model.Session] {
+	logger.Info(reqCtx, "SessionRepo.Rotate called", map[string]interface{}{
+		"user_id":    session.UserID,
+		"session_id": session.ID,
+	})
+	previousHash := session.RefreshTokenHash
+	now := time.Now()
+	err := r.db.WithContext(reqCtx.Context()).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Session{}).
+			Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, previousHash).
+			Updates(map[string]interface{}{
+				"refresh_token_hash": tokenHash,
+				"expires_at":         expiresAt,
+				"ip_address":         session.IPAddress,
+				"last_used_at":       now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenUsed
+		}
+		return tx.Create(&model.RotatedRefreshToken{
+			TokenHash: previousHash,
+			SessionID: session.ID,
+			UserID:    session.UserID,
+			RotatedAt: now,
+		}).Error
+	})
+	if errors.Is(err, errRefreshTokenUsed) {
+		return model.Conflict[*model.Session]("Refresh token has already been used")
+	}
+	if err != nil {
+		return model.InternalError[*model.Session]("Failed to rotate refresh token")
+	}
+
+	session.RefreshTokenHash = tokenHash
+	session.ExpiresAt = expiresAt
+	session.LastUsedAt = now
+	return model.SuccessResponse(session, "Refresh token rotated successfully")
+}
+
+// Revoke ends the session so its refresh token can no longer be used
+func (r *sessionRepository) Revoke(reqCtx *model.RequestContext, sessionID string) model.Response[bool] {
+	logger.Info(reqCtx, "SessionRepo.Revoke called", map[string]interface{}{"session_id": sessionID})
+	result := r.db.WithContext(reqCtx.Context()).
+		Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return model.InternalError[bool]("Failed to revoke session")
+	}
+	return model.SuccessResponse(result.RowsAffected > 0, "Session revoked successfully")
+}
+
+// RevokeByUser ends every session of the user and reports how many were still active
+func (r *sessionRepository) RevokeByUser(reqCtx *model.RequestContext, userID uint) model.Response[int64] {
+	logger.Info(reqCtx, "SessionRepo.RevokeByUser called", map[string]interface{}{"user_id": userID})
+	result := r.db.WithContext(reqCtx.Context()).
+		Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return model.InternalError[int64]("Failed to revoke sessions")
+	}
+	return model.SuccessResponse(result.RowsAffected, "Sessions revoked successfully")
+}
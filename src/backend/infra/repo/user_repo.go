@@ -4,6 +4,8 @@ import (
 	"local/model"
 	"local/util/logger"
 	"log"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -19,6 +21,16 @@ type UserRepo interface {
 	Update(reqCtx *model.RequestContext, us *model.User) model.Response[*model.User]
 	Delete(reqCtx *model.RequestContext, us *model.User) model.Response[*model.User]
 	Count(reqCtx *model.RequestContext) (int64, error)
+	GetByIDs(reqCtx *model.RequestContext, ids []uint) model.Response[[]*model.User]
+	SetPresence(reqCtx *model.RequestContext, userID uint, online bool, seenAt time.Time) model.Response[*model.User]
+	// ClearPresence marks every online user offline as last seen at seenAt and returns how many there were
+	ClearPresence(reqCtx *model.RequestContext, seenAt time.Time) model.Response[int64]
+	// UpdateProfile writes the given profile columns, nil values included, and returns the updated user
+	UpdateProfile(reqCtx *model.RequestContext, userID uint, changes map[string]interface{}) model.Response[*model.User]
+	// Search finds active users by username or display name, best matches first, leaving
+	// out anyone viewerID blocked or was blocked by, and continuing
+	// after the cursor when one is given. It returns at most limit users
+	Search(reqCtx *model.RequestContext, viewerID uint, query string, after *model.UserSearchCursor, limit int) model.Response[[]*model.UserMatch]
 }
 
 func (r *userRepository) QueryOne(reqCtx *model.RequestContext, user *model.User) model.Response[*model.User] {
@@ -84,3 +96,108 @@ func (r *userRepository) Count(reqCtx *model.RequestContext) (int64, error) {
 	return count, nil
 }
 
+func (r *userRepository) GetByIDs(reqCtx *model.RequestContext, ids []uint) model.Response[[]*model.User] {
+	logger.Info(reqCtx, "UserRepo.GetByIDs called", map[string]interface{}{"user_ids": ids})
+	var results []*model.User
+	if len(ids) == 0 {
+		return model.SuccessResponse(results, "Users retrieved successfully")
+	}
+	err := r.db.WithContext(reqCtx.Context()).Where("id IN ?", ids).Order("id ASC").Find(&results).Error
+	if err != nil {
+		log.Printf("Error querying users by ids: %v", err)
+		return model.InternalError[[]*model.User]("Failed to query users")
+	}
+	return model.SuccessResponse(results, "Users retrieved successfully")
+}
+
+func (r *userRepository) SetPresence(reqCtx *model.RequestContext, userID uint, online bool, seenAt time.Time) model.Response[*model.User] {
+	logger.Info(reqCtx, "UserRepo.SetPresence called", map[string]interface{}{"user_id": userID, "online": online})
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.User{ID: userID}).
+		Updates(map[string]interface{}{"online": online, "last_seen_at": seenAt}).Error
+	if err != nil {
+		log.Printf("Error updating user presence: %v", err)
+		return model.InternalError[*model.User]("Failed to update presence")
+	}
+	return r.QueryOne(reqCtx, &model.User{ID: userID})
+}
+
+func (r *userRepository) ClearPresence(reqCtx *model.RequestContext, seenAt time.Time) model.Response[int64] {
+	logger.Info(reqCtx, "UserRepo.ClearPresence called")
+	result := r.db.WithContext(reqCtx.Context()).
+		Model(&model.User{}).
+		Where("online = ?", true).
+		Updates(map[string]interface{}{"online": false, "last_seen_at": seenAt})
+	if result.Error != nil {
+		log.Printf("Error clearing user presence: %v", result.Error)
+		return model.InternalError[int64]("Failed to clear presence")
+	}
+	return model.SuccessResponse(result.RowsAffected, "Presence cleared successfully")
+}
+
+func (r *userRepository) UpdateProfile(reqCtx *model.RequestContext, userID uint, changes map[string]interface{}) model.Response[*model.User] {
+	logger.Info(reqCtx, "UserRepo.UpdateProfile called", map[string]interface{}{"user_id": userID})
+	err := r.db.WithContext(reqCtx.Context()).
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(changes).Error
+	if err != nil {
+		log.Printf("Error updating user profile: %v", err)
+		return model.InternalError[*model.User]("Failed to update profile")
+	}
+	return r.QueryOne(reqCtx, &model.User{ID: userID})
+}
+
+// Search ranks, in order: username prefix, display name or one of its words
+// starting with the query, and the query's characters appearing in order anywhere
+// in either. Ties are broken by username, which is unique, so (rank, username)
+// orders the results completely and serves as the cursor
+func (r *userRepository) Search(reqCtx *model.RequestContext, viewerID uint, query string, after *model.UserSearchCursor, limit int) model.Response[[]*model.UserMatch] {
+	logger.Info(reqCtx, "UserRepo.Search called", map[string]interface{}{"viewer_id": viewerID, "query": query, "limit": limit})
+	rank, rankArgs := "0", []interface{}{}
+	db := r.db.WithContext(reqCtx.Context()).Model(&model.User{}).
+		Where("deactivated_at IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM user_blocks WHERE (blocker_id = ? AND blocked_id = users.id) OR (blocker_id = users.id AND blocked_id = ?))", viewerID, viewerID)
+	if query != "" {
+		prefix := escapeLike(query) + "%"
+		wordPrefix := "% " + prefix
+		fuzzy := "%"
+		for _, c := range query {
+			fuzzy += escapeLike(string(c)) + "%"
+		}
+		rank = "(CASE WHEN username LIKE ? ESCAPE '!' THEN 0" +
+			" WHEN display_name LIKE ? ESCAPE '!' OR display_name LIKE ? ESCAPE '!' THEN 1 ELSE 2 END)"
+		rankArgs = []interface{}{prefix, prefix, wordPrefix}
+		db = db.Where("username LIKE ? ESCAPE '!' OR display_name LIKE ? ESCAPE '!'", fuzzy, fuzzy)
+	}
+	if after != nil {
+		args := append(append([]interface{}{}, rankArgs...), after.Rank)
+		args = append(append(args, rankArgs...), after.Rank, after.UserName)
+		db = db.Where(rank+" > ? OR ("+rank+" = ? AND username > ?)", args...)
+	}
+
+	var rows []*struct {
+		model.User `gorm:"embedded"`
+		MatchRank  int `gorm:"column:match_rank"`
+	}
+	err := db.Select("users.*, "+rank+" AS match_rank", rankArgs...).
+		Order("match_rank ASC, username ASC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		log.Printf("Error searching users: %v", err)
+		return model.InternalError[[]*model.UserMatch]("Failed to search users")
+	}
+
+	matches := make([]*model.UserMatch, 0, len(rows))
+	for _, row := range rows {
+		user := row.User
+		matches = append(matches, &model.UserMatch{User: &user, Rank: row.MatchRank})
+	}
+	return model.SuccessResponse(matches, "Users retrieved successfully")
+}
+
+// escapeLike makes the LIKE wildcards in s match literally, for use with ESCAPE '!'
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -4,17 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"local/infra/provider/search"
 	"local/util/logger"
 )
 
 // ChatMessageHandler handles chat message events
 type ChatMessageHandler struct {
-	// Add dependencies here (e.g., repositories, services)
+	index search.SearchIndex
 }
 
-// NewChatMessageHandler creates a new chat message handler
-func NewChatMessageHandler() *ChatMessageHandler {
-	return &ChatMessageHandler{}
+// NewChatMessageHandler creates a new chat message handler that keeps index current
+func NewChatMessageHandler(index search.SearchIndex) *ChatMessageHandler {
+	return &ChatMessageHandler{index: index}
 }
 
 // Handle processes a chat message event
@@ -26,17 +27,31 @@ func (h *ChatMessageHandler) Handle(ctx context.Context, key []byte, value []byt
 	}
 
 	logger.Info(nil, "Handling chat message", map[string]interface{}{
+		"type":            event.Type,
 		"message_id":      event.MessageID,
 		"conversation_id": event.ConversationID,
 		"user_id":         event.UserID,
 	})
 
-	// Business logic examples:
-	// 1. Update message read status
-	// 2. Trigger real-time notifications via websocket
-	// 3. Update conversation last_message
-	// 4. Analytics/metrics tracking
-	// 5. Content moderation
+	switch event.Type {
+	case "", MessageEventCreated, MessageEventEdited:
+		err := h.index.Index(ctx, search.Document{
+			MessageID:      event.MessageID,
+			ConversationID: event.ConversationID,
+			SenderID:       event.UserID,
+			Content:        event.Content,
+			CreatedAt:      event.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to index message: %w", err)
+		}
+	case MessageEventDeleted:
+		if err := h.index.Remove(ctx, event.MessageID); err != nil {
+			return fmt.Errorf("failed to remove message from index: %w", err)
+		}
+	default:
+		logger.Warn(nil, "Ignoring message event of unknown type", map[string]interface{}{"type": event.Type})
+	}
 
 	return nil
 }
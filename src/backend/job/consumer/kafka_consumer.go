@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"local/config"
+	"local/infra/provider/search"
 	"local/util/logger"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -111,12 +113,22 @@ func (kc *KafkaConsumer) Stop() error {
 	return nil
 }
 
-// MessageEvent represents a chat message event
+// Message event types
+const (
+	MessageEventCreated = "created"
+	MessageEventEdited  = "edited"
+	MessageEventDeleted = "deleted"
+)
+
+// MessageEvent represents a chat message event. Events without a type come from
+// producers that predate it and mean the message was created
 type MessageEvent struct {
-	MessageID      uint   `json:"message_id"`
-	ConversationID uint   `json:"conversation_id"`
-	UserID         uint   `json:"user_id"`
-	Content        string `json:"content"`
+	Type           string    `json:"type,omitempty"`
+	MessageID      uint      `json:"message_id"`
+	ConversationID uint      `json:"conversation_id"`
+	UserID         uint      `json:"user_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 // NotificationEvent represents a notification event
@@ -166,13 +178,13 @@ func NotificationEventHandler(ctx context.Context, key []byte, value []byte) err
 	return nil
 }
 
-// StartMessageConsumer starts the message consumer
-func StartMessageConsumer() error {
+// StartMessageConsumer starts the message consumer, which keeps the search index current
+func StartMessageConsumer(index search.SearchIndex) error {
 	consumer := NewKafkaConsumer(
 		config.Config.KafkaBrokers,
 		config.Config.KafkaConsumerGroup,
 		config.Config.KafkaMessageTopic,
-		MessageEventHandler,
+		NewChatMessageHandler(index).Handle,
 	)
 	defer consumer.Stop()
 	return consumer.Start()
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"local/model"
+	"local/service/outbox"
+	"local/util/logger"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// RunOutboxRelay relays due outbox deliveries every interval until interrupted.
+// Several relays may run side by side; claims keep them from sending a delivery twice.
+func RunOutboxRelay(svc outbox.OutboxService, interval time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		reqCtx := model.NewRequestContext(ctx)
+		relayResponse := svc.Relay(reqCtx)
+		if !relayResponse.OK() {
+			logger.Warn(reqCtx, "Outbox relay pass failed", map[string]interface{}{"error": relayResponse.ErrorString()})
+		} else if relayResponse.Data > 0 {
+			logger.Info(reqCtx, "Outbox relay pass delivered events", map[string]interface{}{"delivered": relayResponse.Data})
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info(nil, "Outbox relay stopped", nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -23,6 +23,11 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey ServiceAuth
+// @in header
+// @name X-Service-Key
+// @description Shared INTERNAL_SERVICE_KEY of the deployment, for internal endpoints.
+
 func main() {
 	cmd.Run()
 }
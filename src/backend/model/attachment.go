@@ -0,0 +1,49 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Message types
+const (
+	MessageTypeText       = "text"
+	MessageTypeAttachment = "attachment"
+)
+
+// Attachment is an uploaded file. It belongs to the conversation it was uploaded
+// to and is bound to at most one message once that message is sent. Avatars
+// belong to no conversation.
+type Attachment struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID uint      `json:"conversation_id" gorm:"column:conversation_id;not null;index"`
+	UploaderID     uint      `json:"uploader_id" gorm:"column:uploader_id;not null"`
+	MessageID      *uint     `json:"message_id,omitempty" gorm:"column:message_id;index"`
+	FileName       string    `json:"file_name" gorm:"column:file_name;not null"`
+	ContentType    string    `json:"content_type" gorm:"column:content_type;not null"`
+	Size           int64     `json:"size" gorm:"column:size;not null"`
+	StorageKey     string    `json:"-" gorm:"column:storage_key;not null"`
+	URL            string    `json:"url" gorm:"-"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// AttachmentContent is an attachment together with a stream of its bytes
+type AttachmentContent struct {
+	Attachment *Attachment
+	Body       io.ReadCloser
+}
+
+// IsAvatar reports whether the file was uploaded as a profile picture
+func (a *Attachment) IsAvatar() bool {
+	return a.ConversationID == 0
+}
+
+// DownloadPath is the authenticated API route that serves the attachment
+func (a *Attachment) DownloadPath() string {
+	return fmt.Sprintf("/api/v1/attachments/%d", a.ID)
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// UserBlock records that BlockerID blocked BlockedID. Blocking works both ways:
+// neither user can start a private conversation with or message the other, and
+// they no longer find each other in the user directory
+type UserBlock struct {
+	BlockerID uint      `json:"-" gorm:"column:blocker_id;primaryKey;autoIncrement:false"`
+	BlockedID uint      `json:"blocked_id" gorm:"column:blocked_id;primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
@@ -6,16 +6,32 @@ import (
 	"time"
 )
 
+// Conversation types
+const (
+	ConversationTypePrivate = "private"
+	ConversationTypeGroup   = "group"
+)
+
+// Participant roles within a conversation
+const (
+	ParticipantRoleOwner  = "owner"
+	ParticipantRoleAdmin  = "admin"
+	ParticipantRoleMember = "member"
+)
+
 type Conversation struct {
 	ID          		uint      `json:"id" gorm:"primaryKey;autoIncrement"`
 	Type        		string    `json:"type" gorm:"column:type;not null"`
 	Name        		string    `json:"name" gorm:"column:name"`
 	CreatedAt   		time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt   		time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
-	EntityJoined 		string `json:"entity_joined" gorm:"type:text"`
+	// EntityJoined names the pair of users of a private conversation, so there is
+	// only ever one per pair; group conversations have none
+	EntityJoined 		*string `json:"entity_joined,omitempty" gorm:"column:entity_joined;type:varchar(64);uniqueIndex:idx_conversations_entity_joined"`
 	UserIds 				UserIds `json:"user_ids" gorm:"type:json"`
 
 	LastMessageID 	uint `json:"last_message_id" gorm:"column:last_message_id"`
+	UnreadCount 		int64 `json:"unread_count" gorm:"-"`
 	
 	Participants 		[]*ConversationParticipant `json:"participants,omitempty" gorm:"foreignKey:ConversationID"`
 }
@@ -52,7 +68,11 @@ type ConversationParticipant struct {
 	ID             uint `json:"id" gorm:"primaryKey;autoIncrement"`
 	ConversationID uint `json:"conversation_id" gorm:"column:conversation_id;not null"`
 	UserID         uint `json:"user_id" gorm:"column:user_id;not null"`
+	Role           string `json:"role" gorm:"column:role;not null;default:'member'"`
 	JoinedAt       time.Time `json:"joined_at" gorm:"column:joined_at;autoCreateTime"`
+	LastReadMessageID uint `json:"last_read_message_id" gorm:"column:last_read_message_id;not null;default:0"`
+	// MutedUntil silences the conversation's notifications for the participant; messages still arrive
+	MutedUntil     *time.Time `json:"muted_until,omitempty" gorm:"column:muted_until"`
 	
 	Conversation Conversation `json:"conversation" gorm:"foreignKey:ConversationID"`
 	User         User         `json:"user" gorm:"foreignKey:UserID"`
@@ -61,6 +81,24 @@ type ConversationParticipant struct {
 	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 }
 
+// MutedForever is the muted_until of a conversation muted without an end
+var MutedForever = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// IsGroup reports whether the conversation is a group conversation
+func (c *Conversation) IsGroup() bool {
+	return c.Type == ConversationTypeGroup
+}
+
+// CanManageMembers reports whether the participant may add or remove members
+func (p *ConversationParticipant) CanManageMembers() bool {
+	return p.Role == ParticipantRoleOwner || p.Role == ParticipantRoleAdmin
+}
+
+// IsMuted reports whether the participant muted the conversation as of now
+func (p *ConversationParticipant) IsMuted(now time.Time) bool {
+	return p.MutedUntil != nil && now.Before(*p.MutedUntil)
+}
+
 func (Conversation) TableName() string {
 	return "conversations"
 }
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// UserIdentity links a user to an account at an external OpenID Connect
+// provider. Subject is the provider's stable id for that account; Email is copied
+// from the latest login and never used to match accounts
+type UserIdentity struct {
+	ID          uint       `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	UserID      uint       `json:"user_id" gorm:"column:user_id;not null;index"`
+	Provider    string     `json:"provider" gorm:"column:provider;size:64;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject     string     `json:"-" gorm:"column:subject;size:255;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email       string     `json:"email,omitempty" gorm:"column:email;size:255"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" gorm:"column:last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCLoginState is a single sign-on login waiting for the user to come back from
+// the provider. Only the hash of the state is kept; the PKCE verifier and nonce
+// are checked against what the provider returns, and the row is used once
+type OIDCLoginState struct {
+	StateHash    string    `json:"-" gorm:"column:state_hash;size:64;primaryKey"`
+	Provider     string    `json:"provider" gorm:"column:provider;size:64;not null"`
+	CodeVerifier string    `json:"-" gorm:"column:code_verifier;size:128;not null"`
+	Nonce        string    `json:"-" gorm:"column:nonce;size:128;not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"column:expires_at;not null;index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// LoginAttempt counts consecutive failed logins for one key, either an account
+// ("user:<name>") or a client address ("ip:<addr>"). How long the key stays locked
+// follows from Failures and LastFailureAt, so no lock deadline is stored.
+type LoginAttempt struct {
+	Key           string    `json:"key" gorm:"column:key;primaryKey;size:191"`
+	Failures      int       `json:"failures" gorm:"column:failures;not null;default:0"`
+	LastFailureAt time.Time `json:"last_failure_at" gorm:"column:last_failure_at;not null;index"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
@@ -2,22 +2,111 @@ package model
 
 import (
 	"time"
+	"unicode/utf8"
 )
 
+// MessagePreviewLength is how many characters of a quoted message are embedded in a reply
+const MessagePreviewLength = 100
+
 type Message struct {
-	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	ConversationID uint      `json:"conversation_id" gorm:"column:conversation_id;not null"`
-	SenderID       uint      `json:"sender_id" gorm:"column:sender_id;not null"`
-	Content        string    `json:"content" gorm:"column:content;type:text;not null"`
-	MessageType    string    `json:"message_type" gorm:"column:message_type;default:'text'"`
-	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
-	SessionID      string    `json:"session_id,omitempty"`
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement;index:idx_messages_conversation_id_id,priority:2"`
+	ConversationID uint       `json:"conversation_id" gorm:"column:conversation_id;not null;index:idx_messages_conversation_id_id,priority:1"`
+	SenderID       uint       `json:"sender_id" gorm:"column:sender_id;not null"`
+	Content        string     `json:"content" gorm:"column:content;type:text;not null"`
+	MessageType    string     `json:"message_type" gorm:"column:message_type;default:'text'"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	EditedAt       *time.Time `json:"edited_at,omitempty" gorm:"column:edited_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" gorm:"column:deleted_at"`
+	ReplyToID      *uint      `json:"reply_to_id,omitempty" gorm:"column:reply_to_id"`
+	ThreadRootID   *uint      `json:"thread_root_id,omitempty" gorm:"column:thread_root_id;index"`
+	AttachmentID   *uint      `json:"attachment_id,omitempty" gorm:"column:attachment_id"`
+	SessionID      string     `json:"session_id,omitempty"`
+
+	ReplyCount int64              `json:"reply_count,omitempty" gorm:"-"`
+	ReplyTo    *MessagePreview    `json:"reply_to,omitempty" gorm:"-"`
+	Reactions  []*ReactionSummary `json:"reactions,omitempty" gorm:"-"`
+	Attachment *Attachment        `json:"attachment,omitempty" gorm:"-"`
 
 	Conversation *Conversation `json:"conversation,omitempty" gorm:"foreignKey:ConversationID;references:ID"`
 	Sender       *User         `json:"sender,omitempty" gorm:"foreignKey:SenderID;references:ID"`
 }
 
+// MaxMessageSearchLength caps how many characters a message search query may have
+const MaxMessageSearchLength = 200
+
+// MessageSearchParams asks for a page of messages matching Query in the caller's
+// conversations, optionally narrowed to one conversation, one sender and a time
+// range. BeforeID is the next_cursor of the previous page
+type MessageSearchParams struct {
+	Query          string     `form:"q" json:"q"`
+	ConversationID uint       `form:"conversation_id" json:"conversation_id,omitempty"`
+	SenderID       uint       `form:"sender_id" json:"sender_id,omitempty"`
+	From           *time.Time `form:"from" json:"from,omitempty"`
+	To             *time.Time `form:"to" json:"to,omitempty"`
+	BeforeID       uint       `form:"before_id" json:"before_id,omitempty"`
+	Limit          int        `form:"limit" json:"limit,omitempty"`
+}
+
+// MessageSearchFilter is what a search index is asked for: messages containing a
+// word starting with each of Terms, in one of ConversationIDs, newest first
+type MessageSearchFilter struct {
+	Terms           []string
+	ConversationIDs []uint
+	SenderID        uint
+	From            *time.Time
+	To              *time.Time
+	BeforeID        uint
+	Limit           int
+}
+
+// MessageSearchResult is a message found by a search. Highlight is an HTML-escaped
+// excerpt of its content with the matching words wrapped in <mark>
+type MessageSearchResult struct {
+	Message   *Message `json:"message"`
+	Highlight string   `json:"highlight"`
+}
+
+// MessageEdit keeps the content a message had before an edit
+type MessageEdit struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageID       uint      `json:"message_id" gorm:"column:message_id;not null;index"`
+	EditorID        uint      `json:"editor_id" gorm:"column:editor_id;not null"`
+	PreviousContent string    `json:"previous_content" gorm:"column:previous_content;type:text;not null"`
+	CreatedAt       time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// MessagePreview is the trimmed copy of a quoted message embedded in replies
+type MessagePreview struct {
+	ID       uint   `json:"id"`
+	SenderID uint   `json:"sender_id"`
+	Content  string `json:"content"`
+	Deleted  bool   `json:"deleted,omitempty"`
+}
+
+// NewMessagePreview trims a message down to what a reply needs to quote it
+func NewMessagePreview(m *Message) *MessagePreview {
+	content := m.Content
+	if utf8.RuneCountInString(content) > MessagePreviewLength {
+		content = string([]rune(content)[:MessagePreviewLength]) + "…"
+	}
+	return &MessagePreview{
+		ID:       m.ID,
+		SenderID: m.SenderID,
+		Content:  content,
+		Deleted:  m.IsDeleted(),
+	}
+}
+
+// IsDeleted reports whether the message has been soft deleted and should render as a tombstone
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
 func (Message) TableName() string {
 	return "messages"
 }
+
+func (MessageEdit) TableName() string {
+	return "message_edits"
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// UserMFA holds a user's TOTP secret. It is pending from enrollment until the
+// first code is verified, and only then required at login. LastUsedStep is the
+// time step of the last accepted code, so a code cannot be used twice.
+type UserMFA struct {
+	UserID       uint       `json:"user_id" gorm:"column:user_id;primaryKey"`
+	Secret       string     `json:"-" gorm:"column:secret;size:64;not null"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty" gorm:"column:enabled_at"`
+	LastUsedStep int64      `json:"-" gorm:"column:last_used_step;not null;default:0"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// Enabled reports whether logins must present a second factor
+func (m *UserMFA) Enabled() bool {
+	return m.EnabledAt != nil
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode is one single-use code that stands in for a TOTP code when the
+// authenticator is lost. Only its hash is kept.
+type MFARecoveryCode struct {
+	ID        uint       `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"column:user_id;not null;index"`
+	CodeHash  string     `json:"-" gorm:"column:code_hash;size:64;not null;uniqueIndex"`
+	UsedAt    *time.Time `json:"used_at,omitempty" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
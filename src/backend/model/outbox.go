@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Outbox destinations
+const (
+	// OutboxDestinationEvents publishes a message event for other services
+	OutboxDestinationEvents = "events"
+	// OutboxDestinationSocket broadcasts to connected clients through the socket service
+	OutboxDestinationSocket = "socket"
+)
+
+// OutboxLease is how long a delivery in progress is left to whoever claimed it
+// before the relay may try it again
+const OutboxLease = 30 * time.Second
+
+// OutboxEvent is a delivery saved in the same transaction as the change it
+// announces, so the change is never saved without it. Deliveries are tried
+// until DeliveredAt is set, so a receiver may see one more than once.
+type OutboxEvent struct {
+	ID          uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Destination string `json:"destination" gorm:"column:destination;type:varchar(16);not null;index:idx_outbox_lane,priority:1"`
+	// Key keeps the deliveries of one conversation in order
+	Key     string `json:"key" gorm:"column:event_key;type:varchar(64);not null;index:idx_outbox_lane,priority:2"`
+	Payload string `json:"payload" gorm:"column:payload;type:mediumtext;not null"`
+	// Attempts counts claims; a claim only succeeds against the count it read
+	Attempts      int        `json:"attempts" gorm:"column:attempts;not null;default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at;not null;index:idx_outbox_due,priority:3"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" gorm:"column:delivered_at;index:idx_outbox_due,priority:1"`
+	FailedAt      *time.Time `json:"failed_at,omitempty" gorm:"column:failed_at;index:idx_outbox_due,priority:2"`
+	LastError     string     `json:"last_error,omitempty" gorm:"column:last_error;type:varchar(512)"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// NewOutboxEvent encodes payload for destination. The delivery is leased to the
+// request that saves it, which tries it right after committing
+func NewOutboxEvent(destination, key string, payload any) (*OutboxEvent, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s outbox payload: %w", destination, err)
+	}
+	return &OutboxEvent{
+		Destination:   destination,
+		Key:           key,
+		Payload:       string(encoded),
+		NextAttemptAt: time.Now().Add(OutboxLease),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package model
+
+// Pagination defaults
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// CursorParams describes a keyset page request.
+// BeforeID returns items older than the cursor, AfterID returns items newer than it;
+// when both are empty the newest items are returned.
+type CursorParams struct {
+	BeforeID uint `form:"before_id" json:"before_id,omitempty"`
+	AfterID  uint `form:"after_id" json:"after_id,omitempty"`
+	Limit    int  `form:"limit" json:"limit,omitempty"`
+}
+
+// Normalize applies the default and maximum page size
+func (p CursorParams) Normalize() CursorParams {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	return p
+}
+
+// Page is the envelope returned by cursor-paginated endpoints.
+// Items are always ordered newest first; NextCursor is the ID to pass as
+// before_id (or after_id when paging forward) to fetch the following page.
+type Page[T any] struct {
+	Items      []T  `json:"items"`
+	NextCursor uint `json:"next_cursor,omitempty"`
+	HasMore    bool `json:"has_more"`
+}
+
+// NewPage builds a page from rows fetched with limit+1, trimming the extra row
+// used to detect whether more items exist. rows must already be in the order
+// they were fetched (newest first for backward paging, oldest first for forward paging).
+func NewPage[T any](rows []T, params CursorParams, idOf func(T) uint) *Page[T] {
+	page := &Page[T]{Items: rows}
+	if len(rows) > params.Limit {
+		page.Items = rows[:params.Limit]
+		page.HasMore = true
+	}
+
+	if params.AfterID != 0 {
+		// Forward pages are fetched oldest first; flip them so clients always get newest first
+		for i, j := 0, len(page.Items)-1; i < j; i, j = i+1, j-1 {
+			page.Items[i], page.Items[j] = page.Items[j], page.Items[i]
+		}
+		page.NextCursor = params.AfterID
+		if len(page.Items) > 0 {
+			page.NextCursor = idOf(page.Items[0])
+		}
+		return page
+	}
+
+	if len(page.Items) > 0 {
+		page.NextCursor = idOf(page.Items[len(page.Items)-1])
+	}
+	return page
+}
@@ -15,3 +15,10 @@ type BroadcastMessage struct {
 	Event   string `json:"event"`
 	Payload any    `json:"payload"`
 }
+
+// DisconnectMessage asks the socket service to drop a user's live connections,
+// either those of one session or, when SessionID is empty, all of them
+type DisconnectMessage struct {
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"session_id,omitempty"`
+}
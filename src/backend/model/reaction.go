@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// MaxEmojiLength bounds the stored reaction so it fits the emoji column
+const MaxEmojiLength = 32
+
+// MessageReaction is one user's emoji on one message; a user reacts with a given emoji at most once
+type MessageReaction struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageID uint      `json:"message_id" gorm:"column:message_id;not null;uniqueIndex:idx_message_reactions_message_user_emoji,priority:1"`
+	UserID    uint      `json:"user_id" gorm:"column:user_id;not null;uniqueIndex:idx_message_reactions_message_user_emoji,priority:2"`
+	Emoji     string    `json:"emoji" gorm:"column:emoji;type:varchar(32);not null;uniqueIndex:idx_message_reactions_message_user_emoji,priority:3"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// ReactionSummary aggregates the reactions on a message for one emoji
+type ReactionSummary struct {
+	Emoji       string `json:"emoji"`
+	Count       int64  `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me,omitempty"`
+}
+
+func (MessageReaction) TableName() string {
+	return "message_reactions"
+}
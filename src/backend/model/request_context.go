@@ -12,6 +12,8 @@ type RequestContext struct {
 	Token    string
 	UserID   uint
 	SessionID string
+	ClientIP  string
+	UserAgent string
 	span     trace.Span
 }
 
@@ -39,6 +41,14 @@ func NewRequestContext(ctx context.Context) *RequestContext {
 		reqCtx.SessionID = sessionID
 	}
 
+	// Extract client details
+	if clientIP, ok := ctx.Value("client_ip").(string); ok {
+		reqCtx.ClientIP = clientIP
+	}
+	if userAgent, ok := ctx.Value("user_agent").(string); ok {
+		reqCtx.UserAgent = userAgent
+	}
+
 	// Extract span from context (OpenTelemetry)
 	reqCtx.span = trace.SpanFromContext(ctx)
 
@@ -58,6 +68,8 @@ func (r *RequestContext) WithToken(token string) *RequestContext {
 		Token:     token,
 		UserID:    r.UserID,
 		SessionID: r.SessionID,
+		ClientIP:  r.ClientIP,
+		UserAgent: r.UserAgent,
 		span:      r.span,
 	}
 }
@@ -70,6 +82,8 @@ func (r *RequestContext) WithUserID(userID uint) *RequestContext {
 		Token:     r.Token,
 		UserID:    userID,
 		SessionID: r.SessionID,
+		ClientIP:  r.ClientIP,
+		UserAgent: r.UserAgent,
 		span:      r.span,
 	}
 }
@@ -82,6 +96,8 @@ func (r *RequestContext) WithSessionID(sessionID string) *RequestContext {
 		Token:     r.Token,
 		UserID:    r.UserID,
 		SessionID: sessionID,
+		ClientIP:  r.ClientIP,
+		UserAgent: r.UserAgent,
 		span:      r.span,
 	}
 }
@@ -96,6 +112,23 @@ func (r *RequestContext) WithClaims(token string, userID uint, sessionID string)
 		Token:     token,
 		UserID:    userID,
 		SessionID: sessionID,
+		ClientIP:  r.ClientIP,
+		UserAgent: r.UserAgent,
+		span:      r.span,
+	}
+}
+
+// WithClient records where the request came from: the caller's IP and user agent
+func (r *RequestContext) WithClient(clientIP, userAgent string) *RequestContext {
+	ctx := context.WithValue(r.ctx, "client_ip", clientIP)
+	ctx = context.WithValue(ctx, "user_agent", userAgent)
+	return &RequestContext{
+		ctx:       ctx,
+		Token:     r.Token,
+		UserID:    r.UserID,
+		SessionID: r.SessionID,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
 		span:      r.span,
 	}
 }
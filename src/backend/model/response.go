@@ -9,6 +9,7 @@ const (
 	CodeNotFound       = 404 // Not Found
 	CodeConflict       = 409 // Conflict
 	CodeValidation     = 422 // Validation Error
+	CodeTooManyRequests = 429 // Too Many Requests
 	CodeInternalError  = 500 // Internal Server Error
 )
 
@@ -22,6 +23,7 @@ var CodeMessages = map[int]string{
 	CodeNotFound:      "Not Found",
 	CodeConflict:      "Conflict",
 	CodeValidation:    "Validation Error",
+	CodeTooManyRequests: "Too Many Requests",
 	CodeInternalError: "Internal Server Error",
 }
 
@@ -136,6 +138,11 @@ func ValidationErrorWithErrors[T any](message string, errors []Error) Response[T
 	return ErrorArray[T](CodeValidation, message, errors)
 }
 
+// TooManyRequests creates a 429 Too Many Requests error response
+func TooManyRequests[T any](message string) Response[T] {
+	return ErrorResponse[T](CodeTooManyRequests, message)
+}
+
 // InternalError creates a 500 Internal Server Error response
 func InternalError[T any](message string) Response[T] {
 	return ErrorResponse[T](CodeInternalError, message)
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// RevokedSession marks a single login session as logged out. Rows are only
+// needed until the session's token would have expired anyway.
+type RevokedSession struct {
+	SessionID string    `json:"session_id" gorm:"column:session_id;primaryKey;size:64"`
+	UserID    uint      `json:"user_id" gorm:"column:user_id;not null;index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"column:expires_at;not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RevokedSession) TableName() string {
+	return "revoked_sessions"
+}
+
+// UserTokenCutoff invalidates every token a user was issued before RevokedBefore;
+// it backs "log out all sessions"
+type UserTokenCutoff struct {
+	UserID        uint      `json:"user_id" gorm:"column:user_id;primaryKey;autoIncrement:false"`
+	RevokedBefore time.Time `json:"revoked_before" gorm:"column:revoked_before;not null"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (UserTokenCutoff) TableName() string {
+	return "user_token_cutoffs"
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// MaxUserAgentLength caps how much of a client's user agent is kept
+const MaxUserAgentLength = 512
+
+// Session is one login. Its ID is the session_id claim of every access token
+// minted for it, and it holds the hash of the one refresh token that may renew it.
+// LastUsedAt and IPAddress follow the latest login or refresh, so they are at
+// most one access token lifetime out of date.
+type Session struct {
+	ID               string     `json:"id" gorm:"column:id;primaryKey;size:64"`
+	UserID           uint       `json:"user_id" gorm:"column:user_id;not null;index"`
+	RefreshTokenHash string     `json:"-" gorm:"column:refresh_token_hash;size:64;not null;uniqueIndex"`
+	UserAgent        string     `json:"user_agent" gorm:"column:user_agent;size:512"`
+	IPAddress        string     `json:"ip_address" gorm:"column:ip_address;size:64"`
+	LastUsedAt       time.Time  `json:"last_used_at" gorm:"column:last_used_at"`
+	ExpiresAt        time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty" gorm:"column:revoked_at"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	Current          bool       `json:"current" gorm:"-"`
+}
+
+// Active reports whether the session can still be refreshed
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// RotatedRefreshToken remembers a refresh token that was exchanged for a new
+// one, so presenting it again can be recognised as reuse of a stolen token
+type RotatedRefreshToken struct {
+	TokenHash string    `json:"-" gorm:"column:token_hash;primaryKey;size:64"`
+	SessionID string    `json:"session_id" gorm:"column:session_id;size:64;not null;index"`
+	UserID    uint      `json:"user_id" gorm:"column:user_id;not null"`
+	RotatedAt time.Time `json:"rotated_at" gorm:"column:rotated_at;not null"`
+}
+
+func (RotatedRefreshToken) TableName() string {
+	return "rotated_refresh_tokens"
+}
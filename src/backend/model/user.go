@@ -4,12 +4,98 @@ import (
 	"time"
 )
 
+// Profile field limits, in characters
+const (
+	MaxDisplayNameLength = 64
+	MaxBioLength         = 500
+	MaxStatusTextLength  = 100
+)
+
 type User struct {
-	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserName    string         `json:"username" gorm:"column:username;unique;not null"`
-	Password    string        `json:"-" gorm:"column:password;not null"`
-	CreatedAt   time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt   time.Time      `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	ID                 uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserName           string     `json:"username" gorm:"column:username;unique;not null"`
+	Password           string     `json:"-" gorm:"column:password;not null"`
+	DisplayName        string     `json:"display_name" gorm:"column:display_name;size:64;not null;default:''"`
+	AvatarAttachmentID *uint      `json:"avatar_attachment_id,omitempty" gorm:"column:avatar_attachment_id"`
+	Bio                string     `json:"-" gorm:"column:bio;size:500;not null;default:''"`
+	StatusText         string     `json:"-" gorm:"column:status_text;size:100;not null;default:''"`
+	StatusExpiresAt    *time.Time `json:"-" gorm:"column:status_expires_at"`
+	// DeactivatedAt marks a closed account; deactivated users are left out of the directory
+	DeactivatedAt *time.Time `json:"-" gorm:"column:deactivated_at;index"`
+	Online        bool       `json:"-" gorm:"column:online;not null;default:false"`
+	LastSeenAt    *time.Time `json:"last_seen_at,omitempty" gorm:"column:last_seen_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// UserPresence is the online state of a user as reported by the socket service
+type UserPresence struct {
+	UserID     uint       `json:"user_id"`
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+}
+
+// UserStatus is a custom status message; one with ExpiresAt is cleared at that time
+type UserStatus struct {
+	Text      string     `json:"text"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// UserProfile is what other users see of a user
+type UserProfile struct {
+	UserID      uint        `json:"user_id"`
+	UserName    string      `json:"username"`
+	DisplayName string      `json:"display_name"`
+	AvatarURL   string      `json:"avatar_url,omitempty"`
+	Bio         string      `json:"bio"`
+	Status      *UserStatus `json:"status"`
+}
+
+// MaxUserSearchLength bounds a directory search query, in characters
+const MaxUserSearchLength = 64
+
+// UserSearchParams asks for a page of the user directory. Without Query every user
+// is listed by username; Cursor is the next_cursor of the previous page
+type UserSearchParams struct {
+	Query  string `form:"q" json:"q,omitempty"`
+	Cursor string `form:"cursor" json:"cursor,omitempty"`
+	Limit  int    `form:"limit" json:"limit,omitempty"`
+}
+
+// UserSearchCursor is the position after which a search page continues
+type UserSearchCursor struct {
+	Rank     int    `json:"r"`
+	UserName string `json:"u"`
+}
+
+// UserMatch is a user found by a search; lower ranks are better matches
+type UserMatch struct {
+	User *User
+	Rank int
+}
+
+// UserSearchPage is a page of the user directory, best matches first
+type UserSearchPage struct {
+	Items      []*UserProfile `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+}
+
+// Profile projects the user to its public profile, leaving out a status that expired before now
+func (u *User) Profile(now time.Time) *UserProfile {
+	profile := &UserProfile{
+		UserID:      u.ID,
+		UserName:    u.UserName,
+		DisplayName: u.DisplayName,
+		Bio:         u.Bio,
+	}
+	if u.AvatarAttachmentID != nil {
+		profile.AvatarURL = (&Attachment{ID: *u.AvatarAttachmentID}).DownloadPath()
+	}
+	if u.StatusText != "" && (u.StatusExpiresAt == nil || now.Before(*u.StatusExpiresAt)) {
+		profile.Status = &UserStatus{Text: u.StatusText, ExpiresAt: u.StatusExpiresAt}
+	}
+	return profile
 }
 
 func (User) TableName() string {
@@ -0,0 +1,176 @@
+package attachment
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"local/config"
+	"local/infra/provider/blob"
+	"local/infra/repo"
+	"local/model"
+	"local/service/common"
+	"local/service/conversation"
+	"local/util/logger"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// sniffLength is how many leading bytes http.DetectContentType looks at
+const sniffLength = 512
+
+// MaxAvatarBytes caps a profile picture below the general attachment limit
+const MaxAvatarBytes = 2 << 20
+
+type AttachmentService interface {
+	Upload(reqCtx *model.RequestContext, conversationID, userID uint, fileName string, size int64, body io.Reader) model.Response[*model.Attachment]
+	UploadAvatar(reqCtx *model.RequestContext, userID uint, fileName string, size int64, body io.Reader) model.Response[*model.Attachment]
+	Download(reqCtx *model.RequestContext, attachmentID, userID uint) model.Response[*model.AttachmentContent]
+}
+
+type attachmentService struct {
+	repo   repo.RepositoryInterface
+	blob   blob.BlobStore
+	cvsSvc conversation.ConversationService
+}
+
+// Upload validates and stores a file for a conversation the user belongs to.
+// The MIME type is sniffed from the content rather than trusted from the client.
+func (svc *attachmentService) Upload(reqCtx *model.RequestContext, conversationID, userID uint, fileName string, size int64, body io.Reader) model.Response[*model.Attachment] {
+	logger.Info(reqCtx, "Upload called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+		"size":            size,
+	})
+	if authResponse := svc.cvsSvc.AuthorizeMember(reqCtx, conversationID, userID); !authResponse.OK() {
+		return model.ErrorArray[*model.Attachment](authResponse.Code, authResponse.Message, authResponse.Errors)
+	}
+	key := fmt.Sprintf("conversations/%d/%s", conversationID, uuid.NewString())
+	return svc.store(reqCtx, &model.Attachment{ConversationID: conversationID, UploaderID: userID}, key, fileName, size, body, config.Config.AttachmentMaxBytes, allowedType)
+}
+
+// UploadAvatar stores an image the user can then pick as their profile picture.
+// Avatars belong to no conversation, so any signed-in user may download them once picked
+func (svc *attachmentService) UploadAvatar(reqCtx *model.RequestContext, userID uint, fileName string, size int64, body io.Reader) model.Response[*model.Attachment] {
+	logger.Info(reqCtx, "UploadAvatar called", map[string]interface{}{"user_id": userID, "size": size})
+	maxBytes := config.Config.AttachmentMaxBytes
+	if maxBytes > MaxAvatarBytes {
+		maxBytes = MaxAvatarBytes
+	}
+	key := fmt.Sprintf("avatars/%d/%s", userID, uuid.NewString())
+	return svc.store(reqCtx, &model.Attachment{UploaderID: userID}, key, fileName, size, body, maxBytes, func(contentType string) bool {
+		return strings.HasPrefix(contentType, "image/") && allowedType(contentType)
+	})
+}
+
+// store validates a file, writes it to the blob store under key and records it as attachment
+func (svc *attachmentService) store(reqCtx *model.RequestContext, attachment *model.Attachment, key, fileName string, size int64, body io.Reader, maxBytes int64, allowed func(string) bool) model.Response[*model.Attachment] {
+	if size <= 0 {
+		return model.ValidationError[*model.Attachment]("File is empty")
+	}
+	if size > maxBytes {
+		return model.ValidationError[*model.Attachment](fmt.Sprintf("File exceeds the %d byte limit", maxBytes))
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return model.BadRequest[*model.Attachment]("Failed to read file")
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !allowed(contentType) {
+		return model.ValidationError[*model.Attachment](fmt.Sprintf("File type %s is not allowed", contentType))
+	}
+
+	content := io.LimitReader(io.MultiReader(bytes.NewReader(head), body), size)
+	if err := svc.blob.Put(reqCtx.Context(), key, content, size, contentType); err != nil {
+		logger.Error(reqCtx, "Failed to store attachment", err)
+		return model.InternalError[*model.Attachment]("Failed to store attachment")
+	}
+
+	attachment.FileName = cleanFileName(fileName)
+	attachment.ContentType = contentType
+	attachment.Size = size
+	attachment.StorageKey = key
+	createResponse := svc.repo.Attachment().Create(reqCtx, attachment)
+	if !createResponse.OK() {
+		if err := svc.blob.Delete(reqCtx.Context(), key); err != nil {
+			logger.Error(reqCtx, "Failed to clean up orphaned attachment", err)
+		}
+	}
+	return createResponse
+}
+
+// Download opens an attachment for a member of the conversation it was uploaded to,
+// or an avatar for anyone once a user picked it. The caller must close the returned body.
+func (svc *attachmentService) Download(reqCtx *model.RequestContext, attachmentID, userID uint) model.Response[*model.AttachmentContent] {
+	logger.Info(reqCtx, "Download called", map[string]interface{}{"attachment_id": attachmentID, "user_id": userID})
+	attachmentResponse := svc.repo.Attachment().GetByID(reqCtx, attachmentID)
+	if !attachmentResponse.OK() {
+		return model.ErrorArray[*model.AttachmentContent](attachmentResponse.Code, attachmentResponse.Message, attachmentResponse.Errors)
+	}
+	attachment := attachmentResponse.Data
+	if attachment.IsAvatar() {
+		// Until it is picked, only the uploader knows the avatar exists
+		if attachment.UploaderID != userID {
+			ownerResponse := svc.repo.User().QueryOne(reqCtx, &model.User{AvatarAttachmentID: &attachment.ID})
+			if !ownerResponse.OK() {
+				return model.NotFound[*model.AttachmentContent]("Attachment not found")
+			}
+		}
+	} else {
+		if authResponse := svc.cvsSvc.AuthorizeMember(reqCtx, attachment.ConversationID, userID); !authResponse.OK() {
+			return model.ErrorArray[*model.AttachmentContent](authResponse.Code, authResponse.Message, authResponse.Errors)
+		}
+		// Until it is sent, only the uploader knows the attachment exists
+		if attachment.MessageID == nil && attachment.UploaderID != userID {
+			return model.NotFound[*model.AttachmentContent]("Attachment not found")
+		}
+	}
+	if attachment.MessageID != nil {
+		messageResponse := svc.repo.Message().GetByID(reqCtx, *attachment.MessageID)
+		if !messageResponse.OK() || messageResponse.Data.IsDeleted() {
+			return model.NotFound[*model.AttachmentContent]("Attachment not found")
+		}
+	}
+
+	body, err := svc.blob.Get(reqCtx.Context(), attachment.StorageKey)
+	if err == blob.ErrNotFound {
+		return model.NotFound[*model.AttachmentContent]("Attachment not found")
+	}
+	if err != nil {
+		logger.Error(reqCtx, "Failed to read attachment", err)
+		return model.InternalError[*model.AttachmentContent]("Failed to read attachment")
+	}
+	return model.SuccessResponse(&model.AttachmentContent{Attachment: attachment, Body: body}, "Attachment retrieved successfully")
+}
+
+func allowedType(contentType string) bool {
+	for _, allowed := range config.Config.AttachmentAllowedTypes {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFileName drops any client-supplied directories from the file name
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		return "file"
+	}
+	return name
+}
+
+func NewAttachmentService(params *common.Params, cvsSvc conversation.ConversationService) AttachmentService {
+	return &attachmentService{
+		repo:   params.Repo,
+		blob:   params.Blob,
+		cvsSvc: cvsSvc,
+	}
+}
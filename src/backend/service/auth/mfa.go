@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"local/config"
+	"local/infra/provider/totp"
+	"local/model"
+	"local/util/logger"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// MFAAudience marks challenge tokens, so they are never accepted as access tokens
+	MFAAudience = "simple-chat-mfa"
+	// recoveryCodeCount is how many recovery codes a confirmed enrollment hands out
+	recoveryCodeCount = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAEnrollment is what an authenticator app needs to start generating codes
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// EnrollMFA creates a new TOTP secret for the user. It is not required at login
+// until ConfirmMFA has seen a code generated from it
+func (svc *authService) EnrollMFA(reqCtx *model.RequestContext, userID uint) model.Response[*MFAEnrollment] {
+	logger.Info(reqCtx, "EnrollMFA called", map[string]interface{}{"user_id": userID})
+	userResponse := svc.repo.User().QueryOne(reqCtx, &model.User{ID: userID})
+	if !userResponse.OK() {
+		return model.ErrorArray[*MFAEnrollment](userResponse.Code, userResponse.Message, userResponse.Errors)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return model.InternalError[*MFAEnrollment]("Failed to generate secret")
+	}
+	saveResponse := svc.repo.MFA().SavePending(reqCtx, userID, secret)
+	if !saveResponse.OK() {
+		return model.ErrorArray[*MFAEnrollment](saveResponse.Code, saveResponse.Message, saveResponse.Errors)
+	}
+
+	return model.SuccessResponse(&MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(config.Config.MFAIssuer, userResponse.Data.UserName, secret),
+	}, "Two-factor authentication enrollment started")
+}
+
+// ConfirmMFA turns on two-factor authentication once the user proves their
+// authenticator works, and returns recovery codes that are never shown again
+func (svc *authService) ConfirmMFA(reqCtx *model.RequestContext, userID uint, code string) model.Response[[]string] {
+	logger.Info(reqCtx, "ConfirmMFA called", map[string]interface{}{"user_id": userID})
+	if code == "" {
+		return model.BadRequest[[]string]("Code is required")
+	}
+	mfaResponse := svc.repo.MFA().Get(reqCtx, userID)
+	if mfaResponse.Code == model.CodeNotFound {
+		return model.BadRequest[[]string]("Two-factor authentication enrollment has not been started")
+	}
+	if !mfaResponse.OK() {
+		return model.ErrorArray[[]string](mfaResponse.Code, mfaResponse.Message, mfaResponse.Errors)
+	}
+	mfa := mfaResponse.Data
+	if mfa.Enabled() {
+		return model.Conflict[[]string]("Two-factor authentication is already enabled")
+	}
+
+	step, ok, err := totp.Validate(mfa.Secret, code, svc.now(), mfa.LastUsedStep)
+	if err != nil {
+		logger.Error(reqCtx, "Failed to validate two-factor code", err, map[string]interface{}{"user_id": userID})
+		return model.InternalError[[]string]("Failed to validate code")
+	}
+	if !ok {
+		return model.ValidationError[[]string]("Invalid code")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return model.InternalError[[]string]("Failed to generate recovery codes")
+	}
+	enableResponse := svc.repo.MFA().Enable(reqCtx, userID, step, svc.now(), hashes)
+	if !enableResponse.OK() {
+		return model.ErrorArray[[]string](enableResponse.Code, enableResponse.Message, enableResponse.Errors)
+	}
+	return model.SuccessResponse(codes, "Two-factor authentication enabled")
+}
+
+// DisableMFA turns two-factor authentication off; it takes a current code or a
+// recovery code, so a stolen access token alone cannot remove it
+func (svc *authService) DisableMFA(reqCtx *model.RequestContext, userID uint, code string) model.Response[string] {
+	logger.Info(reqCtx, "DisableMFA called", map[string]interface{}{"user_id": userID})
+	if code == "" {
+		return model.BadRequest[string]("Code is required")
+	}
+	userResponse := svc.repo.User().QueryOne(reqCtx, &model.User{ID: userID})
+	if !userResponse.OK() {
+		return model.ErrorArray[string](userResponse.Code, userResponse.Message, userResponse.Errors)
+	}
+	mfaResponse := svc.repo.MFA().Get(reqCtx, userID)
+	if mfaResponse.Code == model.CodeNotFound || (mfaResponse.OK() && !mfaResponse.Data.Enabled()) {
+		return model.BadRequest[string]("Two-factor authentication is not enabled")
+	}
+	if !mfaResponse.OK() {
+		return model.ErrorArray[string](mfaResponse.Code, mfaResponse.Message, mfaResponse.Errors)
+	}
+
+	if errResponse := svc.checkSecondFactor(reqCtx, userResponse.Data, mfaResponse.Data, code); errResponse != nil {
+		return model.ErrorResponse[string](errResponse.Code, errResponse.Message)
+	}
+	deleteResponse := svc.repo.MFA().Delete(reqCtx, userID)
+	if !deleteResponse.OK() {
+		return model.ErrorArray[string](deleteResponse.Code, deleteResponse.Message, deleteResponse.Errors)
+	}
+	return model.SuccessResponse("", "Two-factor authentication disabled")
+}
+
+// VerifyMFA completes a login that stopped at the second factor: the challenge
+// token from Login plus a TOTP or recovery code buys the real token pair
+func (svc *authService) VerifyMFA(reqCtx *model.RequestContext, challenge, code string) model.Response[*TokenPair] {
+	logger.Info(reqCtx, "VerifyMFA called")
+	if challenge == "" || code == "" {
+		return model.BadRequest[*TokenPair]("Challenge token and code are required")
+	}
+
+	userID, err := svc.parseMFAChallenge(challenge)
+	if err != nil {
+		return model.Unauthorized[*TokenPair]("Invalid or expired challenge token")
+	}
+	userResponse := svc.repo.User().QueryOne(reqCtx, &model.User{ID: userID})
+	if !userResponse.OK() {
+		return model.Unauthorized[*TokenPair]("Invalid or expired challenge token")
+	}
+	user := userResponse.Data
+
+	mfaResponse := svc.repo.MFA().Get(reqCtx, userID)
+	if !mfaResponse.OK() || !mfaResponse.Data.Enabled() {
+		// Disabled since the challenge was issued; the password step must be redone
+		return model.Unauthorized[*TokenPair]("Invalid or expired challenge token")
+	}
+
+	if errResponse := svc.checkSecondFactor(reqCtx, user, mfaResponse.Data, code); errResponse != nil {
+		return model.ErrorResponse[*TokenPair](errResponse.Code, errResponse.Message)
+	}
+	svc.recordSuccess(reqCtx, user.UserName)
+	return svc.startSession(reqCtx, user, "Login successful")
+}
+
+// checkSecondFactor accepts a TOTP code or an unused recovery code, spending
+// either so it cannot be presented again. Wrong codes count towards the account
+// lockout, which is what stops a six digit code from being guessed
+func (svc *authService) checkSecondFactor(reqCtx *model.RequestContext, user *model.User, mfa *model.UserMFA, code string) *model.Error {
+	if lockErr := svc.lockoutError(reqCtx, user.UserName); lockErr != nil {
+		return lockErr
+	}
+
+	if isTOTPCode(code) {
+		step, ok, err := totp.Validate(mfa.Secret, code, svc.now(), mfa.LastUsedStep)
+		if err != nil {
+			logger.Error(reqCtx, "Failed to validate two-factor code", err, map[string]interface{}{"user_id": user.ID})
+			return &model.Error{Code: model.CodeInternalError, Message: "Failed to validate code"}
+		}
+		if ok {
+			useResponse := svc.repo.MFA().UseStep(reqCtx, user.ID, step)
+			if useResponse.OK() {
+				return nil
+			}
+			if useResponse.Code != model.CodeConflict {
+				return &model.Error{Code: useResponse.Code, Message: useResponse.Message}
+			}
+			// Another request spent this code first
+		}
+	} else {
+		useResponse := svc.repo.MFA().UseRecoveryCode(reqCtx, user.ID, hashRecoveryCode(code), svc.now())
+		if useResponse.OK() {
+			logger.Warn(reqCtx, "Recovery code used", map[string]interface{}{"user_id": user.ID})
+			return nil
+		}
+		if useResponse.Code != model.CodeNotFound {
+			return &model.Error{Code: useResponse.Code, Message: useResponse.Message}
+		}
+	}
+
+	svc.recordFailure(reqCtx, user.UserName)
+	return &model.Error{Code: model.CodeUnauthorized, Message: "Invalid code"}
+}
+
+// mfaChallenge is Login's answer for users with two-factor authentication on: a
+// short-lived token naming the user, which only VerifyMFA accepts
+func (svc *authService) mfaChallenge(user *model.User) model.Response[*TokenPair] {
+	now := svc.now()
+	expiresAt := now.Add(svc.mfaChallengeTTL)
+	token, err := svc.keys.Sign(&jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(user.ID), 10),
+		Audience:  jwt.ClaimStrings{MFAAudience},
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+	})
+	if err != nil {
+		return model.InternalError[*TokenPair]("Failed to generate token")
+	}
+	return model.SuccessResponse(&TokenPair{MFAToken: token, ExpiresAt: expiresAt}, "Two-factor authentication required")
+}
+
+func (svc *authService) parseMFAChallenge(challenge string) (uint, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(challenge, claims, svc.keys.Keyfunc,
+		jwt.WithValidMethods(svc.keys.Methods()),
+		jwt.WithAudience(MFAAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(svc.now),
+	)
+	if err != nil {
+		return 0, err
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID == 0 {
+		return 0, fmt.Errorf("invalid challenge subject %q", claims.Subject)
+	}
+	return uint(userID), nil
+}
+
+// isTOTPCode tells a six digit authenticator code from a recovery code
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCodes returns codes to show the user, formatted xxxxx-xxxxx, and
+// the hashes that are stored for them
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:10]
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, so codes can be typed the
+// way they read. Like refresh tokens they are random, so a plain SHA-256 will do
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
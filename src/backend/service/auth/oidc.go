@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"fmt"
+	"local/infra/provider/oidc"
+	"local/model"
+	"local/util/logger"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// maxUsernameLength keeps provisioned usernames readable
+	maxUsernameLength = 32
+	// usernameAttempts bounds how many numbered usernames are tried before a random suffix
+	usernameAttempts = 5
+)
+
+// OIDCProvider is an identity provider users may sign in with
+type OIDCProvider struct {
+	Name        string
+	DisplayName string
+}
+
+// OIDCAuthorization starts a single sign-on login: the client sends the user to
+// URL and keeps State to check against what comes back to its redirect page
+type OIDCAuthorization struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
+
+// OIDCProviders lists the configured identity providers
+func (svc *authService) OIDCProviders(reqCtx *model.RequestContext) model.Response[[]*OIDCProvider] {
+	logger.Info(reqCtx, "OIDCProviders called")
+	providers := make([]*OIDCProvider, 0)
+	for _, provider := range svc.oidc.List() {
+		providers = append(providers, &OIDCProvider{Name: provider.Name(), DisplayName: provider.DisplayName()})
+	}
+	return model.SuccessResponse(providers, "Identity providers retrieved successfully")
+}
+
+// StartOIDCLogin begins the authorization code flow with PKCE. The verifier and
+// nonce stay on the server with the state, so a code intercepted on its way back
+// is useless without them
+func (svc *authService) StartOIDCLogin(reqCtx *model.RequestContext, providerName string) model.Response[*OIDCAuthorization] {
+	logger.Info(reqCtx, "StartOIDCLogin called", map[string]interface{}{"provider": providerName})
+	provider, err := svc.oidc.Get(providerName)
+	if err != nil {
+		return model.NotFound[*OIDCAuthorization]("Identity provider not found")
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return model.InternalError[*OIDCAuthorization]("Failed to start login")
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return model.InternalError[*OIDCAuthorization]("Failed to start login")
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return model.InternalError[*OIDCAuthorization]("Failed to start login")
+	}
+
+	authURL, err := provider.AuthCodeURL(reqCtx.Context(), state, nonce, verifier)
+	if err != nil {
+		logger.Error(reqCtx, "Failed to reach identity provider", err, map[string]interface{}{"provider": providerName})
+		return model.InternalError[*OIDCAuthorization]("Identity provider is unavailable")
+	}
+
+	expiresAt := svc.now().Add(svc.oidcStateTTL)
+	saveResponse := svc.repo.Identity().SaveLoginState(reqCtx, &model.OIDCLoginState{
+		StateHash:    hashRefreshToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    expiresAt,
+	})
+	if !saveResponse.OK() {
+		return model.ErrorArray[*OIDCAuthorization](saveResponse.Code, saveResponse.Message, saveResponse.Errors)
+	}
+	return model.SuccessResponse(&OIDCAuthorization{URL: authURL, State: state, ExpiresAt: expiresAt}, "Login started")
+}
+
+// CompleteOIDCLogin redeems the code the provider sent back for a verified ID
+// token, then signs in the user linked to that identity, creating one on its
+// first login. Users with two-factor authentication on still get a challenge
+func (svc *authService) CompleteOIDCLogin(reqCtx *model.RequestContext, providerName, state, code string) model.Response[*TokenPair] {
+	logger.Info(reqCtx, "CompleteOIDCLogin called", map[string]interface{}{"provider": providerName})
+	if state == "" || code == "" {
+		return model.BadRequest[*TokenPair]("State and code are required")
+	}
+	provider, err := svc.oidc.Get(providerName)
+	if err != nil {
+		return model.NotFound[*TokenPair]("Identity provider not found")
+	}
+
+	stateResponse := svc.repo.Identity().ConsumeLoginState(reqCtx, hashRefreshToken(state), svc.now())
+	if stateResponse.Code == model.CodeNotFound {
+		return model.Unauthorized[*TokenPair]("Invalid or expired login state")
+	}
+	if !stateResponse.OK() {
+		return model.ErrorArray[*TokenPair](stateResponse.Code, stateResponse.Message, stateResponse.Errors)
+	}
+	login := stateResponse.Data
+	if login.Provider != providerName {
+		return model.Unauthorized[*TokenPair]("Invalid or expired login state")
+	}
+
+	rawIDToken, err := provider.Exchange(reqCtx.Context(), code, login.CodeVerifier)
+	if err != nil {
+		logger.Error(reqCtx, "Failed to redeem authorization code", err, map[string]interface{}{"provider": providerName})
+		return model.Unauthorized[*TokenPair]("Sign-in with the identity provider failed")
+	}
+	claims, err := provider.VerifyIDToken(reqCtx.Context(), rawIDToken, login.Nonce)
+	if err != nil {
+		logger.Error(reqCtx, "Rejected ID token", err, map[string]interface{}{"provider": providerName})
+		return model.Unauthorized[*TokenPair]("Sign-in with the identity provider failed")
+	}
+
+	identityResponse := svc.linkedIdentity(reqCtx, providerName, claims)
+	if !identityResponse.OK() {
+		return model.ErrorArray[*TokenPair](identityResponse.Code, identityResponse.Message, identityResponse.Errors)
+	}
+	identity := identityResponse.Data
+	if recordResponse := svc.repo.Identity().RecordLogin(reqCtx, identity.ID, claims.Email, svc.now()); !recordResponse.OK() {
+		logger.Warn(reqCtx, "Failed to record identity login", map[string]interface{}{"identity_id": identity.ID})
+	}
+
+	userResponse := svc.repo.User().QueryOne(reqCtx, &model.User{ID: identity.UserID})
+	if !userResponse.OK() {
+		return model.Unauthorized[*TokenPair]("Sign-in with the identity provider failed")
+	}
+	user := userResponse.Data
+
+	mfaResponse := svc.repo.MFA().Get(reqCtx, user.ID)
+	if mfaResponse.OK() && mfaResponse.Data.Enabled() {
+		return svc.mfaChallenge(user)
+	}
+	if !mfaResponse.OK() && mfaResponse.Code != model.CodeNotFound {
+		return model.ErrorArray[*TokenPair](mfaResponse.Code, mfaResponse.Message, mfaResponse.Errors)
+	}
+	return svc.startSession(reqCtx, user, "Login successful")
+}
+
+// linkedIdentity finds the user an identity belongs to, or provisions a new user
+// for it. Identities are never linked to existing users by username or email:
+// whoever controls an account at the provider would otherwise take over the
+// local user of the same name
+func (svc *authService) linkedIdentity(reqCtx *model.RequestContext, providerName string, claims *oidc.Claims) model.Response[*model.UserIdentity] {
+	identityResponse := svc.repo.Identity().Get(reqCtx, providerName, claims.Subject)
+	if identityResponse.Code != model.CodeNotFound {
+		return identityResponse
+	}
+
+	base := provisionedUsername(providerName, claims)
+	for attempt := 0; attempt <= usernameAttempts; attempt++ {
+		userName, err := usernameCandidate(base, attempt)
+		if err != nil {
+			return model.InternalError[*model.UserIdentity]("Failed to create user")
+		}
+		if existing := svc.repo.User().QueryOne(reqCtx, &model.User{UserName: userName}); existing.OK() {
+			continue
+		}
+
+		createResponse := svc.repo.Identity().CreateWithUser(reqCtx,
+			&model.User{UserName: userName, DisplayName: provisionedDisplayName(claims)},
+			&model.UserIdentity{Provider: providerName, Subject: claims.Subject, Email: claims.Email},
+		)
+		if createResponse.OK() {
+			logger.Info(reqCtx, "Provisioned user from identity provider", map[string]interface{}{
+				"provider": providerName,
+				"user_id":  createResponse.Data.UserID,
+				"username": userName,
+			})
+			return createResponse
+		}
+		if createResponse.Code != model.CodeConflict {
+			return createResponse
+		}
+		// Either the name was just taken or a concurrent login linked the identity
+		if linked := svc.repo.Identity().Get(reqCtx, providerName, claims.Subject); linked.Code != model.CodeNotFound {
+			return linked
+		}
+	}
+	return model.Conflict[*model.UserIdentity]("Could not find a free username")
+}
+
+// provisionedUsername picks the name a new user starts with: the provider's
+// preferred username, else the local part of the email, else the display name
+func provisionedUsername(providerName string, claims *oidc.Claims) string {
+	email, _, _ := strings.Cut(claims.Email, "@")
+	for _, candidate := range []string{claims.PreferredUsername, email, claims.Name} {
+		if name := sanitizeUsername(candidate); name != "" {
+			return name
+		}
+	}
+	return providerName + "-user"
+}
+
+// provisionedDisplayName takes the provider's name for the user, cut to the profile limit
+func provisionedDisplayName(claims *oidc.Claims) string {
+	runes := []rune(strings.TrimSpace(claims.Name))
+	if len(runes) > model.MaxDisplayNameLength {
+		runes = runes[:model.MaxDisplayNameLength]
+	}
+	return string(runes)
+}
+
+// sanitizeUsername keeps letters, digits, dots, dashes and underscores, turning
+// spaces into dots
+func sanitizeUsername(name string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('.')
+		}
+	}
+	runes := []rune(b.String())
+	if len(runes) > maxUsernameLength {
+		runes = runes[:maxUsernameLength]
+	}
+	return string(runes)
+}
+
+// usernameCandidate is base itself, then base2, base3 and so on, and finally
+// base with a random suffix
+func usernameCandidate(base string, attempt int) (string, error) {
+	switch {
+	case attempt == 0:
+		return base, nil
+	case attempt < usernameAttempts:
+		return fmt.Sprintf("%s%d", base, attempt+1), nil
+	default:
+		suffix, err := oidc.RandomString()
+		if err != nil {
+			return "", err
+		}
+		return base + "-" + strings.ToLower(suffix[:6]), nil
+	}
+}
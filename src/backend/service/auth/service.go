@@ -1,8 +1,19 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"local/client"
 	"local/config"
+	"local/infra/provider/lockout"
+	"local/infra/provider/oidc"
+	"local/infra/provider/password"
+	"local/infra/provider/revocation"
+	"local/infra/provider/signing"
 	"local/infra/repo"
 	"local/model"
 	"local/service/common"
@@ -15,6 +26,12 @@ import (
 	"github.com/google/uuid"
 )
 
+func init() {
+	// Issue timestamps with millisecond precision so a "log out all sessions"
+	// cutoff cleanly separates tokens issued just before it from those just after
+	jwt.TimePrecision = time.Millisecond
+}
+
 // JWTClaims represents the JWT token claims structure
 type JWTClaims struct {
 	SessionID string `json:"session_id"`
@@ -23,20 +40,57 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// TokenPair is what a login or refresh hands to the client: a short-lived access
+// token and the opaque refresh token that renews it exactly once. When the user
+// has two-factor authentication on, Login returns only MFAToken, a challenge to
+// exchange through VerifyMFA, and ExpiresAt is when that challenge runs out
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	MFAToken     string
+	ExpiresAt    time.Time
+}
+
 type AuthService interface {
 	Authenticate(reqCtx *model.RequestContext) model.Response[uint]
 	ParseToken(tokenStr string) model.Response[*JWTClaims]
 	CheckToken(reqCtx *model.RequestContext, token string) model.Response[bool]
 	GetMe(reqCtx *model.RequestContext) model.Response[*model.User]
 	Register(reqCtx *model.RequestContext, userName, password string) model.Response[*model.User]
-	Login(reqCtx *model.RequestContext, userName, password string) model.Response[string]
+	Login(reqCtx *model.RequestContext, userName, password string) model.Response[*TokenPair]
+	Refresh(reqCtx *model.RequestContext, refreshToken string) model.Response[*TokenPair]
 	Logout(reqCtx *model.RequestContext, token string) model.Response[string]
-	GetUsers(reqCtx *model.RequestContext) model.Response[[]*model.User]
+	LogoutAll(reqCtx *model.RequestContext, userID uint) model.Response[string]
+	ListSessions(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Session]
+	DeleteSession(reqCtx *model.RequestContext, userID uint, sessionID string) model.Response[string]
+	ChangePassword(reqCtx *model.RequestContext, userID uint, oldPassword, newPassword string) model.Response[string]
+	EnrollMFA(reqCtx *model.RequestContext, userID uint) model.Response[*MFAEnrollment]
+	ConfirmMFA(reqCtx *model.RequestContext, userID uint, code string) model.Response[[]string]
+	DisableMFA(reqCtx *model.RequestContext, userID uint, code string) model.Response[string]
+	VerifyMFA(reqCtx *model.RequestContext, challenge, code string) model.Response[*TokenPair]
+	OIDCProviders(reqCtx *model.RequestContext) model.Response[[]*OIDCProvider]
+	StartOIDCLogin(reqCtx *model.RequestContext, provider string) model.Response[*OIDCAuthorization]
+	CompleteOIDCLogin(reqCtx *model.RequestContext, provider, state, code string) model.Response[*TokenPair]
+	JWKS(reqCtx *model.RequestContext) model.Response[*signing.JWKS]
 }
 
 type authService struct {
-	repo      *repo.Repository
-	jwtSecret string
+	repo        repo.RepositoryInterface
+	client      *client.Client
+	revocations revocation.Store
+	keys        *signing.KeySet
+	passwords   *password.Policy
+	lockout     *lockout.Guard
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	// mfaChallengeTTL bounds the wait between the password and the second factor
+	mfaChallengeTTL time.Duration
+	// oidc holds the identity providers users may sign in with
+	oidc *oidc.Registry
+	// oidcStateTTL bounds the time a single sign-on login may spend at the provider
+	oidcStateTTL time.Duration
+	// now is the clock two-factor codes and single sign-on logins are checked against
+	now func() time.Time
 }
 
 func (svc *authService) Authenticate(reqCtx *model.RequestContext) model.Response[uint] {
@@ -57,24 +111,34 @@ func (svc *authService) Authenticate(reqCtx *model.RequestContext) model.Respons
 func (svc *authService) ParseToken(tokenStr string) model.Response[*JWTClaims] {
 	logger.Info(nil, "ParseToken called", map[string]interface{}{"token_length": len(tokenStr)})
 	// Parse token
-	token, err := jwt.ParseWithClaims(tokenStr, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Kiểm tra thuật toán
-		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(svc.jwtSecret), nil
-	}, jwt.WithValidMethods([]string{"HS256"}))
+	token, err := jwt.ParseWithClaims(tokenStr, &JWTClaims{}, svc.keys.Keyfunc, jwt.WithValidMethods(svc.keys.Methods()))
 
 	if err != nil {
 		return model.Unauthorized[*JWTClaims]("Invalid token")
 	}
 
 	// Lấy claims
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return model.SuccessResponse(claims, "Token parsed successfully")
+	claims, ok := token.Claims.(*JWTClaims)
+	// Service tokens share the keys but name no user
+	if !ok || !token.Valid || claims.UserID == 0 {
+		return model.Unauthorized[*JWTClaims]("Invalid token claims")
+	}
+
+	// Tokens without an issue time fall before any cutoff and are rejected once one exists
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := svc.revocations.IsRevoked(context.Background(), claims.UserID, claims.SessionID, issuedAt)
+	if err != nil {
+		logger.Error(nil, "Failed to check token revocation", err, map[string]interface{}{"user_id": claims.UserID})
+		return model.Unauthorized[*JWTClaims]("Unable to verify token")
+	}
+	if revoked {
+		return model.Unauthorized[*JWTClaims]("Token has been revoked")
 	}
 
-	return model.Unauthorized[*JWTClaims]("Invalid token claims")
+	return model.SuccessResponse(claims, "Token parsed successfully")
 }
 
 func (svc *authService) CheckToken(reqCtx *model.RequestContext, token string) model.Response[bool] {
@@ -102,7 +166,7 @@ func (svc *authService) GetMe(reqCtx *model.RequestContext) model.Response[*mode
 	}
 
 	claims := tokenResponse.Data
-	response := svc.repo.UserRepo.QueryOne(reqCtx, &model.User{ID: claims.UserID})
+	response := svc.repo.User().QueryOne(reqCtx, &model.User{ID: claims.UserID})
 	if !response.OK() {
 		return response
 	}
@@ -118,11 +182,15 @@ func (svc *authService) Register(reqCtx *model.RequestContext, userName, passwor
 		return model.BadRequest[*model.User]("Username and password are required")
 	}
 	// Check if user already exists
-	existingUserResponse := svc.repo.UserRepo.QueryOne(reqCtx, &model.User{UserName: userName})
+	existingUserResponse := svc.repo.User().QueryOne(reqCtx, &model.User{UserName: userName})
 	if existingUserResponse.OK() {
 		return model.Conflict[*model.User]("User already exists")
 	}
 
+	if problems := svc.passwords.Validate(userName, password); len(problems) > 0 {
+		return model.ValidationErrorWithErrors[*model.User]("Password does not meet the requirements", problems)
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -135,7 +203,7 @@ func (svc *authService) Register(reqCtx *model.RequestContext, userName, passwor
 		Password: string(hashedPassword),
 	}
 
-	response := svc.repo.UserRepo.Create(reqCtx, user)
+	response := svc.repo.User().Create(reqCtx, user)
 	if !response.OK() {
 		return response
 	}
@@ -145,16 +213,21 @@ func (svc *authService) Register(reqCtx *model.RequestContext, userName, passwor
 	return response
 }
 
-func (svc *authService) Login(reqCtx *model.RequestContext, userName, password string) model.Response[string] {
+func (svc *authService) Login(reqCtx *model.RequestContext, userName, password string) model.Response[*TokenPair] {
 	logger.Info(reqCtx, "Login called", map[string]interface{}{"username": userName})
 	if userName == "" || password == "" {
-		return model.BadRequest[string]("Username and password are required")
+		return model.BadRequest[*TokenPair]("Username and password are required")
+	}
+
+	if lockErr := svc.lockoutError(reqCtx, userName); lockErr != nil {
+		return model.ErrorResponse[*TokenPair](lockErr.Code, lockErr.Message)
 	}
 
 	// Find user
-	response := svc.repo.UserRepo.QueryOne(reqCtx, &model.User{UserName: userName})
+	response := svc.repo.User().QueryOne(reqCtx, &model.User{UserName: userName})
 	if !response.OK() {
-		return model.Unauthorized[string]("Invalid credentials")
+		svc.recordFailure(reqCtx, userName)
+		return model.Unauthorized[*TokenPair]("Invalid credentials")
 	}
 
 	user := response.Data
@@ -162,60 +235,397 @@ func (svc *authService) Login(reqCtx *model.RequestContext, userName, password s
 	// Check password
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return model.Unauthorized[string]("Invalid credentials")
+		svc.recordFailure(reqCtx, userName)
+		return model.Unauthorized[*TokenPair]("Invalid credentials")
+	}
+
+	// The account's failures are only cleared once the second factor is in too;
+	// otherwise knowing the password would buy unlimited guesses at the code
+	mfaResponse := svc.repo.MFA().Get(reqCtx, user.ID)
+	if mfaResponse.OK() && mfaResponse.Data.Enabled() {
+		return svc.mfaChallenge(user)
+	}
+	if !mfaResponse.OK() && mfaResponse.Code != model.CodeNotFound {
+		return model.ErrorArray[*TokenPair](mfaResponse.Code, mfaResponse.Message, mfaResponse.Errors)
+	}
+	svc.recordSuccess(reqCtx, userName)
+
+	return svc.startSession(reqCtx, user, "Login successful")
+}
+
+// startSession opens a session holding the first refresh token and issues its tokens
+func (svc *authService) startSession(reqCtx *model.RequestContext, user *model.User, message string) model.Response[*TokenPair] {
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return model.InternalError[*TokenPair]("Failed to generate token")
+	}
+	now := time.Now()
+	session := &model.Session{
+		ID:               uuid.New().String(),
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        truncateUserAgent(reqCtx.UserAgent),
+		IPAddress:        reqCtx.ClientIP,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(svc.refreshTTL),
+	}
+	sessionResponse := svc.repo.Session().Create(reqCtx, session)
+	if !sessionResponse.OK() {
+		return model.ErrorArray[*TokenPair](sessionResponse.Code, sessionResponse.Message, sessionResponse.Errors)
+	}
+
+	return svc.issueTokens(user, session.ID, refreshToken, message)
+}
+
+// lockoutError refuses a password check while the account or the caller's address
+// is locked out, or returns nil when the attempt may go ahead
+func (svc *authService) lockoutError(reqCtx *model.RequestContext, userName string) *model.Error {
+	wait, err := svc.lockout.Locked(reqCtx.Context(), userName, reqCtx.ClientIP)
+	if err != nil {
+		logger.Error(reqCtx, "Failed to check login lockout", err, map[string]interface{}{"username": userName})
+		return &model.Error{Code: model.CodeInternalError, Message: "Unable to verify credentials"}
+	}
+	if wait > 0 {
+		logger.Warn(reqCtx, "Login attempt while locked out", map[string]interface{}{"username": userName, "wait": wait.String()})
+		seconds := int(wait.Round(time.Second) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		return &model.Error{
+			Code:    model.CodeTooManyRequests,
+			Message: fmt.Sprintf("Too many failed login attempts; try again in %d seconds", seconds),
+		}
+	}
+	return nil
+}
+
+// recordFailure counts a wrong password against the account and the caller's address
+func (svc *authService) recordFailure(reqCtx *model.RequestContext, userName string) {
+	if err := svc.lockout.Fail(reqCtx.Context(), userName, reqCtx.ClientIP); err != nil {
+		logger.Error(reqCtx, "Failed to record login failure", err, map[string]interface{}{"username": userName})
+	}
+}
+
+// recordSuccess clears the account's failures once the right password is given
+func (svc *authService) recordSuccess(reqCtx *model.RequestContext, userName string) {
+	if err := svc.lockout.Succeed(reqCtx.Context(), userName); err != nil {
+		logger.Error(reqCtx, "Failed to reset login failures", err, map[string]interface{}{"username": userName})
+	}
+}
+
+// Refresh trades a refresh token for a new token pair. Each refresh token works
+// once; presenting one that was already rotated out means it was copied, so the
+// whole session is revoked for both the thief and the legitimate holder.
+func (svc *authService) Refresh(reqCtx *model.RequestContext, refreshToken string) model.Response[*TokenPair] {
+	logger.Info(reqCtx, "Refresh called")
+	if refreshToken == "" {
+		return model.BadRequest[*TokenPair]("Refresh token is required")
+	}
+
+	tokenHash := hashRefreshToken(refreshToken)
+	sessionResponse := svc.repo.Session().GetByRefreshTokenHash(reqCtx, tokenHash)
+	if sessionResponse.Code == model.CodeNotFound {
+		rotatedResponse := svc.repo.Session().GetRotated(reqCtx, tokenHash)
+		if rotatedResponse.OK() {
+			rotated := rotatedResponse.Data
+			logger.Warn(reqCtx, "Refresh token reuse detected", map[string]interface{}{
+				"user_id":    rotated.UserID,
+				"session_id": rotated.SessionID,
+			})
+			if err := svc.revokeSession(reqCtx, rotated.UserID, rotated.SessionID); err != nil {
+				logger.Error(reqCtx, "Failed to revoke reused session", err, map[string]interface{}{"session_id": rotated.SessionID})
+			}
+			return model.Unauthorized[*TokenPair]("Refresh token reuse detected; session revoked")
+		}
+		return model.Unauthorized[*TokenPair]("Invalid refresh token")
+	}
+	if !sessionResponse.OK() {
+		return model.ErrorArray[*TokenPair](sessionResponse.Code, sessionResponse.Message, sessionResponse.Errors)
+	}
+
+	session := sessionResponse.Data
+	if !session.Active(time.Now()) {
+		return model.Unauthorized[*TokenPair]("Session has expired")
+	}
+
+	userResponse := svc.repo.User().QueryOne(reqCtx, &model.User{ID: session.UserID})
+	if !userResponse.OK() {
+		return model.Unauthorized[*TokenPair]("Invalid refresh token")
+	}
+
+	if reqCtx.ClientIP != "" {
+		session.IPAddress = reqCtx.ClientIP
+	}
+	nextToken, nextHash, err := newRefreshToken()
+	if err != nil {
+		return model.InternalError[*TokenPair]("Failed to generate token")
+	}
+	rotateResponse := svc.repo.Session().Rotate(reqCtx, session, nextHash, time.Now().Add(svc.refreshTTL))
+	if rotateResponse.Code == model.CodeConflict {
+		// Someone else rotated this token between our read and write: same as reuse
+		logger.Warn(reqCtx, "Concurrent refresh token reuse detected", map[string]interface{}{
+			"user_id":    session.UserID,
+			"session_id": session.ID,
+		})
+		if err := svc.revokeSession(reqCtx, session.UserID, session.ID); err != nil {
+			logger.Error(reqCtx, "Failed to revoke reused session", err, map[string]interface{}{"session_id": session.ID})
+		}
+		return model.Unauthorized[*TokenPair]("Refresh token reuse detected; session revoked")
+	}
+	if !rotateResponse.OK() {
+		return model.ErrorArray[*TokenPair](rotateResponse.Code, rotateResponse.Message, rotateResponse.Errors)
+	}
+
+	return svc.issueTokens(userResponse.Data, session.ID, nextToken, "Token refreshed successfully")
+}
+
+// ListSessions returns the user's active sessions, flagging the one making the request
+func (svc *authService) ListSessions(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Session] {
+	logger.Info(reqCtx, "ListSessions called", map[string]interface{}{"user_id": userID})
+	response := svc.repo.Session().ListActiveByUser(reqCtx, userID, time.Now())
+	if !response.OK() {
+		return response
+	}
+	for _, session := range response.Data {
+		session.Current = session.ID == reqCtx.SessionID
+	}
+	return response
+}
+
+// DeleteSession signs one of the user's devices out: its tokens stop working and its sockets are closed
+func (svc *authService) DeleteSession(reqCtx *model.RequestContext, userID uint, sessionID string) model.Response[string] {
+	logger.Info(reqCtx, "DeleteSession called", map[string]interface{}{"user_id": userID, "session_id": sessionID})
+	sessionResponse := svc.repo.Session().GetByID(reqCtx, sessionID)
+	if sessionResponse.Code == model.CodeNotFound {
+		return model.NotFound[string]("Session not found")
+	}
+	if !sessionResponse.OK() {
+		return model.ErrorArray[string](sessionResponse.Code, sessionResponse.Message, sessionResponse.Errors)
+	}
+
+	// Other users' sessions look exactly like missing ones
+	session := sessionResponse.Data
+	if session.UserID != userID || session.RevokedAt != nil {
+		return model.NotFound[string]("Session not found")
+	}
+
+	if err := svc.revokeSession(reqCtx, userID, sessionID); err != nil {
+		logger.Error(reqCtx, "Failed to revoke session", err, map[string]interface{}{"session_id": sessionID})
+		return model.InternalError[string]("Failed to delete session")
+	}
+	return model.SuccessResponse("", "Session deleted successfully")
+}
+
+// ChangePassword replaces the user's password after checking the current one, then
+// signs out every other session; the session making the request stays signed in
+func (svc *authService) ChangePassword(reqCtx *model.RequestContext, userID uint, oldPassword, newPassword string) model.Response[string] {
+	logger.Info(reqCtx, "ChangePassword called", map[string]interface{}{"user_id": userID})
+	if oldPassword == "" || newPassword == "" {
+		return model.BadRequest[string]("Current and new passwords are required")
+	}
+
+	userResponse := svc.repo.User().QueryOne(reqCtx, &model.User{ID: userID})
+	if !userResponse.OK() {
+		return model.ErrorArray[string](userResponse.Code, userResponse.Message, userResponse.Errors)
+	}
+	user := userResponse.Data
+
+	// A stolen access token must not become a way to guess the password
+	if lockErr := svc.lockoutError(reqCtx, user.UserName); lockErr != nil {
+		return model.ErrorResponse[string](lockErr.Code, lockErr.Message)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		svc.recordFailure(reqCtx, user.UserName)
+		// Not 401: the caller's token is fine, and clients refresh tokens on 401
+		return model.Forbidden[string]("Current password is incorrect")
+	}
+	svc.recordSuccess(reqCtx, user.UserName)
+
+	if oldPassword == newPassword {
+		return model.ValidationError[string]("New password must differ from the current password")
+	}
+	if problems := svc.passwords.Validate(user.UserName, newPassword); len(problems) > 0 {
+		return model.ValidationErrorWithErrors[string]("Password does not meet the requirements", problems)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return model.InternalError[string]("Failed to hash password")
+	}
+	user.Password = string(hashedPassword)
+	updateResponse := svc.repo.User().Update(reqCtx, user)
+	if !updateResponse.OK() {
+		return model.ErrorArray[string](updateResponse.Code, updateResponse.Message, updateResponse.Errors)
+	}
+
+	// Whoever knew the old password may hold other sessions; end them all but this one
+	sessionsResponse := svc.repo.Session().ListActiveByUser(reqCtx, userID, time.Now())
+	if !sessionsResponse.OK() {
+		logger.Error(reqCtx, "Failed to list sessions after password change", fmt.Errorf("%s", sessionsResponse.ErrorString()), map[string]interface{}{"user_id": userID})
+		return model.InternalError[string]("Password changed but other sessions could not be signed out")
+	}
+	for _, session := range sessionsResponse.Data {
+		if session.ID == reqCtx.SessionID {
+			continue
+		}
+		if err := svc.revokeSession(reqCtx, userID, session.ID); err != nil {
+			logger.Error(reqCtx, "Failed to revoke session after password change", err, map[string]interface{}{"session_id": session.ID})
+			return model.InternalError[string]("Password changed but other sessions could not be signed out")
+		}
 	}
+	return model.SuccessResponse("", "Password changed successfully")
+}
 
-	// Generate JWT token
+// issueTokens signs an access token for the session and pairs it with the refresh token
+func (svc *authService) issueTokens(user *model.User, sessionID, refreshToken, message string) model.Response[*TokenPair] {
+	now := time.Now()
+	expiresAt := now.Add(svc.accessTTL)
 	claims := &JWTClaims{
-		SessionID: uuid.New().String(),
+		SessionID: sessionID,
 		UserID:    user.ID,
 		UserName:  user.UserName,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)), // 24 hours
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString([]byte(svc.jwtSecret))
+	tokenString, err := svc.keys.Sign(claims)
 	if err != nil {
-		return model.InternalError[string]("Failed to generate token")
+		return model.InternalError[*TokenPair]("Failed to generate token")
+	}
+
+	return model.SuccessResponse(&TokenPair{
+		AccessToken:  tokenString,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, message)
+}
+
+// revokeSession ends a session everywhere: no more refreshes, its outstanding
+// access tokens stop working and its sockets are closed
+func (svc *authService) revokeSession(reqCtx *model.RequestContext, userID uint, sessionID string) error {
+	sessionResponse := svc.repo.Session().Revoke(reqCtx, sessionID)
+	if !sessionResponse.OK() {
+		return fmt.Errorf("%s", sessionResponse.ErrorString())
+	}
+	// Access tokens of the session live at most one access lifetime from now
+	if err := svc.revocations.RevokeSession(reqCtx.Context(), userID, sessionID, time.Now().Add(svc.accessTTL)); err != nil {
+		return err
+	}
+	svc.disconnect(&model.DisconnectMessage{UserID: userID, SessionID: sessionID})
+	return nil
+}
+
+// newRefreshToken returns an opaque random token and the hash that is stored for it
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+func truncateUserAgent(userAgent string) string {
+	runes := []rune(userAgent)
+	if len(runes) <= model.MaxUserAgentLength {
+		return userAgent
 	}
+	return string(runes[:model.MaxUserAgentLength])
+}
 
-	return model.SuccessResponse(tokenString, "Login successful")
+// hashRefreshToken is a plain SHA-256: the tokens are random, so there is nothing to brute force
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
+// Logout revokes the token's session and closes the sockets it opened
 func (svc *authService) Logout(reqCtx *model.RequestContext, token string) model.Response[string] {
 	logger.Info(reqCtx, "Logout called", map[string]interface{}{"token_length": len(token)})
-	// In a real implementation, you might want to blacklist the token
-	// For now, we'll just validate that the token is valid
 	if token == "" {
 		return model.BadRequest[string]("Token is required")
 	}
 
-	_, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(svc.jwtSecret), nil
-	})
-
-	if err != nil {
-		return model.Unauthorized[string]("Invalid token")
+	tokenResponse := svc.ParseToken(token)
+	if !tokenResponse.OK() {
+		return model.Unauthorized[string](tokenResponse.ErrorString())
 	}
 
-	// Token is valid, logout successful
-	// In a production system, you'd typically add the token to a blacklist
+	claims := tokenResponse.Data
+	if err := svc.revokeSession(reqCtx, claims.UserID, claims.SessionID); err != nil {
+		logger.Error(reqCtx, "Failed to revoke session", err, map[string]interface{}{"user_id": claims.UserID})
+		return model.InternalError[string]("Failed to logout")
+	}
 	return model.SuccessResponse("", "Logout successful")
 }
 
-func (svc *authService) GetUsers(reqCtx *model.RequestContext) model.Response[[]*model.User] {
-	logger.Info(reqCtx, "GetUsers called")
-	response := svc.repo.UserRepo.QueryMany(reqCtx, &model.User{})
-	return response
+// LogoutAll revokes every token the user holds, including the one making the request
+func (svc *authService) LogoutAll(reqCtx *model.RequestContext, userID uint) model.Response[string] {
+	logger.Info(reqCtx, "LogoutAll called", map[string]interface{}{"user_id": userID})
+	if userID == 0 {
+		return model.Unauthorized[string]("Unauthorized")
+	}
+
+	sessionsResponse := svc.repo.Session().RevokeByUser(reqCtx, userID)
+	if !sessionsResponse.OK() {
+		return model.InternalError[string]("Failed to logout all sessions")
+	}
+
+	// Round up to the next millisecond so no token issued before this call survives
+	before := time.Now().Truncate(time.Millisecond).Add(time.Millisecond)
+	if err := svc.revocations.RevokeUser(reqCtx.Context(), userID, before); err != nil {
+		logger.Error(reqCtx, "Failed to revoke sessions", err, map[string]interface{}{"user_id": userID})
+		return model.InternalError[string]("Failed to logout all sessions")
+	}
+
+	svc.disconnect(&model.DisconnectMessage{UserID: userID})
+	return model.SuccessResponse("", "Logged out of all sessions")
+}
+
+func (svc *authService) disconnect(message *model.DisconnectMessage) {
+	if svc.client == nil {
+		return
+	}
+	svc.client.SocketClient.Disconnect(message)
+}
+
+// JWKS publishes the public keys tokens are verified with
+func (svc *authService) JWKS(reqCtx *model.RequestContext) model.Response[*signing.JWKS] {
+	return model.SuccessResponse(svc.keys.JWKS(), "Signing keys retrieved successfully")
 }
 
 func NewAuthService(params *common.Params) AuthService {
+	revocations := params.Revocations
+	if revocations == nil {
+		revocations = revocation.NewCachedStore(revocation.NewSQLStore(params.Repo.Revocation()), config.Config.RevocationCacheTTL)
+	}
+	passwords := params.PasswordPolicy
+	if passwords == nil {
+		passwords = password.NewPolicy(config.Config.PasswordMinLength, nil)
+	}
+	guard := params.Lockout
+	if guard == nil {
+		guard = lockout.NewGuardFromConfig(lockout.NewSQLStore(params.Repo.LoginAttempt()))
+	}
+	providers := params.OIDC
+	if providers == nil {
+		providers = oidc.NewRegistry()
+	}
 	return &authService{
-		repo:      params.Repo,
-		jwtSecret: config.Config.JwtSecret,
+		repo:        params.Repo,
+		client:      params.Client,
+		revocations: revocations,
+		keys:        params.Keys,
+		passwords:   passwords,
+		lockout:     guard,
+		accessTTL:   config.Config.AccessTokenTTL,
+		refreshTTL:  config.Config.RefreshTokenTTL,
+
+		mfaChallengeTTL: config.Config.MFAChallengeTTL,
+		oidc:            providers,
+		oidcStateTTL:    config.Config.OIDCStateTTL,
+		now:             time.Now,
 	}
 }
 
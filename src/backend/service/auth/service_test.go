@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"local/infra/provider/lockout"
+	"local/infra/provider/password"
+	"local/infra/provider/revocation"
+	"local/infra/provider/signing"
 	"local/infra/repo"
 	"local/model"
+	"local/test/mocks"
 	"testing"
 	"time"
 
@@ -13,6 +18,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// testKeys signs the tokens handed to the service under test
+var testKeys, _ = signing.Generate()
+
 // MockRepository is a mock implementation of RepositoryInterface
 type MockRepository struct {
 	mock.Mock
@@ -38,6 +46,100 @@ func (m *MockRepository) Message() repo.MessageRepo {
 	return args.Get(0).(repo.MessageRepo)
 }
 
+func (m *MockRepository) Reaction() repo.ReactionRepo {
+	args := m.Called()
+	return args.Get(0).(repo.ReactionRepo)
+}
+
+func (m *MockRepository) Attachment() repo.AttachmentRepo {
+	args := m.Called()
+	return args.Get(0).(repo.AttachmentRepo)
+}
+
+func (m *MockRepository) Revocation() repo.RevocationRepo {
+	args := m.Called()
+	return args.Get(0).(repo.RevocationRepo)
+}
+
+func (m *MockRepository) Session() repo.SessionRepo {
+	args := m.Called()
+	return args.Get(0).(repo.SessionRepo)
+}
+
+func (m *MockRepository) LoginAttempt() repo.LoginAttemptRepo {
+	args := m.Called()
+	return args.Get(0).(repo.LoginAttemptRepo)
+}
+
+func (m *MockRepository) MFA() repo.MFARepo {
+	args := m.Called()
+	return args.Get(0).(repo.MFARepo)
+}
+
+func (m *MockRepository) Identity() repo.IdentityRepo {
+	args := m.Called()
+	return args.Get(0).(repo.IdentityRepo)
+}
+
+func (m *MockRepository) Block() repo.BlockRepo {
+	args := m.Called()
+	return args.Get(0).(repo.BlockRepo)
+}
+
+func (m *MockRepository) Outbox() repo.OutboxRepo {
+	args := m.Called()
+	return args.Get(0).(repo.OutboxRepo)
+}
+
+func (m *MockRepository) WithTx(reqCtx *model.RequestContext, fn func(tx repo.RepositoryInterface) error) error {
+	return fn(m)
+}
+
+// MockSessionRepo is a mock implementation of SessionRepo
+type MockSessionRepo struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepo) Create(reqCtx *model.RequestContext, session *model.Session) model.Response[*model.Session] {
+	args := m.Called(reqCtx, session)
+	return args.Get(0).(model.Response[*model.Session])
+}
+
+func (m *MockSessionRepo) GetByID(reqCtx *model.RequestContext, sessionID string) model.Response[*model.Session] {
+	args := m.Called(reqCtx, sessionID)
+	return args.Get(0).(model.Response[*model.Session])
+}
+
+func (m *MockSessionRepo) ListActiveByUser(reqCtx *model.RequestContext, userID uint, now time.Time) model.Response[[]*model.Session] {
+	args := m.Called(reqCtx, userID, now)
+	return args.Get(0).(model.Response[[]*model.Session])
+}
+
+func (m *MockSessionRepo) GetByRefreshTokenHash(reqCtx *model.RequestContext, tokenHash string) model.Response[*model.Session] {
+	args := m.Called(reqCtx, tokenHash)
+	return args.Get(0).(model.Response[*model.Session])
+}
+
+func (m *MockSessionRepo) GetRotated(reqCtx *model.RequestContext, tokenHash string) model.Response[*model.RotatedRefreshToken] {
+	args := m.Called(reqCtx, tokenHash)
+	return args.Get(0).(model.Response[*model.RotatedRefreshToken])
+}
+
+func (m *MockSessionRepo) Rotate(reqCtx *model.RequestContext, session *model.Session, tokenHash string, expiresAt time.Time) model.Response[*model.Session] {
+	args := m.Called(reqCtx, session, tokenHash, expiresAt)
+	return args.Get(0).(model.Response[*model.Session])
+}
+
+func (m *MockSessionRepo) Revoke(reqCtx *model.RequestContext, sessionID string) model.Response[bool] {
+	args := m.Called(reqCtx, sessionID)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockSessionRepo) RevokeByUser(reqCtx *model.RequestContext, userID uint) model.Response[int64] {
+	args := m.Called(reqCtx, userID)
+	return args.Get(0).(model.Response[int64])
+}
+
 // MockUserRepo is a mock implementation of UserRepo
 type MockUserRepo struct {
 	mock.Mock
@@ -68,6 +170,36 @@ func (m *MockUserRepo) Delete(reqCtx *model.RequestContext, user *model.User) mo
 	return args.Get(0).(model.Response[*model.User])
 }
 
+func (m *MockUserRepo) Count(reqCtx *model.RequestContext) (int64, error) {
+	args := m.Called(reqCtx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepo) GetByIDs(reqCtx *model.RequestContext, ids []uint) model.Response[[]*model.User] {
+	args := m.Called(reqCtx, ids)
+	return args.Get(0).(model.Response[[]*model.User])
+}
+
+func (m *MockUserRepo) SetPresence(reqCtx *model.RequestContext, userID uint, online bool, seenAt time.Time) model.Response[*model.User] {
+	args := m.Called(reqCtx, userID, online, seenAt)
+	return args.Get(0).(model.Response[*model.User])
+}
+
+func (m *MockUserRepo) ClearPresence(reqCtx *model.RequestContext, seenAt time.Time) model.Response[int64] {
+	args := m.Called(reqCtx, seenAt)
+	return args.Get(0).(model.Response[int64])
+}
+
+func (m *MockUserRepo) UpdateProfile(reqCtx *model.RequestContext, userID uint, changes map[string]interface{}) model.Response[*model.User] {
+	args := m.Called(reqCtx, userID, changes)
+	return args.Get(0).(model.Response[*model.User])
+}
+
+func (m *MockUserRepo) Search(reqCtx *model.RequestContext, viewerID uint, query string, after *model.UserSearchCursor, limit int) model.Response[[]*model.UserMatch] {
+	args := m.Called(reqCtx, viewerID, query, after, limit)
+	return args.Get(0).(model.Response[[]*model.UserMatch])
+}
+
 func TestAuthService_Register(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -137,6 +269,32 @@ func TestAuthService_Register(t *testing.T) {
 			expectedOK:    false,
 			expectedError: "User already exists",
 		},
+		{
+			name:     "password too short",
+			userName: "testuser",
+			password: "short",
+			setupMocks: func(mockRepo *MockRepository, mockUserRepo *MockUserRepo) {
+				mockUserRepo.On("QueryOne", mock.Anything, &model.User{UserName: "testuser"}).
+					Return(model.NotFound[*model.User]("User not found"))
+				mockRepo.On("User").Return(mockUserRepo)
+			},
+			expectedCode:  model.CodeValidation,
+			expectedOK:    false,
+			expectedError: "Password does not meet the requirements",
+		},
+		{
+			name:     "breached password",
+			userName: "testuser",
+			password: "Password1234",
+			setupMocks: func(mockRepo *MockRepository, mockUserRepo *MockUserRepo) {
+				mockUserRepo.On("QueryOne", mock.Anything, &model.User{UserName: "testuser"}).
+					Return(model.NotFound[*model.User]("User not found"))
+				mockRepo.On("User").Return(mockUserRepo)
+			},
+			expectedCode:  model.CodeValidation,
+			expectedOK:    false,
+			expectedError: "Password does not meet the requirements",
+		},
 	}
 
 	for _, tt := range tests {
@@ -147,7 +305,8 @@ func TestAuthService_Register(t *testing.T) {
 
 			svc := &authService{
 				repo:      mockRepo,
-				jwtSecret: "test-secret-key-for-testing-only",
+				keys:      testKeys,
+				passwords: password.NewPolicy(8, []string{"password1234"}),
 			}
 
 			reqCtx := &model.RequestContext{}
@@ -193,6 +352,14 @@ func TestAuthService_Login(t *testing.T) {
 						Password: hashedPassword,
 					}, "User found"))
 				mockRepo.On("User").Return(mockUserRepo)
+				mockSessionRepo := new(MockSessionRepo)
+				mockSessionRepo.On("Create", mock.Anything, mock.MatchedBy(func(session *model.Session) bool {
+					return session.UserID == 1 && session.ID != "" && len(session.RefreshTokenHash) == 64
+				})).Return(model.SuccessResponse(&model.Session{}, "Session created"))
+				mockRepo.On("Session").Return(mockSessionRepo)
+				mockMFARepo := new(mocks.MockMFARepo)
+				mockMFARepo.On("Get", mock.Anything, uint(1)).Return(model.NotFound[*model.UserMFA]("Two-factor authentication is not set up"))
+				mockRepo.On("MFA").Return(mockMFARepo)
 			},
 			expectedCode: model.CodeSuccess,
 			expectedOK:   true,
@@ -249,8 +416,12 @@ func TestAuthService_Login(t *testing.T) {
 			tt.setupMocks(mockRepo, mockUserRepo)
 
 			svc := &authService{
-				repo:      mockRepo,
-				jwtSecret: "test-secret-key-for-testing-only",
+				repo:        mockRepo,
+				revocations: revocation.NewMemoryStore(),
+				keys:        testKeys,
+				lockout:     lockout.NewGuard(lockout.NewMemoryStore(), lockout.Policy{}, lockout.Policy{}),
+				accessTTL:   time.Minute,
+				refreshTTL:  time.Hour,
 			}
 
 			reqCtx := &model.RequestContext{}
@@ -262,7 +433,8 @@ func TestAuthService_Login(t *testing.T) {
 				assert.Contains(t, response.ErrorString(), tt.expectedError)
 			}
 			if tt.checkToken {
-				assert.NotEmpty(t, response.Data, "Token should be generated")
+				assert.NotEmpty(t, response.Data.AccessToken, "Token should be generated")
+				assert.NotEmpty(t, response.Data.RefreshToken, "Refresh token should be generated")
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -305,8 +477,9 @@ func TestAuthService_Authenticate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			svc := &authService{
-				repo:      nil,
-				jwtSecret: "test-secret-key-for-testing-only",
+				repo:        nil,
+				revocations: revocation.NewMemoryStore(),
+				keys:        testKeys,
 			}
 			tt.setupMocks(svc)
 
@@ -324,7 +497,6 @@ func TestAuthService_Authenticate(t *testing.T) {
 
 func TestAuthService_GetMe(t *testing.T) {
 	// Generate a valid token for testing
-	jwtSecret := "test-secret-key-for-testing-only"
 	claims := &JWTClaims{
 		SessionID: uuid.New().String(),
 		UserID:    1,
@@ -334,8 +506,7 @@ func TestAuthService_GetMe(t *testing.T) {
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	validToken, _ := token.SignedString([]byte(jwtSecret))
+	validToken, _ := testKeys.Sign(claims)
 
 	tests := []struct {
 		name          string
@@ -386,8 +557,9 @@ func TestAuthService_GetMe(t *testing.T) {
 			tt.setupMocks(mockRepo, mockUserRepo)
 
 			svc := &authService{
-				repo:      mockRepo,
-				jwtSecret: jwtSecret,
+				repo:        mockRepo,
+				revocations: revocation.NewMemoryStore(),
+				keys:        testKeys,
 			}
 
 			reqCtx := &model.RequestContext{Token: tt.token}
@@ -406,7 +578,115 @@ func TestAuthService_GetMe(t *testing.T) {
 	}
 }
 
+func TestAuthService_ParseToken(t *testing.T) {
+	svc := &authService{revocations: revocation.NewMemoryStore(), keys: testKeys}
+
+	parsed := svc.ParseToken(signTestToken(t, 1, "session-1", time.Now()))
+	assert.True(t, parsed.OK())
+
+	// The shared secret scheme is gone: HS256 tokens no longer verify
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &JWTClaims{UserID: 1, SessionID: "session-1"}).SignedString([]byte("your_jwt_secret"))
+	assert.NoError(t, err)
+	parsed = svc.ParseToken(legacy)
+	assert.Equal(t, model.CodeUnauthorized, parsed.Code)
+
+	// Service tokens for the socket service are signed with the same keys but name no user
+	serviceToken, err := testKeys.Sign(jwt.RegisteredClaims{
+		Subject:   "backend",
+		Audience:  jwt.ClaimStrings{"simple-chat-socket"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	assert.NoError(t, err)
+	parsed = svc.ParseToken(serviceToken)
+	assert.Equal(t, model.CodeUnauthorized, parsed.Code)
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockSessionRepo := new(MockSessionRepo)
+	mockSessionRepo.On("Revoke", mock.Anything, "session-1").Return(model.SuccessResponse(true, "Session revoked"))
+	mockRepo.On("Session").Return(mockSessionRepo)
+	svc := &authService{
+		repo:        mockRepo,
+		revocations: revocation.NewMemoryStore(),
+		keys:        testKeys,
+		accessTTL:   time.Minute,
+	}
+	reqCtx := &model.RequestContext{}
+
+	current := signTestToken(t, 1, "session-1", time.Now())
+	other := signTestToken(t, 1, "session-2", time.Now())
+
+	response := svc.Logout(reqCtx, current)
+	assert.True(t, response.OK())
+
+	parsed := svc.ParseToken(current)
+	assert.Equal(t, model.CodeUnauthorized, parsed.Code)
+	assert.Contains(t, parsed.ErrorString(), "Token has been revoked")
+
+	// Only the logged out session is affected
+	parsed = svc.ParseToken(other)
+	assert.True(t, parsed.OK())
+
+	// A revoked token cannot be used to log out again
+	response = svc.Logout(reqCtx, current)
+	assert.Equal(t, model.CodeUnauthorized, response.Code)
+	mockSessionRepo.AssertExpectations(t)
+}
+
+func TestAuthService_LogoutAll(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockSessionRepo := new(MockSessionRepo)
+	mockSessionRepo.On("RevokeByUser", mock.Anything, uint(1)).Return(model.SuccessResponse(int64(2), "Sessions revoked"))
+	mockRepo.On("Session").Return(mockSessionRepo)
+	svc := &authService{
+		repo:        mockRepo,
+		revocations: revocation.NewMemoryStore(),
+		keys:        testKeys,
+	}
+	reqCtx := &model.RequestContext{}
+
+	first := signTestToken(t, 1, "session-1", time.Now())
+	second := signTestToken(t, 1, "session-2", time.Now())
+	stranger := signTestToken(t, 2, "session-3", time.Now())
+
+	response := svc.LogoutAll(reqCtx, 1)
+	assert.True(t, response.OK())
+
+	for _, token := range []string{first, second} {
+		parsed := svc.ParseToken(token)
+		assert.Contains(t, parsed.ErrorString(), "Token has been revoked")
+	}
+	parsed := svc.ParseToken(stranger)
+	assert.True(t, parsed.OK(), "Other users keep their sessions")
+
+	// Logging in again after the cutoff yields a working token
+	time.Sleep(2 * time.Millisecond)
+	fresh := signTestToken(t, 1, "session-4", time.Now())
+	parsed = svc.ParseToken(fresh)
+	assert.True(t, parsed.OK())
+
+	response = svc.LogoutAll(reqCtx, 0)
+	assert.Equal(t, model.CodeUnauthorized, response.Code)
+	mockSessionRepo.AssertExpectations(t)
+}
+
 // Helper functions
+func signTestToken(t *testing.T, userID uint, sessionID string, issuedAt time.Time) string {
+	claims := &JWTClaims{
+		SessionID: sessionID,
+		UserID:    userID,
+		UserName:  "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+		},
+	}
+	token, err := testKeys.Sign(claims)
+	assert.NoError(t, err)
+	return token
+}
+
 func hashPassword(password string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
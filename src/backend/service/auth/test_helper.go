@@ -1,18 +1,48 @@
 package auth
 
 import (
+	"local/config"
+	"local/infra/provider/lockout"
+	"local/infra/provider/oidc"
+	"local/infra/provider/password"
+	"local/infra/provider/revocation"
+	"local/infra/provider/signing"
 	"local/infra/repo"
+	"time"
 )
 
 // NewTestAuthService creates a new auth service instance for testing
-// This allows tests to create service instances with custom repository and jwtSecret
-func NewTestAuthService(repo *repo.Repository, jwtSecret string) AuthService {
+// This allows tests to create service instances with custom repository and signing keys
+func NewTestAuthService(repo repo.RepositoryInterface, keys *signing.KeySet) AuthService {
+	return NewTestAuthServiceWithClock(repo, keys, time.Now)
+}
+
+// NewTestAuthServiceWithClock is NewTestAuthService with two-factor codes and
+// challenge tokens checked against the given clock
+func NewTestAuthServiceWithClock(repo repo.RepositoryInterface, keys *signing.KeySet, now func() time.Time) AuthService {
 	return &authService{
-		repo:      repo,
-		jwtSecret: jwtSecret,
+		repo:        repo,
+		revocations: revocation.NewMemoryStore(),
+		keys:        keys,
+		passwords:   password.NewPolicy(password.DefaultMinLength, nil),
+		lockout:     lockout.NewGuardFromConfig(lockout.NewMemoryStore()),
+		accessTTL:   config.DefaultAccessTokenTTL,
+		refreshTTL:  config.DefaultRefreshTokenTTL,
+
+		mfaChallengeTTL: config.DefaultMFAChallengeTTL,
+		oidc:            oidc.NewRegistry(),
+		oidcStateTTL:    config.DefaultOIDCStateTTL,
+		now:             now,
 	}
 }
 
+// NewTestAuthServiceWithOIDC is NewTestAuthService offering the given identity providers
+func NewTestAuthServiceWithOIDC(repo repo.RepositoryInterface, keys *signing.KeySet, providers *oidc.Registry) AuthService {
+	svc := NewTestAuthService(repo, keys).(*authService)
+	svc.oidc = providers
+	return svc
+}
+
 // GetJWTClaimsType returns the JWTClaims type for testing
 // This allows tests to create JWTClaims instances
 type TestJWTClaims = JWTClaims
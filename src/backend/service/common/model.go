@@ -2,10 +2,34 @@ package common
 
 import (
 	"local/client"
+	"local/infra/provider/blob"
+	"local/infra/provider/events"
+	"local/infra/provider/lockout"
+	"local/infra/provider/oidc"
+	"local/infra/provider/password"
+	"local/infra/provider/revocation"
+	"local/infra/provider/search"
+	"local/infra/provider/signing"
 	"local/infra/repo"
 )
 
 type Params struct {
-	Repo   *repo.Repository
+	Repo   repo.RepositoryInterface
 	Client *client.Client
+	Blob   blob.BlobStore
+
+	// Revocations decides which sessions were logged out; defaults to a store backed by Repo
+	Revocations revocation.Store
+	// Keys sign and verify tokens; required by the auth service
+	Keys *signing.KeySet
+	// PasswordPolicy decides which passwords may be set; defaults to the configured minimum length
+	PasswordPolicy *password.Policy
+	// Lockout throttles failed logins; defaults to counters stored through Repo
+	Lockout *lockout.Guard
+	// OIDC holds the single sign-on providers; defaults to none
+	OIDC *oidc.Registry
+	// Search finds messages by their words; defaults to MySQL full-text search through Repo
+	Search search.SearchIndex
+	// Events publishes what happens to messages; defaults to publishing nothing
+	Events events.EventPublisher
 }
\ No newline at end of file
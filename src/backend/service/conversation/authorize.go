@@ -0,0 +1,23 @@
+package conversation
+
+import (
+	"local/model"
+	"local/util/logger"
+)
+
+// AuthorizeMember returns the caller's participant record, or Forbidden when
+// the user does not belong to the conversation.
+func (svc *conversationService) AuthorizeMember(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "AuthorizeMember called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+	})
+	if userID == 0 {
+		return model.Unauthorized[*model.ConversationParticipant]("Unauthorized")
+	}
+	memberResponse := svc.repo.Participant().GetByConversationAndUser(reqCtx, conversationID, userID)
+	if !memberResponse.OK() {
+		return model.Forbidden[*model.ConversationParticipant]("You are not a member of this conversation")
+	}
+	return memberResponse
+}
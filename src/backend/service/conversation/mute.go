@@ -0,0 +1,32 @@
+package conversation
+
+import (
+	"local/model"
+	"local/util/logger"
+	"time"
+)
+
+// MuteConversation silences the conversation's notifications for the user until
+// the given time, or indefinitely when until is nil. Messages are still delivered
+func (svc *conversationService) MuteConversation(reqCtx *model.RequestContext, conversationID, userID uint, until *time.Time) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "MuteConversation called", map[string]interface{}{"conversation_id": conversationID, "user_id": userID})
+	if authResponse := svc.AuthorizeMember(reqCtx, conversationID, userID); !authResponse.OK() {
+		return authResponse
+	}
+	mutedUntil := model.MutedForever
+	if until != nil {
+		if !until.After(time.Now()) {
+			return model.ValidationError[*model.ConversationParticipant]("Mute end must be in the future")
+		}
+		mutedUntil = *until
+	}
+	return svc.repo.Participant().SetMutedUntil(reqCtx, conversationID, userID, &mutedUntil)
+}
+
+func (svc *conversationService) UnmuteConversation(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "UnmuteConversation called", map[string]interface{}{"conversation_id": conversationID, "user_id": userID})
+	if authResponse := svc.AuthorizeMember(reqCtx, conversationID, userID); !authResponse.OK() {
+		return authResponse
+	}
+	return svc.repo.Participant().SetMutedUntil(reqCtx, conversationID, userID, nil)
+}
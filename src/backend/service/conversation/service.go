@@ -2,22 +2,35 @@ package conversation
 
 import (
 	"fmt"
+	"local/client"
 	"local/infra/repo"
 	"local/model"
 	"local/service/common"
 	"local/util/logger"
 	"sort"
+	"time"
 )
 
 type ConversationService interface {
 	CreateConversation(reqCtx *model.RequestContext, userIDs []uint) model.Response[*model.Conversation]
-	GetUserConversations(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Conversation]
+	GetUserConversations(reqCtx *model.RequestContext, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Conversation]]
 	GetConversationByUserIDs(reqCtx *model.RequestContext, userIDs []uint) model.Response[*model.Conversation]
 	GetConversationByID(reqCtx *model.RequestContext, id uint) model.Response[*model.Conversation]
+	CreateGroupConversation(reqCtx *model.RequestContext, ownerID uint, name string, userIDs []uint) model.Response[*model.Conversation]
+	GetParticipants(reqCtx *model.RequestContext, conversationID, actorID uint) model.Response[[]*model.ConversationParticipant]
+	AddParticipants(reqCtx *model.RequestContext, conversationID, actorID uint, userIDs []uint) model.Response[*model.Conversation]
+	RemoveParticipant(reqCtx *model.RequestContext, conversationID, actorID, userID uint) model.Response[string]
+	PromoteParticipant(reqCtx *model.RequestContext, conversationID, actorID, userID uint) model.Response[*model.ConversationParticipant]
+	LeaveConversation(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[string]
+	AuthorizeMember(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant]
+	MarkAsRead(reqCtx *model.RequestContext, conversationID, userID, messageID uint) model.Response[*model.ConversationParticipant]
+	MuteConversation(reqCtx *model.RequestContext, conversationID, userID uint, until *time.Time) model.Response[*model.ConversationParticipant]
+	UnmuteConversation(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant]
 }
 
 type conversationService struct {
-	repo *repo.Repository
+	repo   repo.RepositoryInterface
+	client *client.Client
 }
 
 func convertUserIdsToEntityJoined(userIds []uint) string {
@@ -43,7 +56,7 @@ func (svc *conversationService) GetConversationByUserIDs(reqCtx *model.RequestCo
 		return model.BadRequest[*model.Conversation]("At least 2 participants are required")
 	}
 
-	response := svc.repo.ConversationRepo.GetByEntityJoined(reqCtx, convertUserIdsToEntityJoined(userIDs))
+	response := svc.repo.Conversation().GetByEntityJoined(reqCtx, convertUserIdsToEntityJoined(userIDs))
 	return response
 }
 
@@ -52,49 +65,511 @@ func (svc *conversationService) CreateConversation(reqCtx *model.RequestContext,
 	if len(userIds) < 2 {
 		return model.BadRequest[*model.Conversation]("At least 2 participants are required")
 	}
+	if len(userIds) > 2 {
+		return model.BadRequest[*model.Conversation]("Private conversations have exactly 2 participants")
+	}
+	if userIds[0] != userIds[1] {
+		blockedResponse := svc.repo.Block().IsBlockedBetween(reqCtx, userIds[0], userIds[1])
+		if !blockedResponse.OK() {
+			return model.ErrorArray[*model.Conversation](blockedResponse.Code, blockedResponse.Message, blockedResponse.Errors)
+		}
+		if blockedResponse.Data {
+			return model.Forbidden[*model.Conversation]("You cannot start a conversation with this user")
+		}
+	}
 
-	// Create conversation
+	entityJoined := convertUserIdsToEntityJoined(userIds)
 	conversation := &model.Conversation{
-		Type: "private",
+		Type: model.ConversationTypePrivate,
 		Name: "",
-		EntityJoined: convertUserIdsToEntityJoined(userIds),
+		EntityJoined: &entityJoined,
 		UserIds: userIds,
 	}
 
-	createResponse := svc.repo.ConversationRepo.Create(reqCtx, conversation)
-	if !createResponse.OK() {
-		return createResponse
-	}
-
-	createdConversation := createResponse.Data
+	// Starting a conversation that already exists returns it; the conversation and
+	// its participants are created together or not at all
+	return repo.InTx(reqCtx, svc.repo, func(tx repo.RepositoryInterface) model.Response[*model.Conversation] {
+		createResponse := tx.Conversation().GetOrCreatePrivate(reqCtx, conversation)
+		if !createResponse.OK() {
+			return model.ErrorArray[*model.Conversation](createResponse.Code, createResponse.Message, createResponse.Errors)
+		}
 
-	// Add participants
-	for _, userID := range userIds {
-		participantResponse := svc.repo.ParticipantRepo.AddParticipantToConversation(reqCtx, createdConversation.ID, userID)
-		if !participantResponse.OK() {
-			return model.BadRequest[*model.Conversation]("Failed to add participant to conversation")
+		if createResponse.Data {
+			for _, userID := range userIds {
+				participantResponse := tx.Participant().AddParticipantToConversation(reqCtx, conversation.ID, userID)
+				if !participantResponse.OK() {
+					return model.BadRequest[*model.Conversation]("Failed to add participant to conversation")
+				}
+			}
 		}
-	}
 
-	// Return conversation with participants
-	queryResponse := svc.repo.ConversationRepo.QueryOne(reqCtx, &model.Conversation{ID: createdConversation.ID})
-	return queryResponse
+		// Return conversation with participants
+		return tx.Conversation().QueryOne(reqCtx, &model.Conversation{ID: conversation.ID})
+	})
 }
 
-func (svc *conversationService) GetUserConversations(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Conversation] {
+func (svc *conversationService) GetUserConversations(reqCtx *model.RequestContext, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Conversation]] {
 	logger.Info(reqCtx, "GetUserConversations called", map[string]interface{}{"user_id": userID})
-	response := svc.repo.ConversationRepo.GetByParticipant(reqCtx, userID)
+	response := svc.repo.Conversation().GetByParticipant(reqCtx, userID, cursor)
+	if !response.OK() || len(response.Data.Items) == 0 {
+		return response
+	}
+
+	conversationIDs := make([]uint, 0, len(response.Data.Items))
+	for _, conversation := range response.Data.Items {
+		conversationIDs = append(conversationIDs, conversation.ID)
+	}
+	unreadResponse := svc.repo.Message().CountUnread(reqCtx, userID, conversationIDs)
+	if !unreadResponse.OK() {
+		return model.ErrorArray[*model.Page[*model.Conversation]](unreadResponse.Code, unreadResponse.Message, unreadResponse.Errors)
+	}
+	for _, conversation := range response.Data.Items {
+		conversation.UnreadCount = unreadResponse.Data[conversation.ID]
+	}
 	return response
 }
 
+// MarkAsRead moves the user's read marker to messageID, or to the latest message
+// when messageID is 0, and tells the other participants.
+func (svc *conversationService) MarkAsRead(reqCtx *model.RequestContext, conversationID, userID, messageID uint) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "MarkAsRead called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+		"message_id":      messageID,
+	})
+	authResponse := svc.AuthorizeMember(reqCtx, conversationID, userID)
+	if !authResponse.OK() {
+		return authResponse
+	}
+	previousReadID := authResponse.Data.LastReadMessageID
+
+	conversationResponse := svc.repo.Conversation().QueryOne(reqCtx, &model.Conversation{ID: conversationID})
+	if !conversationResponse.OK() {
+		return model.ErrorArray[*model.ConversationParticipant](conversationResponse.Code, conversationResponse.Message, conversationResponse.Errors)
+	}
+	conversation := conversationResponse.Data
+
+	if messageID == 0 {
+		messageID = conversation.LastMessageID
+	} else {
+		messageResponse := svc.repo.Message().GetByID(reqCtx, messageID)
+		if !messageResponse.OK() || messageResponse.Data.ConversationID != conversationID {
+			return model.BadRequest[*model.ConversationParticipant]("Message does not belong to this conversation")
+		}
+	}
+
+	readResponse := svc.repo.Participant().MarkRead(reqCtx, conversationID, userID, messageID)
+	if !readResponse.OK() {
+		return readResponse
+	}
+	// The marker never moves back, so re-reading an older message changes nothing others need to hear
+	if readResponse.Data.LastReadMessageID <= previousReadID {
+		return readResponse
+	}
+
+	svc.broadcastEvent(conversation, "read", map[string]interface{}{
+		"conversation_id":      conversationID,
+		"user_id":              userID,
+		"last_read_message_id": readResponse.Data.LastReadMessageID,
+	}, userID)
+
+	return readResponse
+}
+
 func (svc *conversationService) GetConversationByID(reqCtx *model.RequestContext, id uint) model.Response[*model.Conversation] {
 	logger.Info(reqCtx, "GetConversationByID called", map[string]interface{}{"conversation_id": id})
-	response := svc.repo.ConversationRepo.QueryOne(reqCtx, &model.Conversation{ID: id})
+	response := svc.repo.Conversation().QueryOne(reqCtx, &model.Conversation{ID: id})
 	return response
 }
 
+func (svc *conversationService) CreateGroupConversation(reqCtx *model.RequestContext, ownerID uint, name string, userIDs []uint) model.Response[*model.Conversation] {
+	logger.Info(reqCtx, "CreateGroupConversation called", map[string]interface{}{"owner_id": ownerID, "user_ids": userIDs})
+	if name == "" {
+		return model.BadRequest[*model.Conversation]("Group name is required")
+	}
+
+	memberIDs := uniqueUserIDs(ownerID, userIDs)
+	if len(memberIDs) < 2 {
+		return model.BadRequest[*model.Conversation]("At least 2 participants are required")
+	}
+
+	if missingResponse := svc.checkUsersExist(reqCtx, memberIDs); !missingResponse.OK() {
+		return model.ErrorArray[*model.Conversation](missingResponse.Code, missingResponse.Message, missingResponse.Errors)
+	}
+
+	conversation := &model.Conversation{
+		Type:    model.ConversationTypeGroup,
+		Name:    name,
+		UserIds: memberIDs,
+	}
+
+	queryResponse := repo.InTx(reqCtx, svc.repo, func(tx repo.RepositoryInterface) model.Response[*model.Conversation] {
+		createResponse := tx.Conversation().Create(reqCtx, conversation)
+		if !createResponse.OK() {
+			return createResponse
+		}
+
+		createdConversation := createResponse.Data
+
+		for _, userID := range memberIDs {
+			role := model.ParticipantRoleMember
+			if userID == ownerID {
+				role = model.ParticipantRoleOwner
+			}
+			participantResponse := tx.Participant().Create(reqCtx, &model.ConversationParticipant{
+				ConversationID: createdConversation.ID,
+				UserID:         userID,
+				Role:           role,
+			})
+			if !participantResponse.OK() {
+				return model.BadRequest[*model.Conversation]("Failed to add participant to conversation")
+			}
+		}
+
+		return tx.Conversation().QueryOne(reqCtx, &model.Conversation{ID: createdConversation.ID})
+	})
+	if !queryResponse.OK() {
+		return queryResponse
+	}
+
+	svc.broadcastEvent(queryResponse.Data, "member_joined", map[string]interface{}{
+		"conversation": queryResponse.Data,
+		"user_ids":     memberIDs,
+	})
+
+	return queryResponse
+}
+
+func (svc *conversationService) GetParticipants(reqCtx *model.RequestContext, conversationID, actorID uint) model.Response[[]*model.ConversationParticipant] {
+	logger.Info(reqCtx, "GetParticipants called", map[string]interface{}{"conversation_id": conversationID, "actor_id": actorID})
+	if authResponse := svc.AuthorizeMember(reqCtx, conversationID, actorID); !authResponse.OK() {
+		return model.ErrorArray[[]*model.ConversationParticipant](authResponse.Code, authResponse.Message, authResponse.Errors)
+	}
+	return svc.repo.Participant().GetByConversationID(reqCtx, conversationID)
+}
+
+func (svc *conversationService) AddParticipants(reqCtx *model.RequestContext, conversationID, actorID uint, userIDs []uint) model.Response[*model.Conversation] {
+	logger.Info(reqCtx, "AddParticipants called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"actor_id":        actorID,
+		"user_ids":        userIDs,
+	})
+	if len(userIDs) == 0 {
+		return model.BadRequest[*model.Conversation]("At least 1 user is required")
+	}
+
+	actorResponse := svc.AuthorizeMember(reqCtx, conversationID, actorID)
+	if !actorResponse.OK() {
+		return model.ErrorArray[*model.Conversation](actorResponse.Code, actorResponse.Message, actorResponse.Errors)
+	}
+
+	groupResponse := svc.getGroupConversation(reqCtx, conversationID)
+	if !groupResponse.OK() {
+		return groupResponse
+	}
+	conversation := groupResponse.Data
+
+	if !actorResponse.Data.CanManageMembers() {
+		return model.Forbidden[*model.Conversation]("Only owners and admins can add members")
+	}
+
+	addedIDs := []uint{}
+	for _, userID := range uniqueUserIDs(0, userIDs) {
+		if !containsUserID(conversation.UserIds, userID) {
+			addedIDs = append(addedIDs, userID)
+		}
+	}
+	if len(addedIDs) == 0 {
+		return model.SuccessResponse(conversation, "Participants already in conversation")
+	}
+
+	if missingResponse := svc.checkUsersExist(reqCtx, addedIDs); !missingResponse.OK() {
+		return model.ErrorArray[*model.Conversation](missingResponse.Code, missingResponse.Message, missingResponse.Errors)
+	}
+
+	queryResponse := repo.InTx(reqCtx, svc.repo, func(tx repo.RepositoryInterface) model.Response[*model.Conversation] {
+		for _, userID := range addedIDs {
+			participantResponse := tx.Participant().AddParticipantToConversation(reqCtx, conversationID, userID)
+			if !participantResponse.OK() {
+				return model.BadRequest[*model.Conversation]("Failed to add participant to conversation")
+			}
+		}
+
+		conversation.UserIds = append(conversation.UserIds, addedIDs...)
+		updateResponse := tx.Conversation().Update(reqCtx, conversation)
+		if !updateResponse.OK() {
+			return updateResponse
+		}
+
+		return tx.Conversation().QueryOne(reqCtx, &model.Conversation{ID: conversationID})
+	})
+	if !queryResponse.OK() {
+		return queryResponse
+	}
+
+	svc.broadcastEvent(queryResponse.Data, "member_joined", map[string]interface{}{
+		"conversation": queryResponse.Data,
+		"user_ids":     addedIDs,
+		"actor_id":     actorID,
+	})
+
+	return model.SuccessResponse(queryResponse.Data, "Participants added successfully")
+}
+
+func (svc *conversationService) RemoveParticipant(reqCtx *model.RequestContext, conversationID, actorID, userID uint) model.Response[string] {
+	logger.Info(reqCtx, "RemoveParticipant called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"actor_id":        actorID,
+		"user_id":         userID,
+	})
+	if actorID == userID {
+		return svc.LeaveConversation(reqCtx, conversationID, userID)
+	}
+
+	actorResponse := svc.AuthorizeMember(reqCtx, conversationID, actorID)
+	if !actorResponse.OK() {
+		return model.ErrorArray[string](actorResponse.Code, actorResponse.Message, actorResponse.Errors)
+	}
+
+	groupResponse := svc.getGroupConversation(reqCtx, conversationID)
+	if !groupResponse.OK() {
+		return model.ErrorArray[string](groupResponse.Code, groupResponse.Message, groupResponse.Errors)
+	}
+
+	actor := actorResponse.Data
+	if !actor.CanManageMembers() {
+		return model.Forbidden[string]("Only owners and admins can remove members")
+	}
+
+	targetResponse := svc.repo.Participant().GetByConversationAndUser(reqCtx, conversationID, userID)
+	if !targetResponse.OK() {
+		return model.NotFound[string]("Participant not found")
+	}
+	target := targetResponse.Data
+	if target.Role == model.ParticipantRoleOwner {
+		return model.Forbidden[string]("The owner cannot be removed")
+	}
+	if target.Role == model.ParticipantRoleAdmin && actor.Role != model.ParticipantRoleOwner {
+		return model.Forbidden[string]("Only the owner can remove admins")
+	}
+
+	removeResponse := repo.InTx(reqCtx, svc.repo, func(tx repo.RepositoryInterface) model.Response[string] {
+		return removeMember(reqCtx, tx, groupResponse.Data, userID)
+	})
+	if removeResponse.OK() {
+		svc.broadcastMemberLeft(groupResponse.Data, userID, actorID)
+	}
+	return removeResponse
+}
+
+func (svc *conversationService) PromoteParticipant(reqCtx *model.RequestContext, conversationID, actorID, userID uint) model.Response[*model.ConversationParticipant] {
+	logger.Info(reqCtx, "PromoteParticipant called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"actor_id":        actorID,
+		"user_id":         userID,
+	})
+	actorResponse := svc.AuthorizeMember(reqCtx, conversationID, actorID)
+	if !actorResponse.OK() {
+		return model.ErrorArray[*model.ConversationParticipant](actorResponse.Code, actorResponse.Message, actorResponse.Errors)
+	}
+
+	groupResponse := svc.getGroupConversation(reqCtx, conversationID)
+	if !groupResponse.OK() {
+		return model.ErrorArray[*model.ConversationParticipant](groupResponse.Code, groupResponse.Message, groupResponse.Errors)
+	}
+
+	if actorResponse.Data.Role != model.ParticipantRoleOwner {
+		return model.Forbidden[*model.ConversationParticipant]("Only the owner can promote members")
+	}
+
+	targetResponse := svc.repo.Participant().GetByConversationAndUser(reqCtx, conversationID, userID)
+	if !targetResponse.OK() {
+		return model.NotFound[*model.ConversationParticipant]("Participant not found")
+	}
+	target := targetResponse.Data
+	if target.Role != model.ParticipantRoleMember {
+		return model.SuccessResponse(target, "Participant is already an admin")
+	}
+
+	target.Role = model.ParticipantRoleAdmin
+	updateResponse := svc.repo.Participant().Update(reqCtx, target)
+	if !updateResponse.OK() {
+		return updateResponse
+	}
+
+	svc.broadcastEvent(groupResponse.Data, "member_promoted", map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+		"role":            target.Role,
+		"actor_id":        actorID,
+	})
+
+	return model.SuccessResponse(updateResponse.Data, "Participant promoted successfully")
+}
+
+func (svc *conversationService) LeaveConversation(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[string] {
+	logger.Info(reqCtx, "LeaveConversation called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+	})
+	memberResponse := svc.AuthorizeMember(reqCtx, conversationID, userID)
+	if !memberResponse.OK() {
+		return model.ErrorArray[string](memberResponse.Code, memberResponse.Message, memberResponse.Errors)
+	}
+
+	groupResponse := svc.getGroupConversation(reqCtx, conversationID)
+	if !groupResponse.OK() {
+		return model.ErrorArray[string](groupResponse.Code, groupResponse.Message, groupResponse.Errors)
+	}
+	conversation := groupResponse.Data
+
+	leaveResponse := repo.InTx(reqCtx, svc.repo, func(tx repo.RepositoryInterface) model.Response[string] {
+		// Hand ownership over as the owner leaves so the group is never left unmanaged
+		if memberResponse.Data.Role == model.ParticipantRoleOwner {
+			successor := nextOwner(conversation.Participants, userID)
+			if successor != nil {
+				successor.Role = model.ParticipantRoleOwner
+				if updateResponse := tx.Participant().Update(reqCtx, successor); !updateResponse.OK() {
+					return model.BadRequest[string]("Failed to transfer ownership")
+				}
+			}
+		}
+		return removeMember(reqCtx, tx, conversation, userID)
+	})
+	if leaveResponse.OK() {
+		svc.broadcastMemberLeft(conversation, userID, userID)
+	}
+	return leaveResponse
+}
+
+// getGroupConversation loads a conversation and ensures it is a group conversation
+func (svc *conversationService) getGroupConversation(reqCtx *model.RequestContext, conversationID uint) model.Response[*model.Conversation] {
+	response := svc.repo.Conversation().QueryOne(reqCtx, &model.Conversation{ID: conversationID})
+	if !response.OK() {
+		return response
+	}
+	if !response.Data.IsGroup() {
+		return model.BadRequest[*model.Conversation]("Membership can only be changed in group conversations")
+	}
+	return response
+}
+
+// removeMember deletes the participant row and keeps UserIds in sync
+func removeMember(reqCtx *model.RequestContext, tx repo.RepositoryInterface, conversation *model.Conversation, userID uint) model.Response[string] {
+	removeResponse := tx.Participant().RemoveParticipantFromConversation(reqCtx, conversation.ID, userID)
+	if !removeResponse.OK() {
+		return removeResponse
+	}
+
+	remainingIDs := model.UserIds{}
+	for _, id := range conversation.UserIds {
+		if id != userID {
+			remainingIDs = append(remainingIDs, id)
+		}
+	}
+	conversation.UserIds = remainingIDs
+	if updateResponse := tx.Conversation().Update(reqCtx, conversation); !updateResponse.OK() {
+		return model.BadRequest[string]("Failed to update conversation")
+	}
+	return model.SuccessResponse("", "Participant removed successfully")
+}
+
+// broadcastMemberLeft notifies both the remaining members and the removed user
+func (svc *conversationService) broadcastMemberLeft(conversation *model.Conversation, userID, actorID uint) {
+	svc.broadcastEvent(conversation, "member_left", map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"user_id":         userID,
+		"actor_id":        actorID,
+	})
+}
+
+// broadcastEvent notifies the conversation participants, minus any excluded users, about a change
+func (svc *conversationService) broadcastEvent(conversation *model.Conversation, event string, payload map[string]interface{}, excludeUserIDs ...uint) {
+	if svc.client == nil || svc.client.SocketClient == nil {
+		return
+	}
+
+	userIds := []int{}
+	for _, participant := range conversation.Participants {
+		if containsUserID(excludeUserIDs, participant.UserID) {
+			continue
+		}
+		userIds = append(userIds, int(participant.UserID))
+	}
+	if len(userIds) == 0 {
+		return
+	}
+
+	svc.client.SocketClient.Broadcast(&model.BroadcastMessage{
+		UserIds: userIds,
+		Event:   event,
+		Payload: payload,
+	})
+}
+
+// nextOwner picks the admin (or, failing that, the member) who joined first
+func nextOwner(participants []*model.ConversationParticipant, leavingUserID uint) *model.ConversationParticipant {
+	var successor *model.ConversationParticipant
+	for _, participant := range participants {
+		if participant.UserID == leavingUserID {
+			continue
+		}
+		if successor == nil ||
+			(participant.Role == model.ParticipantRoleAdmin && successor.Role != model.ParticipantRoleAdmin) ||
+			(participant.Role == successor.Role && participant.ID < successor.ID) {
+			successor = participant
+		}
+	}
+	return successor
+}
+
+// checkUsersExist fails with a validation error naming the user IDs that match no account
+func (svc *conversationService) checkUsersExist(reqCtx *model.RequestContext, userIDs []uint) model.Response[bool] {
+	usersResponse := svc.repo.User().GetByIDs(reqCtx, userIDs)
+	if !usersResponse.OK() {
+		return model.ErrorArray[bool](usersResponse.Code, usersResponse.Message, usersResponse.Errors)
+	}
+	found := map[uint]bool{}
+	for _, user := range usersResponse.Data {
+		found[user.ID] = true
+	}
+	errors := []model.Error{}
+	for _, userID := range userIDs {
+		if !found[userID] {
+			errors = append(errors, model.Error{Code: model.CodeValidation, Message: fmt.Sprintf("User %d not found", userID)})
+		}
+	}
+	if len(errors) > 0 {
+		return model.ValidationErrorWithErrors[bool]("Some users do not exist", errors)
+	}
+	return model.SuccessResponse(true, "Users exist")
+}
+
+func uniqueUserIDs(first uint, userIDs []uint) model.UserIds {
+	result := model.UserIds{}
+	seen := map[uint]bool{}
+	for _, id := range append([]uint{first}, userIDs...) {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
+func containsUserID(userIDs model.UserIds, userID uint) bool {
+	for _, id := range userIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
 func NewConversationService(params *common.Params) ConversationService {
 	return &conversationService{
-		repo: params.Repo,
+		repo:   params.Repo,
+		client: params.Client,
 	}
 }
@@ -1,24 +1,33 @@
 package initial
 
 import (
+	"local/service/attachment"
 	"local/service/auth"
 	"local/service/common"
 	"local/service/conversation"
 	"local/service/message"
 	"local/service/metrics"
+	"local/service/outbox"
+	"local/service/user"
 )
 
 type Service struct {
 	CvsSvc conversation.ConversationService
 	AuthSvc auth.AuthService
 	MessageSvc message.MessageService
+	OutboxSvc outbox.OutboxService
+	UserSvc user.UserService
+	AttachmentSvc attachment.AttachmentService
 }
 
 
 func NewService(params *common.Params) Service {
 	CvsSvc := conversation.NewConversationService(params)
 	AuthSvc := auth.NewAuthService(params)
-	MessageSvc := message.NewMessageService(params, AuthSvc, CvsSvc)
+	OutboxSvc := outbox.NewOutboxService(params)
+	MessageSvc := message.NewMessageService(params, AuthSvc, CvsSvc, OutboxSvc)
+	UserSvc := user.NewUserService(params)
+	AttachmentSvc := attachment.NewAttachmentService(params, CvsSvc)
 
 	// Initialize Prometheus metrics collector
 	metrics.NewPrometheusMetrics(params)
@@ -27,5 +36,8 @@ func NewService(params *common.Params) Service {
 		CvsSvc: CvsSvc,
 		AuthSvc: AuthSvc,
 		MessageSvc: MessageSvc,
+		OutboxSvc: OutboxSvc,
+		UserSvc: UserSvc,
+		AttachmentSvc: AttachmentSvc,
 	}
 }
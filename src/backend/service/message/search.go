@@ -0,0 +1,111 @@
+package message
+
+import (
+	"fmt"
+	"local/infra/provider/search"
+	"local/model"
+	"local/util/logger"
+	"strings"
+	"unicode/utf8"
+)
+
+// SearchMessages pages through the messages matching the query in the user's
+// conversations, newest first, with the matching words highlighted
+func (svc *messageService) SearchMessages(reqCtx *model.RequestContext, userID uint, params model.MessageSearchParams) model.Response[*model.Page[*model.MessageSearchResult]] {
+	logger.Info(reqCtx, "SearchMessages called", map[string]interface{}{
+		"user_id":         userID,
+		"conversation_id": params.ConversationID,
+		"sender_id":       params.SenderID,
+	})
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		return model.ValidationError[*model.Page[*model.MessageSearchResult]]("Search query is required")
+	}
+	if utf8.RuneCountInString(query) > model.MaxMessageSearchLength {
+		return model.ValidationError[*model.Page[*model.MessageSearchResult]](fmt.Sprintf("Search query must be at most %d characters", model.MaxMessageSearchLength))
+	}
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		return model.ValidationError[*model.Page[*model.MessageSearchResult]]("Search query must contain a letter or digit")
+	}
+	if params.From != nil && params.To != nil && params.From.After(*params.To) {
+		return model.ValidationError[*model.Page[*model.MessageSearchResult]]("Search range must start before it ends")
+	}
+
+	conversationsResponse := svc.searchableConversations(reqCtx, userID, params.ConversationID)
+	if !conversationsResponse.OK() {
+		return model.ErrorArray[*model.Page[*model.MessageSearchResult]](conversationsResponse.Code, conversationsResponse.Message, conversationsResponse.Errors)
+	}
+	page := &model.Page[*model.MessageSearchResult]{Items: []*model.MessageSearchResult{}}
+	if len(conversationsResponse.Data) == 0 {
+		return model.SuccessResponse(page, "Messages searched successfully")
+	}
+
+	// One extra ID tells whether another page follows
+	limit := model.CursorParams{Limit: params.Limit}.Normalize().Limit
+	ids, err := svc.searchIndex().Search(reqCtx.Context(), &model.MessageSearchFilter{
+		Terms:           terms,
+		ConversationIDs: conversationsResponse.Data,
+		SenderID:        params.SenderID,
+		From:            params.From,
+		To:              params.To,
+		BeforeID:        params.BeforeID,
+		Limit:           limit + 1,
+	})
+	if err != nil {
+		logger.Error(reqCtx, "Failed to search messages", err)
+		return model.InternalError[*model.Page[*model.MessageSearchResult]]("Failed to search messages")
+	}
+	if len(ids) > limit {
+		ids = ids[:limit]
+		page.HasMore = true
+	}
+	if len(ids) == 0 {
+		return model.SuccessResponse(page, "Messages searched successfully")
+	}
+	page.NextCursor = ids[len(ids)-1]
+
+	messagesResponse := svc.repo.Message().GetByIDs(reqCtx, ids, userID)
+	if !messagesResponse.OK() {
+		return model.ErrorArray[*model.Page[*model.MessageSearchResult]](messagesResponse.Code, messagesResponse.Message, messagesResponse.Errors)
+	}
+	for _, message := range messagesResponse.Data {
+		// The index may not have caught up with a deletion yet
+		if message.IsDeleted() {
+			continue
+		}
+		page.Items = append(page.Items, &model.MessageSearchResult{
+			Message:   message,
+			Highlight: search.Highlight(message.Content, terms),
+		})
+	}
+	return model.SuccessResponse(page, "Messages searched successfully")
+}
+
+// searchableConversations is the given conversation when the user belongs to it,
+// or every conversation of the user when none is given
+func (svc *messageService) searchableConversations(reqCtx *model.RequestContext, userID, conversationID uint) model.Response[[]uint] {
+	if conversationID != 0 {
+		if authResponse := svc.cvsSvc.AuthorizeMember(reqCtx, conversationID, userID); !authResponse.OK() {
+			return model.ErrorArray[[]uint](authResponse.Code, authResponse.Message, authResponse.Errors)
+		}
+		return model.SuccessResponse([]uint{conversationID}, "Conversation authorized")
+	}
+	participantsResponse := svc.repo.Participant().GetByUserID(reqCtx, userID)
+	if !participantsResponse.OK() {
+		return model.ErrorArray[[]uint](participantsResponse.Code, participantsResponse.Message, participantsResponse.Errors)
+	}
+	conversationIDs := make([]uint, 0, len(participantsResponse.Data))
+	for _, participant := range participantsResponse.Data {
+		conversationIDs = append(conversationIDs, participant.ConversationID)
+	}
+	return model.SuccessResponse(conversationIDs, "Conversations retrieved successfully")
+}
+
+// searchIndex is the configured index, or MySQL full-text search when none was given
+func (svc *messageService) searchIndex() search.SearchIndex {
+	if svc.search == nil {
+		return search.NewMySQLIndex(svc.repo.Message())
+	}
+	return svc.search
+}
@@ -2,24 +2,38 @@ package message
 
 import (
 	"local/client"
+	"local/infra/provider/events"
+	"local/infra/provider/search"
 	"local/infra/repo"
+	"local/job/consumer"
 	"local/model"
 	"local/service/auth"
 	"local/service/common"
 	"local/service/conversation"
+	"local/service/outbox"
 	"local/util/logger"
+	"strings"
+	"time"
 )
 
 type MessageService interface {
 	CreateMessage(reqCtx *model.RequestContext, message *model.Message) model.Response[*model.Message]
-	GetMessagesByConversationID(reqCtx *model.RequestContext, conversationID uint) model.Response[[]*model.Message]
+	GetMessagesByConversationID(reqCtx *model.RequestContext, conversationID, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]]
+	EditMessage(reqCtx *model.RequestContext, conversationID, messageID, userID uint, content string) model.Response[*model.Message]
+	DeleteMessage(reqCtx *model.RequestContext, conversationID, messageID, userID uint) model.Response[*model.Message]
+	GetThread(reqCtx *model.RequestContext, messageID, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]]
+	AddReaction(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[[]*model.ReactionSummary]
+	RemoveReaction(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[[]*model.ReactionSummary]
+	SearchMessages(reqCtx *model.RequestContext, userID uint, params model.MessageSearchParams) model.Response[*model.Page[*model.MessageSearchResult]]
 }
 
 type messageService struct {
-	repo *repo.Repository
+	repo repo.RepositoryInterface
 	client *client.Client
 	authService auth.AuthService
 	cvsSvc conversation.ConversationService
+	search search.SearchIndex
+	outbox outbox.OutboxService
 }
 
 func (svc *messageService) CreateMessage(reqCtx *model.RequestContext, message *model.Message) model.Response[*model.Message] {
@@ -27,55 +41,423 @@ func (svc *messageService) CreateMessage(reqCtx *model.RequestContext, message *
 		"conversation_id": message.ConversationID,
 		"sender_id": message.SenderID,
 	})
-	createResponse := svc.repo.MessageRepo.Create(reqCtx, message)
-	if !createResponse.OK() {
-		return createResponse
+	authResponse := svc.cvsSvc.AuthorizeMember(reqCtx, message.ConversationID, message.SenderID)
+	if !authResponse.OK() {
+		return model.ErrorArray[*model.Message](authResponse.Code, authResponse.Message, authResponse.Errors)
+	}
+	if blockedResponse := svc.rejectBlocked(reqCtx, authResponse.Data); !blockedResponse.OK() {
+		return blockedResponse
+	}
+	if replyResponse := svc.resolveReply(reqCtx, message); !replyResponse.OK() {
+		return replyResponse
+	}
+	attachmentResponse := svc.resolveAttachment(reqCtx, message)
+	if !attachmentResponse.OK() {
+		return model.ErrorArray[*model.Message](attachmentResponse.Code, attachmentResponse.Message, attachmentResponse.Errors)
 	}
-
-	createdMessage := createResponse.Data
 
 	conversationResponse := svc.cvsSvc.GetConversationByID(reqCtx, message.ConversationID)
 	if !conversationResponse.OK() {
 		return model.BadRequest[*model.Message]("Conversation not found")
 	}
-
 	conversation := conversationResponse.Data
-	conversation.LastMessageID = createdMessage.ID
-	updateResponse := svc.repo.ConversationRepo.Update(reqCtx, conversation)
-	if !updateResponse.OK() {
-		return model.BadRequest[*model.Message]("Failed to update conversation")
+
+	// The message, its place as the conversation's last message, its deliveries and
+	// the sender's read marker are saved together, so a saved message is always delivered
+	var deliveries []*model.OutboxEvent
+	createResponse := repo.InTx(reqCtx, svc.repo, func(tx repo.RepositoryInterface) model.Response[*model.Message] {
+		createResponse := tx.Message().Create(reqCtx, message, func(created *model.Message) ([]*model.OutboxEvent, error) {
+			conversation.LastMessageID = created.ID
+			created.Conversation = conversation
+			created.ReplyTo = message.ReplyTo
+			var err error
+			deliveries, err = newMessageDeliveries(created, message.SessionID)
+			return deliveries, err
+		})
+		if !createResponse.OK() {
+			return createResponse
+		}
+
+		// The sender has obviously seen their own message
+		readResponse := tx.Participant().MarkRead(reqCtx, message.ConversationID, message.SenderID, createResponse.Data.ID)
+		if !readResponse.OK() {
+			return model.ErrorArray[*model.Message](readResponse.Code, readResponse.Message, readResponse.Errors)
+		}
+		return createResponse
+	})
+	if !createResponse.OK() {
+		return createResponse
+	}
+	createdMessage := createResponse.Data
+
+	svc.outbox.Dispatch(reqCtx, deliveries)
+	return model.SuccessResponse(createdMessage, "Message created successfully")
+}
+
+func (svc *messageService) GetMessagesByConversationID(reqCtx *model.RequestContext, conversationID, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
+	logger.Info(reqCtx, "GetMessagesByConversationID called", map[string]interface{}{"conversation_id": conversationID, "user_id": userID})
+	if authResponse := svc.cvsSvc.AuthorizeMember(reqCtx, conversationID, userID); !authResponse.OK() {
+		return model.ErrorArray[*model.Page[*model.Message]](authResponse.Code, authResponse.Message, authResponse.Errors)
+	}
+	response := svc.repo.Message().GetByConversationID(reqCtx, conversationID, userID, cursor)
+	return response
+}
+
+// GetThread lists the replies in the thread a message belongs to. Passing a
+// reply resolves to the thread it was posted in.
+func (svc *messageService) GetThread(reqCtx *model.RequestContext, messageID, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
+	logger.Info(reqCtx, "GetThread called", map[string]interface{}{"message_id": messageID, "user_id": userID})
+	messageResponse := svc.repo.Message().GetByID(reqCtx, messageID)
+	if !messageResponse.OK() {
+		return model.ErrorArray[*model.Page[*model.Message]](messageResponse.Code, messageResponse.Message, messageResponse.Errors)
+	}
+	root := messageResponse.Data
+	if authResponse := svc.cvsSvc.AuthorizeMember(reqCtx, root.ConversationID, userID); !authResponse.OK() {
+		return model.ErrorArray[*model.Page[*model.Message]](authResponse.Code, authResponse.Message, authResponse.Errors)
+	}
+
+	rootID := root.ID
+	if root.ThreadRootID != nil {
+		rootID = *root.ThreadRootID
+	}
+	return svc.repo.Message().GetThread(reqCtx, rootID, userID, cursor)
+}
+
+// rejectBlocked stops messages in a private conversation once either user blocked the other
+func (svc *messageService) rejectBlocked(reqCtx *model.RequestContext, sender *model.ConversationParticipant) model.Response[*model.Message] {
+	if sender.Conversation.Type != model.ConversationTypePrivate {
+		return model.SuccessResponse[*model.Message](nil, "Not blocked")
+	}
+	for _, userID := range sender.Conversation.UserIds {
+		if userID == sender.UserID {
+			continue
+		}
+		blockedResponse := svc.repo.Block().IsBlockedBetween(reqCtx, sender.UserID, userID)
+		if !blockedResponse.OK() {
+			return model.ErrorArray[*model.Message](blockedResponse.Code, blockedResponse.Message, blockedResponse.Errors)
+		}
+		if blockedResponse.Data {
+			return model.Forbidden[*model.Message]("You cannot message this user")
+		}
+	}
+	return model.SuccessResponse[*model.Message](nil, "Not blocked")
+}
+
+// resolveReply checks that the quoted message and thread root live in the same
+// conversation, flattens replies-to-replies onto the original thread root and
+// attaches the quote preview.
+func (svc *messageService) resolveReply(reqCtx *model.RequestContext, message *model.Message) model.Response[*model.Message] {
+	if message.ReplyToID != nil {
+		quotedResponse := svc.loadInConversation(reqCtx, *message.ReplyToID, message.ConversationID)
+		if !quotedResponse.OK() {
+			return quotedResponse
+		}
+		message.ReplyTo = model.NewMessagePreview(quotedResponse.Data)
+	}
+
+	if message.ThreadRootID != nil {
+		rootResponse := svc.loadInConversation(reqCtx, *message.ThreadRootID, message.ConversationID)
+		if !rootResponse.OK() {
+			return rootResponse
+		}
+		if rootResponse.Data.ThreadRootID != nil {
+			rootID := *rootResponse.Data.ThreadRootID
+			message.ThreadRootID = &rootID
+		}
 	}
+	return model.SuccessResponse(message, "Reply resolved")
+}
 
-	createdMessage.Conversation = conversation
-	
+// resolveAttachment checks the message type and, for attachment messages, that
+// the sender uploaded the file to this conversation and has not sent it yet.
+func (svc *messageService) resolveAttachment(reqCtx *model.RequestContext, message *model.Message) model.Response[*model.Attachment] {
+	switch message.MessageType {
+	case "", model.MessageTypeText:
+		message.MessageType = model.MessageTypeText
+		if message.AttachmentID != nil {
+			return model.ValidationError[*model.Attachment]("Text messages cannot carry an attachment")
+		}
+		return model.SuccessResponse[*model.Attachment](nil, "No attachment")
+	case model.MessageTypeAttachment:
+	default:
+		return model.ValidationError[*model.Attachment]("Unsupported message type")
+	}
+
+	if message.AttachmentID == nil {
+		return model.ValidationError[*model.Attachment]("attachment_id is required for attachment messages")
+	}
+	attachmentResponse := svc.repo.Attachment().GetByID(reqCtx, *message.AttachmentID)
+	if !attachmentResponse.OK() {
+		return model.ValidationError[*model.Attachment]("Attachment not found")
+	}
+	attachment := attachmentResponse.Data
+	if attachment.ConversationID != message.ConversationID || attachment.UploaderID != message.SenderID {
+		return model.ValidationError[*model.Attachment]("Attachment not found")
+	}
+	if attachment.MessageID != nil {
+		return model.ValidationError[*model.Attachment]("Attachment has already been sent")
+	}
+	return attachmentResponse
+}
+
+func (svc *messageService) loadInConversation(reqCtx *model.RequestContext, messageID, conversationID uint) model.Response[*model.Message] {
+	messageResponse := svc.repo.Message().GetByID(reqCtx, messageID)
+	if !messageResponse.OK() || messageResponse.Data.ConversationID != conversationID {
+		return model.ValidationError[*model.Message]("Replied message not found in this conversation")
+	}
+	return messageResponse
+}
+
+// EditMessage replaces the content of a message. Only the sender may edit, and
+// the previous content is kept in the edit history.
+func (svc *messageService) EditMessage(reqCtx *model.RequestContext, conversationID, messageID, userID uint, content string) model.Response[*model.Message] {
+	logger.Info(reqCtx, "EditMessage called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"message_id": messageID,
+		"user_id": userID,
+	})
+	if content == "" {
+		return model.ValidationError[*model.Message]("Content is required")
+	}
+	ownResponse := svc.authorizeSender(reqCtx, conversationID, messageID, userID, "edit")
+	if !ownResponse.OK() {
+		return ownResponse
+	}
+
+	var deliveries []*model.OutboxEvent
+	userIds := svc.participantIDs(reqCtx, conversationID)
+	editResponse := svc.repo.Message().Edit(reqCtx, ownResponse.Data, userID, content, func(edited *model.Message) ([]*model.OutboxEvent, error) {
+		var err error
+		deliveries, err = messageDeliveries(consumer.MessageEventEdited, edited, changeBroadcast(edited, userIds, "message_updated"))
+		return deliveries, err
+	})
+	if !editResponse.OK() {
+		return editResponse
+	}
+	svc.outbox.Dispatch(reqCtx, deliveries)
+	return editResponse
+}
+
+// DeleteMessage soft deletes a message so it renders as a tombstone. Only the
+// sender may delete.
+func (svc *messageService) DeleteMessage(reqCtx *model.RequestContext, conversationID, messageID, userID uint) model.Response[*model.Message] {
+	logger.Info(reqCtx, "DeleteMessage called", map[string]interface{}{
+		"conversation_id": conversationID,
+		"message_id": messageID,
+		"user_id": userID,
+	})
+	ownResponse := svc.authorizeSender(reqCtx, conversationID, messageID, userID, "delete")
+	if !ownResponse.OK() {
+		return ownResponse
+	}
+
+	var deliveries []*model.OutboxEvent
+	userIds := svc.participantIDs(reqCtx, conversationID)
+	deleteResponse := svc.repo.Message().SoftDelete(reqCtx, ownResponse.Data, func(deleted *model.Message) ([]*model.OutboxEvent, error) {
+		var err error
+		deliveries, err = messageDeliveries(consumer.MessageEventDeleted, deleted, changeBroadcast(deleted, userIds, "message_deleted"))
+		return deliveries, err
+	})
+	if !deleteResponse.OK() {
+		return deleteResponse
+	}
+	svc.outbox.Dispatch(reqCtx, deliveries)
+	return deleteResponse
+}
+
+// authorizeSender loads a live message in the conversation and checks that userID sent it
+func (svc *messageService) authorizeSender(reqCtx *model.RequestContext, conversationID, messageID, userID uint, action string) model.Response[*model.Message] {
+	if authResponse := svc.cvsSvc.AuthorizeMember(reqCtx, conversationID, userID); !authResponse.OK() {
+		return model.ErrorArray[*model.Message](authResponse.Code, authResponse.Message, authResponse.Errors)
+	}
+	messageResponse := svc.repo.Message().GetByID(reqCtx, messageID)
+	if !messageResponse.OK() {
+		return messageResponse
+	}
+	message := messageResponse.Data
+	if message.ConversationID != conversationID || message.IsDeleted() {
+		return model.NotFound[*model.Message]("Message not found")
+	}
+	if message.SenderID != userID {
+		return model.Forbidden[*model.Message]("Only the sender can " + action + " this message")
+	}
+	return model.SuccessResponse(message, "Message authorized")
+}
+
+// AddReaction reacts to a message with an emoji. Reacting twice with the same
+// emoji is a no-op and does not notify anyone.
+func (svc *messageService) AddReaction(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[[]*model.ReactionSummary] {
+	logger.Info(reqCtx, "AddReaction called", map[string]interface{}{"message_id": messageID, "user_id": userID, "emoji": emoji})
+	return svc.react(reqCtx, messageID, userID, emoji, true)
+}
+
+// RemoveReaction withdraws the user's emoji from a message. Removing a reaction
+// that does not exist is a no-op.
+func (svc *messageService) RemoveReaction(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[[]*model.ReactionSummary] {
+	logger.Info(reqCtx, "RemoveReaction called", map[string]interface{}{"message_id": messageID, "user_id": userID, "emoji": emoji})
+	return svc.react(reqCtx, messageID, userID, emoji, false)
+}
+
+func (svc *messageService) react(reqCtx *model.RequestContext, messageID, userID uint, emoji string, add bool) model.Response[[]*model.ReactionSummary] {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || len(emoji) > model.MaxEmojiLength || strings.ContainsAny(emoji, " \t\n") {
+		return model.ValidationError[[]*model.ReactionSummary]("Invalid emoji")
+	}
+	messageResponse := svc.repo.Message().GetByID(reqCtx, messageID)
+	if !messageResponse.OK() {
+		return model.ErrorArray[[]*model.ReactionSummary](messageResponse.Code, messageResponse.Message, messageResponse.Errors)
+	}
+	message := messageResponse.Data
+	if authResponse := svc.cvsSvc.AuthorizeMember(reqCtx, message.ConversationID, userID); !authResponse.OK() {
+		return model.ErrorArray[[]*model.ReactionSummary](authResponse.Code, authResponse.Message, authResponse.Errors)
+	}
+	if message.IsDeleted() {
+		return model.NotFound[[]*model.ReactionSummary]("Message not found")
+	}
+
+	var changeResponse model.Response[bool]
+	if add {
+		changeResponse = svc.repo.Reaction().Add(reqCtx, messageID, userID, emoji)
+	} else {
+		changeResponse = svc.repo.Reaction().Remove(reqCtx, messageID, userID, emoji)
+	}
+	if !changeResponse.OK() {
+		return model.ErrorArray[[]*model.ReactionSummary](changeResponse.Code, changeResponse.Message, changeResponse.Errors)
+	}
+
+	summaryResponse := svc.repo.Reaction().Summarize(reqCtx, []uint{messageID}, userID)
+	if !summaryResponse.OK() {
+		return model.ErrorArray[[]*model.ReactionSummary](summaryResponse.Code, summaryResponse.Message, summaryResponse.Errors)
+	}
+	reactions := summaryResponse.Data[messageID]
+	if reactions == nil {
+		reactions = []*model.ReactionSummary{}
+	}
+
+	if changeResponse.Data {
+		svc.broadcastReaction(reqCtx, message, userID, emoji, add, reactions)
+	}
+	return model.SuccessResponse(reactions, "Reactions updated successfully")
+}
+
+// broadcastReaction tells every participant which reaction changed. The counts
+// are shared, so reacted_by_me is stripped; clients derive it from user_id.
+func (svc *messageService) broadcastReaction(reqCtx *model.RequestContext, message *model.Message, userID uint, emoji string, added bool, reactions []*model.ReactionSummary) {
+	shared := make([]*model.ReactionSummary, 0, len(reactions))
+	for _, reaction := range reactions {
+		shared = append(shared, &model.ReactionSummary{Emoji: reaction.Emoji, Count: reaction.Count})
+	}
+	svc.broadcastToConversation(reqCtx, message.ConversationID, "reaction_updated", map[string]interface{}{
+		"conversation_id": message.ConversationID,
+		"message_id": message.ID,
+		"user_id": userID,
+		"emoji": emoji,
+		"added": added,
+		"reactions": shared,
+	})
+}
+
+// newMessageDeliveries announce a new message: its event for other services and
+// its broadcast to the participants. Those who muted the conversation get their
+// own broadcast, flagged so their clients stay quiet
+func newMessageDeliveries(message *model.Message, sessionID string) ([]*model.OutboxEvent, error) {
+	now := time.Now()
 	userIds := []int{}
-	for _, participant := range conversation.Participants {
+	mutedUserIds := []int{}
+	for _, participant := range message.Conversation.Participants {
+		if participant.UserID != message.SenderID && participant.IsMuted(now) {
+			mutedUserIds = append(mutedUserIds, int(participant.UserID))
+			continue
+		}
 		userIds = append(userIds, int(participant.UserID))
 	}
+	return messageDeliveries(consumer.MessageEventCreated, message,
+		&model.BroadcastMessage{
+			UserIds: userIds,
+			SessionId: sessionID,
+			Event: "message",
+			Payload: map[string]interface{}{
+				"message": message,
+			},
+		},
+		&model.BroadcastMessage{
+			UserIds: mutedUserIds,
+			SessionId: sessionID,
+			Event: "message",
+			Payload: map[string]interface{}{
+				"message": message,
+				"muted": true,
+			},
+		},
+	)
+}
+
+// messageDeliveries are the event of a message change followed by its broadcasts;
+// broadcasts without recipients are dropped
+func messageDeliveries(eventType string, message *model.Message, broadcasts ...*model.BroadcastMessage) ([]*model.OutboxEvent, error) {
+	event := events.NewMessageEvent(eventType, message)
+	key := events.MessageEventKey(event)
+	delivery, err := model.NewOutboxEvent(model.OutboxDestinationEvents, key, event)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []*model.OutboxEvent{delivery}
+	for _, broadcast := range broadcasts {
+		if len(broadcast.UserIds) == 0 {
+			continue
+		}
+		delivery, err := model.NewOutboxEvent(model.OutboxDestinationSocket, key, broadcast)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
 
-	svc.client.SocketClient.Broadcast(&model.BroadcastMessage{
+func changeBroadcast(message *model.Message, userIds []int, event string) *model.BroadcastMessage {
+	return &model.BroadcastMessage{
 		UserIds: userIds,
-		SessionId: message.SessionID,
-		Event: "message",
+		Event: event,
 		Payload: map[string]interface{}{
 			"message": message,
 		},
-	})
-	
-	return model.SuccessResponse(createdMessage, "Message created successfully")
+	}
 }
 
-func (svc *messageService) GetMessagesByConversationID(reqCtx *model.RequestContext, conversationID uint) model.Response[[]*model.Message] {
-	logger.Info(reqCtx, "GetMessagesByConversationID called", map[string]interface{}{"conversation_id": conversationID})
-	response := svc.repo.MessageRepo.GetByConversationID(reqCtx, conversationID)
-	return response
+// participantIDs lists who to broadcast to; without them a change is still saved, just not broadcast
+func (svc *messageService) participantIDs(reqCtx *model.RequestContext, conversationID uint) []int {
+	conversationResponse := svc.cvsSvc.GetConversationByID(reqCtx, conversationID)
+	if !conversationResponse.OK() {
+		logger.Warn(reqCtx, "Failed to load conversation for broadcast", map[string]interface{}{"error": conversationResponse.ErrorString()})
+		return nil
+	}
+	userIds := []int{}
+	for _, participant := range conversationResponse.Data.Participants {
+		userIds = append(userIds, int(participant.UserID))
+	}
+	return userIds
+}
+
+func (svc *messageService) broadcastToConversation(reqCtx *model.RequestContext, conversationID uint, event string, payload interface{}) {
+	userIds := svc.participantIDs(reqCtx, conversationID)
+	if svc.client == nil || svc.client.SocketClient == nil || len(userIds) == 0 {
+		return
+	}
+	svc.client.SocketClient.Broadcast(&model.BroadcastMessage{
+		UserIds: userIds,
+		Event: event,
+		Payload: payload,
+	})
 }
 
-func NewMessageService(params *common.Params, authService auth.AuthService, cvsSvc conversation.ConversationService) MessageService {
+func NewMessageService(params *common.Params, authService auth.AuthService, cvsSvc conversation.ConversationService, outboxSvc outbox.OutboxService) MessageService {
 	return &messageService{
 		repo: params.Repo,
 		client: params.Client,
 		authService: authService,
 		cvsSvc: cvsSvc,
+		search: params.Search,
+		outbox: outboxSvc,
 	}
 }
@@ -1,13 +1,14 @@
 package metrics
 
 import (
+	"context"
+	"errors"
 	"local/model"
 	"local/service/common"
 	"runtime"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 // PrometheusMetrics holds all Prometheus metrics collectors
@@ -30,52 +31,65 @@ type PrometheusMetrics struct {
 	gcRunsTotal      prometheus.Gauge
 }
 
+// registerGauge registers a gauge with the default registry, reusing the existing
+// collector when the service is constructed more than once (e.g. in tests)
+func registerGauge(opts prometheus.GaugeOpts) prometheus.Gauge {
+	gauge := prometheus.NewGauge(opts)
+	if err := prometheus.Register(gauge); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			return alreadyRegistered.ExistingCollector.(prometheus.Gauge)
+		}
+	}
+	return gauge
+}
+
 // NewPrometheusMetrics creates a new Prometheus metrics collector
 func NewPrometheusMetrics(params *common.Params) *PrometheusMetrics {
 	pm := &PrometheusMetrics{
 		params: params,
 
-		usersTotal: promauto.NewGauge(prometheus.GaugeOpts{
+		usersTotal: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_users_total",
 			Help: "Total number of registered users",
 		}),
 
-		conversationsTotal: promauto.NewGauge(prometheus.GaugeOpts{
+		conversationsTotal: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_conversations_total",
 			Help: "Total number of conversations",
 		}),
 
-		messagesTotal: promauto.NewGauge(prometheus.GaugeOpts{
+		messagesTotal: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_messages_total",
 			Help: "Total number of messages",
 		}),
 
-		uptimeSeconds: promauto.NewGauge(prometheus.GaugeOpts{
+		uptimeSeconds: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_uptime_seconds",
 			Help: "Application uptime in seconds",
 		}),
 
-		goroutinesCount: promauto.NewGauge(prometheus.GaugeOpts{
+		goroutinesCount: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_goroutines",
 			Help: "Current number of goroutines",
 		}),
 
-		memoryAllocBytes: promauto.NewGauge(prometheus.GaugeOpts{
+		memoryAllocBytes: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_memory_alloc_bytes",
 			Help: "Bytes of allocated heap objects",
 		}),
 
-		memoryTotalBytes: promauto.NewGauge(prometheus.GaugeOpts{
+		memoryTotalBytes: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_memory_total_bytes",
 			Help: "Cumulative bytes allocated for heap objects",
 		}),
 
-		memorySysBytes: promauto.NewGauge(prometheus.GaugeOpts{
+		memorySysBytes: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_memory_sys_bytes",
 			Help: "Total bytes of memory obtained from the OS",
 		}),
 
-		gcRunsTotal: promauto.NewGauge(prometheus.GaugeOpts{
+		gcRunsTotal: registerGauge(prometheus.GaugeOpts{
 			Name: "simple_chat_gc_runs_total",
 			Help: "Total number of completed GC cycles",
 		}),
@@ -103,20 +117,20 @@ func (pm *PrometheusMetrics) collectMetrics() {
 
 // updateMetrics updates all Prometheus metrics
 func (pm *PrometheusMetrics) updateMetrics(startTime time.Time) {
-	reqCtx := model.NewRequestContext(nil)
+	reqCtx := model.NewRequestContext(context.Background())
 
 	// Count users using repository method
-	if userCount, err := pm.params.Repo.UserRepo.Count(reqCtx); err == nil {
+	if userCount, err := pm.params.Repo.User().Count(reqCtx); err == nil {
 		pm.usersTotal.Set(float64(userCount))
 	}
 
 	// Count conversations using repository method
-	if conversationCount, err := pm.params.Repo.ConversationRepo.Count(reqCtx); err == nil {
+	if conversationCount, err := pm.params.Repo.Conversation().Count(reqCtx); err == nil {
 		pm.conversationsTotal.Set(float64(conversationCount))
 	}
 
 	// Count messages using repository method
-	if messageCount, err := pm.params.Repo.MessageRepo.Count(reqCtx); err == nil {
+	if messageCount, err := pm.params.Repo.Message().Count(reqCtx); err == nil {
 		pm.messagesTotal.Set(float64(messageCount))
 	}
 
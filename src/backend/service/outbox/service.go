@@ -0,0 +1,206 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"local/client"
+	"local/config"
+	"local/infra/provider/events"
+	"local/infra/repo"
+	"local/job/consumer"
+	"local/model"
+	"local/service/common"
+	"local/util/logger"
+	"time"
+)
+
+const (
+	// relayBatchSize is how many due deliveries one relay pass takes
+	relayBatchSize = 100
+	// retryBase is the wait after the first failed attempt; it doubles with every
+	// further failure up to retryMax
+	retryBase = time.Second
+	retryMax  = 5 * time.Minute
+	// deliveredRetention is how long delivered rows stay around for inspection
+	deliveredRetention = 24 * time.Hour
+)
+
+// outcome is what became of one attempt at a delivery
+type outcome int
+
+const (
+	outcomeDelivered outcome = iota
+	// outcomePending means the delivery is still to be done, by a retry or by whoever claimed it
+	outcomePending
+	outcomeGivenUp
+)
+
+type OutboxService interface {
+	// Dispatch tries the deliveries a request has just saved; those that fail are
+	// left to the relay
+	Dispatch(reqCtx *model.RequestContext, deliveries []*model.OutboxEvent)
+	// Relay tries every due delivery once and reports how many went through
+	Relay(reqCtx *model.RequestContext) model.Response[int]
+}
+
+type outboxService struct {
+	repo        repo.RepositoryInterface
+	client      *client.Client
+	publisher   events.EventPublisher
+	maxAttempts int
+	now         func() time.Time
+}
+
+func (svc *outboxService) Dispatch(reqCtx *model.RequestContext, deliveries []*model.OutboxEvent) {
+	svc.deliverInOrder(reqCtx, deliveries)
+}
+
+func (svc *outboxService) Relay(reqCtx *model.RequestContext) model.Response[int] {
+	now := svc.now()
+	if purgeResponse := svc.repo.Outbox().PurgeDelivered(reqCtx, now.Add(-deliveredRetention)); !purgeResponse.OK() {
+		logger.Warn(reqCtx, "Failed to purge delivered outbox events", map[string]interface{}{"error": purgeResponse.ErrorString()})
+	}
+	dueResponse := svc.repo.Outbox().ListDue(reqCtx, now, relayBatchSize)
+	if !dueResponse.OK() {
+		return model.ErrorArray[int](dueResponse.Code, dueResponse.Message, dueResponse.Errors)
+	}
+	delivered := svc.deliverInOrder(reqCtx, dueResponse.Data)
+	return model.SuccessResponse(delivered, "Outbox relayed successfully")
+}
+
+// deliverInOrder tries deliveries oldest first. Once one is left pending, the
+// later ones with the same destination and key wait for the next pass, so a
+// conversation's deliveries are not overtaken by its own retries. Pending ones
+// outside the batch, such as a retry still waiting out its delay, hold their
+// lane too; see attempt.
+func (svc *outboxService) deliverInOrder(reqCtx *model.RequestContext, deliveries []*model.OutboxEvent) int {
+	delivered := 0
+	held := map[string]bool{}
+	for _, delivery := range deliveries {
+		lane := delivery.Destination + "/" + delivery.Key
+		if held[lane] {
+			continue
+		}
+		switch svc.attempt(reqCtx, delivery) {
+		case outcomeDelivered:
+			delivered++
+		case outcomePending:
+			held[lane] = true
+		}
+	}
+	return delivered
+}
+
+// attempt claims a delivery, sends it and records how that went. A delivery
+// waits while an older one with the same destination and key is still pending.
+func (svc *outboxService) attempt(reqCtx *model.RequestContext, delivery *model.OutboxEvent) outcome {
+	pendingResponse := svc.repo.Outbox().HasPendingBefore(reqCtx, delivery)
+	if !pendingResponse.OK() {
+		logger.Warn(reqCtx, "Failed to check outbox event order", map[string]interface{}{"outbox_id": delivery.ID, "error": pendingResponse.ErrorString()})
+		return outcomePending
+	}
+	if pendingResponse.Data {
+		// A delivery just saved is leased to its request; hand it to the relay now
+		// rather than when the lease runs out
+		if now := svc.now(); delivery.NextAttemptAt.After(now) {
+			if markResponse := svc.repo.Outbox().MarkRetry(reqCtx, delivery.ID, now, ""); !markResponse.OK() {
+				logger.Warn(reqCtx, "Failed to release outbox event", map[string]interface{}{"outbox_id": delivery.ID})
+			}
+		}
+		return outcomePending
+	}
+
+	claimResponse := svc.repo.Outbox().Claim(reqCtx, delivery, svc.now().Add(model.OutboxLease))
+	if !claimResponse.OK() {
+		logger.Warn(reqCtx, "Failed to claim outbox event", map[string]interface{}{"outbox_id": delivery.ID, "error": claimResponse.ErrorString()})
+		return outcomePending
+	}
+	if !claimResponse.Data {
+		return outcomePending
+	}
+
+	err := svc.deliver(reqCtx, delivery)
+	now := svc.now()
+	fields := map[string]interface{}{
+		"outbox_id":   delivery.ID,
+		"destination": delivery.Destination,
+		"attempts":    delivery.Attempts,
+	}
+	if err == nil {
+		if markResponse := svc.repo.Outbox().MarkDelivered(reqCtx, delivery.ID, now); !markResponse.OK() {
+			// The lease runs out and the delivery is sent again, which receivers tolerate
+			logger.Warn(reqCtx, "Failed to mark outbox event delivered", fields)
+		}
+		return outcomeDelivered
+	}
+
+	if delivery.Attempts >= svc.maxAttempts {
+		logger.Error(reqCtx, "Giving up outbox event", err, fields)
+		if markResponse := svc.repo.Outbox().MarkFailed(reqCtx, delivery.ID, now, err.Error()); !markResponse.OK() {
+			logger.Warn(reqCtx, "Failed to mark outbox event failed", fields)
+		}
+		return outcomeGivenUp
+	}
+	nextAttemptAt := now.Add(retryDelay(delivery.Attempts))
+	fields["error"] = err.Error()
+	fields["next_attempt_at"] = nextAttemptAt
+	logger.Warn(reqCtx, "Outbox event delivery failed", fields)
+	if markResponse := svc.repo.Outbox().MarkRetry(reqCtx, delivery.ID, nextAttemptAt, err.Error()); !markResponse.OK() {
+		logger.Warn(reqCtx, "Failed to reschedule outbox event", fields)
+	}
+	return outcomePending
+}
+
+func (svc *outboxService) deliver(reqCtx *model.RequestContext, delivery *model.OutboxEvent) error {
+	switch delivery.Destination {
+	case model.OutboxDestinationEvents:
+		var event consumer.MessageEvent
+		if err := json.Unmarshal([]byte(delivery.Payload), &event); err != nil {
+			return fmt.Errorf("failed to decode message event: %w", err)
+		}
+		return svc.publisher.PublishMessageEvent(reqCtx.Context(), event)
+	case model.OutboxDestinationSocket:
+		var broadcast model.BroadcastMessage
+		if err := json.Unmarshal([]byte(delivery.Payload), &broadcast); err != nil {
+			return fmt.Errorf("failed to decode broadcast: %w", err)
+		}
+		if svc.client == nil || svc.client.SocketClient == nil {
+			return errors.New("no socket client configured")
+		}
+		return svc.client.SocketClient.Deliver(&broadcast)
+	default:
+		return fmt.Errorf("unknown outbox destination %q", delivery.Destination)
+	}
+}
+
+// retryDelay is the wait after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts && delay < retryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMax)
+}
+
+func NewOutboxService(params *common.Params) OutboxService {
+	return newOutboxService(params, time.Now)
+}
+
+func newOutboxService(params *common.Params, now func() time.Time) *outboxService {
+	publisher := params.Events
+	if publisher == nil {
+		publisher = events.NoopPublisher{}
+	}
+	maxAttempts := config.Config.OutboxMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = config.DefaultOutboxMaxAttempts
+	}
+	return &outboxService{
+		repo:        params.Repo,
+		client:      params.Client,
+		publisher:   publisher,
+		maxAttempts: maxAttempts,
+		now:         now,
+	}
+}
@@ -0,0 +1,12 @@
+package outbox
+
+import (
+	"local/service/common"
+	"time"
+)
+
+// NewTestOutboxServiceWithClock creates an outbox service that reads the time
+// from now, so tests can check when retries are scheduled
+func NewTestOutboxServiceWithClock(params *common.Params, now func() time.Time) OutboxService {
+	return newOutboxService(params, now)
+}
@@ -0,0 +1,29 @@
+package user
+
+import (
+	"local/model"
+	"local/util/logger"
+)
+
+// Block stops blockedID from starting a private conversation or messaging
+// userID, and hides each of them from the other's directory searches
+func (svc *userService) Block(reqCtx *model.RequestContext, userID, blockedID uint) model.Response[*model.UserBlock] {
+	logger.Info(reqCtx, "Block called", map[string]interface{}{"user_id": userID, "blocked_id": blockedID})
+	if userID == blockedID {
+		return model.BadRequest[*model.UserBlock]("You cannot block yourself")
+	}
+	if userResponse := svc.repo.User().QueryOne(reqCtx, &model.User{ID: blockedID}); !userResponse.OK() {
+		return model.ErrorArray[*model.UserBlock](userResponse.Code, userResponse.Message, userResponse.Errors)
+	}
+	return svc.repo.Block().Block(reqCtx, userID, blockedID)
+}
+
+func (svc *userService) Unblock(reqCtx *model.RequestContext, userID, blockedID uint) model.Response[bool] {
+	logger.Info(reqCtx, "Unblock called", map[string]interface{}{"user_id": userID, "blocked_id": blockedID})
+	return svc.repo.Block().Unblock(reqCtx, userID, blockedID)
+}
+
+func (svc *userService) ListBlocks(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.UserBlock] {
+	logger.Info(reqCtx, "ListBlocks called", map[string]interface{}{"user_id": userID})
+	return svc.repo.Block().ListBlocked(reqCtx, userID)
+}
@@ -0,0 +1,115 @@
+package user
+
+import (
+	"fmt"
+	"local/model"
+	"local/util/logger"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ProfileUpdate changes the profile fields that are set and keeps the others
+type ProfileUpdate struct {
+	DisplayName *string
+	Bio         *string
+	// AvatarAttachmentID picks an uploaded avatar; 0 removes the avatar
+	AvatarAttachmentID *uint
+	// Status sets the custom status; an empty text clears it
+	Status *model.UserStatus
+}
+
+func (svc *userService) GetProfile(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserProfile] {
+	logger.Info(reqCtx, "GetProfile called", map[string]interface{}{"user_id": userID})
+	userResponse := svc.repo.User().QueryOne(reqCtx, &model.User{ID: userID})
+	if !userResponse.OK() {
+		return model.ErrorArray[*model.UserProfile](userResponse.Code, userResponse.Message, userResponse.Errors)
+	}
+	return model.SuccessResponse(userResponse.Data.Profile(time.Now()), "Profile retrieved successfully")
+}
+
+// UpdateProfile validates and saves the changed fields, then tells the users who
+// share a conversation with the user so their lists show the new profile
+func (svc *userService) UpdateProfile(reqCtx *model.RequestContext, userID uint, update *ProfileUpdate) model.Response[*model.UserProfile] {
+	logger.Info(reqCtx, "UpdateProfile called", map[string]interface{}{"user_id": userID})
+	now := time.Now()
+	changes, validationError := svc.profileChanges(reqCtx, userID, update, now)
+	if validationError != nil {
+		return model.ErrorResponse[*model.UserProfile](validationError.Code, validationError.Message)
+	}
+	if len(changes) == 0 {
+		return svc.GetProfile(reqCtx, userID)
+	}
+
+	userResponse := svc.repo.User().UpdateProfile(reqCtx, userID, changes)
+	if !userResponse.OK() {
+		return model.ErrorArray[*model.UserProfile](userResponse.Code, userResponse.Message, userResponse.Errors)
+	}
+	profile := userResponse.Data.Profile(now)
+
+	contactsResponse := svc.repo.Participant().GetContactIDs(reqCtx, userID)
+	if !contactsResponse.OK() {
+		logger.Warn(reqCtx, "Failed to load contacts for profile update", map[string]interface{}{"error": contactsResponse.ErrorString()})
+		return model.SuccessResponse(profile, "Profile updated successfully")
+	}
+	svc.broadcast(contactsResponse.Data, "profile_updated", profile)
+
+	return model.SuccessResponse(profile, "Profile updated successfully")
+}
+
+// profileChanges turns an update into the columns to write
+func (svc *userService) profileChanges(reqCtx *model.RequestContext, userID uint, update *ProfileUpdate, now time.Time) (map[string]interface{}, *model.Error) {
+	changes := map[string]interface{}{}
+	if update == nil {
+		return changes, nil
+	}
+
+	if update.DisplayName != nil {
+		displayName := strings.TrimSpace(*update.DisplayName)
+		if utf8.RuneCountInString(displayName) > model.MaxDisplayNameLength {
+			return nil, validationError(fmt.Sprintf("Display name must be at most %d characters", model.MaxDisplayNameLength))
+		}
+		changes["display_name"] = displayName
+	}
+
+	if update.Bio != nil {
+		bio := strings.TrimSpace(*update.Bio)
+		if utf8.RuneCountInString(bio) > model.MaxBioLength {
+			return nil, validationError(fmt.Sprintf("Bio must be at most %d characters", model.MaxBioLength))
+		}
+		changes["bio"] = bio
+	}
+
+	if update.Status != nil {
+		text := strings.TrimSpace(update.Status.Text)
+		if utf8.RuneCountInString(text) > model.MaxStatusTextLength {
+			return nil, validationError(fmt.Sprintf("Status must be at most %d characters", model.MaxStatusTextLength))
+		}
+		expiresAt := update.Status.ExpiresAt
+		if text == "" {
+			expiresAt = nil
+		} else if expiresAt != nil && !expiresAt.After(now) {
+			return nil, validationError("Status expiry must be in the future")
+		}
+		changes["status_text"] = text
+		changes["status_expires_at"] = expiresAt
+	}
+
+	if update.AvatarAttachmentID != nil {
+		if *update.AvatarAttachmentID == 0 {
+			changes["avatar_attachment_id"] = nil
+		} else {
+			attachmentResponse := svc.repo.Attachment().GetByID(reqCtx, *update.AvatarAttachmentID)
+			// Only the user's own avatar uploads qualify; conversation files stay private to their members
+			if !attachmentResponse.OK() || !attachmentResponse.Data.IsAvatar() || attachmentResponse.Data.UploaderID != userID {
+				return nil, validationError("Avatar not found")
+			}
+			changes["avatar_attachment_id"] = *update.AvatarAttachmentID
+		}
+	}
+	return changes, nil
+}
+
+func validationError(message string) *model.Error {
+	return &model.Error{Code: model.CodeValidation, Message: message}
+}
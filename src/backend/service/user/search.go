@@ -0,0 +1,71 @@
+package user
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"local/model"
+	"local/util/logger"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SearchUsers pages through the user directory, best matches first. Results
+// are public profiles only; deactivated users and users blocked either way are left out
+func (svc *userService) SearchUsers(reqCtx *model.RequestContext, userID uint, params model.UserSearchParams) model.Response[*model.UserSearchPage] {
+	logger.Info(reqCtx, "SearchUsers called", map[string]interface{}{"user_id": userID, "query": params.Query})
+	query := strings.TrimSpace(params.Query)
+	if utf8.RuneCountInString(query) > model.MaxUserSearchLength {
+		return model.ValidationError[*model.UserSearchPage](fmt.Sprintf("Search query must be at most %d characters", model.MaxUserSearchLength))
+	}
+	var after *model.UserSearchCursor
+	if params.Cursor != "" {
+		cursor, err := decodeSearchCursor(params.Cursor)
+		if err != nil {
+			return model.ValidationError[*model.UserSearchPage]("Invalid cursor")
+		}
+		after = cursor
+	}
+	limit := model.CursorParams{Limit: params.Limit}.Normalize().Limit
+
+	// One extra row tells whether another page follows
+	matchesResponse := svc.repo.User().Search(reqCtx, userID, query, after, limit+1)
+	if !matchesResponse.OK() {
+		return model.ErrorArray[*model.UserSearchPage](matchesResponse.Code, matchesResponse.Message, matchesResponse.Errors)
+	}
+	matches := matchesResponse.Data
+
+	page := &model.UserSearchPage{Items: make([]*model.UserProfile, 0, len(matches))}
+	if len(matches) > limit {
+		matches = matches[:limit]
+		page.HasMore = true
+	}
+	now := time.Now()
+	for _, match := range matches {
+		page.Items = append(page.Items, match.User.Profile(now))
+	}
+	if page.HasMore {
+		last := matches[len(matches)-1]
+		page.NextCursor = encodeSearchCursor(&model.UserSearchCursor{Rank: last.Rank, UserName: last.User.UserName})
+	}
+	return model.SuccessResponse(page, "Users retrieved successfully")
+}
+
+// encodeSearchCursor keeps the cursor opaque so clients only pass it back
+func encodeSearchCursor(cursor *model.UserSearchCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSearchCursor(value string) (*model.UserSearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor model.UserSearchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,113 @@
+package user
+
+import (
+	"local/client"
+	"local/infra/repo"
+	"local/model"
+	"local/service/common"
+	"local/util/logger"
+	"time"
+)
+
+// MaxPresenceIDs caps how many users a single presence lookup may ask about
+const MaxPresenceIDs = 100
+
+type UserService interface {
+	SetPresence(reqCtx *model.RequestContext, userID uint, online bool) model.Response[*model.UserPresence]
+	// ResetPresence marks every user offline, for when the socket service starts with no sockets
+	ResetPresence(reqCtx *model.RequestContext) model.Response[int64]
+	GetPresence(reqCtx *model.RequestContext, userIDs []uint) model.Response[[]*model.UserPresence]
+	GetProfile(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserProfile]
+	UpdateProfile(reqCtx *model.RequestContext, userID uint, update *ProfileUpdate) model.Response[*model.UserProfile]
+	SearchUsers(reqCtx *model.RequestContext, userID uint, params model.UserSearchParams) model.Response[*model.UserSearchPage]
+	Block(reqCtx *model.RequestContext, userID, blockedID uint) model.Response[*model.UserBlock]
+	Unblock(reqCtx *model.RequestContext, userID, blockedID uint) model.Response[bool]
+	ListBlocks(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.UserBlock]
+}
+
+type userService struct {
+	repo   repo.RepositoryInterface
+	client *client.Client
+}
+
+// SetPresence records a user's online state and last-seen time, then tells the
+// users who share a conversation with them.
+func (svc *userService) SetPresence(reqCtx *model.RequestContext, userID uint, online bool) model.Response[*model.UserPresence] {
+	logger.Info(reqCtx, "SetPresence called", map[string]interface{}{"user_id": userID, "online": online})
+	userResponse := svc.repo.User().SetPresence(reqCtx, userID, online, time.Now())
+	if !userResponse.OK() {
+		return model.ErrorArray[*model.UserPresence](userResponse.Code, userResponse.Message, userResponse.Errors)
+	}
+	presence := toPresence(userResponse.Data)
+
+	contactsResponse := svc.repo.Participant().GetContactIDs(reqCtx, userID)
+	if !contactsResponse.OK() {
+		logger.Warn(reqCtx, "Failed to load contacts for presence", map[string]interface{}{"error": contactsResponse.ErrorString()})
+		return model.SuccessResponse(presence, "Presence updated successfully")
+	}
+
+	event := "presence_offline"
+	if online {
+		event = "presence_online"
+	}
+	svc.broadcast(contactsResponse.Data, event, presence)
+
+	return model.SuccessResponse(presence, "Presence updated successfully")
+}
+
+func (svc *userService) ResetPresence(reqCtx *model.RequestContext) model.Response[int64] {
+	logger.Info(reqCtx, "ResetPresence called")
+	return svc.repo.User().ClearPresence(reqCtx, time.Now())
+}
+
+func (svc *userService) GetPresence(reqCtx *model.RequestContext, userIDs []uint) model.Response[[]*model.UserPresence] {
+	logger.Info(reqCtx, "GetPresence called", map[string]interface{}{"user_ids": userIDs})
+	if len(userIDs) == 0 {
+		return model.BadRequest[[]*model.UserPresence]("At least 1 user ID is required")
+	}
+	if len(userIDs) > MaxPresenceIDs {
+		return model.BadRequest[[]*model.UserPresence]("Too many user IDs")
+	}
+
+	usersResponse := svc.repo.User().GetByIDs(reqCtx, userIDs)
+	if !usersResponse.OK() {
+		return model.ErrorArray[[]*model.UserPresence](usersResponse.Code, usersResponse.Message, usersResponse.Errors)
+	}
+
+	presences := make([]*model.UserPresence, 0, len(usersResponse.Data))
+	for _, user := range usersResponse.Data {
+		presences = append(presences, toPresence(user))
+	}
+	return model.SuccessResponse(presences, "Presence retrieved successfully")
+}
+
+func (svc *userService) broadcast(userIDs []uint, event string, payload interface{}) {
+	if svc.client == nil || svc.client.SocketClient == nil || len(userIDs) == 0 {
+		return
+	}
+
+	userIds := make([]int, 0, len(userIDs))
+	for _, id := range userIDs {
+		userIds = append(userIds, int(id))
+	}
+	svc.client.SocketClient.Broadcast(&model.BroadcastMessage{
+		UserIds: userIds,
+		Event:   event,
+		Payload: payload,
+	})
+}
+
+func toPresence(user *model.User) *model.UserPresence {
+	return &model.UserPresence{
+		UserID:     user.ID,
+		Online:     user.Online,
+		LastSeenAt: user.LastSeenAt,
+	}
+}
+
+func NewUserService(params *common.Params) UserService {
+	return &userService{
+		repo:   params.Repo,
+		client: params.Client,
+	}
+}
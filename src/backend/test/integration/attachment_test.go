@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"bytes"
+	"local/model"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01")
+
+func uploadAttachment(setup *TestSetup, token string, conversationID uint, fileName string, content []byte) *httptest.ResponseRecorder {
+	path := "/api/v1/conversations/" + strconv.Itoa(int(conversationID)) + "/attachments"
+	return uploadFile(setup, token, path, fileName, content)
+}
+
+// uploadFile posts content as the file field of a multipart form
+func uploadFile(setup *TestSetup, token, path, fileName string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", fileName)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	setup.Router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAttachmentFlow(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+	outsiderToken := registerAndLogin(t, setup, "outsider", "password123")
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	messagesPath := "/api/v1/conversations/" + strconv.Itoa(int(conv.ID)) + "/messages"
+
+	recorder := uploadAttachment(setup, user1Token, conv.ID, "dot.png", testPNG)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	uploaded := parseResponse[*model.Attachment](t, recorder).Data
+	assert.Equal(t, "image/png", uploaded.ContentType)
+	assert.Equal(t, "/api/v1/attachments/"+strconv.Itoa(int(uploaded.ID)), uploaded.URL)
+
+	t.Run("unsent uploads are only visible to the uploader", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodGet, uploaded.URL, user2Token, nil)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("attachment message embeds the file", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPost, messagesPath, user1Token, map[string]interface{}{
+			"content":       "look",
+			"session_id":    "test-session",
+			"message_type":  model.MessageTypeAttachment,
+			"attachment_id": uploaded.ID,
+		})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		messages := getMessages(t, setup, user2Token, conv.ID)
+		assert.Equal(t, model.MessageTypeAttachment, messages[0].MessageType)
+		assert.NotNil(t, messages[0].Attachment)
+		assert.Equal(t, uploaded.ID, messages[0].Attachment.ID)
+	})
+
+	t.Run("members download, outsiders are forbidden", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodGet, uploaded.URL, user2Token, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, testPNG, recorder.Body.Bytes())
+		assert.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+		assert.Contains(t, recorder.Header().Get("Content-Disposition"), "dot.png")
+
+		recorder = makeRequest(setup, http.MethodGet, uploaded.URL, outsiderToken, nil)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("an attachment can only be sent once", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPost, messagesPath, user1Token, map[string]interface{}{
+			"session_id":    "test-session",
+			"message_type":  model.MessageTypeAttachment,
+			"attachment_id": uploaded.ID,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("validates type and size", func(t *testing.T) {
+		recorder := uploadAttachment(setup, user1Token, conv.ID, "script.png", []byte("<html><script>alert(1)</script></html>"))
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+		recorder = uploadAttachment(setup, user1Token, conv.ID, "big.txt", []byte(strings.Repeat("a", 65<<10)))
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("deleting the message hides the file", func(t *testing.T) {
+		messages := getMessages(t, setup, user1Token, conv.ID)
+		recorder := makeRequest(setup, http.MethodDelete, messagePath(conv.ID, messages[0].ID), user1Token, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = makeRequest(setup, http.MethodGet, uploaded.URL, user2Token, nil)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		messages = getMessages(t, setup, user2Token, conv.ID)
+		assert.Nil(t, messages[0].Attachment)
+	})
+}
@@ -1,10 +1,16 @@
 package integration
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
+	"local/infra/provider/signing"
 	"local/model"
 	"net/http"
 	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -60,7 +66,93 @@ func TestAuthFlow_DuplicateRegistration(t *testing.T) {
 	assert.Equal(t, model.CodeConflict, response.Code)
 }
 
+func TestAuthFlow_Logout(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	phoneToken := loginUser(t, setup, "testuser", "password123")
+	laptopToken := loginUser(t, setup, "testuser", "password123")
+
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/logout", phoneToken, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The logged out token is rejected from now on
+	recorder = makeRequest(setup, http.MethodGet, "/api/v1/me", phoneToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Token has been revoked")
+
+	// Other sessions are untouched
+	getUserID(t, setup, laptopToken)
+
+	var revoked []model.RevokedSession
+	assert.NoError(t, setup.DB.Find(&revoked).Error)
+	assert.Len(t, revoked, 1)
+}
+
+func TestAuthFlow_LogoutAll(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	phoneToken := loginUser(t, setup, "testuser", "password123")
+	laptopToken := loginUser(t, setup, "testuser", "password123")
+	otherToken := registerAndLogin(t, setup, "otheruser", "password123")
+
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/logout/all", phoneToken, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	for _, token := range []string{phoneToken, laptopToken} {
+		recorder = makeRequest(setup, http.MethodGet, "/api/v1/me", token, nil)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	}
+
+	// Other users keep their sessions and logging in again works
+	getUserID(t, setup, otherToken)
+	time.Sleep(2 * time.Millisecond)
+	freshToken := loginUser(t, setup, "testuser", "password123")
+	getUserID(t, setup, freshToken)
+
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/logout/all", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
 // Auth helpers - Used across multiple test files
+func TestAuthFlow_JWKS(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	recorder := makeRequest(setup, http.MethodGet, "/.well-known/jwks.json", "", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Cache-Control"), "max-age")
+	var jwks signing.JWKS
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &jwks))
+	assert.Len(t, jwks.Keys, 1)
+
+	// A verifier holding only the published key accepts our tokens
+	token := registerAndLogin(t, setup, "testuser", "password123")
+	published := jwks.Keys[0]
+	x, err := base64.RawURLEncoding.DecodeString(published.X)
+	assert.NoError(t, err)
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, published.Kid, token.Header["kid"])
+		return ed25519.PublicKey(x), nil
+	}, jwt.WithValidMethods([]string{published.Alg}))
+	assert.NoError(t, err)
+	assert.True(t, parsed.Valid)
+
+	// A token signed by any other key is refused
+	otherKeys, err := signing.Generate()
+	assert.NoError(t, err)
+	forged, err := otherKeys.Sign(jwt.MapClaims{"user_id": 1, "session_id": "forged", "exp": time.Now().Add(time.Hour).Unix()})
+	assert.NoError(t, err)
+	recorder = makeRequest(setup, http.MethodGet, "/api/v1/me", forged, nil)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
 func registerUser(t *testing.T, setup *TestSetup, username, password string) {
 	body := map[string]string{"username": username, "password": password}
 	recorder := makeRequest(setup, http.MethodPost, "/api/v1/register", "", body)
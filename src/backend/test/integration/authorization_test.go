@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Every conversation-scoped route must reject callers who are not participants.
+// New message or conversation routes (edit, delete, ...) belong in this table.
+func TestAuthorization_NonMemberForbidden(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+	outsiderToken := registerAndLogin(t, setup, "outsider", "password123")
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	msg := createMessage(t, setup, user1Token, conv.ID, "private", "test-session")
+	conversationPath := "/api/v1/conversations/" + strconv.Itoa(int(conv.ID))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   map[string]interface{}
+	}{
+		{"read messages", http.MethodGet, conversationPath + "/messages", nil},
+		{"write message", http.MethodPost, conversationPath + "/messages", map[string]interface{}{"content": "hi", "session_id": "intruder"}},
+		{"list participants", http.MethodGet, conversationPath + "/participants", nil},
+		{"add participants", http.MethodPost, conversationPath + "/participants", map[string]interface{}{"user_ids": []uint{user2ID}}},
+		{"remove participant", http.MethodDelete, conversationPath + "/participants/" + strconv.Itoa(int(user2ID)), nil},
+		{"promote participant", http.MethodPost, conversationPath + "/participants/" + strconv.Itoa(int(user2ID)) + "/promote", nil},
+		{"leave conversation", http.MethodDelete, conversationPath + "/participants/me", nil},
+		{"edit message", http.MethodPatch, conversationPath + "/messages/" + strconv.Itoa(int(msg.ID)), map[string]interface{}{"content": "defaced"}},
+		{"read thread", http.MethodGet, "/api/v1/messages/" + strconv.Itoa(int(msg.ID)) + "/thread", nil},
+		{"add reaction", http.MethodPost, "/api/v1/messages/" + strconv.Itoa(int(msg.ID)) + "/reactions", map[string]interface{}{"emoji": "👍"}},
+		{"remove reaction", http.MethodDelete, "/api/v1/messages/" + strconv.Itoa(int(msg.ID)) + "/reactions?emoji=%F0%9F%91%8D", nil},
+		{"delete message", http.MethodDelete, conversationPath + "/messages/" + strconv.Itoa(int(msg.ID)), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := makeRequest(setup, tt.method, tt.path, outsiderToken, tt.body)
+			assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+			response := parseResponse[any](t, recorder)
+			assert.Equal(t, model.CodeForbidden, response.Code)
+		})
+	}
+
+	// Uploads are multipart, so they are checked outside the JSON table
+	recorder := uploadAttachment(setup, outsiderToken, conv.ID, "dot.png", testPNG)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// The outsider's write attempt must not have reached the conversation
+	messages := getMessages(t, setup, user1Token, conv.ID)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "private", messages[0].Content)
+	assert.Nil(t, messages[0].DeletedAt)
+}
+
+func TestAuthorization_FormerMemberLosesAccess(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	ownerToken := registerAndLogin(t, setup, "owner", "password123")
+	memberToken := registerAndLogin(t, setup, "member", "password123")
+	memberID := getUserID(t, setup, memberToken)
+	otherToken := registerAndLogin(t, setup, "other", "password123")
+	otherID := getUserID(t, setup, otherToken)
+
+	group := createGroupConversation(t, setup, ownerToken, "team", []uint{memberID, otherID})
+	createMessage(t, setup, memberToken, group.ID, "before leaving", "test-session")
+
+	recorder := makeRequest(setup, http.MethodDelete, participantsPath(group.ID)+"/me", memberToken, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	path := "/api/v1/conversations/" + strconv.Itoa(int(group.ID)) + "/messages"
+	recorder = makeRequest(setup, http.MethodGet, path, memberToken, nil)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodPost, path, memberToken, map[string]interface{}{"content": "after leaving", "session_id": "test-session"})
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"fmt"
+	"local/model"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func blockUser(t *testing.T, setup *TestSetup, token string, userID uint) {
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/me/blocks", token, map[string]interface{}{"user_id": userID})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestBlockFlow(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	aliceToken := registerAndLogin(t, setup, "alice", "password123")
+	bobToken := registerAndLogin(t, setup, "bob", "password123")
+	aliceID := getUserID(t, setup, aliceToken)
+	bobID := getUserID(t, setup, bobToken)
+	conversation := createConversation(t, setup, aliceToken, bobID)
+	messagesPath := fmt.Sprintf("/api/v1/conversations/%d/messages", conversation.ID)
+
+	blockUser(t, setup, aliceToken, bobID)
+	// Blocking twice keeps the block
+	blockUser(t, setup, aliceToken, bobID)
+
+	t.Run("lists the block", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodGet, "/api/v1/me/blocks", aliceToken, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		blocks := parseResponse[[]*model.UserBlock](t, recorder).Data
+		assert.Len(t, blocks, 1)
+		assert.Equal(t, bobID, blocks[0].BlockedID)
+	})
+
+	t.Run("neither side can message the other", func(t *testing.T) {
+		for _, token := range []string{aliceToken, bobToken} {
+			recorder := makeRequest(setup, http.MethodPost, messagesPath, token, map[string]interface{}{"content": "hi"})
+			assert.Equal(t, http.StatusForbidden, recorder.Code)
+		}
+	})
+
+	t.Run("no new private conversation", func(t *testing.T) {
+		setup.DB.Where("conversation_id = ?", conversation.ID).Delete(&model.ConversationParticipant{})
+		setup.DB.Delete(&model.Conversation{}, conversation.ID)
+		recorder := makeRequest(setup, http.MethodPost, "/api/v1/conversations/", bobToken, map[string]interface{}{"user_id": aliceID})
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("hidden from the directory both ways", func(t *testing.T) {
+		assert.Empty(t, searchUsers(t, setup, aliceToken, url.Values{"q": {"bob"}}).Items)
+		assert.Empty(t, searchUsers(t, setup, bobToken, url.Values{"q": {"alice"}}).Items)
+	})
+
+	t.Run("invalid blocks", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPost, "/api/v1/me/blocks", aliceToken, map[string]interface{}{"user_id": aliceID})
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		recorder = makeRequest(setup, http.MethodPost, "/api/v1/me/blocks", aliceToken, map[string]interface{}{"user_id": 999})
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("unblocking restores access", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodDelete, fmt.Sprintf("/api/v1/me/blocks/%d", bobID), aliceToken, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		recorder = makeRequest(setup, http.MethodDelete, fmt.Sprintf("/api/v1/me/blocks/%d", bobID), aliceToken, nil)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+		assert.Equal(t, []string{"bob"}, usernames(searchUsers(t, setup, aliceToken, url.Values{"q": {"bob"}})))
+		reopened := createConversation(t, setup, bobToken, aliceID)
+		createMessage(t, setup, aliceToken, reopened.ID, "hi again", "")
+	})
+}
+
+func TestMuteFlow(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	aliceToken := registerAndLogin(t, setup, "alice", "password123")
+	bobToken := registerAndLogin(t, setup, "bob", "password123")
+	conversation := createConversation(t, setup, aliceToken, getUserID(t, setup, bobToken))
+	mutePath := fmt.Sprintf("/api/v1/conversations/%d/mute", conversation.ID)
+
+	t.Run("mutes until a time", func(t *testing.T) {
+		until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		recorder := makeRequest(setup, http.MethodPut, mutePath, aliceToken, map[string]interface{}{"until": until})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		participant := parseResponse[*model.ConversationParticipant](t, recorder).Data
+		assert.True(t, until.Equal(*participant.MutedUntil))
+	})
+
+	t.Run("mutes indefinitely without a body", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPut, mutePath, aliceToken, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, parseResponse[*model.ConversationParticipant](t, recorder).Data.IsMuted(time.Now().AddDate(100, 0, 0)))
+	})
+
+	t.Run("muted conversations still receive messages", func(t *testing.T) {
+		createMessage(t, setup, bobToken, conversation.ID, "still delivered", "")
+		assert.Len(t, getMessages(t, setup, aliceToken, conversation.ID), 1)
+	})
+
+	t.Run("rejects an end in the past", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPut, mutePath, aliceToken, map[string]interface{}{"until": time.Now().Add(-time.Hour)})
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("unmutes", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodDelete, mutePath, aliceToken, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Nil(t, parseResponse[*model.ConversationParticipant](t, recorder).Data.MutedUntil)
+	})
+
+	t.Run("only members can mute", func(t *testing.T) {
+		carolToken := registerAndLogin(t, setup, "carol", "password123")
+		recorder := makeRequest(setup, http.MethodPut, mutePath, carolToken, nil)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+}
@@ -1,6 +1,8 @@
 package integration
 
 import (
+	"errors"
+	"local/infra/repo"
 	"local/model"
 	"net/http"
 	"strconv"
@@ -34,9 +36,9 @@ func getUserConversations(t *testing.T, setup *TestSetup, token string) []*model
 	recorder := makeRequest(setup, http.MethodGet, "/api/v1/conversations/", token, nil)
 	assert.Equal(t, http.StatusOK, recorder.Code)
 
-	response := parseResponse[[]*model.Conversation](t, recorder)
+	response := parseResponse[*model.Page[*model.Conversation]](t, recorder)
 	assert.True(t, response.OK())
-	return response.Data
+	return response.Data.Items
 }
 
 func TestConversationFlow_CreateAndGet(t *testing.T) {
@@ -72,3 +74,114 @@ func TestConversationFlow_GetUserConversations(t *testing.T) {
 	conversations := getUserConversations(t, setup, user1Token)
 	assert.GreaterOrEqual(t, len(conversations), 1)
 }
+
+func TestConversationFlow_CursorPagination(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user3Token := registerAndLogin(t, setup, "user3", "password123")
+	user4Token := registerAndLogin(t, setup, "user4", "password123")
+
+	conv2 := createConversation(t, setup, user1Token, getUserID(t, setup, user2Token))
+	conv3 := createConversation(t, setup, user1Token, getUserID(t, setup, user3Token))
+	conv4 := createConversation(t, setup, user1Token, getUserID(t, setup, user4Token))
+
+	// Activity bumps the oldest conversation to the top
+	createMessage(t, setup, user1Token, conv2.ID, "hello", "test-session")
+
+	recorder := makeRequest(setup, http.MethodGet, "/api/v1/conversations/?limit=2", user1Token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	first := parseResponse[*model.Page[*model.Conversation]](t, recorder).Data
+	assert.Len(t, first.Items, 2)
+	assert.True(t, first.HasMore)
+	assert.Equal(t, conv2.ID, first.Items[0].ID)
+	assert.Equal(t, conv4.ID, first.Items[1].ID)
+	assert.Equal(t, conv4.ID, first.NextCursor)
+
+	recorder = makeRequest(setup, http.MethodGet, "/api/v1/conversations/?limit=2&before_id="+strconv.Itoa(int(first.NextCursor)), user1Token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	second := parseResponse[*model.Page[*model.Conversation]](t, recorder).Data
+	assert.Len(t, second.Items, 1)
+	assert.False(t, second.HasMore)
+	assert.Equal(t, conv3.ID, second.Items[0].ID)
+}
+
+func TestConversationFlow_PrivateConversationIsUniquePerPair(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user1ID := getUserID(t, setup, user1Token)
+	user2ID := getUserID(t, setup, user2Token)
+
+	// Starting it again, from either side, returns the same conversation
+	conv := createConversation(t, setup, user1Token, user2ID)
+	again := createConversation(t, setup, user2Token, user1ID)
+	assert.Equal(t, conv.ID, again.ID)
+	assert.Len(t, again.Participants, 2)
+
+	var count int64
+	assert.NoError(t, setup.DB.Model(&model.Conversation{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// Group conversations have no pair, so any number of them coexist
+	for _, name := range []string{"one", "two"} {
+		recorder := makeRequest(setup, http.MethodPost, "/api/v1/conversations/group", user1Token, map[string]interface{}{"name": name, "user_ids": []uint{user2ID}})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Nil(t, parseResponse[*model.Conversation](t, recorder).Data.EntityJoined)
+	}
+
+	t.Run("the unique index keeps out a second conversation for the pair", func(t *testing.T) {
+		entityJoined := *conv.EntityJoined
+		duplicate := &model.Conversation{Type: model.ConversationTypePrivate, EntityJoined: &entityJoined}
+		assert.Error(t, setup.DB.Create(duplicate).Error)
+
+		reqCtx := &model.RequestContext{}
+		created := setup.Repo.Conversation().GetOrCreatePrivate(reqCtx, duplicate)
+		assert.True(t, created.OK())
+		assert.False(t, created.Data)
+		assert.Equal(t, conv.ID, duplicate.ID)
+	})
+}
+
+func TestRepository_WithTx(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+	reqCtx := &model.RequestContext{}
+	countConversations := func() int64 {
+		var count int64
+		assert.NoError(t, setup.DB.Model(&model.Conversation{}).Count(&count).Error)
+		return count
+	}
+
+	t.Run("a failed unit of work leaves nothing behind", func(t *testing.T) {
+		err := setup.Repo.WithTx(reqCtx, func(tx repo.RepositoryInterface) error {
+			createResponse := tx.Conversation().Create(reqCtx, &model.Conversation{Type: model.ConversationTypeGroup, Name: "half"})
+			assert.True(t, createResponse.OK())
+			return errors.New("participant missing")
+		})
+		assert.EqualError(t, err, "participant missing")
+		assert.Equal(t, int64(0), countConversations())
+
+		response := repo.InTx(reqCtx, setup.Repo, func(tx repo.RepositoryInterface) model.Response[*model.Conversation] {
+			tx.Conversation().Create(reqCtx, &model.Conversation{Type: model.ConversationTypeGroup, Name: "half"})
+			return model.BadRequest[*model.Conversation]("Failed to add participant to conversation")
+		})
+		assert.Equal(t, model.CodeBadRequest, response.Code)
+		assert.Equal(t, int64(0), countConversations())
+	})
+
+	t.Run("a successful unit of work is committed", func(t *testing.T) {
+		response := repo.InTx(reqCtx, setup.Repo, func(tx repo.RepositoryInterface) model.Response[*model.Conversation] {
+			return tx.Conversation().Create(reqCtx, &model.Conversation{Type: model.ConversationTypeGroup, Name: "whole"})
+		})
+		assert.True(t, response.OK())
+		assert.Equal(t, int64(1), countConversations())
+	})
+}
@@ -7,13 +7,17 @@ import (
 	"local/client"
 	"local/config"
 	"local/endpoint"
+	"local/infra/provider/blob"
+	"local/infra/provider/signing"
 	"local/infra/repo"
 	"local/model"
 	"local/service/common"
 	"local/service/initial"
 	httpTransport "local/transport/http"
 	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -27,6 +31,7 @@ type TestSetup struct {
 	Repo      *repo.Repository
 	Endpoints *endpoint.Endpoints
 	Router    *gin.Engine
+	BlobDir   string
 }
 
 // SetupTestDB creates an in-memory SQLite database for testing
@@ -42,6 +47,20 @@ func SetupTestDB() (*gorm.DB, error) {
 		&model.Conversation{},
 		&model.ConversationParticipant{},
 		&model.Message{},
+		&model.MessageEdit{},
+		&model.MessageReaction{},
+		&model.Attachment{},
+		&model.RevokedSession{},
+		&model.UserTokenCutoff{},
+		&model.Session{},
+		&model.RotatedRefreshToken{},
+		&model.LoginAttempt{},
+		&model.UserMFA{},
+		&model.MFARecoveryCode{},
+		&model.UserIdentity{},
+		&model.OIDCLoginState{},
+		&model.UserBlock{},
+		&model.OutboxEvent{},
 	)
 	if err != nil {
 		return nil, err
@@ -52,6 +71,12 @@ func SetupTestDB() (*gorm.DB, error) {
 
 // SetupTestEnvironment creates a complete test environment
 func SetupTestEnvironment() (*TestSetup, error) {
+	return SetupTestEnvironmentWith(nil)
+}
+
+// SetupTestEnvironmentWith is SetupTestEnvironment with a chance to change the
+// service parameters, such as adding identity providers, before services are built
+func SetupTestEnvironmentWith(configure func(params *common.Params)) (*TestSetup, error) {
 	// Setup test database
 	db, err := SetupTestDB()
 	if err != nil {
@@ -65,15 +90,37 @@ func SetupTestEnvironment() (*TestSetup, error) {
 	}
 
 	// Setup config for testing
-	config.Config.JwtSecret = "test-jwt-secret-key-for-integration-tests"
+	config.Config.AccessTokenTTL = config.DefaultAccessTokenTTL
+	config.Config.RefreshTokenTTL = config.DefaultRefreshTokenTTL
+	config.Config.MFAChallengeTTL = config.DefaultMFAChallengeTTL
+	config.Config.MFAIssuer = "Simple Chat"
+	config.Config.OIDCStateTTL = config.DefaultOIDCStateTTL
+	config.Config.InternalServiceKey = testServiceKey
 
 	// Setup rate limiting config for tests (with permissive defaults)
-	// Individual tests can override these values
-	if config.Config.RateLimitRequestsPerMin == 0 {
-		config.Config.RateLimitRequestsPerMin = 1000 // High default to not interfere with tests
+	// Individual tests can override these values after setup; resetting here keeps
+	// a low limit from one test from leaking into the next
+	config.Config.RateLimitRequestsPerMin = 1000 // High default to not interfere with tests
+	config.Config.RateLimitBurst = 100           // High default to not interfere with tests
+	config.Config.TrustedProxies = nil
+
+	// Login lockout uses the shipped defaults, which the lockout tests rely on
+	config.Config.LoginAccountMaxFailures = 5
+	config.Config.LoginIPMaxFailures = 20
+	config.Config.LoginLockoutBase = 30 * time.Second
+	config.Config.LoginLockoutMax = time.Hour
+	config.Config.PasswordMinLength = 8
+
+	// Attachments go to a throwaway directory with a small limit
+	config.Config.AttachmentMaxBytes = 64 << 10
+	config.Config.AttachmentAllowedTypes = []string{"image/png", "image/jpeg", "application/pdf", "text/plain"}
+	blobDir, err := os.MkdirTemp("", "simple-chat-blobs-")
+	if err != nil {
+		return nil, err
 	}
-	if config.Config.RateLimitBurst == 0 {
-		config.Config.RateLimitBurst = 100 // High default to not interfere with tests
+	blobStore, err := blob.NewLocalStore(blobDir)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create services
@@ -81,12 +128,23 @@ func SetupTestEnvironment() (*TestSetup, error) {
 		ServiceName: "test-service",
 		Ctx:         context.Background(),
 	}
-	clt := client.NewClient(initParams)
+	// A fresh key per environment; tokens only need to verify within the test
+	keys, err := signing.Generate()
+	if err != nil {
+		return nil, err
+	}
+	clt := client.NewClient(initParams, keys)
 
-	svc := initial.NewService(&common.Params{
+	params := &common.Params{
 		Repo:   testRepo,
 		Client: clt,
-	})
+		Blob:   blobStore,
+		Keys:   keys,
+	}
+	if configure != nil {
+		configure(params)
+	}
+	svc := initial.NewService(params)
 
 	// Create endpoints
 	endpoints := endpoint.NewEndpoints(&svc)
@@ -99,6 +157,7 @@ func SetupTestEnvironment() (*TestSetup, error) {
 		Repo:      testRepo,
 		Endpoints: endpoints,
 		Router:    router,
+		BlobDir:   blobDir,
 	}, nil
 }
 
@@ -114,6 +173,9 @@ func (ts *TestSetup) CleanupTestEnvironment() error {
 			ts.DB.Exec("DELETE FROM " + table)
 		}
 	}
+	if ts.BlobDir != "" {
+		os.RemoveAll(ts.BlobDir)
+	}
 	return nil
 }
 
@@ -135,6 +197,27 @@ func makeRequest(setup *TestSetup, method, path, token string, body interface{})
 	return recorder
 }
 
+// testServiceKey is the internal service key the test environment accepts
+const testServiceKey = "test-service-key"
+
+// makeServiceRequest calls an internal endpoint the way the socket service does
+func makeServiceRequest(setup *TestSetup, method, path, serviceKey string, body interface{}) *httptest.ResponseRecorder {
+	var bodyBytes []byte
+	if body != nil {
+		bodyBytes, _ = json.Marshal(body)
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	if serviceKey != "" {
+		req.Header.Set("X-Service-Key", serviceKey)
+	}
+
+	recorder := httptest.NewRecorder()
+	setup.Router.ServeHTTP(recorder, req)
+	return recorder
+}
+
 func parseResponse[T any](t *testing.T, recorder *httptest.ResponseRecorder) model.Response[T] {
 	var response model.Response[T]
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func messagePath(conversationID, messageID uint) string {
+	return "/api/v1/conversations/" + strconv.Itoa(int(conversationID)) + "/messages/" + strconv.Itoa(int(messageID))
+}
+
+func TestMessageFlow_EditMessage(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	msg := createMessage(t, setup, user1Token, conv.ID, "helo", "test-session")
+
+	t.Run("sender edits and history is kept", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, messagePath(conv.ID, msg.ID), user1Token, map[string]interface{}{"content": "hello"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		response := parseResponse[*model.Message](t, recorder)
+		assert.Equal(t, "hello", response.Data.Content)
+		assert.NotNil(t, response.Data.EditedAt)
+
+		var edits []model.MessageEdit
+		assert.NoError(t, setup.DB.Where("message_id = ?", msg.ID).Find(&edits).Error)
+		assert.Len(t, edits, 1)
+		assert.Equal(t, "helo", edits[0].PreviousContent)
+
+		messages := getMessages(t, setup, user2Token, conv.ID)
+		assert.Equal(t, "hello", messages[0].Content)
+		assert.NotNil(t, messages[0].EditedAt)
+	})
+
+	t.Run("other participant cannot edit", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, messagePath(conv.ID, msg.ID), user2Token, map[string]interface{}{"content": "hijacked"})
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("empty content is rejected", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, messagePath(conv.ID, msg.ID), user1Token, map[string]interface{}{"content": ""})
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("unknown message is not found", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, messagePath(conv.ID, 9999), user1Token, map[string]interface{}{"content": "ghost"})
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestMessageFlow_DeleteMessage(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	msg := createMessage(t, setup, user1Token, conv.ID, "secret", "test-session")
+	createMessage(t, setup, user2Token, conv.ID, "reply", "test-session")
+
+	recorder := makeRequest(setup, http.MethodDelete, messagePath(conv.ID, msg.ID), user2Token, nil)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodDelete, messagePath(conv.ID, msg.ID), user1Token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The deleted message stays in the listing as a tombstone without content
+	messages := getMessages(t, setup, user2Token, conv.ID)
+	assert.Len(t, messages, 2)
+	tombstone := messages[1]
+	assert.Equal(t, msg.ID, tombstone.ID)
+	assert.Empty(t, tombstone.Content)
+	assert.NotNil(t, tombstone.DeletedAt)
+
+	// Tombstones can be neither edited nor deleted again
+	recorder = makeRequest(setup, http.MethodPatch, messagePath(conv.ID, msg.ID), user1Token, map[string]interface{}{"content": "undo"})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = makeRequest(setup, http.MethodDelete, messagePath(conv.ID, msg.ID), user1Token, nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -25,11 +25,18 @@ func createMessage(t *testing.T, setup *TestSetup, token string, conversationID
 }
 
 func getMessages(t *testing.T, setup *TestSetup, token string, conversationID uint) []*model.Message {
+	return getMessagesPage(t, setup, token, conversationID, "").Items
+}
+
+func getMessagesPage(t *testing.T, setup *TestSetup, token string, conversationID uint, query string) *model.Page[*model.Message] {
 	path := "/api/v1/conversations/" + strconv.Itoa(int(conversationID)) + "/messages"
+	if query != "" {
+		path += "?" + query
+	}
 	recorder := makeRequest(setup, http.MethodGet, path, token, nil)
 	assert.Equal(t, http.StatusOK, recorder.Code)
 
-	response := parseResponse[[]*model.Message](t, recorder)
+	response := parseResponse[*model.Page[*model.Message]](t, recorder)
 	assert.True(t, response.OK())
 	return response.Data
 }
@@ -81,3 +88,52 @@ func TestMessageFlow_MultipleMessages(t *testing.T) {
 	allMessages := getMessages(t, setup, user1Token, conv.ID)
 	assert.Equal(t, 3, len(allMessages), "Should have 3 messages")
 }
+
+func TestMessageFlow_CursorPagination(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+
+	var created []*model.Message
+	for i := 0; i < 5; i++ {
+		created = append(created, createMessage(t, setup, user1Token, conv.ID, "Message "+strconv.Itoa(i), "test-session"))
+	}
+
+	// Newest page
+	first := getMessagesPage(t, setup, user1Token, conv.ID, "limit=2")
+	assert.Len(t, first.Items, 2)
+	assert.True(t, first.HasMore)
+	assert.Equal(t, created[4].ID, first.Items[0].ID)
+	assert.Equal(t, created[3].ID, first.Items[1].ID)
+	assert.Equal(t, created[3].ID, first.NextCursor)
+
+	// Walk back through history
+	second := getMessagesPage(t, setup, user1Token, conv.ID, "limit=2&before_id="+strconv.Itoa(int(first.NextCursor)))
+	assert.Len(t, second.Items, 2)
+	assert.True(t, second.HasMore)
+	assert.Equal(t, created[2].ID, second.Items[0].ID)
+
+	last := getMessagesPage(t, setup, user1Token, conv.ID, "limit=2&before_id="+strconv.Itoa(int(second.NextCursor)))
+	assert.Len(t, last.Items, 1)
+	assert.False(t, last.HasMore)
+	assert.Equal(t, created[0].ID, last.Items[0].ID)
+
+	// Catch up on newer messages
+	newer := getMessagesPage(t, setup, user1Token, conv.ID, "limit=2&after_id="+strconv.Itoa(int(created[1].ID)))
+	assert.Len(t, newer.Items, 2)
+	assert.True(t, newer.HasMore)
+	assert.Equal(t, created[3].ID, newer.Items[0].ID)
+	assert.Equal(t, created[2].ID, newer.Items[1].ID)
+	assert.Equal(t, created[3].ID, newer.NextCursor)
+
+	// Invalid limit is rejected
+	path := "/api/v1/conversations/" + strconv.Itoa(int(conv.ID)) + "/messages?limit=abc"
+	recorder := makeRequest(setup, http.MethodGet, path, user1Token, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"local/endpoint"
+	"local/infra/provider/totp"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// enableMFA enrolls the user and confirms with the current code, returning the
+// secret and the recovery codes
+func enableMFA(t *testing.T, setup *TestSetup, token string) (string, []string) {
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/me/mfa/enroll", token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	enrollment := parseResponse[endpoint.MFAEnrollmentResponse](t, recorder).Data
+	assert.Contains(t, enrollment.OtpauthURI, "otpauth://totp/")
+
+	code, err := totp.Code(enrollment.Secret, time.Now())
+	assert.NoError(t, err)
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/me/mfa/confirm", token, map[string]string{"code": code})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return enrollment.Secret, parseResponse[endpoint.MFARecoveryCodesResponse](t, recorder).Data.RecoveryCodes
+}
+
+// loginChallenge logs in with the password and expects to be asked for a code
+func loginChallenge(t *testing.T, setup *TestSetup, username, password string) string {
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/login", "", map[string]string{"username": username, "password": password})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	response := parseResponse[endpoint.LoginResponse](t, recorder).Data
+	assert.True(t, response.MFARequired)
+	assert.Empty(t, response.Token)
+	assert.Empty(t, response.RefreshToken)
+	return response.MFAToken
+}
+
+func verifyMFA(t *testing.T, setup *TestSetup, challenge, code string) (int, endpoint.LoginResponse) {
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/login/mfa", "", map[string]string{"mfa_token": challenge, "code": code})
+	var response endpoint.LoginResponse
+	if recorder.Code == http.StatusOK {
+		response = parseResponse[endpoint.LoginResponse](t, recorder).Data
+	}
+	return recorder.Code, response
+}
+
+func TestMFAFlow(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	token := loginUser(t, setup, "testuser", "password123")
+	secret, recoveryCodes := enableMFA(t, setup, token)
+	assert.Len(t, recoveryCodes, 10)
+
+	// Enrolling again needs MFA switched off first
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/me/mfa/enroll", token, nil)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	// The password alone now only earns a challenge, which is no access token
+	challenge := loginChallenge(t, setup, "testuser", "password123")
+	assert.Equal(t, http.StatusUnauthorized, makeRequest(setup, http.MethodGet, "/api/v1/me", challenge, nil).Code)
+
+	// The confirming code's step is spent; the next step's code is within the allowed drift
+	next, err := totp.Code(secret, time.Now().Add(totp.Period))
+	assert.NoError(t, err)
+	code, tokens := verifyMFA(t, setup, challenge, next)
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, tokens.MFARequired)
+	assert.Equal(t, http.StatusOK, makeRequest(setup, http.MethodGet, "/api/v1/me", tokens.Token, nil).Code)
+
+	// Codes cannot be replayed
+	code, _ = verifyMFA(t, setup, challenge, next)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// Recovery codes work once each
+	challenge = loginChallenge(t, setup, "testuser", "password123")
+	code, tokens = verifyMFA(t, setup, challenge, recoveryCodes[0])
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, tokens.Token)
+	code, _ = verifyMFA(t, setup, challenge, recoveryCodes[0])
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// Turning MFA off takes a code too
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/me/mfa/disable", token, map[string]string{"code": "zzzzz-zzzzz"})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/me/mfa/disable", token, map[string]string{"code": recoveryCodes[1]})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/login", "", map[string]string{"username": "testuser", "password": "password123"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	login := parseResponse[endpoint.LoginResponse](t, recorder).Data
+	assert.False(t, login.MFARequired)
+	assert.NotEmpty(t, login.Token)
+}
+
+func TestMFAFlow_WrongCodesLockTheAccount(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	token := loginUser(t, setup, "testuser", "password123")
+	enableMFA(t, setup, token)
+
+	// Logging in again with the password does not clear the count of wrong codes
+	for i := 0; i < 5; i++ {
+		challenge := loginChallenge(t, setup, "testuser", "password123")
+		code, _ := verifyMFA(t, setup, challenge, "zzzzz-zzzzz")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	}
+
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/login", "", map[string]string{"username": "testuser", "password": "password123"})
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
+
+func TestMFAFlow_ConfirmRequiresValidCode(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	token := loginUser(t, setup, "testuser", "password123")
+
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/me/mfa/confirm", token, map[string]string{"code": "123456"})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/me/mfa/enroll", token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	secret := parseResponse[endpoint.MFAEnrollmentResponse](t, recorder).Data.Secret
+	stale, err := totp.Code(secret, time.Now().Add(-10*totp.Period))
+	assert.NoError(t, err)
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/me/mfa/confirm", token, map[string]string{"code": stale})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	// An unconfirmed enrollment does not change how login works
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/login", "", map[string]string{"username": "testuser", "password": "password123"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.False(t, parseResponse[endpoint.LoginResponse](t, recorder).Data.MFARequired)
+}
@@ -0,0 +1,217 @@
+package integration
+
+import (
+	"local/endpoint"
+	"local/infra/provider/oidc"
+	"local/infra/provider/oidc/oidctest"
+	"local/model"
+	"local/service/common"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const oidcRedirectURL = "http://localhost:5173/auth/callback"
+
+var corpAlice = oidctest.User{
+	Subject:           "00u1alice",
+	Email:             "alice@corp.example.com",
+	EmailVerified:     true,
+	Name:              "Alice Nguyen",
+	PreferredUsername: "alice",
+}
+
+// oidcTestSetup is a test environment offering two mock identity providers: corp,
+// a confidential client, and partner, a public one
+type oidcTestSetup struct {
+	*TestSetup
+	corp    *oidctest.Server
+	partner *oidctest.Server
+}
+
+func setupOIDC(t *testing.T) *oidcTestSetup {
+	corp, err := oidctest.NewServer("simple-chat", "corp-secret")
+	assert.NoError(t, err)
+	t.Cleanup(corp.Close)
+	partner, err := oidctest.NewServer("simple-chat-partner", "")
+	assert.NoError(t, err)
+	t.Cleanup(partner.Close)
+
+	corpProvider, err := oidc.NewProvider(corp.ProviderConfig("corp", oidcRedirectURL), nil)
+	assert.NoError(t, err)
+	partnerProvider, err := oidc.NewProvider(partner.ProviderConfig("partner", oidcRedirectURL), nil)
+	assert.NoError(t, err)
+
+	setup, err := SetupTestEnvironmentWith(func(params *common.Params) {
+		params.OIDC = oidc.NewRegistry(corpProvider, partnerProvider)
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() { setup.CleanupTestEnvironment() })
+	return &oidcTestSetup{TestSetup: setup, corp: corp, partner: partner}
+}
+
+// startOIDC asks the API for an authorization URL and signs in at the provider,
+// returning the state and code the provider sent back
+func startOIDC(t *testing.T, setup *oidcTestSetup, server *oidctest.Server, provider string, user oidctest.User) (string, string) {
+	recorder := makeRequest(setup.TestSetup, http.MethodPost, "/api/v1/login/oidc/"+provider, "", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	authorization := parseResponse[endpoint.OIDCAuthorizationResponse](t, recorder).Data
+
+	callback, err := server.SignIn(authorization.AuthorizationURL, user)
+	assert.NoError(t, err)
+	assert.Equal(t, oidcRedirectURL, (&url.URL{Scheme: callback.Scheme, Host: callback.Host, Path: callback.Path}).String())
+	assert.Equal(t, authorization.State, callback.Query().Get("state"))
+	return authorization.State, callback.Query().Get("code")
+}
+
+func completeOIDC(setup *oidcTestSetup, provider, state, code string) *httptest.ResponseRecorder {
+	return makeRequest(setup.TestSetup, http.MethodPost, "/api/v1/login/oidc/"+provider+"/callback", "", map[string]string{"state": state, "code": code})
+}
+
+// oidcLogin runs a whole single sign-on login and returns the tokens
+func oidcLogin(t *testing.T, setup *oidcTestSetup, server *oidctest.Server, provider string, user oidctest.User) endpoint.LoginResponse {
+	state, code := startOIDC(t, setup, server, provider, user)
+	recorder := completeOIDC(setup, provider, state, code)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[endpoint.LoginResponse](t, recorder).Data
+}
+
+func getMe(t *testing.T, setup *oidcTestSetup, token string) *model.User {
+	recorder := makeRequest(setup.TestSetup, http.MethodGet, "/api/v1/me", token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[*model.User](t, recorder).Data
+}
+
+func TestOIDCLogin_ListsProviders(t *testing.T) {
+	setup := setupOIDC(t)
+
+	recorder := makeRequest(setup.TestSetup, http.MethodGet, "/api/v1/login/oidc", "", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	providers := parseResponse[[]endpoint.OIDCProviderResponse](t, recorder).Data
+	assert.Equal(t, []endpoint.OIDCProviderResponse{
+		{Name: "corp", DisplayName: "corp"},
+		{Name: "partner", DisplayName: "partner"},
+	}, providers)
+
+	recorder = makeRequest(setup.TestSetup, http.MethodPost, "/api/v1/login/oidc/unknown", "", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestOIDCLogin_ProvisionsOnFirstLogin(t *testing.T) {
+	setup := setupOIDC(t)
+
+	first := oidcLogin(t, setup, setup.corp, "corp", corpAlice)
+	assert.NotEmpty(t, first.Token)
+	assert.NotEmpty(t, first.RefreshToken)
+	assert.False(t, first.MFARequired)
+	user := getMe(t, setup, first.Token)
+	assert.Equal(t, "alice", user.UserName)
+	assert.Equal(t, "Alice Nguyen", user.DisplayName)
+
+	// The next login finds the linked user instead of creating another
+	second := oidcLogin(t, setup, setup.corp, "corp", corpAlice)
+	assert.Equal(t, user.ID, getMe(t, setup, second.Token).ID)
+
+	var identities []model.UserIdentity
+	setup.DB.Find(&identities)
+	assert.Len(t, identities, 1)
+	assert.Equal(t, "corp", identities[0].Provider)
+	assert.Equal(t, "00u1alice", identities[0].Subject)
+	assert.Equal(t, user.ID, identities[0].UserID)
+	assert.NotNil(t, identities[0].LastLoginAt)
+
+	// The refresh token works like one from a password login
+	recorder := makeRequest(setup.TestSetup, http.MethodPost, "/api/v1/token/refresh", "", map[string]string{"refresh_token": second.RefreshToken})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Provisioned users have no password to log in with
+	recorder = makeRequest(setup.TestSetup, http.MethodPost, "/api/v1/login", "", map[string]string{"username": "alice", "password": ""})
+	assert.NotEqual(t, http.StatusOK, recorder.Code)
+}
+
+func TestOIDCLogin_NeverTakesOverExistingUsers(t *testing.T) {
+	setup := setupOIDC(t)
+	registerUser(t, setup.TestSetup, "alice", "password123")
+	localToken := loginUser(t, setup.TestSetup, "alice", "password123")
+	local := getMe(t, setup, localToken)
+
+	// Same preferred username: a new user with a free name
+	tokens := oidcLogin(t, setup, setup.corp, "corp", corpAlice)
+	provisioned := getMe(t, setup, tokens.Token)
+	assert.NotEqual(t, local.ID, provisioned.ID)
+	assert.Equal(t, "alice2", provisioned.UserName)
+
+	// The same subject at another provider is another account
+	tokens = oidcLogin(t, setup, setup.partner, "partner", corpAlice)
+	other := getMe(t, setup, tokens.Token)
+	assert.NotEqual(t, provisioned.ID, other.ID)
+	assert.Equal(t, "alice3", other.UserName)
+
+	// Without a preferred username the email's local part is used
+	tokens = oidcLogin(t, setup, setup.corp, "corp", oidctest.User{Subject: "00u2bob", Email: "bob.tran@corp.example.com"})
+	assert.Equal(t, "bob.tran", getMe(t, setup, tokens.Token).UserName)
+}
+
+func TestOIDCLogin_RejectsBadCallbacks(t *testing.T) {
+	setup := setupOIDC(t)
+
+	t.Run("state is used once", func(t *testing.T) {
+		state, code := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+		assert.Equal(t, http.StatusOK, completeOIDC(setup, "corp", state, code).Code)
+		assert.Equal(t, http.StatusUnauthorized, completeOIDC(setup, "corp", state, code).Code)
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		_, code := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+		assert.Equal(t, http.StatusUnauthorized, completeOIDC(setup, "corp", "forged-state", code).Code)
+	})
+
+	t.Run("expired state", func(t *testing.T) {
+		state, code := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+		setup.DB.Model(&model.OIDCLoginState{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Second))
+		assert.Equal(t, http.StatusUnauthorized, completeOIDC(setup, "corp", state, code).Code)
+	})
+
+	t.Run("state of another provider", func(t *testing.T) {
+		state, code := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+		assert.Equal(t, http.StatusUnauthorized, completeOIDC(setup, "partner", state, code).Code)
+	})
+
+	t.Run("code from another login", func(t *testing.T) {
+		// The code is bound to the first login's PKCE challenge
+		_, code := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+		state, _ := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+		assert.Equal(t, http.StatusUnauthorized, completeOIDC(setup, "corp", state, code).Code)
+	})
+
+	t.Run("ID token for another login", func(t *testing.T) {
+		setup.corp.EditClaims = func(claims jwt.MapClaims) { claims["nonce"] = "replayed" }
+		defer func() { setup.corp.EditClaims = nil }()
+		state, code := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+		assert.Equal(t, http.StatusUnauthorized, completeOIDC(setup, "corp", state, code).Code)
+	})
+
+	t.Run("missing code", func(t *testing.T) {
+		state, _ := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+		assert.Equal(t, http.StatusUnprocessableEntity, completeOIDC(setup, "corp", state, "").Code)
+	})
+}
+
+func TestOIDCLogin_KeepsTwoFactorAuthentication(t *testing.T) {
+	setup := setupOIDC(t)
+	tokens := oidcLogin(t, setup, setup.corp, "corp", corpAlice)
+	enableMFA(t, setup.TestSetup, tokens.Token)
+
+	state, code := startOIDC(t, setup, setup.corp, "corp", corpAlice)
+	recorder := completeOIDC(setup, "corp", state, code)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	login := parseResponse[endpoint.LoginResponse](t, recorder).Data
+	assert.True(t, login.MFARequired)
+	assert.NotEmpty(t, login.MFAToken)
+	assert.Empty(t, login.Token)
+}
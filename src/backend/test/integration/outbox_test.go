@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"errors"
+	"local/client"
+	"local/infra/provider/events"
+	"local/model"
+	"local/service/common"
+	"local/service/outbox"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakySocketClient stands in for the socket service, failing deliveries while it is down
+type flakySocketClient struct {
+	lock      sync.Mutex
+	down      bool
+	delivered []*model.BroadcastMessage
+}
+
+func (c *flakySocketClient) Broadcast(message *model.BroadcastMessage) {
+	_ = c.Deliver(message)
+}
+
+func (c *flakySocketClient) Deliver(message *model.BroadcastMessage) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.down {
+		return errors.New("socket service unavailable")
+	}
+	c.delivered = append(c.delivered, message)
+	return nil
+}
+
+func (c *flakySocketClient) Disconnect(message *model.DisconnectMessage) {}
+
+func (c *flakySocketClient) setDown(down bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.down = down
+}
+
+func (c *flakySocketClient) events() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	names := []string{}
+	for _, message := range c.delivered {
+		names = append(names, message.Event)
+	}
+	return names
+}
+
+func outboxRows(t *testing.T, setup *TestSetup) []*model.OutboxEvent {
+	rows := []*model.OutboxEvent{}
+	assert.NoError(t, setup.DB.Order("id ASC").Find(&rows).Error)
+	return rows
+}
+
+func TestOutbox(t *testing.T) {
+	socket := &flakySocketClient{down: true}
+	publisher := events.NewMemoryPublisher()
+	var params *common.Params
+	setup, err := SetupTestEnvironmentWith(func(p *common.Params) {
+		p.Client = &client.Client{SocketClient: socket}
+		p.Events = publisher
+		params = p
+	})
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	aliceToken := registerAndLogin(t, setup, "alice", "password123")
+	bobToken := registerAndLogin(t, setup, "bob", "password123")
+	bobID := getUserID(t, setup, bobToken)
+	conversation := createConversation(t, setup, aliceToken, bobID)
+
+	t.Run("a message is saved with its deliveries and sent right away", func(t *testing.T) {
+		message := createMessage(t, setup, aliceToken, conversation.ID, "hello", "s1")
+
+		var saved model.Conversation
+		assert.NoError(t, setup.DB.First(&saved, conversation.ID).Error)
+		assert.Equal(t, message.ID, saved.LastMessageID)
+
+		rows := outboxRows(t, setup)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, model.OutboxDestinationEvents, rows[0].Destination)
+		assert.NotNil(t, rows[0].DeliveredAt)
+		assert.Len(t, publisher.Events(), 1)
+
+		// The socket service is down, so the broadcast waits for a retry
+		assert.Equal(t, model.OutboxDestinationSocket, rows[1].Destination)
+		assert.Nil(t, rows[1].DeliveredAt)
+		assert.Equal(t, 1, rows[1].Attempts)
+		assert.Equal(t, "socket service unavailable", rows[1].LastError)
+	})
+
+	t.Run("the relay retries until the socket service is back", func(t *testing.T) {
+		later := time.Now().Add(time.Minute)
+		relay := outbox.NewTestOutboxServiceWithClock(params, func() time.Time { return later })
+		reqCtx := &model.RequestContext{}
+
+		// Still down: the retry is rescheduled further out
+		resp := relay.Relay(reqCtx)
+		assert.True(t, resp.OK())
+		assert.Equal(t, 0, resp.Data)
+		assert.Equal(t, 2, outboxRows(t, setup)[1].Attempts)
+
+		socket.setDown(false)
+		later = later.Add(time.Minute)
+		resp = relay.Relay(reqCtx)
+		assert.True(t, resp.OK())
+		assert.Equal(t, 1, resp.Data)
+		assert.Equal(t, []string{"message"}, socket.events())
+		assert.NotNil(t, outboxRows(t, setup)[1].DeliveredAt)
+
+		// Nothing is sent twice
+		resp = relay.Relay(reqCtx)
+		assert.Equal(t, 0, resp.Data)
+		assert.Len(t, socket.events(), 1)
+	})
+
+	t.Run("edits and deletions go through the outbox too", func(t *testing.T) {
+		message := createMessage(t, setup, bobToken, conversation.ID, "typo", "s2")
+		recorder := makeRequest(setup, http.MethodPatch, messagePath(conversation.ID, message.ID), bobToken, map[string]interface{}{"content": "fixed"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		recorder = makeRequest(setup, http.MethodDelete, messagePath(conversation.ID, message.ID), bobToken, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		assert.Equal(t, []string{"message", "message", "message_updated", "message_deleted"}, socket.events())
+		assert.Len(t, publisher.Events(), 4)
+		for _, row := range outboxRows(t, setup) {
+			assert.NotNil(t, row.DeliveredAt)
+		}
+	})
+}
+
+func TestOutbox_ConversationDeliveriesStayInOrder(t *testing.T) {
+	socket := &flakySocketClient{down: true}
+	var params *common.Params
+	setup, err := SetupTestEnvironmentWith(func(p *common.Params) {
+		p.Client = &client.Client{SocketClient: socket}
+		p.Events = events.NewMemoryPublisher()
+		params = p
+	})
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	aliceToken := registerAndLogin(t, setup, "alice", "password123")
+	bobToken := registerAndLogin(t, setup, "bob", "password123")
+	bobID := getUserID(t, setup, bobToken)
+	conversation := createConversation(t, setup, aliceToken, bobID)
+
+	// The broadcast of the new message fails and waits out its retry delay
+	message := createMessage(t, setup, aliceToken, conversation.ID, "typo", "s1")
+	assert.Empty(t, socket.events())
+
+	// The socket service is back, but the edit must not overtake the message
+	socket.setDown(false)
+	recorder := makeRequest(setup, http.MethodPatch, messagePath(conversation.ID, message.ID), aliceToken, map[string]interface{}{"content": "fixed"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, socket.events())
+
+	later := time.Now().Add(time.Minute)
+	relay := outbox.NewTestOutboxServiceWithClock(params, func() time.Time { return later })
+	resp := relay.Relay(&model.RequestContext{})
+	assert.True(t, resp.OK())
+	assert.Equal(t, 2, resp.Data)
+	assert.Equal(t, []string{"message", "message_updated"}, socket.events())
+	for _, row := range outboxRows(t, setup) {
+		assert.NotNil(t, row.DeliveredAt)
+	}
+}
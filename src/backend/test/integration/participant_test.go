@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Participant-specific helpers
+func createGroupConversation(t *testing.T, setup *TestSetup, token, name string, userIDs []uint) *model.Conversation {
+	body := map[string]interface{}{"name": name, "user_ids": userIDs}
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/conversations/group", token, body)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	response := parseResponse[*model.Conversation](t, recorder)
+	assert.True(t, response.OK())
+	return response.Data
+}
+
+func participantsPath(conversationID uint) string {
+	return "/api/v1/conversations/" + strconv.Itoa(int(conversationID)) + "/participants"
+}
+
+func getParticipantRoles(t *testing.T, setup *TestSetup, token string, conversationID uint) map[uint]string {
+	recorder := makeRequest(setup, http.MethodGet, participantsPath(conversationID), token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	response := parseResponse[[]*model.ConversationParticipant](t, recorder)
+	assert.True(t, response.OK())
+	roles := map[uint]string{}
+	for _, participant := range response.Data {
+		roles[participant.UserID] = participant.Role
+	}
+	return roles
+}
+
+func TestParticipantFlow_GroupMembership(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	ownerToken := registerAndLogin(t, setup, "owner", "password123")
+	ownerID := getUserID(t, setup, ownerToken)
+	memberToken := registerAndLogin(t, setup, "member", "password123")
+	memberID := getUserID(t, setup, memberToken)
+	otherToken := registerAndLogin(t, setup, "other", "password123")
+	otherID := getUserID(t, setup, otherToken)
+	lateToken := registerAndLogin(t, setup, "late", "password123")
+	lateID := getUserID(t, setup, lateToken)
+
+	group := createGroupConversation(t, setup, ownerToken, "team", []uint{memberID, otherID})
+	assert.Equal(t, model.ConversationTypeGroup, group.Type)
+	assert.Equal(t, "team", group.Name)
+
+	roles := getParticipantRoles(t, setup, ownerToken, group.ID)
+	assert.Equal(t, model.ParticipantRoleOwner, roles[ownerID])
+	assert.Equal(t, model.ParticipantRoleMember, roles[memberID])
+
+	// Plain members cannot add people
+	recorder := makeRequest(setup, http.MethodPost, participantsPath(group.ID), memberToken, map[string]interface{}{"user_ids": []uint{lateID}})
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Unknown users are rejected and nobody is added
+	recorder = makeRequest(setup, http.MethodPost, participantsPath(group.ID), ownerToken, map[string]interface{}{"user_ids": []uint{lateID, 9999}})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Len(t, getParticipantRoles(t, setup, ownerToken, group.ID), 3)
+
+	// The owner can
+	recorder = makeRequest(setup, http.MethodPost, participantsPath(group.ID), ownerToken, map[string]interface{}{"user_ids": []uint{lateID}})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	added := parseResponse[*model.Conversation](t, recorder)
+	assert.Len(t, added.Data.Participants, 4)
+
+	// Promote member to admin, who can then remove another member
+	recorder = makeRequest(setup, http.MethodPost, participantsPath(group.ID)+"/"+strconv.Itoa(int(memberID))+"/promote", ownerToken, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodDelete, participantsPath(group.ID)+"/"+strconv.Itoa(int(lateID)), memberToken, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Admins cannot remove the owner
+	recorder = makeRequest(setup, http.MethodDelete, participantsPath(group.ID)+"/"+strconv.Itoa(int(ownerID)), memberToken, nil)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Owner leaves, the admin takes over
+	recorder = makeRequest(setup, http.MethodDelete, participantsPath(group.ID)+"/me", ownerToken, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	roles = getParticipantRoles(t, setup, memberToken, group.ID)
+	assert.Len(t, roles, 2)
+	assert.Equal(t, model.ParticipantRoleOwner, roles[memberID])
+	_, ownerStillMember := roles[ownerID]
+	assert.False(t, ownerStillMember)
+}
+
+func TestParticipantFlow_PrivateConversationIsFixed(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+	user3Token := registerAndLogin(t, setup, "user3", "password123")
+	user3ID := getUserID(t, setup, user3Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+
+	recorder := makeRequest(setup, http.MethodPost, participantsPath(conv.ID), user1Token, map[string]interface{}{"user_ids": []uint{user3ID}})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestParticipantFlow_InternalMembershipCheck(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user1ID := getUserID(t, setup, user1Token)
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+	user3Token := registerAndLogin(t, setup, "user3", "password123")
+	user3ID := getUserID(t, setup, user3Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	path := func(userID uint) string {
+		return "/api/v1/internal/users/" + strconv.Itoa(int(userID)) + "/conversations/" + strconv.Itoa(int(conv.ID)) + "/participants"
+	}
+
+	// The socket service asks on behalf of a member, whatever state their token is in
+	recorder := makeServiceRequest(setup, http.MethodGet, path(user1ID), testServiceKey, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, parseResponse[[]*model.ConversationParticipant](t, recorder).Data, 2)
+
+	recorder = makeServiceRequest(setup, http.MethodGet, path(user3ID), testServiceKey, nil)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodGet, path(user1ID), user1Token, nil)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
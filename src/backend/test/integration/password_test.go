@@ -0,0 +1,194 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"local/config"
+	"local/service/common"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loginAttempt posts credentials from an address and returns the response code
+func loginAttempt(setup *TestSetup, username, password, ip string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":40000"
+
+	recorder := httptest.NewRecorder()
+	setup.Router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestPasswordPolicy_Register(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/register", "", map[string]string{"username": "testuser", "password": "short"})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	response := parseResponse[any](t, recorder)
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, "Password must be at least 8 characters", response.Errors[0].Message)
+	}
+
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/register", "", map[string]string{"username": "testuser", "password": "TestUser"})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
+
+func TestLoginLockout_Account(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+
+	// Guesses spread over addresses still count against the account
+	for i := 1; i <= config.Config.LoginAccountMaxFailures; i++ {
+		ip := fmt.Sprintf("203.0.113.%d", i)
+		assert.Equal(t, http.StatusUnauthorized, loginAttempt(setup, "testuser", "wrongpassword", ip).Code, "attempt %d", i)
+	}
+
+	recorder := loginAttempt(setup, "testuser", "password123", "198.51.100.9")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Contains(t, parseResponse[any](t, recorder).Message, "try again in 30 seconds")
+
+	// Other accounts are unaffected
+	registerUser(t, setup, "otheruser", "password123")
+	assert.Equal(t, http.StatusOK, loginAttempt(setup, "otheruser", "password123", "198.51.100.9").Code)
+}
+
+func TestLoginLockout_IP(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+
+	// Spraying one password over many accounts locks out the address
+	for i := 1; i <= config.Config.LoginIPMaxFailures; i++ {
+		assert.Equal(t, http.StatusUnauthorized, loginAttempt(setup, fmt.Sprintf("user%d", i), "password123", "203.0.113.50").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, loginAttempt(setup, "testuser", "password123", "203.0.113.50").Code)
+	assert.Equal(t, http.StatusOK, loginAttempt(setup, "testuser", "password123", "198.51.100.9").Code)
+}
+
+// spoofedLoginAttempt is loginAttempt from ip with forwarding headers naming another address
+func spoofedLoginAttempt(setup *TestSetup, username, password, ip, forwardedFor string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	req.Header.Set("X-Real-IP", forwardedFor)
+	req.RemoteAddr = ip + ":40000"
+
+	recorder := httptest.NewRecorder()
+	setup.Router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestLoginLockout_IPIgnoresForwardedHeaders(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+
+	// A fresh forwarded address per attempt does not give the attacker a fresh count,
+	// and naming the victim's address does not lock the victim out
+	for i := 1; i <= config.Config.LoginIPMaxFailures; i++ {
+		recorder := spoofedLoginAttempt(setup, fmt.Sprintf("user%d", i), "password123", "203.0.113.50", fmt.Sprintf("198.51.100.%d", i))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, spoofedLoginAttempt(setup, "testuser", "password123", "203.0.113.50", "192.0.2.1").Code)
+	assert.Equal(t, http.StatusOK, loginAttempt(setup, "testuser", "password123", "198.51.100.9").Code)
+}
+
+func TestLoginLockout_IPBehindTrustedProxy(t *testing.T) {
+	setup, err := SetupTestEnvironmentWith(func(params *common.Params) {
+		config.Config.TrustedProxies = []string{"10.0.0.1"}
+	})
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+
+	// Behind a trusted proxy each client keeps its own count
+	for i := 1; i <= config.Config.LoginIPMaxFailures; i++ {
+		assert.Equal(t, http.StatusUnauthorized, spoofedLoginAttempt(setup, fmt.Sprintf("user%d", i), "password123", "10.0.0.1", "203.0.113.50").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, spoofedLoginAttempt(setup, "testuser", "password123", "10.0.0.1", "203.0.113.50").Code)
+	assert.Equal(t, http.StatusOK, spoofedLoginAttempt(setup, "testuser", "password123", "10.0.0.1", "198.51.100.9").Code)
+}
+
+func TestLoginLockout_SuccessResetsAccount(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+
+	// One short of the limit, twice over: the success in between starts the count again
+	for round := 0; round < 2; round++ {
+		for i := 1; i < config.Config.LoginAccountMaxFailures; i++ {
+			assert.Equal(t, http.StatusUnauthorized, loginAttempt(setup, "testuser", "wrongpassword", "203.0.113.1").Code)
+		}
+		assert.Equal(t, http.StatusOK, loginAttempt(setup, "testuser", "password123", "203.0.113.1").Code)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	laptop := loginFrom(t, setup, "testuser", "password123", "Firefox on Linux", "203.0.113.7")
+	phone := loginFrom(t, setup, "testuser", "password123", "Safari on iPhone", "198.51.100.23")
+
+	// The current password must be right
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/me/password", laptop.Token, map[string]string{
+		"old_password": "wrongpassword",
+		"new_password": "a much better passphrase",
+	})
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// And the new one must pass the policy
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/me/password", laptop.Token, map[string]string{
+		"old_password": "password123",
+		"new_password": "short",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/me/password", laptop.Token, map[string]string{
+		"old_password": "password123",
+		"new_password": "a much better passphrase",
+	})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The device that changed it stays signed in; the other one is signed out
+	assert.Equal(t, http.StatusOK, makeRequest(setup, http.MethodGet, "/api/v1/me", laptop.Token, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, makeRequest(setup, http.MethodGet, "/api/v1/me", phone.Token, nil).Code)
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/token/refresh", "", map[string]string{"refresh_token": phone.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, loginAttempt(setup, "testuser", "password123", "203.0.113.7").Code)
+	assert.Equal(t, http.StatusOK, loginAttempt(setup, "testuser", "a much better passphrase", "203.0.113.7").Code)
+}
+
+func TestChangePassword_RequiresAuth(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/me/password", "", map[string]string{
+		"old_password": "password123",
+		"new_password": "a much better passphrase",
+	})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
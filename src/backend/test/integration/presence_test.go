@@ -0,0 +1,131 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getPresence(t *testing.T, setup *TestSetup, token string, userIDs ...uint) map[uint]*model.UserPresence {
+	ids := ""
+	for i, id := range userIDs {
+		if i > 0 {
+			ids += ","
+		}
+		ids += strconv.Itoa(int(id))
+	}
+	recorder := makeRequest(setup, http.MethodGet, "/api/v1/users/presence?ids="+ids, token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	response := parseResponse[[]*model.UserPresence](t, recorder)
+	assert.True(t, response.OK())
+	presences := map[uint]*model.UserPresence{}
+	for _, presence := range response.Data {
+		presences[presence.UserID] = presence
+	}
+	return presences
+}
+
+func presencePath(userID uint) string {
+	return "/api/v1/internal/users/" + strconv.Itoa(int(userID)) + "/presence"
+}
+
+func TestPresenceFlow_OnlineOffline(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user1ID := getUserID(t, setup, user1Token)
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	// Never connected
+	presences := getPresence(t, setup, user1Token, user1ID, user2ID)
+	assert.Len(t, presences, 2)
+	assert.False(t, presences[user2ID].Online)
+	assert.Nil(t, presences[user2ID].LastSeenAt)
+
+	recorder := makeServiceRequest(setup, http.MethodPost, presencePath(user2ID), testServiceKey, map[string]interface{}{"online": true})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	presences = getPresence(t, setup, user1Token, user2ID)
+	assert.True(t, presences[user2ID].Online)
+	assert.NotNil(t, presences[user2ID].LastSeenAt)
+
+	recorder = makeServiceRequest(setup, http.MethodPost, presencePath(user2ID), testServiceKey, map[string]interface{}{"online": false})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	presences = getPresence(t, setup, user1Token, user2ID)
+	assert.False(t, presences[user2ID].Online)
+	assert.NotNil(t, presences[user2ID].LastSeenAt)
+}
+
+func TestPresenceFlow_OnlyServicesSetPresence(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	token := registerAndLogin(t, setup, "user1", "password123")
+	userID := getUserID(t, setup, token)
+
+	// Users cannot set presence themselves
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/me/presence", token, map[string]interface{}{"online": true})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Internal endpoints refuse user tokens and wrong keys
+	recorder = makeRequest(setup, http.MethodPost, presencePath(userID), token, map[string]interface{}{"online": true})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	recorder = makeServiceRequest(setup, http.MethodPost, presencePath(userID), "wrong-key", map[string]interface{}{"online": true})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.False(t, getPresence(t, setup, token, userID)[userID].Online)
+
+	recorder = makeServiceRequest(setup, http.MethodPost, presencePath(9999), testServiceKey, map[string]interface{}{"online": true})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestPresenceFlow_Reset(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user1ID := getUserID(t, setup, user1Token)
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	for _, userID := range []uint{user1ID, user2ID} {
+		recorder := makeServiceRequest(setup, http.MethodPost, presencePath(userID), testServiceKey, map[string]interface{}{"online": true})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	// The socket service restarted, so nobody is connected any more
+	recorder := makeServiceRequest(setup, http.MethodPost, "/api/v1/internal/presence/reset", testServiceKey, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int64(2), parseResponse[int64](t, recorder).Data)
+
+	presences := getPresence(t, setup, user1Token, user1ID, user2ID)
+	assert.False(t, presences[user1ID].Online)
+	assert.False(t, presences[user2ID].Online)
+	assert.NotNil(t, presences[user2ID].LastSeenAt)
+}
+
+func TestPresenceFlow_Validation(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	token := registerAndLogin(t, setup, "user1", "password123")
+
+	recorder := makeRequest(setup, http.MethodGet, "/api/v1/users/presence?ids=1,abc", token, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodGet, "/api/v1/users/presence", token, nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodGet, "/api/v1/users/presence?ids=1", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
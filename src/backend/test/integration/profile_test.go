@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getProfile(t *testing.T, setup *TestSetup, token, path string) *model.UserProfile {
+	recorder := makeRequest(setup, http.MethodGet, path, token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[*model.UserProfile](t, recorder).Data
+}
+
+func TestProfileFlow(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	aliceToken := registerAndLogin(t, setup, "alice", "password123")
+	aliceID := getUserID(t, setup, aliceToken)
+	bobToken := registerAndLogin(t, setup, "bob", "password123")
+	bobPath := "/api/v1/users/" + strconv.Itoa(int(aliceID))
+
+	// A new user has an empty profile
+	profile := getProfile(t, setup, aliceToken, "/api/v1/me/profile")
+	assert.Equal(t, "alice", profile.UserName)
+	assert.Empty(t, profile.DisplayName)
+	assert.Nil(t, profile.Status)
+
+	recorder := makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{
+		"display_name": "Alice Nguyen",
+		"bio":          "Backend",
+		"status":       map[string]interface{}{"text": "On holiday", "expires_at": time.Now().Add(time.Hour)},
+	})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	profile = getProfile(t, setup, bobToken, bobPath)
+	assert.Equal(t, aliceID, profile.UserID)
+	assert.Equal(t, "Alice Nguyen", profile.DisplayName)
+	assert.Equal(t, "Backend", profile.Bio)
+	assert.Equal(t, "On holiday", profile.Status.Text)
+
+	t.Run("fields left out keep their value", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{"bio": "Backend and infra"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		profile := parseResponse[*model.UserProfile](t, recorder).Data
+		assert.Equal(t, "Alice Nguyen", profile.DisplayName)
+		assert.Equal(t, "Backend and infra", profile.Bio)
+		assert.NotNil(t, profile.Status)
+	})
+
+	t.Run("expired status is hidden", func(t *testing.T) {
+		setup.DB.Model(&model.User{}).Where("id = ?", aliceID).Update("status_expires_at", time.Now().Add(-time.Second))
+		assert.Nil(t, getProfile(t, setup, bobToken, bobPath).Status)
+	})
+
+	t.Run("empty status text clears it", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{"status": map[string]interface{}{"text": "Busy"}})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "Busy", getProfile(t, setup, bobToken, bobPath).Status.Text)
+
+		recorder = makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{"status": map[string]interface{}{"text": ""}})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Nil(t, getProfile(t, setup, bobToken, bobPath).Status)
+	})
+
+	t.Run("invalid fields are rejected", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{"bio": strings.Repeat("a", model.MaxBioLength+1)})
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		recorder = makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{
+			"status": map[string]interface{}{"text": "Away", "expires_at": time.Now().Add(-time.Hour)},
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodGet, "/api/v1/users/9999", bobToken, nil)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestProfileFlow_Avatar(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	aliceToken := registerAndLogin(t, setup, "alice", "password123")
+	bobToken := registerAndLogin(t, setup, "bob", "password123")
+	bobID := getUserID(t, setup, bobToken)
+
+	recorder := uploadFile(setup, aliceToken, "/api/v1/me/avatar", "me.png", testPNG)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	avatar := parseResponse[*model.Attachment](t, recorder).Data
+
+	// Only the uploader sees an avatar until it is picked
+	recorder = makeRequest(setup, http.MethodGet, avatar.URL, bobToken, nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{"avatar_attachment_id": avatar.ID})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	profile := parseResponse[*model.UserProfile](t, recorder).Data
+	assert.Equal(t, avatar.URL, profile.AvatarURL)
+
+	// Anyone signed in may download a picked avatar, without sharing a conversation
+	recorder = makeRequest(setup, http.MethodGet, profile.AvatarURL, bobToken, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, testPNG, recorder.Body.Bytes())
+
+	t.Run("avatars must be images", func(t *testing.T) {
+		recorder := uploadFile(setup, aliceToken, "/api/v1/me/avatar", "notes.txt", []byte("hello"))
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("only own avatar uploads can be picked", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", bobToken, map[string]interface{}{"avatar_attachment_id": avatar.ID})
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+		conv := createConversation(t, setup, aliceToken, bobID)
+		recorder = uploadAttachment(setup, aliceToken, conv.ID, "dot.png", testPNG)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		file := parseResponse[*model.Attachment](t, recorder).Data
+		recorder = makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{"avatar_attachment_id": file.ID})
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("zero removes the avatar", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, "/api/v1/me/profile", aliceToken, map[string]interface{}{"avatar_attachment_id": 0})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Empty(t, parseResponse[*model.UserProfile](t, recorder).Data.AvatarURL)
+
+		recorder = makeRequest(setup, http.MethodGet, avatar.URL, bobToken, nil)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reactionsPath(messageID uint) string {
+	return "/api/v1/messages/" + strconv.Itoa(int(messageID)) + "/reactions"
+}
+
+func react(t *testing.T, setup *TestSetup, token string, messageID uint, emoji string) []*model.ReactionSummary {
+	recorder := makeRequest(setup, http.MethodPost, reactionsPath(messageID), token, map[string]interface{}{"emoji": emoji})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[[]*model.ReactionSummary](t, recorder).Data
+}
+
+func unreact(t *testing.T, setup *TestSetup, token string, messageID uint, emoji string) []*model.ReactionSummary {
+	recorder := makeRequest(setup, http.MethodDelete, reactionsPath(messageID)+"?emoji="+url.QueryEscape(emoji), token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[[]*model.ReactionSummary](t, recorder).Data
+}
+
+func TestMessageFlow_Reactions(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	msg := createMessage(t, setup, user1Token, conv.ID, "deployed", "test-session")
+
+	t.Run("reactions are idempotent per user and emoji", func(t *testing.T) {
+		react(t, setup, user1Token, msg.ID, "🎉")
+		react(t, setup, user2Token, msg.ID, "🎉")
+		reactions := react(t, setup, user2Token, msg.ID, "🎉")
+		assert.Len(t, reactions, 1)
+		assert.Equal(t, int64(2), reactions[0].Count)
+		assert.True(t, reactions[0].ReactedByMe)
+
+		var count int64
+		setup.DB.Model(&model.MessageReaction{}).Where("message_id = ?", msg.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("listing aggregates reactions with reacted-by-me", func(t *testing.T) {
+		react(t, setup, user2Token, msg.ID, "👀")
+
+		messages := getMessages(t, setup, user1Token, conv.ID)
+		reactions := messages[0].Reactions
+		assert.Len(t, reactions, 2)
+		assert.Equal(t, "🎉", reactions[0].Emoji)
+		assert.Equal(t, int64(2), reactions[0].Count)
+		assert.True(t, reactions[0].ReactedByMe)
+		assert.Equal(t, "👀", reactions[1].Emoji)
+		assert.False(t, reactions[1].ReactedByMe)
+	})
+
+	t.Run("removing is idempotent", func(t *testing.T) {
+		reactions := unreact(t, setup, user2Token, msg.ID, "👀")
+		assert.Len(t, reactions, 1)
+		reactions = unreact(t, setup, user2Token, msg.ID, "👀")
+		assert.Len(t, reactions, 1)
+		assert.Equal(t, "🎉", reactions[0].Emoji)
+	})
+
+	t.Run("deleted messages cannot be reacted to", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodDelete, messagePath(conv.ID, msg.ID), user1Token, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = makeRequest(setup, http.MethodPost, reactionsPath(msg.ID), user2Token, map[string]interface{}{"emoji": "🙈"})
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
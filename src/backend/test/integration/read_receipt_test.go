@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func markAsRead(t *testing.T, setup *TestSetup, token string, conversationID uint, body map[string]interface{}) *model.ConversationParticipant {
+	path := "/api/v1/conversations/" + strconv.Itoa(int(conversationID)) + "/read"
+	recorder := makeRequest(setup, http.MethodPost, path, token, body)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	response := parseResponse[*model.ConversationParticipant](t, recorder)
+	assert.True(t, response.OK())
+	return response.Data
+}
+
+func findConversation(conversations []*model.Conversation, id uint) *model.Conversation {
+	for _, conversation := range conversations {
+		if conversation.ID == id {
+			return conversation
+		}
+	}
+	return nil
+}
+
+func TestReadReceiptFlow_UnreadCounts(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	first := createMessage(t, setup, user1Token, conv.ID, "Message 1", "test-session")
+	createMessage(t, setup, user1Token, conv.ID, "Message 2", "test-session")
+	createMessage(t, setup, user1Token, conv.ID, "Message 3", "test-session")
+
+	// The sender has read their own messages, the recipient has not
+	assert.Equal(t, int64(0), findConversation(getUserConversations(t, setup, user1Token), conv.ID).UnreadCount)
+	assert.Equal(t, int64(3), findConversation(getUserConversations(t, setup, user2Token), conv.ID).UnreadCount)
+
+	participant := markAsRead(t, setup, user2Token, conv.ID, map[string]interface{}{"message_id": first.ID})
+	assert.Equal(t, first.ID, participant.LastReadMessageID)
+	assert.Equal(t, int64(2), findConversation(getUserConversations(t, setup, user2Token), conv.ID).UnreadCount)
+
+	// Without a message ID everything is marked as read
+	markAsRead(t, setup, user2Token, conv.ID, nil)
+	assert.Equal(t, int64(0), findConversation(getUserConversations(t, setup, user2Token), conv.ID).UnreadCount)
+
+	// The read marker never moves backwards
+	participant = markAsRead(t, setup, user2Token, conv.ID, map[string]interface{}{"message_id": first.ID})
+	assert.NotEqual(t, first.ID, participant.LastReadMessageID)
+	assert.Equal(t, int64(0), findConversation(getUserConversations(t, setup, user2Token), conv.ID).UnreadCount)
+}
+
+func TestReadReceiptFlow_DeletedMessagesAreNotUnread(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	createMessage(t, setup, user1Token, conv.ID, "Message 1", "test-session")
+	unsent := createMessage(t, setup, user1Token, conv.ID, "Message 2", "test-session")
+	assert.Equal(t, int64(2), findConversation(getUserConversations(t, setup, user2Token), conv.ID).UnreadCount)
+
+	// A message deleted before the recipient read it no longer counts as unread
+	recorder := makeRequest(setup, http.MethodDelete, messagePath(conv.ID, unsent.ID), user1Token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int64(1), findConversation(getUserConversations(t, setup, user2Token), conv.ID).UnreadCount)
+}
+
+func TestReadReceiptFlow_Validation(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+	user3Token := registerAndLogin(t, setup, "user3", "password123")
+	user3ID := getUserID(t, setup, user3Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	other := createConversation(t, setup, user1Token, user3ID)
+	foreign := createMessage(t, setup, user1Token, other.ID, "elsewhere", "test-session")
+
+	path := "/api/v1/conversations/" + strconv.Itoa(int(conv.ID)) + "/read"
+	recorder := makeRequest(setup, http.MethodPost, path, user2Token, map[string]interface{}{"message_id": foreign.ID})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = makeRequest(setup, http.MethodPost, path, user3Token, nil)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,145 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"local/infra/provider/events"
+	"local/infra/provider/search"
+	"local/job/consumer"
+	"local/model"
+	"local/service/common"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func searchMessages(t *testing.T, setup *TestSetup, token string, params url.Values) *model.Page[*model.MessageSearchResult] {
+	recorder := makeRequest(setup, http.MethodGet, "/api/v1/search/messages?"+params.Encode(), token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[*model.Page[*model.MessageSearchResult]](t, recorder).Data
+}
+
+func resultIDs(page *model.Page[*model.MessageSearchResult]) []uint {
+	ids := make([]uint, 0, len(page.Items))
+	for _, result := range page.Items {
+		ids = append(ids, result.Message.ID)
+	}
+	return ids
+}
+
+// deliverMessageEvents hands the events published since the last delivery to the
+// consumer, the way Kafka would
+func deliverMessageEvents(t *testing.T, handler *consumer.ChatMessageHandler, publisher *events.MemoryPublisher, delivered *int) {
+	published := publisher.Events()
+	for _, event := range published[*delivered:] {
+		value, err := json.Marshal(event.Event)
+		assert.NoError(t, err)
+		assert.NoError(t, handler.Handle(context.Background(), []byte(event.Key), value))
+	}
+	*delivered = len(published)
+}
+
+func TestMessageSearch(t *testing.T) {
+	index := search.NewEmbeddedIndex()
+	publisher := events.NewMemoryPublisher()
+	setup, err := SetupTestEnvironmentWith(func(params *common.Params) {
+		params.Search = index
+		params.Events = publisher
+	})
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+	handler := consumer.NewChatMessageHandler(index)
+	delivered := 0
+
+	aliceToken := registerAndLogin(t, setup, "alice", "password123")
+	bobToken := registerAndLogin(t, setup, "bob", "password123")
+	carolToken := registerAndLogin(t, setup, "carol", "password123")
+	bobID := getUserID(t, setup, bobToken)
+	carolID := getUserID(t, setup, carolToken)
+	withBob := createConversation(t, setup, aliceToken, bobID)
+	withCarol := createConversation(t, setup, aliceToken, carolID)
+
+	deploy := createMessage(t, setup, aliceToken, withBob.ID, "Deploy the release on Friday", "s1")
+	failed := createMessage(t, setup, bobToken, withBob.ID, "the deployment <failed>", "s2")
+	notes := createMessage(t, setup, carolToken, withCarol.ID, "deploy notes", "s3")
+	lunch := createMessage(t, setup, aliceToken, withBob.ID, "lunch?", "s4")
+	deliverMessageEvents(t, handler, publisher, &delivered)
+
+	t.Run("searches every conversation of the caller", func(t *testing.T) {
+		page := searchMessages(t, setup, aliceToken, url.Values{"q": {"deploy"}})
+		assert.Equal(t, []uint{notes.ID, failed.ID, deploy.ID}, resultIDs(page))
+		assert.Equal(t, "the <mark>deployment</mark> &lt;failed&gt;", page.Items[1].Highlight)
+		assert.False(t, page.HasMore)
+		assert.Equal(t, []uint{lunch.ID}, resultIDs(searchMessages(t, setup, aliceToken, url.Values{"q": {"lunch"}})))
+	})
+
+	t.Run("events are keyed by conversation", func(t *testing.T) {
+		published := publisher.Events()
+		assert.Len(t, published, 4)
+		assert.Equal(t, strconv.Itoa(int(withBob.ID)), published[0].Key)
+		assert.Equal(t, strconv.Itoa(int(withCarol.ID)), published[2].Key)
+	})
+
+	t.Run("never shows conversations the caller is not in", func(t *testing.T) {
+		page := searchMessages(t, setup, bobToken, url.Values{"q": {"deploy"}})
+		assert.Equal(t, []uint{failed.ID, deploy.ID}, resultIDs(page))
+
+		recorder := makeRequest(setup, http.MethodGet, "/api/v1/search/messages?q=deploy&conversation_id="+strconv.Itoa(int(withCarol.ID)), bobToken, nil)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		byConversation := searchMessages(t, setup, aliceToken, url.Values{"q": {"deploy"}, "conversation_id": {strconv.Itoa(int(withCarol.ID))}})
+		assert.Equal(t, []uint{notes.ID}, resultIDs(byConversation))
+
+		bySender := searchMessages(t, setup, aliceToken, url.Values{"q": {"deploy"}, "sender_id": {strconv.Itoa(int(bobID))}})
+		assert.Equal(t, []uint{failed.ID}, resultIDs(bySender))
+
+		future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		assert.Empty(t, searchMessages(t, setup, aliceToken, url.Values{"q": {"deploy"}, "from": {future}}).Items)
+		assert.Len(t, searchMessages(t, setup, aliceToken, url.Values{"q": {"deploy"}, "to": {future}}).Items, 3)
+	})
+
+	t.Run("cursor pages through the results", func(t *testing.T) {
+		var seen []uint
+		params := url.Values{"q": {"deploy"}, "limit": {"2"}}
+		for {
+			page := searchMessages(t, setup, aliceToken, params)
+			seen = append(seen, resultIDs(page)...)
+			if !page.HasMore {
+				break
+			}
+			params.Set("before_id", strconv.Itoa(int(page.NextCursor)))
+		}
+		assert.Equal(t, []uint{notes.ID, failed.ID, deploy.ID}, seen)
+	})
+
+	t.Run("follows edits and deletions", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodPatch, messagePath(withBob.ID, deploy.ID), aliceToken, map[string]interface{}{"content": "Ship it on Monday"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		deliverMessageEvents(t, handler, publisher, &delivered)
+
+		recorder = makeRequest(setup, http.MethodDelete, messagePath(withBob.ID, failed.ID), bobToken, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		// Until the deletion reaches the index the message is filtered out on load
+		assert.Equal(t, []uint{notes.ID}, resultIDs(searchMessages(t, setup, aliceToken, url.Values{"q": {"deploy"}})))
+		deliverMessageEvents(t, handler, publisher, &delivered)
+
+		assert.Equal(t, []uint{notes.ID}, resultIDs(searchMessages(t, setup, aliceToken, url.Values{"q": {"deploy"}})))
+		assert.Equal(t, []uint{deploy.ID}, resultIDs(searchMessages(t, setup, aliceToken, url.Values{"q": {"monday"}})))
+	})
+
+	t.Run("rejects an empty query", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodGet, "/api/v1/search/messages?q=", aliceToken, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodGet, "/api/v1/search/messages?q=deploy", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+}
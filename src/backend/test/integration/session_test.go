@@ -0,0 +1,122 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"local/endpoint"
+	"local/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loginFrom logs in the way a specific device would, with its own user agent and address
+func loginFrom(t *testing.T, setup *TestSetup, username, password, userAgent, ip string) endpoint.LoginResponse {
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = ip + ":40000"
+
+	recorder := httptest.NewRecorder()
+	setup.Router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[endpoint.LoginResponse](t, recorder).Data
+}
+
+func listSessions(t *testing.T, setup *TestSetup, token string) []*model.Session {
+	recorder := makeRequest(setup, http.MethodGet, "/api/v1/sessions/", token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[[]*model.Session](t, recorder).Data
+}
+
+func TestSessionFlow_ListAndDelete(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	laptop := loginFrom(t, setup, "testuser", "password123", "Firefox on Linux", "203.0.113.7")
+	phone := loginFrom(t, setup, "testuser", "password123", "Safari on iPhone", "198.51.100.23")
+
+	sessions := listSessions(t, setup, laptop.Token)
+	assert.Len(t, sessions, 2)
+
+	var current, other *model.Session
+	for _, session := range sessions {
+		if session.Current {
+			current = session
+		} else {
+			other = session
+		}
+	}
+	if assert.NotNil(t, current) && assert.NotNil(t, other) {
+		assert.Equal(t, "Firefox on Linux", current.UserAgent)
+		assert.Equal(t, "203.0.113.7", current.IPAddress)
+		assert.Equal(t, "Safari on iPhone", other.UserAgent)
+		assert.Equal(t, "198.51.100.23", other.IPAddress)
+		assert.False(t, other.LastUsedAt.IsZero())
+	}
+
+	t.Run("sign out another device", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodDelete, "/api/v1/sessions/"+other.ID, laptop.Token, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = makeRequest(setup, http.MethodGet, "/api/v1/me", phone.Token, nil)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		_, code := refreshTokens(t, setup, phone.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, code)
+
+		sessions := listSessions(t, setup, laptop.Token)
+		assert.Len(t, sessions, 1)
+		assert.True(t, sessions[0].Current)
+
+		recorder = makeRequest(setup, http.MethodDelete, "/api/v1/sessions/"+other.ID, laptop.Token, nil)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("cannot touch another user's session", func(t *testing.T) {
+		strangerToken := registerAndLogin(t, setup, "stranger", "password123")
+
+		recorder := makeRequest(setup, http.MethodDelete, "/api/v1/sessions/"+current.ID, strangerToken, nil)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		getUserID(t, setup, laptop.Token)
+
+		for _, session := range listSessions(t, setup, strangerToken) {
+			assert.NotEqual(t, current.ID, session.ID)
+		}
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodDelete, "/api/v1/sessions/does-not-exist", laptop.Token, nil)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestSessionFlow_RefreshUpdatesLastUse(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	login := loginFrom(t, setup, "testuser", "password123", "Firefox on Linux", "203.0.113.7")
+	before := listSessions(t, setup, login.Token)[0]
+
+	body, _ := json.Marshal(map[string]string{"refresh_token": login.RefreshToken})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/token/refresh", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.44:40000"
+	recorder := httptest.NewRecorder()
+	setup.Router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	refreshed := parseResponse[endpoint.LoginResponse](t, recorder).Data
+
+	after := listSessions(t, setup, refreshed.Token)
+	assert.Len(t, after, 1)
+	assert.Equal(t, before.ID, after[0].ID)
+	assert.Equal(t, "192.0.2.44", after[0].IPAddress)
+	assert.Equal(t, "Firefox on Linux", after[0].UserAgent)
+	assert.False(t, after[0].LastUsedAt.Before(before.LastUsedAt))
+}
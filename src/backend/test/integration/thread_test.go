@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createReply(t *testing.T, setup *TestSetup, token string, conversationID uint, content string, replyToID, threadRootID uint) *model.Message {
+	body := map[string]interface{}{
+		"content":    content,
+		"session_id": "test-session",
+	}
+	if replyToID != 0 {
+		body["reply_to_id"] = replyToID
+	}
+	if threadRootID != 0 {
+		body["thread_root_id"] = threadRootID
+	}
+	path := "/api/v1/conversations/" + strconv.Itoa(int(conversationID)) + "/messages"
+	recorder := makeRequest(setup, http.MethodPost, path, token, body)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[*model.Message](t, recorder).Data
+}
+
+func getThreadPage(t *testing.T, setup *TestSetup, token string, messageID uint, query string) *model.Page[*model.Message] {
+	path := "/api/v1/messages/" + strconv.Itoa(int(messageID)) + "/thread"
+	if query != "" {
+		path += "?" + query
+	}
+	recorder := makeRequest(setup, http.MethodGet, path, token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[*model.Page[*model.Message]](t, recorder).Data
+}
+
+func TestMessageFlow_Threads(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	user1Token := registerAndLogin(t, setup, "user1", "password123")
+	user2Token := registerAndLogin(t, setup, "user2", "password123")
+	user2ID := getUserID(t, setup, user2Token)
+
+	conv := createConversation(t, setup, user1Token, user2ID)
+	root := createMessage(t, setup, user1Token, conv.ID, "lunch?", "test-session")
+	first := createReply(t, setup, user2Token, conv.ID, "sure", 0, root.ID)
+	createReply(t, setup, user1Token, conv.ID, "noon", 0, root.ID)
+	// Replying to a reply lands in the original thread
+	nested := createReply(t, setup, user2Token, conv.ID, "see you", 0, first.ID)
+	assert.Equal(t, root.ID, *nested.ThreadRootID)
+
+	t.Run("thread is paginated newest first", func(t *testing.T) {
+		page := getThreadPage(t, setup, user1Token, root.ID, "limit=2")
+		assert.Len(t, page.Items, 2)
+		assert.True(t, page.HasMore)
+		assert.Equal(t, nested.ID, page.Items[0].ID)
+
+		page = getThreadPage(t, setup, user1Token, root.ID, "before_id="+strconv.Itoa(int(page.NextCursor)))
+		assert.Len(t, page.Items, 1)
+		assert.False(t, page.HasMore)
+		assert.Equal(t, first.ID, page.Items[0].ID)
+	})
+
+	t.Run("root carries the reply count", func(t *testing.T) {
+		messages := getMessages(t, setup, user2Token, conv.ID)
+		for _, message := range messages {
+			if message.ID == root.ID {
+				assert.Equal(t, int64(3), message.ReplyCount)
+			}
+		}
+	})
+
+	t.Run("quote embeds a trimmed preview", func(t *testing.T) {
+		long := createMessage(t, setup, user1Token, conv.ID, strings.Repeat("x", 300), "test-session")
+		quote := createReply(t, setup, user2Token, conv.ID, "tl;dr", long.ID, 0)
+		assert.NotNil(t, quote.ReplyTo)
+		assert.Equal(t, long.ID, quote.ReplyTo.ID)
+		assert.Less(t, len(quote.ReplyTo.Content), 300)
+
+		messages := getMessages(t, setup, user1Token, conv.ID)
+		assert.Equal(t, quote.ID, messages[0].ID)
+		assert.NotNil(t, messages[0].ReplyTo)
+		assert.Equal(t, long.ID, messages[0].ReplyTo.ID)
+	})
+
+	t.Run("cannot reply across conversations", func(t *testing.T) {
+		otherToken := registerAndLogin(t, setup, "user3", "password123")
+		otherID := getUserID(t, setup, otherToken)
+		other := createConversation(t, setup, user1Token, otherID)
+
+		path := "/api/v1/conversations/" + strconv.Itoa(int(other.ID)) + "/messages"
+		recorder := makeRequest(setup, http.MethodPost, path, user1Token, map[string]interface{}{
+			"content":     "leak",
+			"session_id":  "test-session",
+			"reply_to_id": root.ID,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+}
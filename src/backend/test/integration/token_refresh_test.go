@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"local/endpoint"
+	"local/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loginWithRefresh(t *testing.T, setup *TestSetup, username, password string) endpoint.LoginResponse {
+	body := map[string]string{"username": username, "password": password}
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/login", "", body)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[endpoint.LoginResponse](t, recorder).Data
+}
+
+func refreshTokens(t *testing.T, setup *TestSetup, refreshToken string) (model.Response[endpoint.LoginResponse], int) {
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/token/refresh", "", map[string]string{"refresh_token": refreshToken})
+	return parseResponse[endpoint.LoginResponse](t, recorder), recorder.Code
+}
+
+func TestTokenRefresh_Rotation(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	login := loginWithRefresh(t, setup, "testuser", "password123")
+	assert.NotEmpty(t, login.Token)
+	assert.NotEmpty(t, login.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), login.ExpiresAt, time.Minute)
+
+	// Only the hash of the refresh token is stored
+	var session model.Session
+	assert.NoError(t, setup.DB.First(&session).Error)
+	assert.NotEqual(t, login.RefreshToken, session.RefreshTokenHash)
+
+	refreshed, code := refreshTokens(t, setup, login.RefreshToken)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEqual(t, login.RefreshToken, refreshed.Data.RefreshToken)
+	userID := getUserID(t, setup, refreshed.Data.Token)
+	assert.NotZero(t, userID)
+
+	// The new refresh token can be rotated again
+	again, code := refreshTokens(t, setup, refreshed.Data.RefreshToken)
+	assert.Equal(t, http.StatusOK, code)
+	getUserID(t, setup, again.Data.Token)
+
+	_, code = refreshTokens(t, setup, "not-a-real-token")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/token/refresh", "", map[string]string{})
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
+
+func TestTokenRefresh_ReuseRevokesSession(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	login := loginWithRefresh(t, setup, "testuser", "password123")
+	otherDevice := loginWithRefresh(t, setup, "testuser", "password123")
+
+	refreshed, code := refreshTokens(t, setup, login.RefreshToken)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Replaying the rotated-out token is treated as theft
+	replayed, code := refreshTokens(t, setup, login.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Contains(t, replayed.ErrorString(), "reuse detected")
+
+	// Every token of that session is dead now, including the legitimate holder's
+	_, code = refreshTokens(t, setup, refreshed.Data.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	for _, token := range []string{login.Token, refreshed.Data.Token} {
+		recorder := makeRequest(setup, http.MethodGet, "/api/v1/me", token, nil)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	}
+
+	// Other sessions of the same user are unaffected
+	getUserID(t, setup, otherDevice.Token)
+	_, code = refreshTokens(t, setup, otherDevice.RefreshToken)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestTokenRefresh_AfterLogout(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	registerUser(t, setup, "testuser", "password123")
+	first := loginWithRefresh(t, setup, "testuser", "password123")
+	second := loginWithRefresh(t, setup, "testuser", "password123")
+
+	recorder := makeRequest(setup, http.MethodPost, "/api/v1/logout", first.Token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	_, code := refreshTokens(t, setup, first.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	recorder = makeRequest(setup, http.MethodPost, "/api/v1/logout/all", second.Token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	_, code = refreshTokens(t, setup, second.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"local/model"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func searchUsers(t *testing.T, setup *TestSetup, token string, params url.Values) *model.UserSearchPage {
+	recorder := makeRequest(setup, http.MethodGet, "/api/v1/users/?"+params.Encode(), token, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	return parseResponse[*model.UserSearchPage](t, recorder).Data
+}
+
+func usernames(page *model.UserSearchPage) []string {
+	names := make([]string, 0, len(page.Items))
+	for _, profile := range page.Items {
+		names = append(names, profile.UserName)
+	}
+	return names
+}
+
+func TestUserSearch(t *testing.T) {
+	setup, err := SetupTestEnvironment()
+	assert.NoError(t, err)
+	defer setup.CleanupTestEnvironment()
+
+	token := registerAndLogin(t, setup, "zed", "password123")
+	for _, name := range []string{"alicia", "alice", "bob", "malik", "carol", "retired.alina"} {
+		registerUser(t, setup, name, "password123")
+	}
+	setup.DB.Model(&model.User{}).Where("username = ?", "bob").Update("display_name", "Bob Alison")
+	setup.DB.Model(&model.User{}).Where("username = ?", "retired.alina").Update("deactivated_at", time.Now())
+
+	t.Run("prefix matches rank before fuzzy ones", func(t *testing.T) {
+		page := searchUsers(t, setup, token, url.Values{"q": {"ali"}})
+		// Username prefix, then a word of the display name, then the letters in order
+		assert.Equal(t, []string{"alice", "alicia", "bob", "malik"}, usernames(page))
+		assert.False(t, page.HasMore)
+		assert.Empty(t, page.NextCursor)
+		assert.Equal(t, "Bob Alison", page.Items[2].DisplayName)
+	})
+
+	t.Run("fuzzy matches skip letters", func(t *testing.T) {
+		page := searchUsers(t, setup, token, url.Values{"q": {"crl"}})
+		assert.Equal(t, []string{"carol"}, usernames(page))
+	})
+
+	t.Run("cursor pages through the ranking", func(t *testing.T) {
+		var seen []string
+		params := url.Values{"q": {"ali"}, "limit": {"1"}}
+		for {
+			page := searchUsers(t, setup, token, params)
+			seen = append(seen, usernames(page)...)
+			if !page.HasMore {
+				break
+			}
+			params.Set("cursor", page.NextCursor)
+		}
+		assert.Equal(t, []string{"alice", "alicia", "bob", "malik"}, seen)
+	})
+
+	t.Run("without a query lists active users by username", func(t *testing.T) {
+		page := searchUsers(t, setup, token, url.Values{})
+		assert.Equal(t, []string{"alice", "alicia", "bob", "carol", "malik", "zed"}, usernames(page))
+	})
+
+	t.Run("wildcards match literally", func(t *testing.T) {
+		assert.Empty(t, searchUsers(t, setup, token, url.Values{"q": {"%"}}).Items)
+		assert.Empty(t, searchUsers(t, setup, token, url.Values{"q": {"_"}}).Items)
+	})
+
+	t.Run("results expose public profile fields only", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodGet, "/api/v1/users/?q=alice", token, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		body := recorder.Body.String()
+		assert.NotContains(t, body, "password")
+		assert.NotContains(t, body, "online")
+		assert.NotContains(t, body, "deactivated")
+		assert.NotContains(t, body, "created_at")
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		recorder := makeRequest(setup, http.MethodGet, "/api/v1/users/?cursor=not-a-cursor", token, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		recorder = makeRequest(setup, http.MethodGet, "/api/v1/users/?q="+strings.Repeat("a", model.MaxUserSearchLength+1), token, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+}
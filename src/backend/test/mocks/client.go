@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"local/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSocketClient is a mock implementation of client.SocketClient
+type MockSocketClient struct {
+	mock.Mock
+}
+
+func (m *MockSocketClient) Broadcast(message *model.BroadcastMessage) {
+	m.Called(message)
+}
+
+func (m *MockSocketClient) Deliver(message *model.BroadcastMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockSocketClient) Disconnect(message *model.DisconnectMessage) {
+	m.Called(message)
+}
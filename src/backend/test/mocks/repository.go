@@ -3,6 +3,7 @@ package mocks
 import (
 	"local/infra/repo"
 	"local/model"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -14,6 +15,86 @@ type MockRepository struct {
 	ConversationRepo repo.ConversationRepo
 	ParticipantRepo  repo.ParticipantRepo
 	MessageRepo      repo.MessageRepo
+	ReactionRepo     repo.ReactionRepo
+	AttachmentRepo   repo.AttachmentRepo
+	RevocationRepo   repo.RevocationRepo
+	SessionRepo      repo.SessionRepo
+	LoginAttemptRepo repo.LoginAttemptRepo
+	MFARepo          repo.MFARepo
+	IdentityRepo     repo.IdentityRepo
+	BlockRepo        repo.BlockRepo
+	OutboxRepo       repo.OutboxRepo
+}
+
+func (m *MockRepository) User() repo.UserRepo {
+	args := m.Called()
+	return args.Get(0).(repo.UserRepo)
+}
+
+func (m *MockRepository) Conversation() repo.ConversationRepo {
+	args := m.Called()
+	return args.Get(0).(repo.ConversationRepo)
+}
+
+func (m *MockRepository) Participant() repo.ParticipantRepo {
+	args := m.Called()
+	return args.Get(0).(repo.ParticipantRepo)
+}
+
+func (m *MockRepository) Message() repo.MessageRepo {
+	args := m.Called()
+	return args.Get(0).(repo.MessageRepo)
+}
+
+func (m *MockRepository) Reaction() repo.ReactionRepo {
+	args := m.Called()
+	return args.Get(0).(repo.ReactionRepo)
+}
+
+func (m *MockRepository) Attachment() repo.AttachmentRepo {
+	args := m.Called()
+	return args.Get(0).(repo.AttachmentRepo)
+}
+
+func (m *MockRepository) Revocation() repo.RevocationRepo {
+	args := m.Called()
+	return args.Get(0).(repo.RevocationRepo)
+}
+
+func (m *MockRepository) Session() repo.SessionRepo {
+	args := m.Called()
+	return args.Get(0).(repo.SessionRepo)
+}
+
+func (m *MockRepository) LoginAttempt() repo.LoginAttemptRepo {
+	args := m.Called()
+	return args.Get(0).(repo.LoginAttemptRepo)
+}
+
+func (m *MockRepository) MFA() repo.MFARepo {
+	args := m.Called()
+	return args.Get(0).(repo.MFARepo)
+}
+
+func (m *MockRepository) Identity() repo.IdentityRepo {
+	args := m.Called()
+	return args.Get(0).(repo.IdentityRepo)
+}
+
+func (m *MockRepository) Block() repo.BlockRepo {
+	args := m.Called()
+	return args.Get(0).(repo.BlockRepo)
+}
+
+func (m *MockRepository) Outbox() repo.OutboxRepo {
+	args := m.Called()
+	return args.Get(0).(repo.OutboxRepo)
+}
+
+// WithTx runs fn against the mock itself, so a unit of work's calls are expected
+// like any others; nothing is rolled back
+func (m *MockRepository) WithTx(reqCtx *model.RequestContext, fn func(tx repo.RepositoryInterface) error) error {
+	return fn(m)
 }
 
 // MockUserRepo is a mock implementation of UserRepo
@@ -46,6 +127,36 @@ func (m *MockUserRepo) Delete(reqCtx *model.RequestContext, user *model.User) mo
 	return args.Get(0).(model.Response[*model.User])
 }
 
+func (m *MockUserRepo) Count(reqCtx *model.RequestContext) (int64, error) {
+	args := m.Called(reqCtx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepo) GetByIDs(reqCtx *model.RequestContext, ids []uint) model.Response[[]*model.User] {
+	args := m.Called(reqCtx, ids)
+	return args.Get(0).(model.Response[[]*model.User])
+}
+
+func (m *MockUserRepo) SetPresence(reqCtx *model.RequestContext, userID uint, online bool, seenAt time.Time) model.Response[*model.User] {
+	args := m.Called(reqCtx, userID, online, seenAt)
+	return args.Get(0).(model.Response[*model.User])
+}
+
+func (m *MockUserRepo) ClearPresence(reqCtx *model.RequestContext, seenAt time.Time) model.Response[int64] {
+	args := m.Called(reqCtx, seenAt)
+	return args.Get(0).(model.Response[int64])
+}
+
+func (m *MockUserRepo) UpdateProfile(reqCtx *model.RequestContext, userID uint, changes map[string]interface{}) model.Response[*model.User] {
+	args := m.Called(reqCtx, userID, changes)
+	return args.Get(0).(model.Response[*model.User])
+}
+
+func (m *MockUserRepo) Search(reqCtx *model.RequestContext, viewerID uint, query string, after *model.UserSearchCursor, limit int) model.Response[[]*model.UserMatch] {
+	args := m.Called(reqCtx, viewerID, query, after, limit)
+	return args.Get(0).(model.Response[[]*model.UserMatch])
+}
+
 // MockConversationRepo is a mock implementation of ConversationRepo
 type MockConversationRepo struct {
 	mock.Mock
@@ -76,9 +187,9 @@ func (m *MockConversationRepo) Delete(reqCtx *model.RequestContext, conversation
 	return args.Get(0).(model.Response[*model.Conversation])
 }
 
-func (m *MockConversationRepo) GetByParticipant(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Conversation] {
-	args := m.Called(reqCtx, userID)
-	return args.Get(0).(model.Response[[]*model.Conversation])
+func (m *MockConversationRepo) GetByParticipant(reqCtx *model.RequestContext, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Conversation]] {
+	args := m.Called(reqCtx, userID, cursor)
+	return args.Get(0).(model.Response[*model.Page[*model.Conversation]])
 }
 
 func (m *MockConversationRepo) GetByEntityJoined(reqCtx *model.RequestContext, entityJoined string) model.Response[*model.Conversation] {
@@ -86,6 +197,16 @@ func (m *MockConversationRepo) GetByEntityJoined(reqCtx *model.RequestContext, e
 	return args.Get(0).(model.Response[*model.Conversation])
 }
 
+func (m *MockConversationRepo) GetOrCreatePrivate(reqCtx *model.RequestContext, conversation *model.Conversation) model.Response[bool] {
+	args := m.Called(reqCtx, conversation)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockConversationRepo) Count(reqCtx *model.RequestContext) (int64, error) {
+	args := m.Called(reqCtx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // MockParticipantRepo is a mock implementation of ParticipantRepo
 type MockParticipantRepo struct {
 	mock.Mock
@@ -126,6 +247,21 @@ func (m *MockParticipantRepo) GetByUserID(reqCtx *model.RequestContext, userID u
 	return args.Get(0).(model.Response[[]*model.ConversationParticipant])
 }
 
+func (m *MockParticipantRepo) GetContactIDs(reqCtx *model.RequestContext, userID uint) model.Response[[]uint] {
+	args := m.Called(reqCtx, userID)
+	return args.Get(0).(model.Response[[]uint])
+}
+
+func (m *MockParticipantRepo) MarkRead(reqCtx *model.RequestContext, conversationID, userID, messageID uint) model.Response[*model.ConversationParticipant] {
+	args := m.Called(reqCtx, conversationID, userID, messageID)
+	return args.Get(0).(model.Response[*model.ConversationParticipant])
+}
+
+func (m *MockParticipantRepo) SetMutedUntil(reqCtx *model.RequestContext, conversationID, userID uint, until *time.Time) model.Response[*model.ConversationParticipant] {
+	args := m.Called(reqCtx, conversationID, userID, until)
+	return args.Get(0).(model.Response[*model.ConversationParticipant])
+}
+
 func (m *MockParticipantRepo) GetByConversationAndUser(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant] {
 	args := m.Called(reqCtx, conversationID, userID)
 	return args.Get(0).(model.Response[*model.ConversationParticipant])
@@ -146,16 +282,329 @@ type MockMessageRepo struct {
 	mock.Mock
 }
 
-func (m *MockMessageRepo) Create(reqCtx *model.RequestContext, message *model.Message) model.Response[*model.Message] {
+// Create, Edit and SoftDelete run the outbox builder on a successful response, as
+// the repository does inside its transaction; expectations leave the builder out
+func (m *MockMessageRepo) Create(reqCtx *model.RequestContext, message *model.Message, outbox repo.OutboxBuilder) model.Response[*model.Message] {
 	args := m.Called(reqCtx, message)
+	return runOutbox(args.Get(0).(model.Response[*model.Message]), outbox)
+}
+
+func (m *MockMessageRepo) GetByID(reqCtx *model.RequestContext, id uint) model.Response[*model.Message] {
+	args := m.Called(reqCtx, id)
 	return args.Get(0).(model.Response[*model.Message])
 }
 
-func (m *MockMessageRepo) GetByConversationID(reqCtx *model.RequestContext, conversationID uint) model.Response[[]*model.Message] {
-	args := m.Called(reqCtx, conversationID)
+func (m *MockMessageRepo) GetByIDs(reqCtx *model.RequestContext, ids []uint, viewerID uint) model.Response[[]*model.Message] {
+	args := m.Called(reqCtx, ids, viewerID)
 	return args.Get(0).(model.Response[[]*model.Message])
 }
 
+func (m *MockMessageRepo) CountUnread(reqCtx *model.RequestContext, userID uint, conversationIDs []uint) model.Response[map[uint]int64] {
+	args := m.Called(reqCtx, userID, conversationIDs)
+	return args.Get(0).(model.Response[map[uint]int64])
+}
+
+func (m *MockMessageRepo) GetThread(reqCtx *model.RequestContext, rootID, viewerID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
+	args := m.Called(reqCtx, rootID, viewerID, cursor)
+	return args.Get(0).(model.Response[*model.Page[*model.Message]])
+}
+
+func (m *MockMessageRepo) Edit(reqCtx *model.RequestContext, message *model.Message, editorID uint, content string, outbox repo.OutboxBuilder) model.Response[*model.Message] {
+	args := m.Called(reqCtx, message, editorID, content)
+	return runOutbox(args.Get(0).(model.Response[*model.Message]), outbox)
+}
+
+func (m *MockMessageRepo) SoftDelete(reqCtx *model.RequestContext, message *model.Message, outbox repo.OutboxBuilder) model.Response[*model.Message] {
+	args := m.Called(reqCtx, message)
+	return runOutbox(args.Get(0).(model.Response[*model.Message]), outbox)
+}
+
+func runOutbox(response model.Response[*model.Message], outbox repo.OutboxBuilder) model.Response[*model.Message] {
+	if !response.OK() || outbox == nil {
+		return response
+	}
+	if _, err := outbox(response.Data); err != nil {
+		return model.InternalError[*model.Message](err.Error())
+	}
+	return response
+}
+
+func (m *MockMessageRepo) GetByConversationID(reqCtx *model.RequestContext, conversationID, viewerID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Message]] {
+	args := m.Called(reqCtx, conversationID, viewerID, cursor)
+	return args.Get(0).(model.Response[*model.Page[*model.Message]])
+}
+
+func (m *MockMessageRepo) Count(reqCtx *model.RequestContext) (int64, error) {
+	args := m.Called(reqCtx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepo) Search(reqCtx *model.RequestContext, filter *model.MessageSearchFilter) model.Response[[]uint] {
+	args := m.Called(reqCtx, filter)
+	return args.Get(0).(model.Response[[]uint])
+}
+
+// MockReactionRepo is a mock implementation of ReactionRepo
+type MockReactionRepo struct {
+	mock.Mock
+}
+
+func (m *MockReactionRepo) Add(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[bool] {
+	args := m.Called(reqCtx, messageID, userID, emoji)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockReactionRepo) Remove(reqCtx *model.RequestContext, messageID, userID uint, emoji string) model.Response[bool] {
+	args := m.Called(reqCtx, messageID, userID, emoji)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockReactionRepo) Summarize(reqCtx *model.RequestContext, messageIDs []uint, viewerID uint) model.Response[map[uint][]*model.ReactionSummary] {
+	args := m.Called(reqCtx, messageIDs, viewerID)
+	return args.Get(0).(model.Response[map[uint][]*model.ReactionSummary])
+}
+
+// MockAttachmentRepo is a mock implementation of AttachmentRepo
+type MockAttachmentRepo struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepo) Create(reqCtx *model.RequestContext, attachment *model.Attachment) model.Response[*model.Attachment] {
+	args := m.Called(reqCtx, attachment)
+	return args.Get(0).(model.Response[*model.Attachment])
+}
+
+func (m *MockAttachmentRepo) GetByID(reqCtx *model.RequestContext, id uint) model.Response[*model.Attachment] {
+	args := m.Called(reqCtx, id)
+	return args.Get(0).(model.Response[*model.Attachment])
+}
+
+// MockRevocationRepo is a mock implementation of RevocationRepo
+type MockRevocationRepo struct {
+	mock.Mock
+}
+
+func (m *MockRevocationRepo) RevokeSession(reqCtx *model.RequestContext, session *model.RevokedSession) model.Response[bool] {
+	args := m.Called(reqCtx, session)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockRevocationRepo) RevokeUser(reqCtx *model.RequestContext, userID uint, before time.Time) model.Response[*model.UserTokenCutoff] {
+	args := m.Called(reqCtx, userID, before)
+	return args.Get(0).(model.Response[*model.UserTokenCutoff])
+}
+
+func (m *MockRevocationRepo) IsSessionRevoked(reqCtx *model.RequestContext, sessionID string) model.Response[bool] {
+	args := m.Called(reqCtx, sessionID)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockRevocationRepo) GetUserCutoff(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserTokenCutoff] {
+	args := m.Called(reqCtx, userID)
+	return args.Get(0).(model.Response[*model.UserTokenCutoff])
+}
+
+// MockLoginAttemptRepo is a mock implementation of LoginAttemptRepo
+type MockLoginAttemptRepo struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepo) Get(reqCtx *model.RequestContext, key string) model.Response[*model.LoginAttempt] {
+	args := m.Called(reqCtx, key)
+	return args.Get(0).(model.Response[*model.LoginAttempt])
+}
+
+func (m *MockLoginAttemptRepo) RecordFailure(reqCtx *model.RequestContext, key string, now time.Time, resetAfter time.Duration) model.Response[*model.LoginAttempt] {
+	args := m.Called(reqCtx, key, now, resetAfter)
+	return args.Get(0).(model.Response[*model.LoginAttempt])
+}
+
+func (m *MockLoginAttemptRepo) Reset(reqCtx *model.RequestContext, key string) model.Response[bool] {
+	args := m.Called(reqCtx, key)
+	return args.Get(0).(model.Response[bool])
+}
+
+// MockMFARepo is a mock implementation of MFARepo
+type MockMFARepo struct {
+	mock.Mock
+}
+
+func (m *MockMFARepo) Get(reqCtx *model.RequestContext, userID uint) model.Response[*model.UserMFA] {
+	args := m.Called(reqCtx, userID)
+	return args.Get(0).(model.Response[*model.UserMFA])
+}
+
+func (m *MockMFARepo) SavePending(reqCtx *model.RequestContext, userID uint, secret string) model.Response[*model.UserMFA] {
+	args := m.Called(reqCtx, userID, secret)
+	return args.Get(0).(model.Response[*model.UserMFA])
+}
+
+func (m *MockMFARepo) Enable(reqCtx *model.RequestContext, userID uint, step int64, enabledAt time.Time, codeHashes []string) model.Response[*model.UserMFA] {
+	args := m.Called(reqCtx, userID, step, enabledAt, codeHashes)
+	return args.Get(0).(model.Response[*model.UserMFA])
+}
+
+func (m *MockMFARepo) UseStep(reqCtx *model.RequestContext, userID uint, step int64) model.Response[bool] {
+	args := m.Called(reqCtx, userID, step)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockMFARepo) UseRecoveryCode(reqCtx *model.RequestContext, userID uint, codeHash string, usedAt time.Time) model.Response[bool] {
+	args := m.Called(reqCtx, userID, codeHash, usedAt)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockMFARepo) Delete(reqCtx *model.RequestContext, userID uint) model.Response[bool] {
+	args := m.Called(reqCtx, userID)
+	return args.Get(0).(model.Response[bool])
+}
+
+// MockIdentityRepo is a mock implementation of IdentityRepo
+type MockIdentityRepo struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepo) Get(reqCtx *model.RequestContext, provider, subject string) model.Response[*model.UserIdentity] {
+	args := m.Called(reqCtx, provider, subject)
+	return args.Get(0).(model.Response[*model.UserIdentity])
+}
+
+func (m *MockIdentityRepo) CreateWithUser(reqCtx *model.RequestContext, user *model.User, identity *model.UserIdentity) model.Response[*model.UserIdentity] {
+	args := m.Called(reqCtx, user, identity)
+	return args.Get(0).(model.Response[*model.UserIdentity])
+}
+
+func (m *MockIdentityRepo) RecordLogin(reqCtx *model.RequestContext, id uint, email string, at time.Time) model.Response[bool] {
+	args := m.Called(reqCtx, id, email, at)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockIdentityRepo) SaveLoginState(reqCtx *model.RequestContext, state *model.OIDCLoginState) model.Response[*model.OIDCLoginState] {
+	args := m.Called(reqCtx, state)
+	return args.Get(0).(model.Response[*model.OIDCLoginState])
+}
+
+func (m *MockIdentityRepo) ConsumeLoginState(reqCtx *model.RequestContext, stateHash string, now time.Time) model.Response[*model.OIDCLoginState] {
+	args := m.Called(reqCtx, stateHash, now)
+	return args.Get(0).(model.Response[*model.OIDCLoginState])
+}
+
+// MockBlockRepo is a mock implementation of BlockRepo
+type MockBlockRepo struct {
+	mock.Mock
+}
+
+func (m *MockBlockRepo) Block(reqCtx *model.RequestContext, blockerID, blockedID uint) model.Response[*model.UserBlock] {
+	args := m.Called(reqCtx, blockerID, blockedID)
+	return args.Get(0).(model.Response[*model.UserBlock])
+}
+
+func (m *MockBlockRepo) Unblock(reqCtx *model.RequestContext, blockerID, blockedID uint) model.Response[bool] {
+	args := m.Called(reqCtx, blockerID, blockedID)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockBlockRepo) ListBlocked(reqCtx *model.RequestContext, blockerID uint) model.Response[[]*model.UserBlock] {
+	args := m.Called(reqCtx, blockerID)
+	return args.Get(0).(model.Response[[]*model.UserBlock])
+}
+
+func (m *MockBlockRepo) IsBlockedBetween(reqCtx *model.RequestContext, userID, otherID uint) model.Response[bool] {
+	args := m.Called(reqCtx, userID, otherID)
+	return args.Get(0).(model.Response[bool])
+}
+
+// MockOutboxRepo is a mock implementation of OutboxRepo
+type MockOutboxRepo struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepo) ListDue(reqCtx *model.RequestContext, now time.Time, limit int) model.Response[[]*model.OutboxEvent] {
+	args := m.Called(reqCtx, now, limit)
+	return args.Get(0).(model.Response[[]*model.OutboxEvent])
+}
+
+func (m *MockOutboxRepo) HasPendingBefore(reqCtx *model.RequestContext, event *model.OutboxEvent) model.Response[bool] {
+	args := m.Called(reqCtx, event)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockOutboxRepo) Claim(reqCtx *model.RequestContext, event *model.OutboxEvent, leaseUntil time.Time) model.Response[bool] {
+	args := m.Called(reqCtx, event, leaseUntil)
+	response := args.Get(0).(model.Response[bool])
+	// Like the repository, a won claim counts an attempt
+	if response.OK() && response.Data {
+		event.Attempts++
+		event.NextAttemptAt = leaseUntil
+	}
+	return response
+}
+
+func (m *MockOutboxRepo) MarkDelivered(reqCtx *model.RequestContext, id uint, deliveredAt time.Time) model.Response[bool] {
+	args := m.Called(reqCtx, id, deliveredAt)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockOutboxRepo) MarkRetry(reqCtx *model.RequestContext, id uint, nextAttemptAt time.Time, lastError string) model.Response[bool] {
+	args := m.Called(reqCtx, id, nextAttemptAt, lastError)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockOutboxRepo) MarkFailed(reqCtx *model.RequestContext, id uint, failedAt time.Time, lastError string) model.Response[bool] {
+	args := m.Called(reqCtx, id, failedAt, lastError)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockOutboxRepo) PurgeDelivered(reqCtx *model.RequestContext, before time.Time) model.Response[int64] {
+	args := m.Called(reqCtx, before)
+	return args.Get(0).(model.Response[int64])
+}
+
+// MockSessionRepo is a mock implementation of SessionRepo
+type MockSessionRepo struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepo) Create(reqCtx *model.RequestContext, session *model.Session) model.Response[*model.Session] {
+	args := m.Called(reqCtx, session)
+	return args.Get(0).(model.Response[*model.Session])
+}
+
+func (m *MockSessionRepo) GetByID(reqCtx *model.RequestContext, sessionID string) model.Response[*model.Session] {
+	args := m.Called(reqCtx, sessionID)
+	return args.Get(0).(model.Response[*model.Session])
+}
+
+func (m *MockSessionRepo) ListActiveByUser(reqCtx *model.RequestContext, userID uint, now time.Time) model.Response[[]*model.Session] {
+	args := m.Called(reqCtx, userID, now)
+	return args.Get(0).(model.Response[[]*model.Session])
+}
+
+func (m *MockSessionRepo) GetByRefreshTokenHash(reqCtx *model.RequestContext, tokenHash string) model.Response[*model.Session] {
+	args := m.Called(reqCtx, tokenHash)
+	return args.Get(0).(model.Response[*model.Session])
+}
+
+func (m *MockSessionRepo) GetRotated(reqCtx *model.RequestContext, tokenHash string) model.Response[*model.RotatedRefreshToken] {
+	args := m.Called(reqCtx, tokenHash)
+	return args.Get(0).(model.Response[*model.RotatedRefreshToken])
+}
+
+func (m *MockSessionRepo) Rotate(reqCtx *model.RequestContext, session *model.Session, tokenHash string, expiresAt time.Time) model.Response[*model.Session] {
+	args := m.Called(reqCtx, session, tokenHash, expiresAt)
+	return args.Get(0).(model.Response[*model.Session])
+}
+
+func (m *MockSessionRepo) Revoke(reqCtx *model.RequestContext, sessionID string) model.Response[bool] {
+	args := m.Called(reqCtx, sessionID)
+	return args.Get(0).(model.Response[bool])
+}
+
+func (m *MockSessionRepo) RevokeByUser(reqCtx *model.RequestContext, userID uint) model.Response[int64] {
+	args := m.Called(reqCtx, userID)
+	return args.Get(0).(model.Response[int64])
+}
+
 // Helper functions to create mocks with default return values
 // These can be used to simplify test setup when you need common default behaviors
 
@@ -165,13 +614,44 @@ func NewMockRepositoryWithDefaults() (*MockRepository, *MockUserRepo, *MockConve
 	mockConversationRepo := new(MockConversationRepo)
 	mockParticipantRepo := new(MockParticipantRepo)
 	mockMessageRepo := new(MockMessageRepo)
+	mockReactionRepo := new(MockReactionRepo)
+	mockAttachmentRepo := new(MockAttachmentRepo)
+	mockRevocationRepo := new(MockRevocationRepo)
+	mockSessionRepo := new(MockSessionRepo)
+	mockLoginAttemptRepo := new(MockLoginAttemptRepo)
+	mockMFARepo := new(MockMFARepo)
+	mockIdentityRepo := new(MockIdentityRepo)
+	mockBlockRepo := new(MockBlockRepo)
+	mockOutboxRepo := new(MockOutboxRepo)
 
 	mockRepo := &MockRepository{
 		UserRepo:        mockUserRepo,
 		ConversationRepo: mockConversationRepo,
 		ParticipantRepo:  mockParticipantRepo,
 		MessageRepo:      mockMessageRepo,
+		ReactionRepo:     mockReactionRepo,
+		AttachmentRepo:   mockAttachmentRepo,
+		RevocationRepo:   mockRevocationRepo,
+		SessionRepo:      mockSessionRepo,
+		LoginAttemptRepo: mockLoginAttemptRepo,
+		MFARepo:          mockMFARepo,
+		IdentityRepo:     mockIdentityRepo,
+		BlockRepo:        mockBlockRepo,
+		OutboxRepo:       mockOutboxRepo,
 	}
+	mockRepo.On("User").Return(mockUserRepo)
+	mockRepo.On("Conversation").Return(mockConversationRepo)
+	mockRepo.On("Participant").Return(mockParticipantRepo)
+	mockRepo.On("Message").Return(mockMessageRepo)
+	mockRepo.On("Reaction").Return(mockReactionRepo)
+	mockRepo.On("Attachment").Return(mockAttachmentRepo)
+	mockRepo.On("Revocation").Return(mockRevocationRepo)
+	mockRepo.On("Session").Return(mockSessionRepo)
+	mockRepo.On("LoginAttempt").Return(mockLoginAttemptRepo)
+	mockRepo.On("MFA").Return(mockMFARepo)
+	mockRepo.On("Identity").Return(mockIdentityRepo)
+	mockRepo.On("Block").Return(mockBlockRepo)
+	mockRepo.On("Outbox").Return(mockOutboxRepo)
 
 	return mockRepo, mockUserRepo, mockConversationRepo, mockParticipantRepo, mockMessageRepo
 }
@@ -226,6 +706,6 @@ func SetupMockMessageCreateSuccess(mockMessageRepo *MockMessageRepo, message *mo
 
 // SetupMockMessageGetByConversationIDSuccess sets up a successful GetByConversationID mock
 func SetupMockMessageGetByConversationIDSuccess(mockMessageRepo *MockMessageRepo, conversationID uint, messages []*model.Message) {
-	mockMessageRepo.On("GetByConversationID", mock.Anything, conversationID).
-		Return(model.SuccessResponse(messages, "Messages retrieved successfully"))
+	mockMessageRepo.On("GetByConversationID", mock.Anything, conversationID, mock.Anything).
+		Return(model.SuccessResponse(&model.Page[*model.Message]{Items: messages}, "Messages retrieved successfully"))
 }
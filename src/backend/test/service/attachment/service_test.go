@@ -0,0 +1,150 @@
+package attachment_test
+
+import (
+	"bytes"
+	"io"
+	"local/config"
+	"local/infra/provider/blob"
+	"local/model"
+	"local/service/attachment"
+	"local/service/common"
+	"local/service/conversation"
+	"local/test/mocks"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newAttachmentService(t *testing.T, mockRepo *mocks.MockRepository) (attachment.AttachmentService, blob.BlobStore) {
+	config.Config.AttachmentMaxBytes = 1 << 10
+	config.Config.AttachmentAllowedTypes = []string{"image/png", "text/plain"}
+	store, err := blob.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	params := &common.Params{Repo: mockRepo, Blob: store}
+	return attachment.NewAttachmentService(params, conversation.NewConversationService(params)), store
+}
+
+func TestAttachmentService_Upload(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+	member := model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 5}, "ok")
+
+	t.Run("stores the file with the sniffed type", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockAttachmentRepo := mockRepo.AttachmentRepo.(*mocks.MockAttachmentRepo)
+		svc, store := newAttachmentService(t, mockRepo)
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(4), uint(5)).Return(member)
+		mockAttachmentRepo.On("Create", reqCtx, mock.MatchedBy(func(a *model.Attachment) bool {
+			return a.ContentType == "image/png" && a.FileName == "cat.png" && a.Size == int64(len(pngHeader))
+		})).Return(model.SuccessResponse(&model.Attachment{ID: 1}, "ok"))
+
+		resp := svc.Upload(reqCtx, 4, 5, "../../etc/cat.png", int64(len(pngHeader)), bytes.NewReader(pngHeader))
+
+		assert.True(t, resp.OK())
+		stored := mockAttachmentRepo.Calls[0].Arguments.Get(1).(*model.Attachment)
+		body, err := store.Get(reqCtx.Context(), stored.StorageKey)
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(body)
+		body.Close()
+		assert.Equal(t, pngHeader, data)
+	})
+
+	t.Run("rejects disallowed types regardless of file name", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockAttachmentRepo := mockRepo.AttachmentRepo.(*mocks.MockAttachmentRepo)
+		svc, _ := newAttachmentService(t, mockRepo)
+		pdf := []byte("%PDF-1.4 fake")
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(4), uint(5)).Return(member)
+
+		resp := svc.Upload(reqCtx, 4, 5, "innocent.png", int64(len(pdf)), bytes.NewReader(pdf))
+
+		assert.Equal(t, model.CodeValidation, resp.Code)
+		mockAttachmentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects files over the size limit", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc, _ := newAttachmentService(t, mockRepo)
+		big := strings.Repeat("a", 2<<10)
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(4), uint(5)).Return(member)
+
+		resp := svc.Upload(reqCtx, 4, 5, "big.txt", int64(len(big)), strings.NewReader(big))
+
+		assert.Equal(t, model.CodeValidation, resp.Code)
+	})
+
+	t.Run("rejects non-members", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc, _ := newAttachmentService(t, mockRepo)
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(4), uint(7)).
+			Return(model.NotFound[*model.ConversationParticipant]("Participant not found"))
+
+		resp := svc.Upload(reqCtx, 4, 7, "cat.png", int64(len(pngHeader)), bytes.NewReader(pngHeader))
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+	})
+}
+
+func TestAttachmentService_Download(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+	messageID := uint(20)
+
+	t.Run("members can download sent attachments", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, mockMessageRepo := mocks.NewMockRepositoryWithDefaults()
+		mockAttachmentRepo := mockRepo.AttachmentRepo.(*mocks.MockAttachmentRepo)
+		svc, store := newAttachmentService(t, mockRepo)
+		assert.NoError(t, store.Put(reqCtx.Context(), "conversations/4/x", bytes.NewReader(pngHeader), int64(len(pngHeader)), "image/png"))
+
+		mockAttachmentRepo.On("GetByID", reqCtx, uint(1)).
+			Return(model.SuccessResponse(&model.Attachment{ID: 1, ConversationID: 4, UploaderID: 5, MessageID: &messageID, StorageKey: "conversations/4/x"}, "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(4), uint(6)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 6}, "ok"))
+		mockMessageRepo.On("GetByID", reqCtx, messageID).
+			Return(model.SuccessResponse(&model.Message{ID: messageID, ConversationID: 4}, "ok"))
+
+		resp := svc.Download(reqCtx, 1, 6)
+
+		assert.True(t, resp.OK())
+		data, _ := io.ReadAll(resp.Data.Body)
+		resp.Data.Body.Close()
+		assert.Equal(t, pngHeader, data)
+	})
+
+	t.Run("unsent attachments are private to the uploader", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockAttachmentRepo := mockRepo.AttachmentRepo.(*mocks.MockAttachmentRepo)
+		svc, _ := newAttachmentService(t, mockRepo)
+
+		mockAttachmentRepo.On("GetByID", reqCtx, uint(1)).
+			Return(model.SuccessResponse(&model.Attachment{ID: 1, ConversationID: 4, UploaderID: 5, StorageKey: "conversations/4/x"}, "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(4), uint(6)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 6}, "ok"))
+
+		resp := svc.Download(reqCtx, 1, 6)
+
+		assert.Equal(t, model.CodeNotFound, resp.Code)
+	})
+
+	t.Run("non-members are forbidden", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockAttachmentRepo := mockRepo.AttachmentRepo.(*mocks.MockAttachmentRepo)
+		svc, _ := newAttachmentService(t, mockRepo)
+
+		mockAttachmentRepo.On("GetByID", reqCtx, uint(1)).
+			Return(model.SuccessResponse(&model.Attachment{ID: 1, ConversationID: 4, UploaderID: 5, MessageID: &messageID}, "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(4), uint(9)).
+			Return(model.NotFound[*model.ConversationParticipant]("Participant not found"))
+
+		resp := svc.Download(reqCtx, 1, 9)
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+	})
+}
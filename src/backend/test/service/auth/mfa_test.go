@@ -0,0 +1,217 @@
+package auth_test
+
+import (
+	"local/infra/provider/totp"
+	"local/model"
+	"local/service/auth"
+	"local/test/mocks"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mfaSecret is a fixed TOTP key, so codes can be computed for the fixed clock
+const mfaSecret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+// mfaClock is a clock tests move by hand
+type mfaClock struct {
+	now time.Time
+}
+
+func (c *mfaClock) Now() time.Time {
+	return c.now
+}
+
+func newMFAClock() *mfaClock {
+	return &mfaClock{now: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)}
+}
+
+func codeAt(t *testing.T, at time.Time) string {
+	code, err := totp.Code(mfaSecret, at)
+	assert.NoError(t, err)
+	return code
+}
+
+func enabledMFA(lastUsedStep int64) *model.UserMFA {
+	enabledAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	return &model.UserMFA{UserID: 1, Secret: mfaSecret, EnabledAt: &enabledAt, LastUsedStep: lastUsedStep}
+}
+
+type mfaTestSetup struct {
+	clock       *mfaClock
+	svc         auth.AuthService
+	userRepo    *mocks.MockUserRepo
+	mfaRepo     *mocks.MockMFARepo
+	sessionRepo *mocks.MockSessionRepo
+}
+
+func newMFATestSetup(t *testing.T) *mfaTestSetup {
+	clock := newMFAClock()
+	mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+	hashedPassword, err := hashPassword("password123")
+	assert.NoError(t, err)
+	user := &model.User{ID: 1, UserName: "testuser", Password: hashedPassword}
+	mockUserRepo.On("QueryOne", mock.Anything, &model.User{UserName: "testuser"}).Return(model.SuccessResponse(user, "User found"))
+	mockUserRepo.On("QueryOne", mock.Anything, &model.User{ID: 1}).Return(model.SuccessResponse(user, "User found"))
+
+	return &mfaTestSetup{
+		clock:       clock,
+		svc:         auth.NewTestAuthServiceWithClock(mockRepo, testKeys, clock.Now),
+		userRepo:    mockUserRepo,
+		mfaRepo:     mockRepo.MFARepo.(*mocks.MockMFARepo),
+		sessionRepo: mockRepo.SessionRepo.(*mocks.MockSessionRepo),
+	}
+}
+
+// challenge logs in with the right password and returns the challenge token
+func (s *mfaTestSetup) challenge(t *testing.T) string {
+	response := s.svc.Login(&model.RequestContext{}, "testuser", "password123")
+	assert.Equal(t, model.CodeSuccess, response.Code)
+	assert.Equal(t, "Two-factor authentication required", response.Message)
+	assert.Empty(t, response.Data.AccessToken)
+	assert.Empty(t, response.Data.RefreshToken)
+	assert.NotEmpty(t, response.Data.MFAToken)
+	return response.Data.MFAToken
+}
+
+func TestAuthService_EnrollAndConfirmMFA(t *testing.T) {
+	setup := newMFATestSetup(t)
+	reqCtx := &model.RequestContext{}
+
+	var secret string
+	setup.mfaRepo.On("SavePending", mock.Anything, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		secret = args.String(2)
+	}).Return(model.SuccessResponse(&model.UserMFA{UserID: 1}, "Two-factor authentication enrollment started"))
+
+	enrollment := setup.svc.EnrollMFA(reqCtx, 1)
+	assert.Equal(t, model.CodeSuccess, enrollment.Code)
+	assert.Equal(t, secret, enrollment.Data.Secret)
+	assert.Contains(t, enrollment.Data.URI, "otpauth://totp/")
+	assert.Contains(t, enrollment.Data.URI, "secret="+secret)
+
+	// The pending secret is only switched on by a code it generated
+	setup.mfaRepo.On("Get", mock.Anything, uint(1)).Return(model.SuccessResponse(&model.UserMFA{UserID: 1, Secret: mfaSecret}, "Found"))
+	wrong := setup.svc.ConfirmMFA(reqCtx, 1, codeAt(t, setup.clock.now.Add(-10*totp.Period)))
+	assert.Equal(t, model.CodeValidation, wrong.Code)
+	setup.mfaRepo.AssertNotCalled(t, "Enable", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	var hashes []string
+	setup.mfaRepo.On("Enable", mock.Anything, uint(1), totp.Step(setup.clock.now), setup.clock.now, mock.Anything).Run(func(args mock.Arguments) {
+		hashes = args.Get(4).([]string)
+	}).Return(model.SuccessResponse(enabledMFA(totp.Step(setup.clock.now)), "Enabled"))
+
+	confirmed := setup.svc.ConfirmMFA(reqCtx, 1, codeAt(t, setup.clock.now))
+	assert.Equal(t, model.CodeSuccess, confirmed.Code)
+	assert.Len(t, confirmed.Data, 10)
+	assert.Len(t, hashes, 10)
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := map[string]bool{}
+	for i, code := range confirmed.Data {
+		assert.Regexp(t, format, code)
+		assert.False(t, seen[code], "recovery codes are unique")
+		seen[code] = true
+		// Only hashes are stored
+		assert.NotContains(t, hashes, code)
+		assert.Len(t, hashes[i], 64)
+	}
+}
+
+func TestAuthService_LoginWithMFA(t *testing.T) {
+	setup := newMFATestSetup(t)
+	setup.mfaRepo.On("Get", mock.Anything, uint(1)).Return(model.SuccessResponse(enabledMFA(0), "Found"))
+	setup.sessionRepo.On("Create", mock.Anything, mock.Anything).Return(model.SuccessResponse(&model.Session{}, "Session created"))
+
+	challenge := setup.challenge(t)
+	// The password alone opened no session
+	setup.sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// The challenge is not an access token
+	parsed := setup.svc.ParseToken(challenge)
+	assert.Equal(t, model.CodeUnauthorized, parsed.Code)
+
+	setup.clock.now = setup.clock.now.Add(time.Minute)
+	step := totp.Step(setup.clock.now)
+	setup.mfaRepo.On("UseStep", mock.Anything, uint(1), step).Return(model.SuccessResponse(true, "Recorded")).Once()
+
+	response := setup.svc.VerifyMFA(&model.RequestContext{}, challenge, codeAt(t, setup.clock.now))
+	assert.Equal(t, model.CodeSuccess, response.Code)
+	assert.NotEmpty(t, response.Data.AccessToken)
+	assert.NotEmpty(t, response.Data.RefreshToken)
+	assert.Empty(t, response.Data.MFAToken)
+	setup.sessionRepo.AssertNumberOfCalls(t, "Create", 1)
+
+	// A code whose step was already spent is refused
+	setup.mfaRepo.On("UseStep", mock.Anything, uint(1), step).Return(model.Conflict[bool]("Code has already been used")).Once()
+	replay := setup.svc.VerifyMFA(&model.RequestContext{}, challenge, codeAt(t, setup.clock.now))
+	assert.Equal(t, model.CodeUnauthorized, replay.Code)
+	assert.Equal(t, "Invalid code", replay.Message)
+	setup.sessionRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestAuthService_VerifyMFA_Rejects(t *testing.T) {
+	setup := newMFATestSetup(t)
+	setup.mfaRepo.On("Get", mock.Anything, uint(1)).Return(model.SuccessResponse(enabledMFA(0), "Found"))
+	challenge := setup.challenge(t)
+
+	t.Run("code from too long ago", func(t *testing.T) {
+		response := setup.svc.VerifyMFA(&model.RequestContext{}, challenge, codeAt(t, setup.clock.now.Add(-2*totp.Period)))
+		assert.Equal(t, model.CodeUnauthorized, response.Code)
+		assert.Equal(t, "Invalid code", response.Message)
+	})
+
+	t.Run("unknown recovery code", func(t *testing.T) {
+		setup.mfaRepo.On("UseRecoveryCode", mock.Anything, uint(1), mock.Anything, mock.Anything).Return(model.NotFound[bool]("Recovery code not found")).Once()
+		response := setup.svc.VerifyMFA(&model.RequestContext{}, challenge, "aaaaa-bbbbb")
+		assert.Equal(t, model.CodeUnauthorized, response.Code)
+	})
+
+	t.Run("access token instead of a challenge", func(t *testing.T) {
+		token, err := testKeys.Sign(&auth.TestJWTClaims{
+			SessionID: "session-1",
+			UserID:    1,
+			UserName:  "testuser",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(setup.clock.now.Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(setup.clock.now),
+			},
+		})
+		assert.NoError(t, err)
+		response := setup.svc.VerifyMFA(&model.RequestContext{}, token, codeAt(t, setup.clock.now))
+		assert.Equal(t, model.CodeUnauthorized, response.Code)
+		assert.Equal(t, "Invalid or expired challenge token", response.Message)
+	})
+
+	t.Run("expired challenge", func(t *testing.T) {
+		setup.clock.now = setup.clock.now.Add(6 * time.Minute)
+		response := setup.svc.VerifyMFA(&model.RequestContext{}, challenge, codeAt(t, setup.clock.now))
+		assert.Equal(t, model.CodeUnauthorized, response.Code)
+		assert.Equal(t, "Invalid or expired challenge token", response.Message)
+	})
+
+	setup.mfaRepo.AssertNotCalled(t, "UseStep", mock.Anything, mock.Anything, mock.Anything)
+	setup.sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_VerifyMFA_RecoveryCode(t *testing.T) {
+	setup := newMFATestSetup(t)
+	setup.mfaRepo.On("Get", mock.Anything, uint(1)).Return(model.SuccessResponse(enabledMFA(0), "Found"))
+	setup.sessionRepo.On("Create", mock.Anything, mock.Anything).Return(model.SuccessResponse(&model.Session{}, "Session created"))
+	challenge := setup.challenge(t)
+
+	// Codes match however they are typed, and are spent at the current time
+	var hash string
+	setup.mfaRepo.On("UseRecoveryCode", mock.Anything, uint(1), mock.Anything, setup.clock.now).Run(func(args mock.Arguments) {
+		hash = args.String(2)
+	}).Return(model.SuccessResponse(true, "Used")).Once()
+	response := setup.svc.VerifyMFA(&model.RequestContext{}, challenge, "ABCDE-fghij")
+	assert.Equal(t, model.CodeSuccess, response.Code)
+	assert.NotEmpty(t, response.Data.AccessToken)
+
+	setup.mfaRepo.On("UseRecoveryCode", mock.Anything, uint(1), hash, setup.clock.now).Return(model.NotFound[bool]("Recovery code not found")).Once()
+	response = setup.svc.VerifyMFA(&model.RequestContext{}, challenge, "abcde fghij")
+	assert.Equal(t, model.CodeUnauthorized, response.Code)
+}
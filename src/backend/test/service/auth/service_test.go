@@ -1,6 +1,8 @@
 package auth_test
 
 import (
+	"context"
+	"local/infra/provider/signing"
 	"local/model"
 	"local/service/auth"
 	"local/test/mocks"
@@ -14,6 +16,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// testKeys signs the tokens handed to the service under test
+var testKeys, _ = signing.Generate()
+
 
 func TestAuthService_Register(t *testing.T) {
 	tests := []struct {
@@ -92,7 +97,7 @@ func TestAuthService_Register(t *testing.T) {
 			mockUserRepo := new(mocks.MockUserRepo)
 			tt.setupMocks(mockRepo, mockUserRepo)
 
-			svc := auth.NewTestAuthService(mockRepo, "test-secret-key-for-testing-only")
+			svc := auth.NewTestAuthService(mockRepo, testKeys)
 
 			reqCtx := &model.RequestContext{}
 			response := svc.Register(reqCtx, tt.userName, tt.password)
@@ -137,6 +142,14 @@ func TestAuthService_Login(t *testing.T) {
 						Password: hashedPassword,
 					}, "User found"))
 				mockRepo.On("User").Return(mockUserRepo)
+				mockSessionRepo := new(mocks.MockSessionRepo)
+				mockSessionRepo.On("Create", mock.Anything, mock.MatchedBy(func(session *model.Session) bool {
+					return session.UserID == 1 && session.ID != "" && len(session.RefreshTokenHash) == 64
+				})).Return(model.SuccessResponse(&model.Session{}, "Session created"))
+				mockRepo.On("Session").Return(mockSessionRepo)
+				mockMFARepo := new(mocks.MockMFARepo)
+				mockMFARepo.On("Get", mock.Anything, uint(1)).Return(model.NotFound[*model.UserMFA]("Two-factor authentication is not set up"))
+				mockRepo.On("MFA").Return(mockMFARepo)
 			},
 			expectedCode: model.CodeSuccess,
 			expectedOK:   true,
@@ -192,7 +205,7 @@ func TestAuthService_Login(t *testing.T) {
 			mockUserRepo := new(mocks.MockUserRepo)
 			tt.setupMocks(mockRepo, mockUserRepo)
 
-			svc := auth.NewTestAuthService(mockRepo, "test-secret-key-for-testing-only")
+			svc := auth.NewTestAuthService(mockRepo, testKeys)
 
 			reqCtx := &model.RequestContext{}
 			response := svc.Login(reqCtx, tt.userName, tt.password)
@@ -203,7 +216,8 @@ func TestAuthService_Login(t *testing.T) {
 				assert.Contains(t, response.ErrorString(), tt.expectedError)
 			}
 			if tt.checkToken {
-				assert.NotEmpty(t, response.Data, "Token should be generated")
+				assert.NotEmpty(t, response.Data.AccessToken, "Token should be generated")
+				assert.NotEmpty(t, response.Data.RefreshToken, "Refresh token should be generated")
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -245,7 +259,7 @@ func TestAuthService_Authenticate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			svc := auth.NewTestAuthService(nil, "test-secret-key-for-testing-only")
+			svc := auth.NewTestAuthService(nil, testKeys)
 			tt.setupMocks(svc)
 
 			reqCtx := &model.RequestContext{Token: tt.token}
@@ -262,7 +276,6 @@ func TestAuthService_Authenticate(t *testing.T) {
 
 func TestAuthService_GetMe(t *testing.T) {
 	// Generate a valid token for testing
-	jwtSecret := "test-secret-key-for-testing-only"
 	claims := &auth.TestJWTClaims{
 		SessionID: uuid.New().String(),
 		UserID:    1,
@@ -272,8 +285,7 @@ func TestAuthService_GetMe(t *testing.T) {
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	validToken, _ := token.SignedString([]byte(jwtSecret))
+	validToken, _ := testKeys.Sign(claims)
 
 	tests := []struct {
 		name          string
@@ -323,7 +335,7 @@ func TestAuthService_GetMe(t *testing.T) {
 			mockUserRepo := new(mocks.MockUserRepo)
 			tt.setupMocks(mockRepo, mockUserRepo)
 
-			svc := auth.NewTestAuthService(mockRepo, jwtSecret)
+			svc := auth.NewTestAuthService(mockRepo, testKeys)
 
 			reqCtx := &model.RequestContext{Token: tt.token}
 			response := svc.GetMe(reqCtx)
@@ -342,6 +354,251 @@ func TestAuthService_GetMe(t *testing.T) {
 }
 
 // Helper functions
+func TestAuthService_Refresh(t *testing.T) {
+	activeSession := func() *model.Session {
+		return &model.Session{
+			ID:               "session-1",
+			UserID:           1,
+			RefreshTokenHash: "current-hash",
+			ExpiresAt:        time.Now().Add(time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		refreshToken  string
+		setupMocks    func(*mocks.MockUserRepo, *mocks.MockSessionRepo)
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:          "missing token",
+			refreshToken:  "",
+			setupMocks:    func(*mocks.MockUserRepo, *mocks.MockSessionRepo) {},
+			expectedCode:  model.CodeBadRequest,
+			expectedError: "Refresh token is required",
+		},
+		{
+			name:         "unknown token",
+			refreshToken: "made-up",
+			setupMocks: func(_ *mocks.MockUserRepo, sessionRepo *mocks.MockSessionRepo) {
+				sessionRepo.On("GetByRefreshTokenHash", mock.Anything, mock.Anything).Return(model.NotFound[*model.Session]("Session not found"))
+				sessionRepo.On("GetRotated", mock.Anything, mock.Anything).Return(model.NotFound[*model.RotatedRefreshToken]("Refresh token not found"))
+			},
+			expectedCode:  model.CodeUnauthorized,
+			expectedError: "Invalid refresh token",
+		},
+		{
+			name:         "rotated out token revokes the session",
+			refreshToken: "stolen",
+			setupMocks: func(_ *mocks.MockUserRepo, sessionRepo *mocks.MockSessionRepo) {
+				sessionRepo.On("GetByRefreshTokenHash", mock.Anything, mock.Anything).Return(model.NotFound[*model.Session]("Session not found"))
+				sessionRepo.On("GetRotated", mock.Anything, mock.Anything).Return(model.SuccessResponse(&model.RotatedRefreshToken{
+					SessionID: "session-1",
+					UserID:    1,
+				}, "Refresh token found"))
+				sessionRepo.On("Revoke", mock.Anything, "session-1").Return(model.SuccessResponse(true, "Session revoked"))
+			},
+			expectedCode:  model.CodeUnauthorized,
+			expectedError: "Refresh token reuse detected",
+		},
+		{
+			name:         "expired session",
+			refreshToken: "old",
+			setupMocks: func(_ *mocks.MockUserRepo, sessionRepo *mocks.MockSessionRepo) {
+				session := activeSession()
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+				sessionRepo.On("GetByRefreshTokenHash", mock.Anything, mock.Anything).Return(model.SuccessResponse(session, "Session found"))
+			},
+			expectedCode:  model.CodeUnauthorized,
+			expectedError: "Session has expired",
+		},
+		{
+			name:         "lost rotation race counts as reuse",
+			refreshToken: "raced",
+			setupMocks: func(userRepo *mocks.MockUserRepo, sessionRepo *mocks.MockSessionRepo) {
+				sessionRepo.On("GetByRefreshTokenHash", mock.Anything, mock.Anything).Return(model.SuccessResponse(activeSession(), "Session found"))
+				userRepo.On("QueryOne", mock.Anything, &model.User{ID: 1}).Return(model.SuccessResponse(&model.User{ID: 1, UserName: "testuser"}, "User found"))
+				sessionRepo.On("Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(model.Conflict[*model.Session]("Refresh token has already been used"))
+				sessionRepo.On("Revoke", mock.Anything, "session-1").Return(model.SuccessResponse(true, "Session revoked"))
+			},
+			expectedCode:  model.CodeUnauthorized,
+			expectedError: "Refresh token reuse detected",
+		},
+		{
+			name:         "successful rotation",
+			refreshToken: "current",
+			setupMocks: func(userRepo *mocks.MockUserRepo, sessionRepo *mocks.MockSessionRepo) {
+				sessionRepo.On("GetByRefreshTokenHash", mock.Anything, mock.Anything).Return(model.SuccessResponse(activeSession(), "Session found"))
+				userRepo.On("QueryOne", mock.Anything, &model.User{ID: 1}).Return(model.SuccessResponse(&model.User{ID: 1, UserName: "testuser"}, "User found"))
+				sessionRepo.On("Rotate", mock.Anything, mock.MatchedBy(func(session *model.Session) bool {
+					return session.ID == "session-1"
+				}), mock.MatchedBy(func(hash string) bool {
+					return len(hash) == 64 && hash != "current-hash"
+				}), mock.Anything).Return(model.SuccessResponse(activeSession(), "Refresh token rotated"))
+			},
+			expectedCode: model.CodeSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+			mockSessionRepo := mockRepo.SessionRepo.(*mocks.MockSessionRepo)
+			tt.setupMocks(mockUserRepo, mockSessionRepo)
+
+			svc := auth.NewTestAuthService(mockRepo, testKeys)
+			response := svc.Refresh(&model.RequestContext{}, tt.refreshToken)
+
+			assert.Equal(t, tt.expectedCode, response.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, response.ErrorString(), tt.expectedError)
+			}
+			if response.OK() {
+				assert.NotEmpty(t, response.Data.AccessToken)
+				assert.NotEmpty(t, response.Data.RefreshToken)
+				assert.NotEqual(t, tt.refreshToken, response.Data.RefreshToken)
+
+				// The new access token keeps the session it was refreshed from
+				parsed := svc.ParseToken(response.Data.AccessToken)
+				assert.True(t, parsed.OK())
+				assert.Equal(t, "session-1", parsed.Data.SessionID)
+			}
+
+			mockSessionRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_DeleteSession(t *testing.T) {
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name         string
+		session      model.Response[*model.Session]
+		expectRevoke bool
+		expectedCode int
+	}{
+		{
+			name:         "unknown session",
+			session:      model.NotFound[*model.Session]("Session not found"),
+			expectedCode: model.CodeNotFound,
+		},
+		{
+			name:         "session owned by another user",
+			session:      model.SuccessResponse(&model.Session{ID: "session-1", UserID: 2, ExpiresAt: time.Now().Add(time.Hour)}, "Session found"),
+			expectedCode: model.CodeNotFound,
+		},
+		{
+			name:         "already revoked",
+			session:      model.SuccessResponse(&model.Session{ID: "session-1", UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, "Session found"),
+			expectedCode: model.CodeNotFound,
+		},
+		{
+			name:         "own active session",
+			session:      model.SuccessResponse(&model.Session{ID: "session-1", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, "Session found"),
+			expectRevoke: true,
+			expectedCode: model.CodeSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, _, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+			mockSessionRepo := mockRepo.SessionRepo.(*mocks.MockSessionRepo)
+			mockSessionRepo.On("GetByID", mock.Anything, "session-1").Return(tt.session)
+			if tt.expectRevoke {
+				mockSessionRepo.On("Revoke", mock.Anything, "session-1").Return(model.SuccessResponse(true, "Session revoked"))
+			}
+
+			svc := auth.NewTestAuthService(mockRepo, testKeys)
+			response := svc.DeleteSession(&model.RequestContext{}, 1, "session-1")
+
+			assert.Equal(t, tt.expectedCode, response.Code)
+			mockSessionRepo.AssertExpectations(t)
+			if !tt.expectRevoke {
+				mockSessionRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	currentHash := hashPasswordForTest("password123")
+
+	tests := []struct {
+		name          string
+		oldPassword   string
+		newPassword   string
+		expectChange  bool
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:          "wrong current password",
+			oldPassword:   "wrongpassword",
+			newPassword:   "a much better passphrase",
+			expectedCode:  model.CodeForbidden,
+			expectedError: "Current password is incorrect",
+		},
+		{
+			name:          "unchanged password",
+			oldPassword:   "password123",
+			newPassword:   "password123",
+			expectedCode:  model.CodeValidation,
+			expectedError: "New password must differ from the current password",
+		},
+		{
+			name:          "new password too short",
+			oldPassword:   "password123",
+			newPassword:   "short",
+			expectedCode:  model.CodeValidation,
+			expectedError: "Password does not meet the requirements",
+		},
+		{
+			name:         "password changed",
+			oldPassword:  "password123",
+			newPassword:  "a much better passphrase",
+			expectChange: true,
+			expectedCode: model.CodeSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+			mockSessionRepo := mockRepo.SessionRepo.(*mocks.MockSessionRepo)
+			mockUserRepo.On("QueryOne", mock.Anything, &model.User{ID: 1}).
+				Return(model.SuccessResponse(&model.User{ID: 1, UserName: "testuser", Password: currentHash}, "User found"))
+			if tt.expectChange {
+				mockUserRepo.On("Update", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+					return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(tt.newPassword)) == nil
+				})).Return(model.SuccessResponse(&model.User{ID: 1}, "User updated successfully"))
+				mockSessionRepo.On("ListActiveByUser", mock.Anything, uint(1), mock.Anything).
+					Return(model.SuccessResponse([]*model.Session{{ID: "current", UserID: 1}, {ID: "other", UserID: 1}}, "Sessions found"))
+				mockSessionRepo.On("Revoke", mock.Anything, "other").Return(model.SuccessResponse(true, "Session revoked"))
+			}
+
+			svc := auth.NewTestAuthService(mockRepo, testKeys)
+			reqCtx := model.NewRequestContext(context.Background()).WithClaims("token", 1, "current")
+			response := svc.ChangePassword(reqCtx, 1, tt.oldPassword, tt.newPassword)
+
+			assert.Equal(t, tt.expectedCode, response.Code)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response.Message)
+			}
+			mockUserRepo.AssertExpectations(t)
+			mockSessionRepo.AssertExpectations(t)
+			// The session that made the change stays signed in
+			mockSessionRepo.AssertNotCalled(t, "Revoke", mock.Anything, "current")
+			if !tt.expectChange {
+				mockUserRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
 func hashPassword(password string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
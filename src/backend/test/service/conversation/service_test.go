@@ -1,11 +1,13 @@
 package conversation_test
 
 import (
+	"local/client"
 	"local/model"
 	"local/service/common"
 	"local/service/conversation"
 	"local/test/mocks"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,6 +48,7 @@ func TestConversationService_CreateConversation(t *testing.T) {
 	mockRepo := new(mocks.MockRepository)
 	mockConversationRepo := new(mocks.MockConversationRepo)
 	mockParticipantRepo := new(mocks.MockParticipantRepo)
+	mockBlockRepo := new(mocks.MockBlockRepo)
 	svc := conversation.NewConversationService(&common.Params{Repo: mockRepo})
 	reqCtx := &model.RequestContext{}
 
@@ -55,13 +58,30 @@ func TestConversationService_CreateConversation(t *testing.T) {
 		mockRepo.AssertNotCalled(t, "Conversation")
 	})
 
+	t.Run("rejects users who blocked each other", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockBlockRepo.ExpectedCalls = nil
+
+		mockRepo.On("Block").Return(mockBlockRepo)
+		mockBlockRepo.On("IsBlockedBetween", reqCtx, uint(1), uint(2)).Return(model.SuccessResponse(true, "ok"))
+
+		resp := svc.CreateConversation(reqCtx, []uint{1, 2})
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockBlockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Conversation")
+	})
+
 	t.Run("returns error when create fails", func(t *testing.T) {
 		mockRepo.ExpectedCalls = nil
 		mockConversationRepo.ExpectedCalls = nil
+		mockBlockRepo.ExpectedCalls = nil
 
 		mockRepo.On("Conversation").Return(mockConversationRepo)
-		mockConversationRepo.On("Create", reqCtx, mock.AnythingOfType("*model.Conversation")).
-			Return(model.BadRequest[*model.Conversation]("Failed to create conversation"))
+		mockRepo.On("Block").Return(mockBlockRepo)
+		mockBlockRepo.On("IsBlockedBetween", reqCtx, uint(1), uint(2)).Return(model.SuccessResponse(false, "ok"))
+		mockConversationRepo.On("GetOrCreatePrivate", reqCtx, mock.AnythingOfType("*model.Conversation")).
+			Return(model.BadRequest[bool]("Failed to create conversation"))
 
 		resp := svc.CreateConversation(reqCtx, []uint{1, 2})
 
@@ -74,13 +94,16 @@ func TestConversationService_CreateConversation(t *testing.T) {
 		mockRepo.ExpectedCalls = nil
 		mockConversationRepo.ExpectedCalls = nil
 		mockParticipantRepo.ExpectedCalls = nil
+		mockBlockRepo.ExpectedCalls = nil
 
 		mockRepo.On("Conversation").Return(mockConversationRepo)
 		mockRepo.On("Participant").Return(mockParticipantRepo)
+		mockRepo.On("Block").Return(mockBlockRepo)
+		mockBlockRepo.On("IsBlockedBetween", reqCtx, uint(1), uint(3)).Return(model.SuccessResponse(false, "ok"))
 
-		created := &model.Conversation{ID: 7}
-		mockConversationRepo.On("Create", reqCtx, mock.AnythingOfType("*model.Conversation")).
-			Return(model.SuccessResponse(created, "created"))
+		mockConversationRepo.On("GetOrCreatePrivate", reqCtx, mock.AnythingOfType("*model.Conversation")).
+			Run(func(args mock.Arguments) { args.Get(1).(*model.Conversation).ID = 7 }).
+			Return(model.SuccessResponse(true, "created"))
 		mockParticipantRepo.On("AddParticipantToConversation", reqCtx, uint(7), uint(1)).
 			Return(model.BadRequest[*model.ConversationParticipant]("fail"))
 
@@ -95,13 +118,18 @@ func TestConversationService_CreateConversation(t *testing.T) {
 		mockRepo.ExpectedCalls = nil
 		mockConversationRepo.ExpectedCalls = nil
 		mockParticipantRepo.ExpectedCalls = nil
+		mockBlockRepo.ExpectedCalls = nil
 
 		mockRepo.On("Conversation").Return(mockConversationRepo)
 		mockRepo.On("Participant").Return(mockParticipantRepo)
-
-		created := &model.Conversation{ID: 9}
-		mockConversationRepo.On("Create", reqCtx, mock.AnythingOfType("*model.Conversation")).
-			Return(model.SuccessResponse(created, "created"))
+		mockRepo.On("Block").Return(mockBlockRepo)
+		mockBlockRepo.On("IsBlockedBetween", reqCtx, uint(1), uint(2)).Return(model.SuccessResponse(false, "ok"))
+
+		mockConversationRepo.On("GetOrCreatePrivate", reqCtx, mock.MatchedBy(func(c *model.Conversation) bool {
+			return c.Type == model.ConversationTypePrivate && *c.EntityJoined == "user:1-user:2"
+		})).
+			Run(func(args mock.Arguments) { args.Get(1).(*model.Conversation).ID = 9 }).
+			Return(model.SuccessResponse(true, "created"))
 		mockParticipantRepo.On("AddParticipantToConversation", reqCtx, uint(9), uint(1)).
 			Return(model.SuccessResponse(&model.ConversationParticipant{}, "added"))
 		mockParticipantRepo.On("AddParticipantToConversation", reqCtx, uint(9), uint(2)).
@@ -116,24 +144,56 @@ func TestConversationService_CreateConversation(t *testing.T) {
 		mockConversationRepo.AssertExpectations(t)
 		mockParticipantRepo.AssertExpectations(t)
 	})
+
+	t.Run("returns the existing conversation of the pair", func(t *testing.T) {
+		mockRepo.ExpectedCalls = nil
+		mockConversationRepo.ExpectedCalls = nil
+		mockParticipantRepo.ExpectedCalls = nil
+		mockParticipantRepo.Calls = nil
+		mockBlockRepo.ExpectedCalls = nil
+
+		mockRepo.On("Conversation").Return(mockConversationRepo)
+		mockRepo.On("Participant").Return(mockParticipantRepo)
+		mockRepo.On("Block").Return(mockBlockRepo)
+		mockBlockRepo.On("IsBlockedBetween", reqCtx, uint(2), uint(1)).Return(model.SuccessResponse(false, "ok"))
+		mockConversationRepo.On("GetOrCreatePrivate", reqCtx, mock.AnythingOfType("*model.Conversation")).
+			Run(func(args mock.Arguments) { args.Get(1).(*model.Conversation).ID = 9 }).
+			Return(model.SuccessResponse(false, "exists"))
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 9}).
+			Return(model.SuccessResponse(&model.Conversation{ID: 9}, "ok"))
+
+		resp := svc.CreateConversation(reqCtx, []uint{2, 1})
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, uint(9), resp.Data.ID)
+		mockConversationRepo.AssertExpectations(t)
+		mockParticipantRepo.AssertNotCalled(t, "AddParticipantToConversation", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 func TestConversationService_GetUserConversations(t *testing.T) {
 	mockRepo := new(mocks.MockRepository)
 	mockConversationRepo := new(mocks.MockConversationRepo)
+	mockMessageRepo := new(mocks.MockMessageRepo)
 	svc := conversation.NewConversationService(&common.Params{Repo: mockRepo})
 	reqCtx := &model.RequestContext{}
 
 	mockRepo.On("Conversation").Return(mockConversationRepo)
-	mockConversationRepo.On("GetByParticipant", reqCtx, uint(5)).
-		Return(model.SuccessResponse([]*model.Conversation{{ID: 1}}, "ok"))
+	mockRepo.On("Message").Return(mockMessageRepo)
+	mockConversationRepo.On("GetByParticipant", reqCtx, uint(5), model.CursorParams{}).
+		Return(model.SuccessResponse(&model.Page[*model.Conversation]{Items: []*model.Conversation{{ID: 1}, {ID: 2}}}, "ok"))
+	mockMessageRepo.On("CountUnread", reqCtx, uint(5), []uint{1, 2}).
+		Return(model.SuccessResponse(map[uint]int64{2: 3}, "ok"))
 
-	resp := svc.GetUserConversations(reqCtx, 5)
+	resp := svc.GetUserConversations(reqCtx, 5, model.CursorParams{})
 
 	assert.True(t, resp.OK())
-	assert.Len(t, resp.Data, 1)
+	assert.Len(t, resp.Data.Items, 2)
+	assert.Equal(t, int64(0), resp.Data.Items[0].UnreadCount)
+	assert.Equal(t, int64(3), resp.Data.Items[1].UnreadCount)
 	mockRepo.AssertExpectations(t)
 	mockConversationRepo.AssertExpectations(t)
+	mockMessageRepo.AssertExpectations(t)
 }
 
 func TestConversationService_GetConversationByID(t *testing.T) {
@@ -153,3 +213,403 @@ func TestConversationService_GetConversationByID(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 	mockConversationRepo.AssertExpectations(t)
 }
+
+func newGroupConversationService(mockRepo *mocks.MockRepository, mockSocket *mocks.MockSocketClient) conversation.ConversationService {
+	return conversation.NewConversationService(&common.Params{
+		Repo:   mockRepo,
+		Client: &client.Client{SocketClient: mockSocket},
+	})
+}
+
+func TestConversationService_CreateGroupConversation(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("requires a name", func(t *testing.T) {
+		mockRepo, _, mockConversationRepo, _, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newGroupConversationService(mockRepo, new(mocks.MockSocketClient))
+
+		resp := svc.CreateGroupConversation(reqCtx, 1, "", []uint{2, 3})
+
+		assert.Equal(t, model.CodeBadRequest, resp.Code)
+		mockConversationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects users that do not exist", func(t *testing.T) {
+		mockRepo, mockUserRepo, mockConversationRepo, _, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newGroupConversationService(mockRepo, mockSocket)
+
+		mockUserRepo.On("GetByIDs", reqCtx, []uint{1, 2, 98, 99}).
+			Return(model.SuccessResponse([]*model.User{{ID: 1}, {ID: 2}}, "ok"))
+
+		resp := svc.CreateGroupConversation(reqCtx, 1, "team", []uint{2, 98, 99})
+
+		assert.Equal(t, model.CodeValidation, resp.Code)
+		assert.Equal(t, []model.Error{
+			{Code: model.CodeValidation, Message: "User 98 not found"},
+			{Code: model.CodeValidation, Message: "User 99 not found"},
+		}, resp.Errors)
+		mockConversationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	})
+
+	t.Run("creates owner and members and broadcasts member_joined", func(t *testing.T) {
+		mockRepo, mockUserRepo, mockConversationRepo, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newGroupConversationService(mockRepo, mockSocket)
+
+		mockUserRepo.On("GetByIDs", reqCtx, []uint{1, 2, 3}).
+			Return(model.SuccessResponse([]*model.User{{ID: 1}, {ID: 2}, {ID: 3}}, "ok"))
+		mockConversationRepo.On("Create", reqCtx, mock.MatchedBy(func(c *model.Conversation) bool {
+			return c.Type == model.ConversationTypeGroup && c.Name == "team" && len(c.UserIds) == 3
+		})).Return(model.SuccessResponse(&model.Conversation{ID: 4}, "created"))
+		mockParticipantRepo.On("Create", reqCtx, mock.MatchedBy(func(p *model.ConversationParticipant) bool {
+			return p.UserID == 1 && p.Role == model.ParticipantRoleOwner
+		})).Return(model.SuccessResponse(&model.ConversationParticipant{}, "created")).Once()
+		mockParticipantRepo.On("Create", reqCtx, mock.MatchedBy(func(p *model.ConversationParticipant) bool {
+			return p.UserID != 1 && p.Role == model.ParticipantRoleMember
+		})).Return(model.SuccessResponse(&model.ConversationParticipant{}, "created")).Twice()
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 4}).
+			Return(model.SuccessResponse(&model.Conversation{
+				ID:   4,
+				Type: model.ConversationTypeGroup,
+				Participants: []*model.ConversationParticipant{
+					{UserID: 1}, {UserID: 2}, {UserID: 3},
+				},
+			}, "ok"))
+		mockSocket.On("Broadcast", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			return b.Event == "member_joined" && len(b.UserIds) == 3
+		})).Return()
+
+		resp := svc.CreateGroupConversation(reqCtx, 1, "team", []uint{2, 3, 2})
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, uint(4), resp.Data.ID)
+		mockConversationRepo.AssertExpectations(t)
+		mockParticipantRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+	})
+}
+
+func TestConversationService_AddParticipants(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+	group := func() *model.Conversation {
+		return &model.Conversation{
+			ID:      5,
+			Type:    model.ConversationTypeGroup,
+			UserIds: model.UserIds{1, 2},
+			Participants: []*model.ConversationParticipant{
+				{UserID: 1, Role: model.ParticipantRoleOwner},
+				{UserID: 2, Role: model.ParticipantRoleMember},
+			},
+		}
+	}
+
+	t.Run("rejects private conversations", func(t *testing.T) {
+		mockRepo, _, mockConversationRepo, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newGroupConversationService(mockRepo, new(mocks.MockSocketClient))
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(5), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, Role: model.ParticipantRoleMember}, "ok"))
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 5}).
+			Return(model.SuccessResponse(&model.Conversation{ID: 5, Type: model.ConversationTypePrivate}, "ok"))
+
+		resp := svc.AddParticipants(reqCtx, 5, 1, []uint{3})
+
+		assert.Equal(t, model.CodeBadRequest, resp.Code)
+		mockParticipantRepo.AssertNotCalled(t, "AddParticipantToConversation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("forbids plain members", func(t *testing.T) {
+		mockRepo, _, mockConversationRepo, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newGroupConversationService(mockRepo, new(mocks.MockSocketClient))
+
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 5}).
+			Return(model.SuccessResponse(group(), "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(5), uint(2)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 2, Role: model.ParticipantRoleMember}, "ok"))
+
+		resp := svc.AddParticipants(reqCtx, 5, 2, []uint{3})
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockParticipantRepo.AssertNotCalled(t, "AddParticipantToConversation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects users that do not exist", func(t *testing.T) {
+		mockRepo, mockUserRepo, mockConversationRepo, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newGroupConversationService(mockRepo, mockSocket)
+
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 5}).
+			Return(model.SuccessResponse(group(), "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(5), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, Role: model.ParticipantRoleOwner}, "ok"))
+		mockUserRepo.On("GetByIDs", reqCtx, []uint{3, 99}).
+			Return(model.SuccessResponse([]*model.User{{ID: 3}}, "ok"))
+
+		resp := svc.AddParticipants(reqCtx, 5, 1, []uint{3, 99})
+
+		assert.Equal(t, model.CodeValidation, resp.Code)
+		assert.Equal(t, []model.Error{{Code: model.CodeValidation, Message: "User 99 not found"}}, resp.Errors)
+		mockParticipantRepo.AssertNotCalled(t, "AddParticipantToConversation", mock.Anything, mock.Anything, mock.Anything)
+		mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	})
+
+	t.Run("owner adds new members only", func(t *testing.T) {
+		mockRepo, mockUserRepo, mockConversationRepo, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newGroupConversationService(mockRepo, mockSocket)
+
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 5}).
+			Return(model.SuccessResponse(group(), "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(5), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, Role: model.ParticipantRoleOwner}, "ok"))
+		mockUserRepo.On("GetByIDs", reqCtx, []uint{3}).
+			Return(model.SuccessResponse([]*model.User{{ID: 3}}, "ok"))
+		mockParticipantRepo.On("AddParticipantToConversation", reqCtx, uint(5), uint(3)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 3}, "added"))
+		mockConversationRepo.On("Update", reqCtx, mock.MatchedBy(func(c *model.Conversation) bool {
+			return len(c.UserIds) == 3
+		})).Return(model.SuccessResponse(&model.Conversation{ID: 5}, "updated"))
+		mockSocket.On("Broadcast", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			return b.Event == "member_joined"
+		})).Return()
+
+		resp := svc.AddParticipants(reqCtx, 5, 1, []uint{2, 3})
+
+		assert.True(t, resp.OK())
+		mockParticipantRepo.AssertNumberOfCalls(t, "AddParticipantToConversation", 1)
+		mockSocket.AssertExpectations(t)
+	})
+}
+
+func TestConversationService_RemoveParticipant(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+	group := &model.Conversation{ID: 6, Type: model.ConversationTypeGroup, UserIds: model.UserIds{1, 2, 3}}
+
+	t.Run("admins cannot remove the owner", func(t *testing.T) {
+		mockRepo, _, mockConversationRepo, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newGroupConversationService(mockRepo, new(mocks.MockSocketClient))
+
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 6}).
+			Return(model.SuccessResponse(group, "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(6), uint(2)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 2, Role: model.ParticipantRoleAdmin}, "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(6), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, Role: model.ParticipantRoleOwner}, "ok"))
+
+		resp := svc.RemoveParticipant(reqCtx, 6, 2, 1)
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockParticipantRepo.AssertNotCalled(t, "RemoveParticipantFromConversation", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConversationService_LeaveConversation(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("owner leaving hands ownership to the first admin", func(t *testing.T) {
+		mockRepo, _, mockConversationRepo, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newGroupConversationService(mockRepo, mockSocket)
+
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 7}).
+			Return(model.SuccessResponse(&model.Conversation{
+				ID:      7,
+				Type:    model.ConversationTypeGroup,
+				UserIds: model.UserIds{1, 2, 3},
+				Participants: []*model.ConversationParticipant{
+					{ID: 1, UserID: 1, Role: model.ParticipantRoleOwner},
+					{ID: 2, UserID: 2, Role: model.ParticipantRoleMember},
+					{ID: 3, UserID: 3, Role: model.ParticipantRoleAdmin},
+				},
+			}, "ok"))
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(7), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, Role: model.ParticipantRoleOwner}, "ok"))
+		mockParticipantRepo.On("Update", reqCtx, mock.MatchedBy(func(p *model.ConversationParticipant) bool {
+			return p.UserID == 3 && p.Role == model.ParticipantRoleOwner
+		})).Return(model.SuccessResponse(&model.ConversationParticipant{}, "updated"))
+		mockParticipantRepo.On("RemoveParticipantFromConversation", reqCtx, uint(7), uint(1)).
+			Return(model.SuccessResponse("", "removed"))
+		mockConversationRepo.On("Update", reqCtx, mock.MatchedBy(func(c *model.Conversation) bool {
+			return len(c.UserIds) == 2
+		})).Return(model.SuccessResponse(&model.Conversation{ID: 7}, "updated"))
+		mockSocket.On("Broadcast", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			return b.Event == "member_left" && len(b.UserIds) == 3
+		})).Return()
+
+		resp := svc.LeaveConversation(reqCtx, 7, 1)
+
+		assert.True(t, resp.OK())
+		mockParticipantRepo.AssertExpectations(t)
+		mockConversationRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+	})
+}
+
+func TestConversationService_AuthorizeMember(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("members are allowed", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := conversation.NewConversationService(&common.Params{Repo: mockRepo})
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(8), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 8, UserID: 1}, "ok"))
+
+		resp := svc.AuthorizeMember(reqCtx, 8, 1)
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, uint(1), resp.Data.UserID)
+	})
+
+	t.Run("non-members are forbidden", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := conversation.NewConversationService(&common.Params{Repo: mockRepo})
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(8), uint(9)).
+			Return(model.NotFound[*model.ConversationParticipant]("Participant not found"))
+
+		resp := svc.AuthorizeMember(reqCtx, 8, 9)
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+	})
+
+	t.Run("non-members cannot list participants", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := conversation.NewConversationService(&common.Params{Repo: mockRepo})
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(8), uint(9)).
+			Return(model.NotFound[*model.ConversationParticipant]("Participant not found"))
+
+		resp := svc.GetParticipants(reqCtx, 8, 9)
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockParticipantRepo.AssertNotCalled(t, "GetByConversationID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestConversationService_MarkAsRead(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+	conversationData := &model.Conversation{
+		ID:            10,
+		LastMessageID: 42,
+		Participants: []*model.ConversationParticipant{
+			{UserID: 1},
+			{UserID: 2},
+		},
+	}
+
+	t.Run("defaults to the latest message and notifies others", func(t *testing.T) {
+		mockRepo, _, mockConversationRepo, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newGroupConversationService(mockRepo, mockSocket)
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(10), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1}, "ok"))
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 10}).
+			Return(model.SuccessResponse(conversationData, "ok"))
+		mockParticipantRepo.On("MarkRead", reqCtx, uint(10), uint(1), uint(42)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, LastReadMessageID: 42}, "ok"))
+		mockSocket.On("Broadcast", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			payload, ok := b.Payload.(map[string]interface{})
+			return b.Event == "read" &&
+				len(b.UserIds) == 1 &&
+				b.UserIds[0] == 2 &&
+				ok && payload["last_read_message_id"] == uint(42)
+		})).Return()
+
+		resp := svc.MarkAsRead(reqCtx, 10, 1, 0)
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, uint(42), resp.Data.LastReadMessageID)
+		mockParticipantRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+	})
+
+	t.Run("stays quiet when the marker does not advance", func(t *testing.T) {
+		mockRepo, _, mockConversationRepo, mockParticipantRepo, mockMessageRepo := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newGroupConversationService(mockRepo, mockSocket)
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(10), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, LastReadMessageID: 42}, "ok"))
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 10}).
+			Return(model.SuccessResponse(conversationData, "ok"))
+		mockMessageRepo.On("GetByID", reqCtx, uint(7)).
+			Return(model.SuccessResponse(&model.Message{ID: 7, ConversationID: 10}, "ok"))
+		mockParticipantRepo.On("MarkRead", reqCtx, uint(10), uint(1), uint(7)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, LastReadMessageID: 42}, "ok"))
+
+		resp := svc.MarkAsRead(reqCtx, 10, 1, 7)
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, uint(42), resp.Data.LastReadMessageID)
+		mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	})
+
+	t.Run("rejects messages from another conversation", func(t *testing.T) {
+		mockRepo, _, mockConversationRepo, mockParticipantRepo, mockMessageRepo := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newGroupConversationService(mockRepo, mockSocket)
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(10), uint(1)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1}, "ok"))
+		mockConversationRepo.On("QueryOne", reqCtx, &model.Conversation{ID: 10}).
+			Return(model.SuccessResponse(conversationData, "ok"))
+		mockMessageRepo.On("GetByID", reqCtx, uint(7)).
+			Return(model.SuccessResponse(&model.Message{ID: 7, ConversationID: 99}, "ok"))
+
+		resp := svc.MarkAsRead(reqCtx, 10, 1, 7)
+
+		assert.Equal(t, model.CodeBadRequest, resp.Code)
+		mockParticipantRepo.AssertNotCalled(t, "MarkRead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	})
+}
+
+func TestConversationService_MuteConversation(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+	member := model.SuccessResponse(&model.ConversationParticipant{ConversationID: 10, UserID: 1}, "ok")
+
+	t.Run("mutes indefinitely without an end", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newGroupConversationService(mockRepo, new(mocks.MockSocketClient))
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(10), uint(1)).Return(member)
+		mockParticipantRepo.On("SetMutedUntil", reqCtx, uint(10), uint(1), &model.MutedForever).
+			Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 1, MutedUntil: &model.MutedForever}, "ok"))
+
+		resp := svc.MuteConversation(reqCtx, 10, 1, nil)
+
+		assert.True(t, resp.OK())
+		assert.True(t, resp.Data.IsMuted(time.Now()))
+		mockParticipantRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an end in the past", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newGroupConversationService(mockRepo, new(mocks.MockSocketClient))
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(10), uint(1)).Return(member)
+		past := time.Now().Add(-time.Minute)
+
+		resp := svc.MuteConversation(reqCtx, 10, 1, &past)
+
+		assert.Equal(t, model.CodeValidation, resp.Code)
+		mockParticipantRepo.AssertNotCalled(t, "SetMutedUntil", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("only members can mute", func(t *testing.T) {
+		mockRepo, _, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newGroupConversationService(mockRepo, new(mocks.MockSocketClient))
+
+		mockParticipantRepo.On("GetByConversationAndUser", reqCtx, uint(10), uint(3)).
+			Return(model.NotFound[*model.ConversationParticipant]("Participant not found"))
+
+		resp := svc.MuteConversation(reqCtx, 10, 3, nil)
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockParticipantRepo.AssertNotCalled(t, "SetMutedUntil", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -1,14 +1,23 @@
 package message_test
 
 import (
+	"context"
 	"local/client"
+	"local/infra/provider/events"
+	"local/infra/provider/search"
+	"local/infra/provider/signing"
+	"local/job/consumer"
 	"local/model"
 	"local/service/auth"
 	"local/service/common"
 	"local/service/conversation"
 	"local/service/message"
+	"local/service/outbox"
 	"local/test/mocks"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -23,9 +32,9 @@ func (m *MockConversationService) CreateConversation(reqCtx *model.RequestContex
 	return args.Get(0).(model.Response[*model.Conversation])
 }
 
-func (m *MockConversationService) GetUserConversations(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Conversation] {
-	args := m.Called(reqCtx, userID)
-	return args.Get(0).(model.Response[[]*model.Conversation])
+func (m *MockConversationService) GetUserConversations(reqCtx *model.RequestContext, userID uint, cursor model.CursorParams) model.Response[*model.Page[*model.Conversation]] {
+	args := m.Called(reqCtx, userID, cursor)
+	return args.Get(0).(model.Response[*model.Page[*model.Conversation]])
 }
 
 func (m *MockConversationService) GetConversationByUserIDs(reqCtx *model.RequestContext, userIDs []uint) model.Response[*model.Conversation] {
@@ -38,6 +47,56 @@ func (m *MockConversationService) GetConversationByID(reqCtx *model.RequestConte
 	return args.Get(0).(model.Response[*model.Conversation])
 }
 
+func (m *MockConversationService) CreateGroupConversation(reqCtx *model.RequestContext, ownerID uint, name string, userIDs []uint) model.Response[*model.Conversation] {
+	args := m.Called(reqCtx, ownerID, name, userIDs)
+	return args.Get(0).(model.Response[*model.Conversation])
+}
+
+func (m *MockConversationService) GetParticipants(reqCtx *model.RequestContext, conversationID, actorID uint) model.Response[[]*model.ConversationParticipant] {
+	args := m.Called(reqCtx, conversationID, actorID)
+	return args.Get(0).(model.Response[[]*model.ConversationParticipant])
+}
+
+func (m *MockConversationService) AddParticipants(reqCtx *model.RequestContext, conversationID, actorID uint, userIDs []uint) model.Response[*model.Conversation] {
+	args := m.Called(reqCtx, conversationID, actorID, userIDs)
+	return args.Get(0).(model.Response[*model.Conversation])
+}
+
+func (m *MockConversationService) RemoveParticipant(reqCtx *model.RequestContext, conversationID, actorID, userID uint) model.Response[string] {
+	args := m.Called(reqCtx, conversationID, actorID, userID)
+	return args.Get(0).(model.Response[string])
+}
+
+func (m *MockConversationService) PromoteParticipant(reqCtx *model.RequestContext, conversationID, actorID, userID uint) model.Response[*model.ConversationParticipant] {
+	args := m.Called(reqCtx, conversationID, actorID, userID)
+	return args.Get(0).(model.Response[*model.ConversationParticipant])
+}
+
+func (m *MockConversationService) LeaveConversation(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[string] {
+	args := m.Called(reqCtx, conversationID, userID)
+	return args.Get(0).(model.Response[string])
+}
+
+func (m *MockConversationService) MarkAsRead(reqCtx *model.RequestContext, conversationID, userID, messageID uint) model.Response[*model.ConversationParticipant] {
+	args := m.Called(reqCtx, conversationID, userID, messageID)
+	return args.Get(0).(model.Response[*model.ConversationParticipant])
+}
+
+func (m *MockConversationService) MuteConversation(reqCtx *model.RequestContext, conversationID, userID uint, until *time.Time) model.Response[*model.ConversationParticipant] {
+	args := m.Called(reqCtx, conversationID, userID, until)
+	return args.Get(0).(model.Response[*model.ConversationParticipant])
+}
+
+func (m *MockConversationService) UnmuteConversation(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant] {
+	args := m.Called(reqCtx, conversationID, userID)
+	return args.Get(0).(model.Response[*model.ConversationParticipant])
+}
+
+func (m *MockConversationService) AuthorizeMember(reqCtx *model.RequestContext, conversationID, userID uint) model.Response[*model.ConversationParticipant] {
+	args := m.Called(reqCtx, conversationID, userID)
+	return args.Get(0).(model.Response[*model.ConversationParticipant])
+}
+
 type MockAuthService struct{}
 
 func (m *MockAuthService) Authenticate(reqCtx *model.RequestContext) model.Response[uint] {
@@ -55,14 +114,50 @@ func (m *MockAuthService) GetMe(reqCtx *model.RequestContext) model.Response[*mo
 func (m *MockAuthService) Register(reqCtx *model.RequestContext, userName, password string) model.Response[*model.User] {
 	return model.Response[*model.User]{}
 }
-func (m *MockAuthService) Login(reqCtx *model.RequestContext, userName, password string) model.Response[string] {
-	return model.Response[string]{}
+func (m *MockAuthService) Login(reqCtx *model.RequestContext, userName, password string) model.Response[*auth.TokenPair] {
+	return model.Response[*auth.TokenPair]{}
+}
+func (m *MockAuthService) Refresh(reqCtx *model.RequestContext, refreshToken string) model.Response[*auth.TokenPair] {
+	return model.Response[*auth.TokenPair]{}
 }
 func (m *MockAuthService) Logout(reqCtx *model.RequestContext, token string) model.Response[string] {
 	return model.Response[string]{}
 }
-func (m *MockAuthService) GetUsers(reqCtx *model.RequestContext) model.Response[[]*model.User] {
-	return model.Response[[]*model.User]{}
+func (m *MockAuthService) LogoutAll(reqCtx *model.RequestContext, userID uint) model.Response[string] {
+	return model.Response[string]{}
+}
+func (m *MockAuthService) ListSessions(reqCtx *model.RequestContext, userID uint) model.Response[[]*model.Session] {
+	return model.Response[[]*model.Session]{}
+}
+func (m *MockAuthService) DeleteSession(reqCtx *model.RequestContext, userID uint, sessionID string) model.Response[string] {
+	return model.Response[string]{}
+}
+func (m *MockAuthService) ChangePassword(reqCtx *model.RequestContext, userID uint, oldPassword, newPassword string) model.Response[string] {
+	return model.Response[string]{}
+}
+func (m *MockAuthService) EnrollMFA(reqCtx *model.RequestContext, userID uint) model.Response[*auth.MFAEnrollment] {
+	return model.Response[*auth.MFAEnrollment]{}
+}
+func (m *MockAuthService) ConfirmMFA(reqCtx *model.RequestContext, userID uint, code string) model.Response[[]string] {
+	return model.Response[[]string]{}
+}
+func (m *MockAuthService) DisableMFA(reqCtx *model.RequestContext, userID uint, code string) model.Response[string] {
+	return model.Response[string]{}
+}
+func (m *MockAuthService) VerifyMFA(reqCtx *model.RequestContext, challenge, code string) model.Response[*auth.TokenPair] {
+	return model.Response[*auth.TokenPair]{}
+}
+func (m *MockAuthService) OIDCProviders(reqCtx *model.RequestContext) model.Response[[]*auth.OIDCProvider] {
+	return model.Response[[]*auth.OIDCProvider]{}
+}
+func (m *MockAuthService) StartOIDCLogin(reqCtx *model.RequestContext, provider string) model.Response[*auth.OIDCAuthorization] {
+	return model.Response[*auth.OIDCAuthorization]{}
+}
+func (m *MockAuthService) CompleteOIDCLogin(reqCtx *model.RequestContext, provider, state, code string) model.Response[*auth.TokenPair] {
+	return model.Response[*auth.TokenPair]{}
+}
+func (m *MockAuthService) JWKS(reqCtx *model.RequestContext) model.Response[*signing.JWKS] {
+	return model.Response[*signing.JWKS]{}
 }
 
 type MockSocketClient struct {
@@ -73,12 +168,41 @@ func (m *MockSocketClient) Broadcast(message *model.BroadcastMessage) {
 	m.Called(message)
 }
 
+func (m *MockSocketClient) Deliver(message *model.BroadcastMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockSocketClient) Disconnect(message *model.DisconnectMessage) {
+	m.Called(message)
+}
+
 func newMessageService(mockRepo *mocks.MockRepository, mockSocket *MockSocketClient, cvsSvc conversation.ConversationService) message.MessageService {
+	return newMessageServiceWith(mockRepo, mockSocket, cvsSvc, func(*common.Params) {})
+}
+
+// newMessageServiceWith lets a test add dependencies, such as a search index or an event publisher.
+// Deliveries go through a real outbox service whose claims always succeed.
+func newMessageServiceWith(mockRepo *mocks.MockRepository, mockSocket *MockSocketClient, cvsSvc conversation.ConversationService, configure func(params *common.Params)) message.MessageService {
 	params := &common.Params{
 		Repo:   mockRepo,
 		Client: &client.Client{SocketClient: mockSocket},
 	}
-	return message.NewMessageService(params, &MockAuthService{}, cvsSvc)
+	configure(params)
+	acceptOutbox(mockRepo)
+	return message.NewMessageService(params, &MockAuthService{}, cvsSvc, outbox.NewOutboxService(params))
+}
+
+func acceptOutbox(mockRepo *mocks.MockRepository) {
+	outboxRepo, ok := mockRepo.OutboxRepo.(*mocks.MockOutboxRepo)
+	if !ok {
+		outboxRepo = new(mocks.MockOutboxRepo)
+		mockRepo.OutboxRepo = outboxRepo
+		mockRepo.On("Outbox").Return(outboxRepo)
+	}
+	outboxRepo.On("HasPendingBefore", mock.Anything, mock.Anything).Return(model.SuccessResponse(false, "ok"))
+	outboxRepo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(model.SuccessResponse(true, "claimed"))
+	outboxRepo.On("MarkDelivered", mock.Anything, mock.Anything, mock.Anything).Return(model.SuccessResponse(true, "delivered"))
 }
 
 func TestMessageService_CreateMessage_CreateFails(t *testing.T) {
@@ -88,12 +212,17 @@ func TestMessageService_CreateMessage_CreateFails(t *testing.T) {
 	mockConversationService := new(MockConversationService)
 	mockSocket := new(MockSocketClient)
 
-	svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+	publisher := events.NewMemoryPublisher()
+	svc := newMessageServiceWith(mockRepo, mockSocket, mockConversationService, func(params *common.Params) { params.Events = publisher })
 	reqCtx := &model.RequestContext{}
 	msg := &model.Message{ConversationID: 1, SenderID: 2, Content: "hello", SessionID: "s1"}
 
 	mockRepo.On("Message").Return(mockMessageRepo)
 	mockRepo.On("Conversation").Return(mockConversationRepo)
+	mockConversationService.On("AuthorizeMember", reqCtx, uint(1), uint(2)).
+		Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 1, UserID: 2}, "ok"))
+	mockConversationService.On("GetConversationByID", reqCtx, uint(1)).
+		Return(model.SuccessResponse(&model.Conversation{ID: 1, Participants: []*model.ConversationParticipant{{UserID: 2}}}, "ok"))
 	mockMessageRepo.On("Create", reqCtx, msg).
 		Return(model.BadRequest[*model.Message]("create fail"))
 
@@ -102,8 +231,8 @@ func TestMessageService_CreateMessage_CreateFails(t *testing.T) {
 	assert.False(t, resp.OK())
 	assert.Equal(t, model.CodeBadRequest, resp.Code)
 	mockMessageRepo.AssertExpectations(t)
-	mockConversationService.AssertNotCalled(t, "GetConversationByID", mock.Anything, mock.Anything)
-	mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	mockSocket.AssertNotCalled(t, "Deliver", mock.Anything)
+	assert.Empty(t, publisher.Events())
 }
 
 func TestMessageService_CreateMessage_ConversationMissing(t *testing.T) {
@@ -117,12 +246,10 @@ func TestMessageService_CreateMessage_ConversationMissing(t *testing.T) {
 	reqCtx := &model.RequestContext{}
 	msg := &model.Message{ConversationID: 2, SenderID: 3, Content: "hello"}
 
-	created := &model.Message{ID: 10, ConversationID: 2, SenderID: 3, Content: "hello"}
-
 	mockRepo.On("Message").Return(mockMessageRepo)
 	mockRepo.On("Conversation").Return(mockConversationRepo)
-	mockMessageRepo.On("Create", reqCtx, msg).
-		Return(model.SuccessResponse(created, "created"))
+	mockConversationService.On("AuthorizeMember", reqCtx, uint(2), uint(3)).
+		Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 2, UserID: 3}, "ok"))
 	mockConversationService.On("GetConversationByID", reqCtx, uint(2)).
 		Return(model.NotFound[*model.Conversation]("Conversation not found"))
 
@@ -131,95 +258,152 @@ func TestMessageService_CreateMessage_ConversationMissing(t *testing.T) {
 	assert.False(t, resp.OK())
 	assert.Equal(t, model.CodeBadRequest, resp.Code)
 	assert.Contains(t, resp.ErrorString(), "Conversation not found")
-	mockMessageRepo.AssertExpectations(t)
+	mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 	mockConversationService.AssertExpectations(t)
-	mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	mockSocket.AssertNotCalled(t, "Deliver", mock.Anything)
 }
 
-func TestMessageService_CreateMessage_UpdateFails(t *testing.T) {
+func TestMessageService_CreateMessage_Success(t *testing.T) {
 	mockRepo := new(mocks.MockRepository)
 	mockMessageRepo := new(mocks.MockMessageRepo)
 	mockConversationRepo := new(mocks.MockConversationRepo)
+	mockParticipantRepo := new(mocks.MockParticipantRepo)
 	mockConversationService := new(MockConversationService)
 	mockSocket := new(MockSocketClient)
 
-	svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+	publisher := events.NewMemoryPublisher()
+	svc := newMessageServiceWith(mockRepo, mockSocket, mockConversationService, func(params *common.Params) { params.Events = publisher })
 	reqCtx := &model.RequestContext{}
-	msg := &model.Message{ConversationID: 3, SenderID: 4, Content: "hi"}
+	msg := &model.Message{ConversationID: 4, SenderID: 5, Content: "content", SessionID: "session-1"}
 
-	created := &model.Message{ID: 11, ConversationID: 3, SenderID: 4, Content: "hi"}
-	conversationData := &model.Conversation{ID: 3}
+	created := &model.Message{ID: 20, ConversationID: 4, SenderID: 5, Content: "content", SessionID: "session-1"}
+	conversationData := &model.Conversation{
+		ID: 4,
+		Participants: []*model.ConversationParticipant{
+			{UserID: 1},
+			{UserID: 5},
+		},
+	}
 
 	mockRepo.On("Message").Return(mockMessageRepo)
 	mockRepo.On("Conversation").Return(mockConversationRepo)
+	mockRepo.On("Participant").Return(mockParticipantRepo)
+	mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).
+		Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 5}, "ok"))
 
 	mockMessageRepo.On("Create", reqCtx, msg).
 		Return(model.SuccessResponse(created, "created"))
-	mockConversationService.On("GetConversationByID", reqCtx, uint(3)).
+	mockConversationService.On("GetConversationByID", reqCtx, uint(4)).
 		Return(model.SuccessResponse(conversationData, "ok"))
-	mockConversationRepo.On("Update", reqCtx, mock.MatchedBy(func(c *model.Conversation) bool {
-		return c.LastMessageID == created.ID
-	})).Return(model.BadRequest[*model.Conversation]("Failed to update conversation"))
+	mockParticipantRepo.On("MarkRead", reqCtx, uint(4), uint(5), uint(20)).
+		Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 5, LastReadMessageID: 20}, "ok"))
+	mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+		return b.Event == "message" &&
+			len(b.UserIds) == 2 &&
+			b.UserIds[0] == 1 &&
+			b.UserIds[1] == 5 &&
+			b.SessionId == msg.SessionID
+	})).Return(nil)
 
 	resp := svc.CreateMessage(reqCtx, msg)
 
-	assert.False(t, resp.OK())
-	assert.Equal(t, model.CodeBadRequest, resp.Code)
-	assert.Contains(t, resp.ErrorString(), "Failed to update conversation")
+	assert.True(t, resp.OK())
+	assert.NotNil(t, resp.Data)
+	assert.Equal(t, uint(20), resp.Data.ID)
+	assert.NotNil(t, resp.Data.Conversation)
+	assert.Equal(t, uint(20), resp.Data.Conversation.LastMessageID)
+	assert.Equal(t, []events.PublishedEvent{{
+		Key:   "4",
+		Event: consumer.MessageEvent{Type: consumer.MessageEventCreated, MessageID: 20, ConversationID: 4, UserID: 5, Content: "content"},
+	}}, publisher.Events())
 	mockMessageRepo.AssertExpectations(t)
 	mockConversationService.AssertExpectations(t)
 	mockConversationRepo.AssertExpectations(t)
-	mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	mockParticipantRepo.AssertExpectations(t)
+	mockSocket.AssertExpectations(t)
 }
 
-func TestMessageService_CreateMessage_Success(t *testing.T) {
+func TestMessageService_CreateMessage_Blocked(t *testing.T) {
+	mockRepo := new(mocks.MockRepository)
+	mockMessageRepo := new(mocks.MockMessageRepo)
+	mockBlockRepo := new(mocks.MockBlockRepo)
+	mockConversationService := new(MockConversationService)
+	mockSocket := new(MockSocketClient)
+
+	svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+	reqCtx := &model.RequestContext{}
+	msg := &model.Message{ConversationID: 6, SenderID: 5, Content: "hello"}
+
+	mockRepo.On("Message").Return(mockMessageRepo)
+	mockRepo.On("Block").Return(mockBlockRepo)
+	mockConversationService.On("AuthorizeMember", reqCtx, uint(6), uint(5)).
+		Return(model.SuccessResponse(&model.ConversationParticipant{
+			ConversationID: 6,
+			UserID:         5,
+			Conversation:   model.Conversation{ID: 6, Type: model.ConversationTypePrivate, UserIds: model.UserIds{1, 5}},
+		}, "ok"))
+	mockBlockRepo.On("IsBlockedBetween", reqCtx, uint(5), uint(1)).Return(model.SuccessResponse(true, "ok"))
+
+	resp := svc.CreateMessage(reqCtx, msg)
+
+	assert.Equal(t, model.CodeForbidden, resp.Code)
+	mockBlockRepo.AssertExpectations(t)
+	mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockSocket.AssertNotCalled(t, "Deliver", mock.Anything)
+}
+
+func TestMessageService_CreateMessage_MutedParticipants(t *testing.T) {
 	mockRepo := new(mocks.MockRepository)
 	mockMessageRepo := new(mocks.MockMessageRepo)
 	mockConversationRepo := new(mocks.MockConversationRepo)
+	mockParticipantRepo := new(mocks.MockParticipantRepo)
 	mockConversationService := new(MockConversationService)
 	mockSocket := new(MockSocketClient)
 
 	svc := newMessageService(mockRepo, mockSocket, mockConversationService)
 	reqCtx := &model.RequestContext{}
-	msg := &model.Message{ConversationID: 4, SenderID: 5, Content: "content", SessionID: "session-1"}
+	msg := &model.Message{ConversationID: 7, SenderID: 5, Content: "content"}
 
-	created := &model.Message{ID: 20, ConversationID: 4, SenderID: 5, Content: "content", SessionID: "session-1"}
+	created := &model.Message{ID: 21, ConversationID: 7, SenderID: 5, Content: "content"}
+	later := time.Now().Add(time.Hour)
+	earlier := time.Now().Add(-time.Hour)
 	conversationData := &model.Conversation{
-		ID: 4,
+		ID:   7,
+		Type: model.ConversationTypeGroup,
 		Participants: []*model.ConversationParticipant{
-			{UserID: 1},
-			{UserID: 5},
+			{UserID: 1, MutedUntil: &later},
+			{UserID: 2, MutedUntil: &earlier},
+			{UserID: 5, MutedUntil: &later},
 		},
 	}
 
 	mockRepo.On("Message").Return(mockMessageRepo)
 	mockRepo.On("Conversation").Return(mockConversationRepo)
-
+	mockRepo.On("Participant").Return(mockParticipantRepo)
+	mockConversationService.On("AuthorizeMember", reqCtx, uint(7), uint(5)).
+		Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 7, UserID: 5, Conversation: *conversationData}, "ok"))
 	mockMessageRepo.On("Create", reqCtx, msg).
 		Return(model.SuccessResponse(created, "created"))
-	mockConversationService.On("GetConversationByID", reqCtx, uint(4)).
+	mockConversationService.On("GetConversationByID", reqCtx, uint(7)).
 		Return(model.SuccessResponse(conversationData, "ok"))
-	mockConversationRepo.On("Update", reqCtx, mock.MatchedBy(func(c *model.Conversation) bool {
-		return c.LastMessageID == created.ID
-	})).Return(model.SuccessResponse(conversationData, "updated"))
-	mockSocket.On("Broadcast", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
-		return b.Event == "message" &&
-			len(b.UserIds) == 2 &&
-			b.UserIds[0] == 1 &&
-			b.UserIds[1] == 5 &&
-			b.SessionId == msg.SessionID
-	})).Return()
+	mockParticipantRepo.On("MarkRead", reqCtx, uint(7), uint(5), uint(21)).
+		Return(model.SuccessResponse(&model.ConversationParticipant{UserID: 5, LastReadMessageID: 21}, "ok"))
+	// The sender's own mute never hides their message; an expired mute is over
+	mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+		payload := b.Payload.(map[string]interface{})
+		_, muted := payload["muted"]
+		return b.Event == "message" && !muted &&
+			len(b.UserIds) == 2 && b.UserIds[0] == 2 && b.UserIds[1] == 5
+	})).Return(nil).Once()
+	mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+		payload := b.Payload.(map[string]interface{})
+		return b.Event == "message" && payload["muted"] == true &&
+			len(b.UserIds) == 1 && b.UserIds[0] == 1
+	})).Return(nil).Once()
 
 	resp := svc.CreateMessage(reqCtx, msg)
 
 	assert.True(t, resp.OK())
-	assert.NotNil(t, resp.Data)
-	assert.Equal(t, uint(20), resp.Data.ID)
-	assert.NotNil(t, resp.Data.Conversation)
-	assert.Equal(t, uint(20), resp.Data.Conversation.LastMessageID)
-	mockMessageRepo.AssertExpectations(t)
-	mockConversationService.AssertExpectations(t)
-	mockConversationRepo.AssertExpectations(t)
 	mockSocket.AssertExpectations(t)
 }
 
@@ -227,19 +411,550 @@ func TestMessageService_GetMessagesByConversationID(t *testing.T) {
 	mockRepo := new(mocks.MockRepository)
 	mockMessageRepo := new(mocks.MockMessageRepo)
 	mockConversationRepo := new(mocks.MockConversationRepo)
+	mockConversationService := new(MockConversationService)
 	mockSocket := new(MockSocketClient)
 
-	svc := newMessageService(mockRepo, mockSocket, &MockConversationService{})
+	svc := newMessageService(mockRepo, mockSocket, mockConversationService)
 	reqCtx := &model.RequestContext{}
 
 	mockRepo.On("Message").Return(mockMessageRepo)
 	mockRepo.On("Conversation").Return(mockConversationRepo)
-	mockMessageRepo.On("GetByConversationID", reqCtx, uint(9)).
-		Return(model.SuccessResponse([]*model.Message{{ID: 1}}, "ok"))
+	mockConversationService.On("AuthorizeMember", reqCtx, uint(9), uint(3)).
+		Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 9, UserID: 3}, "ok"))
+	cursor := model.CursorParams{BeforeID: 20, Limit: 10}
+	mockMessageRepo.On("GetByConversationID", reqCtx, uint(9), uint(3), cursor).
+		Return(model.SuccessResponse(&model.Page[*model.Message]{Items: []*model.Message{{ID: 1}}}, "ok"))
 
-	resp := svc.GetMessagesByConversationID(reqCtx, 9)
+	resp := svc.GetMessagesByConversationID(reqCtx, 9, 3, cursor)
 
 	assert.True(t, resp.OK())
-	assert.Len(t, resp.Data, 1)
+	assert.Len(t, resp.Data.Items, 1)
 	mockMessageRepo.AssertExpectations(t)
 }
+
+func TestMessageService_CreateMessage_NotMember(t *testing.T) {
+	mockRepo := new(mocks.MockRepository)
+	mockMessageRepo := new(mocks.MockMessageRepo)
+	mockConversationService := new(MockConversationService)
+	mockSocket := new(MockSocketClient)
+
+	svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+	reqCtx := &model.RequestContext{}
+	msg := &model.Message{ConversationID: 5, SenderID: 6, Content: "intruder"}
+
+	mockRepo.On("Message").Return(mockMessageRepo)
+	mockConversationService.On("AuthorizeMember", reqCtx, uint(5), uint(6)).
+		Return(model.Forbidden[*model.ConversationParticipant]("You are not a member of this conversation"))
+
+	resp := svc.CreateMessage(reqCtx, msg)
+
+	assert.False(t, resp.OK())
+	assert.Equal(t, model.CodeForbidden, resp.Code)
+	mockConversationService.AssertExpectations(t)
+	mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockSocket.AssertNotCalled(t, "Deliver", mock.Anything)
+}
+
+func TestMessageService_GetMessagesByConversationID_NotMember(t *testing.T) {
+	mockRepo := new(mocks.MockRepository)
+	mockMessageRepo := new(mocks.MockMessageRepo)
+	mockConversationService := new(MockConversationService)
+	mockSocket := new(MockSocketClient)
+
+	svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+	reqCtx := &model.RequestContext{}
+
+	mockRepo.On("Message").Return(mockMessageRepo)
+	mockConversationService.On("AuthorizeMember", reqCtx, uint(9), uint(4)).
+		Return(model.Forbidden[*model.ConversationParticipant]("You are not a member of this conversation"))
+
+	resp := svc.GetMessagesByConversationID(reqCtx, 9, 4, model.CursorParams{})
+
+	assert.False(t, resp.OK())
+	assert.Equal(t, model.CodeForbidden, resp.Code)
+	mockConversationService.AssertExpectations(t)
+	mockMessageRepo.AssertNotCalled(t, "GetByConversationID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMessageService_EditMessage(t *testing.T) {
+	member := model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 5}, "ok")
+
+	t.Run("sender edits and participants are notified", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		mockSocket := new(MockSocketClient)
+		publisher := events.NewMemoryPublisher()
+		svc := newMessageServiceWith(mockRepo, mockSocket, mockConversationService, func(params *common.Params) { params.Events = publisher })
+		reqCtx := &model.RequestContext{}
+
+		original := &model.Message{ID: 20, ConversationID: 4, SenderID: 5, Content: "old"}
+		edited := &model.Message{ID: 20, ConversationID: 4, SenderID: 5, Content: "new"}
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockMessageRepo.On("GetByID", reqCtx, uint(20)).Return(model.SuccessResponse(original, "ok"))
+		mockMessageRepo.On("Edit", reqCtx, original, uint(5), "new").Return(model.SuccessResponse(edited, "ok"))
+		mockConversationService.On("GetConversationByID", reqCtx, uint(4)).
+			Return(model.SuccessResponse(&model.Conversation{ID: 4, Participants: []*model.ConversationParticipant{{UserID: 1}, {UserID: 5}}}, "ok"))
+		mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			return b.Event == "message_updated" && len(b.UserIds) == 2
+		})).Return(nil)
+
+		resp := svc.EditMessage(reqCtx, 4, 20, 5, "new")
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, "new", resp.Data.Content)
+		mockMessageRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+		assert.Len(t, publisher.Events(), 1)
+		assert.Equal(t, consumer.MessageEventEdited, publisher.Events()[0].Event.Type)
+		assert.Equal(t, "new", publisher.Events()[0].Event.Content)
+	})
+
+	t.Run("rejects non-sender", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		mockSocket := new(MockSocketClient)
+		svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockMessageRepo.On("GetByID", reqCtx, uint(20)).
+			Return(model.SuccessResponse(&model.Message{ID: 20, ConversationID: 4, SenderID: 1, Content: "theirs"}, "ok"))
+
+		resp := svc.EditMessage(reqCtx, 4, 20, 5, "mine now")
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockMessageRepo.AssertNotCalled(t, "Edit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockSocket.AssertNotCalled(t, "Deliver", mock.Anything)
+	})
+
+	t.Run("rejects message from another conversation", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		svc := newMessageService(mockRepo, new(MockSocketClient), mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockMessageRepo.On("GetByID", reqCtx, uint(20)).
+			Return(model.SuccessResponse(&model.Message{ID: 20, ConversationID: 99, SenderID: 5}, "ok"))
+
+		resp := svc.EditMessage(reqCtx, 4, 20, 5, "moved")
+
+		assert.Equal(t, model.CodeNotFound, resp.Code)
+	})
+
+	t.Run("rejects empty content", func(t *testing.T) {
+		mockConversationService := new(MockConversationService)
+		svc := newMessageService(new(mocks.MockRepository), new(MockSocketClient), mockConversationService)
+
+		resp := svc.EditMessage(&model.RequestContext{}, 4, 20, 5, "")
+
+		assert.Equal(t, model.CodeValidation, resp.Code)
+		mockConversationService.AssertNotCalled(t, "AuthorizeMember", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_DeleteMessage(t *testing.T) {
+	member := model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 5}, "ok")
+
+	t.Run("sender deletes and participants are notified", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		mockSocket := new(MockSocketClient)
+		publisher := events.NewMemoryPublisher()
+		svc := newMessageServiceWith(mockRepo, mockSocket, mockConversationService, func(params *common.Params) { params.Events = publisher })
+		reqCtx := &model.RequestContext{}
+
+		original := &model.Message{ID: 20, ConversationID: 4, SenderID: 5, Content: "oops"}
+		now := time.Now()
+		tombstone := &model.Message{ID: 20, ConversationID: 4, SenderID: 5, DeletedAt: &now}
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockMessageRepo.On("GetByID", reqCtx, uint(20)).Return(model.SuccessResponse(original, "ok"))
+		mockMessageRepo.On("SoftDelete", reqCtx, original).Return(model.SuccessResponse(tombstone, "ok"))
+		mockConversationService.On("GetConversationByID", reqCtx, uint(4)).
+			Return(model.SuccessResponse(&model.Conversation{ID: 4, Participants: []*model.ConversationParticipant{{UserID: 1}, {UserID: 5}}}, "ok"))
+		mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			return b.Event == "message_deleted" && len(b.UserIds) == 2
+		})).Return(nil)
+
+		resp := svc.DeleteMessage(reqCtx, 4, 20, 5)
+
+		assert.True(t, resp.OK())
+		assert.True(t, resp.Data.IsDeleted())
+		mockMessageRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+		assert.Len(t, publisher.Events(), 1)
+		assert.Equal(t, consumer.MessageEventDeleted, publisher.Events()[0].Event.Type)
+		assert.Empty(t, publisher.Events()[0].Event.Content)
+	})
+
+	t.Run("rejects already deleted message", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		svc := newMessageService(mockRepo, new(MockSocketClient), mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		now := time.Now()
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockMessageRepo.On("GetByID", reqCtx, uint(20)).
+			Return(model.SuccessResponse(&model.Message{ID: 20, ConversationID: 4, SenderID: 5, DeletedAt: &now}, "ok"))
+
+		resp := svc.DeleteMessage(reqCtx, 4, 20, 5)
+
+		assert.Equal(t, model.CodeNotFound, resp.Code)
+		mockMessageRepo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects non-member", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		svc := newMessageService(mockRepo, new(MockSocketClient), mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(7)).
+			Return(model.Forbidden[*model.ConversationParticipant]("You are not a member of this conversation"))
+
+		resp := svc.DeleteMessage(reqCtx, 4, 20, 7)
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockMessageRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_CreateMessage_Reply(t *testing.T) {
+	member := model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 5}, "ok")
+	uintPtr := func(v uint) *uint { return &v }
+
+	t.Run("rejects quoting a message from another conversation", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		svc := newMessageService(mockRepo, new(MockSocketClient), mockConversationService)
+		reqCtx := &model.RequestContext{}
+		msg := &model.Message{ConversationID: 4, SenderID: 5, Content: "re", ReplyToID: uintPtr(30)}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockMessageRepo.On("GetByID", reqCtx, uint(30)).
+			Return(model.SuccessResponse(&model.Message{ID: 30, ConversationID: 8, Content: "elsewhere"}, "ok"))
+
+		resp := svc.CreateMessage(reqCtx, msg)
+
+		assert.Equal(t, model.CodeValidation, resp.Code)
+		mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("flattens thread replies and broadcasts the quote preview", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationRepo := new(mocks.MockConversationRepo)
+		mockParticipantRepo := new(mocks.MockParticipantRepo)
+		mockConversationService := new(MockConversationService)
+		mockSocket := new(MockSocketClient)
+		svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+		reqCtx := &model.RequestContext{}
+		msg := &model.Message{ConversationID: 4, SenderID: 5, Content: "agreed", ReplyToID: uintPtr(31), ThreadRootID: uintPtr(31)}
+		quoted := &model.Message{ID: 31, ConversationID: 4, SenderID: 1, Content: strings.Repeat("a", 150), ThreadRootID: uintPtr(30)}
+		conversationData := &model.Conversation{ID: 4, Participants: []*model.ConversationParticipant{{UserID: 1}, {UserID: 5}}}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockRepo.On("Conversation").Return(mockConversationRepo)
+		mockRepo.On("Participant").Return(mockParticipantRepo)
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockMessageRepo.On("GetByID", reqCtx, uint(31)).Return(model.SuccessResponse(quoted, "ok"))
+		mockMessageRepo.On("Create", reqCtx, mock.MatchedBy(func(m *model.Message) bool {
+			return m.ThreadRootID != nil && *m.ThreadRootID == 30
+		})).Return(model.SuccessResponse(&model.Message{ID: 32, ConversationID: 4, SenderID: 5}, "created"))
+		mockConversationService.On("GetConversationByID", reqCtx, uint(4)).Return(model.SuccessResponse(conversationData, "ok"))
+		mockParticipantRepo.On("MarkRead", reqCtx, uint(4), uint(5), uint(32)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{}, "ok"))
+		mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			payload := b.Payload.(map[string]interface{})
+			preview, ok := payload["message"].(map[string]interface{})["reply_to"].(map[string]interface{})
+			return ok && preview["id"] == float64(31) &&
+				utf8.RuneCountInString(preview["content"].(string)) == model.MessagePreviewLength+1
+		})).Return(nil)
+
+		resp := svc.CreateMessage(reqCtx, msg)
+
+		assert.True(t, resp.OK())
+		assert.NotNil(t, resp.Data.ReplyTo)
+		mockMessageRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_GetThread(t *testing.T) {
+	uintPtr := func(v uint) *uint { return &v }
+	cursor := model.CursorParams{Limit: 10}
+
+	t.Run("resolves a reply to its thread root", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		svc := newMessageService(mockRepo, new(MockSocketClient), mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockMessageRepo.On("GetByID", reqCtx, uint(31)).
+			Return(model.SuccessResponse(&model.Message{ID: 31, ConversationID: 4, ThreadRootID: uintPtr(30)}, "ok"))
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).
+			Return(model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 5}, "ok"))
+		mockMessageRepo.On("GetThread", reqCtx, uint(30), uint(5), cursor).
+			Return(model.SuccessResponse(&model.Page[*model.Message]{Items: []*model.Message{{ID: 31}}}, "ok"))
+
+		resp := svc.GetThread(reqCtx, 31, 5, cursor)
+
+		assert.True(t, resp.OK())
+		assert.Len(t, resp.Data.Items, 1)
+		mockMessageRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects non-member", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockConversationService := new(MockConversationService)
+		svc := newMessageService(mockRepo, new(MockSocketClient), mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockMessageRepo.On("GetByID", reqCtx, uint(30)).
+			Return(model.SuccessResponse(&model.Message{ID: 30, ConversationID: 4}, "ok"))
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(7)).
+			Return(model.Forbidden[*model.ConversationParticipant]("You are not a member of this conversation"))
+
+		resp := svc.GetThread(reqCtx, 30, 7, cursor)
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockMessageRepo.AssertNotCalled(t, "GetThread", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_Reactions(t *testing.T) {
+	member := model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 5}, "ok")
+	conversationData := &model.Conversation{ID: 4, Participants: []*model.ConversationParticipant{{UserID: 1}, {UserID: 5}}}
+	target := &model.Message{ID: 20, ConversationID: 4, SenderID: 1, Content: "ship it"}
+
+	t.Run("adding a reaction notifies participants", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockReactionRepo := new(mocks.MockReactionRepo)
+		mockConversationService := new(MockConversationService)
+		mockSocket := new(MockSocketClient)
+		svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockRepo.On("Reaction").Return(mockReactionRepo)
+		mockMessageRepo.On("GetByID", reqCtx, uint(20)).Return(model.SuccessResponse(target, "ok"))
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockReactionRepo.On("Add", reqCtx, uint(20), uint(5), "👍").Return(model.SuccessResponse(true, "ok"))
+		mockReactionRepo.On("Summarize", reqCtx, []uint{20}, uint(5)).
+			Return(model.SuccessResponse(map[uint][]*model.ReactionSummary{20: {{Emoji: "👍", Count: 2, ReactedByMe: true}}}, "ok"))
+		mockConversationService.On("GetConversationByID", reqCtx, uint(4)).Return(model.SuccessResponse(conversationData, "ok"))
+		mockSocket.On("Broadcast", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			payload := b.Payload.(map[string]interface{})
+			reactions := payload["reactions"].([]*model.ReactionSummary)
+			return b.Event == "reaction_updated" && len(b.UserIds) == 2 &&
+				payload["added"] == true && reactions[0].Count == 2 && !reactions[0].ReactedByMe
+		})).Return()
+
+		resp := svc.AddReaction(reqCtx, 20, 5, "👍")
+
+		assert.True(t, resp.OK())
+		assert.Len(t, resp.Data, 1)
+		assert.True(t, resp.Data[0].ReactedByMe)
+		mockReactionRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+	})
+
+	t.Run("repeating a reaction does not broadcast", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockReactionRepo := new(mocks.MockReactionRepo)
+		mockConversationService := new(MockConversationService)
+		mockSocket := new(MockSocketClient)
+		svc := newMessageService(mockRepo, mockSocket, mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockRepo.On("Reaction").Return(mockReactionRepo)
+		mockMessageRepo.On("GetByID", reqCtx, uint(20)).Return(model.SuccessResponse(target, "ok"))
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+		mockReactionRepo.On("Remove", reqCtx, uint(20), uint(5), "👍").Return(model.SuccessResponse(false, "ok"))
+		mockReactionRepo.On("Summarize", reqCtx, []uint{20}, uint(5)).
+			Return(model.SuccessResponse(map[uint][]*model.ReactionSummary{}, "ok"))
+
+		resp := svc.RemoveReaction(reqCtx, 20, 5, "👍")
+
+		assert.True(t, resp.OK())
+		assert.Empty(t, resp.Data)
+		mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	})
+
+	t.Run("rejects non-member", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		mockMessageRepo := new(mocks.MockMessageRepo)
+		mockReactionRepo := new(mocks.MockReactionRepo)
+		mockConversationService := new(MockConversationService)
+		svc := newMessageService(mockRepo, new(MockSocketClient), mockConversationService)
+		reqCtx := &model.RequestContext{}
+
+		mockRepo.On("Message").Return(mockMessageRepo)
+		mockRepo.On("Reaction").Return(mockReactionRepo)
+		mockMessageRepo.On("GetByID", reqCtx, uint(20)).Return(model.SuccessResponse(target, "ok"))
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(7)).
+			Return(model.Forbidden[*model.ConversationParticipant]("You are not a member of this conversation"))
+
+		resp := svc.AddReaction(reqCtx, 20, 7, "👍")
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockReactionRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects invalid emoji", func(t *testing.T) {
+		mockRepo := new(mocks.MockRepository)
+		svc := newMessageService(mockRepo, new(MockSocketClient), new(MockConversationService))
+
+		for _, emoji := range []string{"", "  ", "two words", strings.Repeat("x", model.MaxEmojiLength+1)} {
+			resp := svc.AddReaction(&model.RequestContext{}, 20, 5, emoji)
+			assert.Equal(t, model.CodeValidation, resp.Code, emoji)
+		}
+		mockRepo.AssertNotCalled(t, "Message")
+	})
+}
+
+func TestMessageService_CreateMessage_Attachment(t *testing.T) {
+	member := model.SuccessResponse(&model.ConversationParticipant{ConversationID: 4, UserID: 5}, "ok")
+	attachmentID := uint(3)
+
+	tests := []struct {
+		name       string
+		message    *model.Message
+		attachment *model.Attachment
+	}{
+		{"unsupported type", &model.Message{ConversationID: 4, SenderID: 5, MessageType: "video"}, nil},
+		{"text with attachment", &model.Message{ConversationID: 4, SenderID: 5, AttachmentID: &attachmentID}, nil},
+		{"attachment without id", &model.Message{ConversationID: 4, SenderID: 5, MessageType: model.MessageTypeAttachment}, nil},
+		{"someone else's upload", &model.Message{ConversationID: 4, SenderID: 5, MessageType: model.MessageTypeAttachment, AttachmentID: &attachmentID},
+			&model.Attachment{ID: 3, ConversationID: 4, UploaderID: 1}},
+		{"upload from another conversation", &model.Message{ConversationID: 4, SenderID: 5, MessageType: model.MessageTypeAttachment, AttachmentID: &attachmentID},
+			&model.Attachment{ID: 3, ConversationID: 8, UploaderID: 5}},
+		{"already sent", &model.Message{ConversationID: 4, SenderID: 5, MessageType: model.MessageTypeAttachment, AttachmentID: &attachmentID},
+			&model.Attachment{ID: 3, ConversationID: 4, UploaderID: 5, MessageID: &attachmentID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, _, _, _, mockMessageRepo := mocks.NewMockRepositoryWithDefaults()
+			mockAttachmentRepo := mockRepo.AttachmentRepo.(*mocks.MockAttachmentRepo)
+			mockConversationService := new(MockConversationService)
+			svc := newMessageService(mockRepo, new(MockSocketClient), mockConversationService)
+			reqCtx := &model.RequestContext{}
+
+			mockConversationService.On("AuthorizeMember", reqCtx, uint(4), uint(5)).Return(member)
+			if tt.attachment != nil {
+				mockAttachmentRepo.On("GetByID", reqCtx, attachmentID).Return(model.SuccessResponse(tt.attachment, "ok"))
+			}
+
+			resp := svc.CreateMessage(reqCtx, tt.message)
+
+			assert.Equal(t, model.CodeValidation, resp.Code)
+			mockMessageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMessageService_SearchMessages(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	newSearch := func(t *testing.T) (message.MessageService, *mocks.MockMessageRepo, *mocks.MockParticipantRepo, *MockConversationService) {
+		index := search.NewEmbeddedIndex()
+		for _, doc := range []search.Document{
+			{MessageID: 1, ConversationID: 1, SenderID: 5, Content: "Deploy on Friday", CreatedAt: createdAt},
+			{MessageID: 2, ConversationID: 2, SenderID: 6, Content: "deploy failed", CreatedAt: createdAt},
+			{MessageID: 3, ConversationID: 3, SenderID: 7, Content: "deploy elsewhere", CreatedAt: createdAt},
+		} {
+			assert.NoError(t, index.Index(context.Background(), doc))
+		}
+		mockRepo, _, _, mockParticipantRepo, mockMessageRepo := mocks.NewMockRepositoryWithDefaults()
+		mockConversationService := new(MockConversationService)
+		svc := newMessageServiceWith(mockRepo, new(MockSocketClient), mockConversationService, func(params *common.Params) { params.Search = index })
+		return svc, mockMessageRepo, mockParticipantRepo, mockConversationService
+	}
+
+	t.Run("rejects invalid queries", func(t *testing.T) {
+		svc, mockMessageRepo, _, _ := newSearch(t)
+		from, to := createdAt, createdAt.Add(-time.Hour)
+		for _, params := range []model.MessageSearchParams{
+			{Query: "  "},
+			{Query: "?!"},
+			{Query: strings.Repeat("a", model.MaxMessageSearchLength+1)},
+			{Query: "deploy", From: &from, To: &to},
+		} {
+			resp := svc.SearchMessages(&model.RequestContext{}, 5, params)
+			assert.Equal(t, model.CodeValidation, resp.Code)
+		}
+		mockMessageRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("searches only the user's conversations", func(t *testing.T) {
+		svc, mockMessageRepo, mockParticipantRepo, _ := newSearch(t)
+		reqCtx := &model.RequestContext{}
+		deletedAt := time.Now()
+		mockParticipantRepo.On("GetByUserID", reqCtx, uint(5)).Return(model.SuccessResponse([]*model.ConversationParticipant{
+			{ConversationID: 1, UserID: 5},
+			{ConversationID: 2, UserID: 5},
+		}, "ok"))
+		mockMessageRepo.On("GetByIDs", reqCtx, []uint{2, 1}, uint(5)).Return(model.SuccessResponse([]*model.Message{
+			{ID: 2, ConversationID: 2, DeletedAt: &deletedAt},
+			{ID: 1, ConversationID: 1, Content: "Deploy on Friday"},
+		}, "ok"))
+
+		resp := svc.SearchMessages(reqCtx, 5, model.MessageSearchParams{Query: "deploy"})
+
+		assert.True(t, resp.OK())
+		assert.Len(t, resp.Data.Items, 1)
+		assert.Equal(t, uint(1), resp.Data.Items[0].Message.ID)
+		assert.Equal(t, "<mark>Deploy</mark> on Friday", resp.Data.Items[0].Highlight)
+		assert.False(t, resp.Data.HasMore)
+	})
+
+	t.Run("pages with a cursor", func(t *testing.T) {
+		svc, mockMessageRepo, mockParticipantRepo, _ := newSearch(t)
+		reqCtx := &model.RequestContext{}
+		mockParticipantRepo.On("GetByUserID", reqCtx, uint(5)).Return(model.SuccessResponse([]*model.ConversationParticipant{
+			{ConversationID: 1, UserID: 5},
+			{ConversationID: 2, UserID: 5},
+		}, "ok"))
+		mockMessageRepo.On("GetByIDs", reqCtx, []uint{2}, uint(5)).Return(model.SuccessResponse([]*model.Message{{ID: 2, Content: "deploy failed"}}, "ok"))
+
+		resp := svc.SearchMessages(reqCtx, 5, model.MessageSearchParams{Query: "deploy", Limit: 1})
+
+		assert.True(t, resp.OK())
+		assert.True(t, resp.Data.HasMore)
+		assert.Equal(t, uint(2), resp.Data.NextCursor)
+	})
+
+	t.Run("checks membership of the requested conversation", func(t *testing.T) {
+		svc, mockMessageRepo, _, mockConversationService := newSearch(t)
+		reqCtx := &model.RequestContext{}
+		mockConversationService.On("AuthorizeMember", reqCtx, uint(3), uint(5)).
+			Return(model.Forbidden[*model.ConversationParticipant]("You are not a member of this conversation"))
+
+		resp := svc.SearchMessages(reqCtx, 5, model.MessageSearchParams{Query: "deploy", ConversationID: 3})
+
+		assert.Equal(t, model.CodeForbidden, resp.Code)
+		mockMessageRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
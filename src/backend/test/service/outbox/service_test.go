@@ -0,0 +1,211 @@
+package outbox_test
+
+import (
+	"errors"
+	"local/client"
+	"local/config"
+	"local/infra/provider/events"
+	"local/job/consumer"
+	"local/model"
+	"local/service/common"
+	"local/service/outbox"
+	"local/test/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSocketClient struct {
+	mock.Mock
+}
+
+func (m *MockSocketClient) Broadcast(message *model.BroadcastMessage) {
+	m.Called(message)
+}
+
+func (m *MockSocketClient) Deliver(message *model.BroadcastMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockSocketClient) Disconnect(message *model.DisconnectMessage) {
+	m.Called(message)
+}
+
+var now = time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+func newOutboxService(mockSocket *MockSocketClient) (outbox.OutboxService, *mocks.MockOutboxRepo, *events.MemoryPublisher) {
+	config.Config.OutboxMaxAttempts = 3
+	mockRepo, _, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+	publisher := events.NewMemoryPublisher()
+	params := &common.Params{
+		Repo:   mockRepo,
+		Client: &client.Client{SocketClient: mockSocket},
+		Events: publisher,
+	}
+	return outbox.NewTestOutboxServiceWithClock(params, func() time.Time { return now }), mockRepo.OutboxRepo.(*mocks.MockOutboxRepo), publisher
+}
+
+// inOrder answers that no older delivery is pending for any of the deliveries
+func inOrder(outboxRepo *mocks.MockOutboxRepo) {
+	outboxRepo.On("HasPendingBefore", mock.Anything, mock.Anything).Return(model.SuccessResponse(false, "ok"))
+}
+
+func eventDelivery(t *testing.T, id uint, event consumer.MessageEvent) *model.OutboxEvent {
+	delivery, err := model.NewOutboxEvent(model.OutboxDestinationEvents, events.MessageEventKey(event), event)
+	assert.NoError(t, err)
+	delivery.ID = id
+	return delivery
+}
+
+func socketDelivery(t *testing.T, id uint, key string, broadcast *model.BroadcastMessage) *model.OutboxEvent {
+	delivery, err := model.NewOutboxEvent(model.OutboxDestinationSocket, key, broadcast)
+	assert.NoError(t, err)
+	delivery.ID = id
+	return delivery
+}
+
+func TestOutboxService_Dispatch(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("delivers to every destination and marks the deliveries done", func(t *testing.T) {
+		mockSocket := new(MockSocketClient)
+		svc, outboxRepo, publisher := newOutboxService(mockSocket)
+		event := consumer.MessageEvent{Type: consumer.MessageEventCreated, MessageID: 20, ConversationID: 4, UserID: 5, Content: "hi"}
+		deliveries := []*model.OutboxEvent{
+			eventDelivery(t, 1, event),
+			socketDelivery(t, 2, "4", &model.BroadcastMessage{UserIds: []int{1, 5}, Event: "message"}),
+		}
+
+		inOrder(outboxRepo)
+		outboxRepo.On("Claim", reqCtx, mock.Anything, now.Add(model.OutboxLease)).Return(model.SuccessResponse(true, "claimed"))
+		mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			return b.Event == "message" && len(b.UserIds) == 2
+		})).Return(nil)
+		outboxRepo.On("MarkDelivered", reqCtx, uint(1), now).Return(model.SuccessResponse(true, "ok"))
+		outboxRepo.On("MarkDelivered", reqCtx, uint(2), now).Return(model.SuccessResponse(true, "ok"))
+
+		svc.Dispatch(reqCtx, deliveries)
+
+		assert.Equal(t, []events.PublishedEvent{{Key: "4", Event: event}}, publisher.Events())
+		mockSocket.AssertExpectations(t)
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("a failed delivery is retried later and holds back its conversation", func(t *testing.T) {
+		mockSocket := new(MockSocketClient)
+		svc, outboxRepo, _ := newOutboxService(mockSocket)
+		deliveries := []*model.OutboxEvent{
+			socketDelivery(t, 1, "4", &model.BroadcastMessage{UserIds: []int{1}, Event: "message"}),
+			socketDelivery(t, 2, "4", &model.BroadcastMessage{UserIds: []int{1}, Event: "message_updated"}),
+			socketDelivery(t, 3, "6", &model.BroadcastMessage{UserIds: []int{2}, Event: "message"}),
+		}
+		deliveries[0].Attempts = 1
+
+		inOrder(outboxRepo)
+		outboxRepo.On("Claim", reqCtx, mock.Anything, mock.Anything).Return(model.SuccessResponse(true, "claimed"))
+		mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool { return b.UserIds[0] == 1 })).
+			Return(errors.New("socket service unavailable"))
+		mockSocket.On("Deliver", mock.MatchedBy(func(b *model.BroadcastMessage) bool { return b.UserIds[0] == 2 })).Return(nil)
+		// The second attempt failed, so the next one waits twice the first delay
+		outboxRepo.On("MarkRetry", reqCtx, uint(1), now.Add(2*time.Second), "socket service unavailable").Return(model.SuccessResponse(true, "ok"))
+		outboxRepo.On("MarkDelivered", reqCtx, uint(3), now).Return(model.SuccessResponse(true, "ok"))
+
+		svc.Dispatch(reqCtx, deliveries)
+
+		outboxRepo.AssertExpectations(t)
+		outboxRepo.AssertNumberOfCalls(t, "HasPendingBefore", 2)
+		outboxRepo.AssertNumberOfCalls(t, "Claim", 2)
+		mockSocket.AssertNumberOfCalls(t, "Deliver", 2)
+	})
+
+	t.Run("gives a delivery up after the last attempt", func(t *testing.T) {
+		mockSocket := new(MockSocketClient)
+		svc, outboxRepo, _ := newOutboxService(mockSocket)
+		delivery := socketDelivery(t, 1, "4", &model.BroadcastMessage{UserIds: []int{1}, Event: "message"})
+		delivery.Attempts = 2
+
+		inOrder(outboxRepo)
+		outboxRepo.On("Claim", reqCtx, delivery, mock.Anything).Return(model.SuccessResponse(true, "claimed"))
+		mockSocket.On("Deliver", mock.Anything).Return(errors.New("socket service unavailable"))
+		outboxRepo.On("MarkFailed", reqCtx, uint(1), now, "socket service unavailable").Return(model.SuccessResponse(true, "ok"))
+
+		svc.Dispatch(reqCtx, []*model.OutboxEvent{delivery})
+
+		outboxRepo.AssertExpectations(t)
+		outboxRepo.AssertNotCalled(t, "MarkRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("skips a delivery claimed elsewhere", func(t *testing.T) {
+		mockSocket := new(MockSocketClient)
+		svc, outboxRepo, publisher := newOutboxService(mockSocket)
+		delivery := eventDelivery(t, 1, consumer.MessageEvent{Type: consumer.MessageEventCreated, MessageID: 20, ConversationID: 4})
+
+		inOrder(outboxRepo)
+		outboxRepo.On("Claim", reqCtx, delivery, mock.Anything).Return(model.SuccessResponse(false, "claimed elsewhere"))
+
+		svc.Dispatch(reqCtx, []*model.OutboxEvent{delivery})
+
+		assert.Empty(t, publisher.Events())
+		outboxRepo.AssertNotCalled(t, "MarkDelivered", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("waits while an older delivery of its conversation is pending", func(t *testing.T) {
+		mockSocket := new(MockSocketClient)
+		svc, outboxRepo, _ := newOutboxService(mockSocket)
+		edited := socketDelivery(t, 2, "4", &model.BroadcastMessage{UserIds: []int{1}, Event: "message_updated"})
+		edited.NextAttemptAt = now.Add(model.OutboxLease)
+		other := socketDelivery(t, 3, "6", &model.BroadcastMessage{UserIds: []int{2}, Event: "message"})
+
+		outboxRepo.On("HasPendingBefore", reqCtx, edited).Return(model.SuccessResponse(true, "ok"))
+		outboxRepo.On("HasPendingBefore", reqCtx, other).Return(model.SuccessResponse(false, "ok"))
+		// The edit is handed to the relay instead of waiting out its lease
+		outboxRepo.On("MarkRetry", reqCtx, uint(2), now, "").Return(model.SuccessResponse(true, "ok"))
+		outboxRepo.On("Claim", reqCtx, other, mock.Anything).Return(model.SuccessResponse(true, "claimed"))
+		mockSocket.On("Deliver", mock.Anything).Return(nil)
+		outboxRepo.On("MarkDelivered", reqCtx, uint(3), now).Return(model.SuccessResponse(true, "ok"))
+
+		svc.Dispatch(reqCtx, []*model.OutboxEvent{edited, other})
+
+		outboxRepo.AssertExpectations(t)
+		outboxRepo.AssertNumberOfCalls(t, "Claim", 1)
+		mockSocket.AssertNumberOfCalls(t, "Deliver", 1)
+	})
+}
+
+func TestOutboxService_Relay(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("purges old deliveries and delivers the due ones", func(t *testing.T) {
+		mockSocket := new(MockSocketClient)
+		svc, outboxRepo, publisher := newOutboxService(mockSocket)
+		event := consumer.MessageEvent{Type: consumer.MessageEventDeleted, MessageID: 20, ConversationID: 4}
+
+		outboxRepo.On("PurgeDelivered", reqCtx, now.Add(-24*time.Hour)).Return(model.SuccessResponse(int64(3), "ok"))
+		outboxRepo.On("ListDue", reqCtx, now, mock.Anything).Return(model.SuccessResponse([]*model.OutboxEvent{eventDelivery(t, 7, event)}, "ok"))
+		inOrder(outboxRepo)
+		outboxRepo.On("Claim", reqCtx, mock.Anything, mock.Anything).Return(model.SuccessResponse(true, "claimed"))
+		outboxRepo.On("MarkDelivered", reqCtx, uint(7), now).Return(model.SuccessResponse(true, "ok"))
+
+		resp := svc.Relay(reqCtx)
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, 1, resp.Data)
+		assert.Equal(t, []events.PublishedEvent{{Key: "4", Event: event}}, publisher.Events())
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports a failure to list", func(t *testing.T) {
+		svc, outboxRepo, _ := newOutboxService(new(MockSocketClient))
+
+		outboxRepo.On("PurgeDelivered", reqCtx, mock.Anything).Return(model.SuccessResponse(int64(0), "ok"))
+		outboxRepo.On("ListDue", reqCtx, now, mock.Anything).Return(model.InternalError[[]*model.OutboxEvent]("Failed to list outbox"))
+
+		resp := svc.Relay(reqCtx)
+
+		assert.False(t, resp.OK())
+		assert.Contains(t, resp.ErrorString(), "Failed to list outbox")
+	})
+}
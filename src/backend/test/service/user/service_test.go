@@ -0,0 +1,312 @@
+package user_test
+
+import (
+	"local/client"
+	"local/model"
+	"local/service/common"
+	"local/service/user"
+	"local/test/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newUserService(mockRepo *mocks.MockRepository, mockSocket *mocks.MockSocketClient) user.UserService {
+	return user.NewUserService(&common.Params{
+		Repo:   mockRepo,
+		Client: &client.Client{SocketClient: mockSocket},
+	})
+}
+
+func TestUserService_SetPresence(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+	seenAt := time.Now()
+
+	t.Run("going online notifies contacts", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newUserService(mockRepo, mockSocket)
+
+		mockUserRepo.On("SetPresence", reqCtx, uint(1), true, mock.AnythingOfType("time.Time")).
+			Return(model.SuccessResponse(&model.User{ID: 1, Online: true, LastSeenAt: &seenAt}, "ok"))
+		mockParticipantRepo.On("GetContactIDs", reqCtx, uint(1)).
+			Return(model.SuccessResponse([]uint{2, 3}, "ok"))
+		mockSocket.On("Broadcast", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			presence, ok := b.Payload.(*model.UserPresence)
+			return b.Event == "presence_online" &&
+				len(b.UserIds) == 2 &&
+				ok && presence.UserID == 1 && presence.Online
+		})).Return()
+
+		resp := svc.SetPresence(reqCtx, 1, true)
+
+		assert.True(t, resp.OK())
+		assert.True(t, resp.Data.Online)
+		mockUserRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+	})
+
+	t.Run("users without contacts are not broadcast", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newUserService(mockRepo, mockSocket)
+
+		mockUserRepo.On("SetPresence", reqCtx, uint(1), false, mock.AnythingOfType("time.Time")).
+			Return(model.SuccessResponse(&model.User{ID: 1, LastSeenAt: &seenAt}, "ok"))
+		mockParticipantRepo.On("GetContactIDs", reqCtx, uint(1)).
+			Return(model.SuccessResponse([]uint{}, "ok"))
+
+		resp := svc.SetPresence(reqCtx, 1, false)
+
+		assert.True(t, resp.OK())
+		assert.False(t, resp.Data.Online)
+		mockSocket.AssertNotCalled(t, "Broadcast", mock.Anything)
+	})
+}
+
+func TestUserService_GetPresence(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("requires ids", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		resp := svc.GetPresence(reqCtx, nil)
+
+		assert.Equal(t, model.CodeBadRequest, resp.Code)
+		mockUserRepo.AssertNotCalled(t, "GetByIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("maps users to presence", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		mockUserRepo.On("GetByIDs", reqCtx, []uint{1, 2}).
+			Return(model.SuccessResponse([]*model.User{{ID: 1, Online: true}, {ID: 2}}, "ok"))
+
+		resp := svc.GetPresence(reqCtx, []uint{1, 2})
+
+		assert.True(t, resp.OK())
+		assert.Len(t, resp.Data, 2)
+		assert.True(t, resp.Data[0].Online)
+		assert.False(t, resp.Data[1].Online)
+	})
+}
+
+func TestUserService_GetProfile(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+	avatarID := uint(9)
+
+	t.Run("expired status is left out", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		expired := time.Now().Add(-time.Minute)
+		mockUserRepo.On("QueryOne", reqCtx, &model.User{ID: 1}).
+			Return(model.SuccessResponse(&model.User{ID: 1, UserName: "alice", DisplayName: "Alice", AvatarAttachmentID: &avatarID, StatusText: "lunch", StatusExpiresAt: &expired}, "ok"))
+
+		resp := svc.GetProfile(reqCtx, 1)
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, "Alice", resp.Data.DisplayName)
+		assert.Equal(t, "/api/v1/attachments/9", resp.Data.AvatarURL)
+		assert.Nil(t, resp.Data.Status)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		mockUserRepo.On("QueryOne", reqCtx, &model.User{ID: 2}).
+			Return(model.NotFound[*model.User]("User not found"))
+
+		resp := svc.GetProfile(reqCtx, 2)
+
+		assert.Equal(t, model.CodeNotFound, resp.Code)
+	})
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("saves the changes and notifies contacts", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		mockSocket := new(mocks.MockSocketClient)
+		svc := newUserService(mockRepo, mockSocket)
+
+		displayName := "  Alice  "
+		mockUserRepo.On("UpdateProfile", reqCtx, uint(1), map[string]interface{}{
+			"display_name":      "Alice",
+			"status_text":       "in a meeting",
+			"status_expires_at": (*time.Time)(nil),
+		}).Return(model.SuccessResponse(&model.User{ID: 1, UserName: "alice", DisplayName: "Alice", StatusText: "in a meeting"}, "ok"))
+		mockParticipantRepo.On("GetContactIDs", reqCtx, uint(1)).
+			Return(model.SuccessResponse([]uint{2}, "ok"))
+		mockSocket.On("Broadcast", mock.MatchedBy(func(b *model.BroadcastMessage) bool {
+			profile, ok := b.Payload.(*model.UserProfile)
+			return b.Event == "profile_updated" &&
+				len(b.UserIds) == 1 && b.UserIds[0] == 2 &&
+				ok && profile.DisplayName == "Alice"
+		})).Return()
+
+		resp := svc.UpdateProfile(reqCtx, 1, &user.ProfileUpdate{
+			DisplayName: &displayName,
+			Status:      &model.UserStatus{Text: "in a meeting"},
+		})
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, "in a meeting", resp.Data.Status.Text)
+		mockUserRepo.AssertExpectations(t)
+		mockSocket.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid fields", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		long := strings.Repeat("a", model.MaxDisplayNameLength+1)
+		for name, update := range map[string]*user.ProfileUpdate{
+			"display name too long":  {DisplayName: &long},
+			"status already expired": {Status: &model.UserStatus{Text: "away", ExpiresAt: &past}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+				svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+				resp := svc.UpdateProfile(reqCtx, 1, update)
+
+				assert.Equal(t, model.CodeValidation, resp.Code)
+				mockUserRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("avatar must be an avatar upload of the user", func(t *testing.T) {
+		for name, attachment := range map[string]*model.Attachment{
+			"conversation file":     {ID: 7, ConversationID: 3, UploaderID: 1},
+			"someone else's avatar": {ID: 7, UploaderID: 2},
+		} {
+			t.Run(name, func(t *testing.T) {
+				mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+				mockAttachmentRepo := mockRepo.AttachmentRepo.(*mocks.MockAttachmentRepo)
+				svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+				mockAttachmentRepo.On("GetByID", reqCtx, uint(7)).Return(model.SuccessResponse(attachment, "ok"))
+				avatarID := uint(7)
+
+				resp := svc.UpdateProfile(reqCtx, 1, &user.ProfileUpdate{AvatarAttachmentID: &avatarID})
+
+				assert.Equal(t, model.CodeValidation, resp.Code)
+				mockUserRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("zero removes the avatar", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, mockParticipantRepo, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		noAvatar := uint(0)
+		mockUserRepo.On("UpdateProfile", reqCtx, uint(1), map[string]interface{}{"avatar_attachment_id": nil}).
+			Return(model.SuccessResponse(&model.User{ID: 1}, "ok"))
+		mockParticipantRepo.On("GetContactIDs", reqCtx, uint(1)).
+			Return(model.SuccessResponse([]uint{}, "ok"))
+
+		resp := svc.UpdateProfile(reqCtx, 1, &user.ProfileUpdate{AvatarAttachmentID: &noAvatar})
+
+		assert.True(t, resp.OK())
+		assert.Empty(t, resp.Data.AvatarURL)
+		mockUserRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserService_SearchUsers(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("returns a cursor that continues after the last match", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		mockUserRepo.On("Search", reqCtx, uint(9), "al", (*model.UserSearchCursor)(nil), 3).
+			Return(model.SuccessResponse([]*model.UserMatch{
+				{User: &model.User{ID: 1, UserName: "alice", Password: "hash"}, Rank: 0},
+				{User: &model.User{ID: 2, UserName: "bob", DisplayName: "Bob Alison"}, Rank: 1},
+				{User: &model.User{ID: 3, UserName: "malik"}, Rank: 2},
+			}, "ok"))
+
+		resp := svc.SearchUsers(reqCtx, 9, model.UserSearchParams{Query: " al ", Limit: 2})
+
+		assert.True(t, resp.OK())
+		assert.Len(t, resp.Data.Items, 2)
+		assert.Equal(t, "bob", resp.Data.Items[1].UserName)
+		assert.True(t, resp.Data.HasMore)
+
+		mockUserRepo.On("Search", reqCtx, uint(9), "al", &model.UserSearchCursor{Rank: 1, UserName: "bob"}, 3).
+			Return(model.SuccessResponse([]*model.UserMatch{{User: &model.User{ID: 3, UserName: "malik"}, Rank: 2}}, "ok"))
+
+		next := svc.SearchUsers(reqCtx, 9, model.UserSearchParams{Query: "al", Cursor: resp.Data.NextCursor, Limit: 2})
+
+		assert.True(t, next.OK())
+		assert.Len(t, next.Data.Items, 1)
+		assert.False(t, next.Data.HasMore)
+		assert.Empty(t, next.Data.NextCursor)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a malformed cursor", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		resp := svc.SearchUsers(reqCtx, 9, model.UserSearchParams{Cursor: "%%%"})
+
+		assert.Equal(t, model.CodeValidation, resp.Code)
+		mockUserRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_Block(t *testing.T) {
+	reqCtx := &model.RequestContext{}
+
+	t.Run("blocks an existing user", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		mockBlockRepo := mockRepo.BlockRepo.(*mocks.MockBlockRepo)
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		mockUserRepo.On("QueryOne", reqCtx, &model.User{ID: 2}).
+			Return(model.SuccessResponse(&model.User{ID: 2}, "ok"))
+		mockBlockRepo.On("Block", reqCtx, uint(1), uint(2)).
+			Return(model.SuccessResponse(&model.UserBlock{BlockerID: 1, BlockedID: 2}, "ok"))
+
+		resp := svc.Block(reqCtx, 1, 2)
+
+		assert.True(t, resp.OK())
+		assert.Equal(t, uint(2), resp.Data.BlockedID)
+		mockBlockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cannot block yourself", func(t *testing.T) {
+		mockRepo, _, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		mockBlockRepo := mockRepo.BlockRepo.(*mocks.MockBlockRepo)
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		resp := svc.Block(reqCtx, 1, 1)
+
+		assert.Equal(t, model.CodeBadRequest, resp.Code)
+		mockBlockRepo.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown users cannot be blocked", func(t *testing.T) {
+		mockRepo, mockUserRepo, _, _, _ := mocks.NewMockRepositoryWithDefaults()
+		mockBlockRepo := mockRepo.BlockRepo.(*mocks.MockBlockRepo)
+		svc := newUserService(mockRepo, new(mocks.MockSocketClient))
+
+		mockUserRepo.On("QueryOne", reqCtx, &model.User{ID: 9}).
+			Return(model.NotFound[*model.User]("User not found"))
+
+		resp := svc.Block(reqCtx, 1, 9)
+
+		assert.Equal(t, model.CodeNotFound, resp.Code)
+		mockBlockRepo.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,162 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"local/config"
+	"local/endpoint"
+	"local/infra/provider/signing"
+	"local/model"
+	"local/service/auth"
+	"local/service/common"
+	"local/service/conversation"
+	"local/service/initial"
+	"local/test/mocks"
+	httpTransport "local/transport/http"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type authTestSetup struct {
+	router   *gin.Engine
+	keys     *signing.KeySet
+	userRepo *mocks.MockUserRepo
+	cvsRepo  *mocks.MockConversationRepo
+}
+
+// newAuthTestSetup builds the real router over mocked repositories. Only the auth
+// and conversation services are wired; the routes under test need nothing else
+func newAuthTestSetup(t *testing.T, rateLimit bool, burst int) *authTestSetup {
+	gin.SetMode(gin.TestMode)
+	previous := config.Config
+	t.Cleanup(func() { config.Config = previous })
+	config.Config.RateLimitEnabled = rateLimit
+	config.Config.RateLimitRequestsPerMin = 60
+	config.Config.RateLimitBurst = burst
+
+	keys, err := signing.Generate()
+	assert.NoError(t, err)
+	mockRepo, mockUserRepo, mockConversationRepo, _, _ := mocks.NewMockRepositoryWithDefaults()
+	svc := initial.Service{
+		AuthSvc: auth.NewTestAuthService(mockRepo, keys),
+		CvsSvc:  conversation.NewConversationService(&common.Params{Repo: mockRepo}),
+	}
+	router := httpTransport.MakeHttpTransport(&model.InitParams{Ctx: context.Background()}, endpoint.NewEndpoints(&svc))
+
+	return &authTestSetup{router: router, keys: keys, userRepo: mockUserRepo, cvsRepo: mockConversationRepo}
+}
+
+func (s *authTestSetup) get(path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func userClaims(userID uint) *auth.TestJWTClaims {
+	return &auth.TestJWTClaims{
+		SessionID: fmt.Sprintf("session-%d", userID),
+		UserID:    userID,
+		UserName:  fmt.Sprintf("user%d", userID),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+}
+
+// forgedTokens are bearer tokens claiming to be the user that the backend never issued
+func forgedTokens(t *testing.T, userID uint) map[string]string {
+	otherKeys, err := signing.Generate()
+	assert.NoError(t, err)
+	otherKeySigned, err := otherKeys.Sign(userClaims(userID))
+	assert.NoError(t, err)
+
+	hmacSigned, err := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims(userID)).SignedString([]byte("your_jwt_secret"))
+	assert.NoError(t, err)
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, userClaims(userID)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	return map[string]string{
+		"signed by another key": otherKeySigned,
+		"signed with a secret":  hmacSigned,
+		"unsigned":              unsigned,
+	}
+}
+
+func TestAuthMiddleware_ForgedTokensCannotImpersonate(t *testing.T) {
+	setup := newAuthTestSetup(t, false, 0)
+
+	for name, token := range forgedTokens(t, 1) {
+		t.Run(name, func(t *testing.T) {
+			recorder := setup.get("/api/v1/me", token)
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+			recorder = setup.get("/api/v1/conversations/", token)
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+			recorder = setup.get("/api/v1/conversations/1/messages", token)
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+
+	// Nothing reached the data layer on behalf of the claimed user
+	setup.userRepo.AssertNotCalled(t, "QueryOne", mock.Anything, mock.Anything)
+	setup.cvsRepo.AssertNotCalled(t, "GetByParticipant", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthMiddleware_ValidToken(t *testing.T) {
+	setup := newAuthTestSetup(t, false, 0)
+	setup.userRepo.On("QueryOne", mock.Anything, &model.User{ID: 1}).Return(model.SuccessResponse(&model.User{ID: 1, UserName: "user1"}, "User found"))
+
+	token, err := setup.keys.Sign(userClaims(1))
+	assert.NoError(t, err)
+
+	recorder := setup.get("/api/v1/me", token)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"username":"user1"`)
+
+	recorder = setup.get("/api/v1/me", "")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Token is required")
+}
+
+func TestAuthMiddleware_ForgedTokensCannotDodgeRateLimit(t *testing.T) {
+	setup := newAuthTestSetup(t, true, 2)
+
+	// Each request claims a different user, but unverified claims are ignored and
+	// every request from the address shares one IP bucket
+	codes := []int{}
+	for userID := uint(1); userID <= 4; userID++ {
+		codes = append(codes, setup.get("/api/v1/me", forgedTokens(t, userID)["signed by another key"]).Code)
+	}
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests}, codes)
+}
+
+func TestAuthMiddleware_VerifiedUsersGetOwnRateLimit(t *testing.T) {
+	setup := newAuthTestSetup(t, true, 2)
+	setup.userRepo.On("QueryOne", mock.Anything, mock.Anything).Return(model.SuccessResponse(&model.User{ID: 1, UserName: "user1"}, "User found"))
+
+	first, err := setup.keys.Sign(userClaims(1))
+	assert.NoError(t, err)
+	second, err := setup.keys.Sign(userClaims(2))
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, setup.get("/api/v1/me", first).Code)
+	assert.Equal(t, http.StatusOK, setup.get("/api/v1/me", first).Code)
+	assert.Equal(t, http.StatusTooManyRequests, setup.get("/api/v1/me", first).Code)
+
+	// Another verified user from the same address is limited separately
+	assert.Equal(t, http.StatusOK, setup.get("/api/v1/me", second).Code)
+}
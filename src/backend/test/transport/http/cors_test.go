@@ -0,0 +1,31 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *authTestSetup) preflight(path, method string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	req.Header.Set("Access-Control-Request-Method", method)
+	req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCORS_PreflightAllowsPatch(t *testing.T) {
+	setup := newAuthTestSetup(t, false, 0)
+
+	// Browsers preflight PATCH; profile updates and message edits both use it
+	for _, path := range []string{"/api/v1/me/profile", "/api/v1/conversations/1/messages/1"} {
+		recorder := setup.preflight(path, http.MethodPatch)
+		assert.Equal(t, http.StatusNoContent, recorder.Code, path)
+		assert.Contains(t, recorder.Header().Get("Access-Control-Allow-Methods"), http.MethodPatch, path)
+		assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"), path)
+	}
+}
@@ -1,17 +1,52 @@
 package httpTransport
 
 import (
+	"errors"
+	"io"
+	"local/config"
 	"local/endpoint"
 	"local/model"
+	"mime"
+	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// multipartOverhead is the slack allowed on top of the attachment size limit for multipart headers and boundaries
+const multipartOverhead = 1 << 20
+
 type handler struct {
 	endpoints *endpoint.Endpoints
 }
 
+// getUintParam parses a numeric path parameter
+func getUintParam(c *gin.Context, name string) (uint, error) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(value), nil
+}
+
+// parseUintList parses a comma-separated list of IDs such as "1,2,3"
+func parseUintList(raw string) ([]uint, error) {
+	ids := []uint{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(value))
+	}
+	return ids, nil
+}
+
 // GetMe godoc
 // @Summary Get current authenticated user information
 // @Description Returns the current user's information based on the JWT token
@@ -40,6 +75,7 @@ func (h *handler) GetMe() gin.HandlerFunc {
 // @Success 200 {object} model.Response[model.User]
 // @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
 // @Failure 409 {object} model.Response[any] "Conflict - User already exists"
+// @Failure 422 {object} model.Response[any] "Validation Error - Password does not meet the requirements"
 // @Failure 500 {object} model.Response[any] "Internal Server Error"
 // @Router /register [post]
 func (h *handler) Register() gin.HandlerFunc {
@@ -57,8 +93,8 @@ func (h *handler) Register() gin.HandlerFunc {
 }
 
 // Login godoc
-// @Summary Authenticate user and get JWT token
-// @Description Validates user credentials and returns a JWT token for authentication
+// @Summary Authenticate user and get tokens
+// @Description Validates user credentials and returns a short-lived JWT access token plus a refresh token. Users with two-factor authentication get mfa_required and an mfa_token to exchange at /login/mfa instead.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -66,6 +102,7 @@ func (h *handler) Register() gin.HandlerFunc {
 // @Success 200 {object} model.Response[endpoint.LoginResponse]
 // @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
 // @Failure 401 {object} model.Response[any] "Unauthorized - Invalid credentials"
+// @Failure 429 {object} model.Response[any] "Too Many Requests - Too many failed attempts for the account or address"
 // @Failure 500 {object} model.Response[any] "Internal Server Error"
 // @Router /login [post]
 func (h *handler) Login() gin.HandlerFunc {
@@ -83,9 +120,126 @@ func (h *handler) Login() gin.HandlerFunc {
 	}
 }
 
+// VerifyMFA godoc
+// @Summary Complete a login with a two-factor code
+// @Description Exchanges the mfa_token from /login and a current TOTP code or an unused recovery code for the access and refresh tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body endpoint.VerifyMFARequest true "Challenge token and code"
+// @Success 200 {object} model.Response[endpoint.LoginResponse]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid code or expired challenge token"
+// @Failure 422 {object} model.Response[any] "Validation Error - Missing challenge token or code"
+// @Failure 429 {object} model.Response[any] "Too Many Requests - Too many failed attempts"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /login/mfa [post]
+func (h *handler) VerifyMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.VerifyMFARequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[endpoint.LoginResponse]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		response := h.endpoints.Auth.VerifyMFA(reqCtx, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// OIDCProviders godoc
+// @Summary List single sign-on providers
+// @Description Lists the OpenID Connect identity providers users may sign in with; empty when single sign-on is not configured
+// @Tags auth
+// @Produce json
+// @Success 200 {object} model.Response[[]endpoint.OIDCProviderResponse]
+// @Router /login/oidc [get]
+func (h *handler) OIDCProviders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		response := h.endpoints.Auth.OIDCProviders(reqCtx)
+		c.JSON(response.Code, response)
+	}
+}
+
+// StartOIDCLogin godoc
+// @Summary Start a single sign-on login
+// @Description Starts the authorization code flow with PKCE. Send the user to authorization_url; the provider returns them to the configured redirect page with state and code, which go to the callback endpoint
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} model.Response[endpoint.OIDCAuthorizationResponse]
+// @Failure 404 {object} model.Response[any] "Not Found - Unknown provider"
+// @Failure 500 {object} model.Response[any] "Internal Server Error - Provider unavailable"
+// @Router /login/oidc/{provider} [post]
+func (h *handler) StartOIDCLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		response := h.endpoints.Auth.StartOIDCLogin(reqCtx, c.Param("provider"))
+		c.JSON(response.Code, response)
+	}
+}
+
+// CompleteOIDCLogin godoc
+// @Summary Complete a single sign-on login
+// @Description Redeems the code the provider returned for the access and refresh tokens. A user is created on the first login with an identity. Users with two-factor authentication on get an mfa_token as from /login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param request body endpoint.OIDCCallbackRequest true "State and code from the redirect"
+// @Success 200 {object} model.Response[endpoint.LoginResponse]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Unknown or expired state, or the provider rejected the login"
+// @Failure 404 {object} model.Response[any] "Not Found - Unknown provider"
+// @Failure 422 {object} model.Response[any] "Validation Error - Missing state or code"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /login/oidc/{provider}/callback [post]
+func (h *handler) CompleteOIDCLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.OIDCCallbackRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[endpoint.LoginResponse]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		response := h.endpoints.Auth.CompleteOIDCLogin(reqCtx, c.Param("provider"), req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// RefreshToken godoc
+// @Summary Exchange a refresh token for a new token pair
+// @Description Rotates the refresh token and issues a new short-lived access token. A refresh token works only once; reusing a rotated-out token revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body endpoint.RefreshTokenRequest true "Refresh token from login or the previous refresh"
+// @Success 200 {object} model.Response[endpoint.LoginResponse]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid, expired or reused refresh token"
+// @Failure 422 {object} model.Response[any] "Validation Error - Missing refresh token"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /token/refresh [post]
+func (h *handler) RefreshToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.RefreshTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[endpoint.LoginResponse]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		response := h.endpoints.Auth.Refresh(reqCtx, req)
+		c.JSON(response.Code, response)
+	}
+}
+
 // Logout godoc
 // @Summary Logout user and invalidate token
-// @Description Invalidates the current user's JWT token
+// @Description Revokes the current session so its token stops working and its live sockets are disconnected
 // @Tags auth
 // @Security BearerAuth
 // @Accept json
@@ -104,12 +258,224 @@ func (h *handler) Logout() gin.HandlerFunc {
 	}
 }
 
+// LogoutAll godoc
+// @Summary Logout from all sessions
+// @Description Revokes every token issued to the authenticated user, including the current one, and disconnects their live sockets
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.Response[string]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /logout/all [post]
+func (h *handler) LogoutAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[string]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		response := h.endpoints.Auth.LogoutAll(reqCtx)
+		c.JSON(response.Code, response)
+	}
+}
+
+// ChangePassword godoc
+// @Summary Change the current user's password
+// @Description Checks the current password, sets a new one that meets the password policy and signs out every other session. The session making the request stays signed in.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body endpoint.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} model.Response[string]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Current password is incorrect"
+// @Failure 422 {object} model.Response[any] "Validation Error - New password does not meet the requirements"
+// @Failure 429 {object} model.Response[any] "Too Many Requests - Too many failed attempts"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /me/password [post]
+func (h *handler) ChangePassword() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[string]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		var req endpoint.ChangePasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[string]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+		response := h.endpoints.Auth.ChangePassword(reqCtx, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// EnrollMFA godoc
+// @Summary Start two-factor authentication enrollment
+// @Description Creates a TOTP secret and returns it with an otpauth URI for authenticator apps. Logins do not ask for codes until the enrollment is confirmed.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.Response[endpoint.MFAEnrollmentResponse]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 409 {object} model.Response[any] "Conflict - Two-factor authentication is already enabled"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /me/mfa/enroll [post]
+func (h *handler) EnrollMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[endpoint.MFAEnrollmentResponse]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		response := h.endpoints.Auth.EnrollMFA(reqCtx)
+		c.JSON(response.Code, response)
+	}
+}
+
+// ConfirmMFA godoc
+// @Summary Confirm two-factor authentication enrollment
+// @Description Verifies the first code from the authenticator app, turns two-factor authentication on and returns one-time recovery codes. The codes are not shown again.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body endpoint.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} model.Response[endpoint.MFARecoveryCodesResponse]
+// @Failure 400 {object} model.Response[any] "Bad Request - Enrollment not started"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 409 {object} model.Response[any] "Conflict - Two-factor authentication is already enabled"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid code"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /me/mfa/confirm [post]
+func (h *handler) ConfirmMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[endpoint.MFARecoveryCodesResponse]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		var req endpoint.MFACodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[endpoint.MFARecoveryCodesResponse]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+		response := h.endpoints.Auth.ConfirmMFA(reqCtx, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// DisableMFA godoc
+// @Summary Turn off two-factor authentication
+// @Description Removes the TOTP secret and recovery codes. Requires a current code or an unused recovery code.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body endpoint.MFACodeRequest true "Current code or recovery code"
+// @Success 200 {object} model.Response[string]
+// @Failure 400 {object} model.Response[any] "Bad Request - Two-factor authentication is not enabled"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid token or code"
+// @Failure 429 {object} model.Response[any] "Too Many Requests - Too many failed attempts"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /me/mfa/disable [post]
+func (h *handler) DisableMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[string]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		var req endpoint.MFACodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[string]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+		response := h.endpoints.Auth.DisableMFA(reqCtx, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// GetJWKS serves the public signing keys as a bare JWK Set (RFC 7517) for other
+// services verifying tokens. It lives outside /api/v1, so it is not in the swagger docs
+func (h *handler) GetJWKS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		response := h.endpoints.Auth.JWKS(reqCtx)
+		if !response.OK() {
+			c.JSON(response.Code, response)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, response.Data)
+	}
+}
+
+// ListSessions godoc
+// @Summary List active sessions
+// @Description Lists the authenticated user's signed-in devices with their user agent, IP address and last use. The session making the request is flagged as current
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.Response[[]model.Session]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /sessions [get]
+func (h *handler) ListSessions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[[]*model.Session]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		response := h.endpoints.Auth.ListSessions(reqCtx)
+		c.JSON(response.Code, response)
+	}
+}
+
+// DeleteSession godoc
+// @Summary Sign out a session
+// @Description Revokes one of the authenticated user's sessions: its tokens stop working and its live sockets are disconnected
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param sessionID path string true "Session ID"
+// @Success 200 {object} model.Response[string]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 404 {object} model.Response[any] "Not Found - Session not found"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /sessions/{sessionID} [delete]
+func (h *handler) DeleteSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[string]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		response := h.endpoints.Auth.DeleteSession(reqCtx, c.Param("sessionID"))
+		c.JSON(response.Code, response)
+	}
+}
+
 type CreateConversationRequest struct {
 	UserID uint `json:"user_id"`
 }
 
 // CreateConversation godoc
-// @Summary Create a new private conversation
+// @Description Creates a private conversation between the authenticated user and another user, or returns the one they already have
 // @Description Creates a new private conversation between the authenticated user and another user
 // @Tags conversations
 // @Security BearerAuth
@@ -178,11 +544,14 @@ func (h *handler) GetConversationByUserID() gin.HandlerFunc {
 
 // GetConversations godoc
 // @Summary Get all conversations for the authenticated user
-// @Description Returns a list of all conversations where the authenticated user is a participant
+// @Description Returns a page of conversations where the authenticated user is a participant, most recently active first
 // @Tags conversations
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {object} model.Response[[]model.Conversation]
+// @Param before_id query int false "Return conversations older than this conversation ID"
+// @Param after_id query int false "Return conversations newer than this conversation ID"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Success 200 {object} model.Response[model.Page[model.Conversation]]
 // @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
 // @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
 // @Failure 500 {object} model.Response[any] "Internal Server Error"
@@ -191,101 +560,1173 @@ func (h *handler) GetConversations() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reqCtx := model.NewRequestContext(c.Request.Context())
 		if reqCtx.UserID == 0 {
-			response := model.Unauthorized[[]*model.Conversation]("Unauthorized")
+			response := model.Unauthorized[*model.Page[*model.Conversation]]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		var cursor model.CursorParams
+		if err := c.ShouldBindQuery(&cursor); err != nil {
+			response := model.ValidationError[*model.Page[*model.Conversation]]("Invalid pagination parameters")
 			c.JSON(response.Code, response)
 			return
 		}
-		response := h.endpoints.Conversation.GetUserConversations(reqCtx, reqCtx.UserID)
+		response := h.endpoints.Conversation.GetUserConversations(reqCtx, reqCtx.UserID, cursor)
 		c.JSON(response.Code, response)
 	}
 }
 
-// CreateMessage godoc
-// @Summary Create a new message in a conversation
-// @Description Creates a new message in the specified conversation
-// @Tags messages
+// CreateGroupConversation godoc
+// @Summary Create a new group conversation
+// @Description Creates a named group conversation owned by the authenticated user
+// @Tags conversations
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param conversationID path int true "Conversation ID"
-// @Param request body endpoint.CreateMessageRequest true "Message data"
-// @Success 200 {object} model.Response[model.Message]
+// @Param request body endpoint.CreateGroupConversationRequest true "Group conversation data"
+// @Success 200 {object} model.Response[model.Conversation]
 // @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
 // @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
-// @Failure 404 {object} model.Response[any] "Not Found - Conversation not found"
+// @Failure 422 {object} model.Response[any] "Validation Error - Unknown user IDs"
 // @Failure 500 {object} model.Response[any] "Internal Server Error"
-// @Router /conversations/{conversationID}/messages [post]
-func (h *handler) CreateMessage() gin.HandlerFunc {
+// @Router /conversations/group [post]
+func (h *handler) CreateGroupConversation() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req endpoint.CreateMessageRequest
+		var req endpoint.CreateGroupConversationRequest
 		reqCtx := model.NewRequestContext(c.Request.Context())
 		if reqCtx.UserID == 0 {
-			response := model.Unauthorized[*model.Message]("Unauthorized")
-			c.JSON(response.Code, response)
-			return
-		}
-		conversationIDStr := c.Param("conversationID")
-		conversationID, err := strconv.ParseUint(conversationIDStr, 10, 64)
-		if err != nil {
-			response := model.ValidationError[*model.Message]("Invalid conversation ID")
+			response := model.Unauthorized[*model.Conversation]("Unauthorized")
 			c.JSON(response.Code, response)
 			return
 		}
-		req.ConversationID = uint(conversationID)
-		req.SenderID = reqCtx.UserID
+
 		if err := c.ShouldBindJSON(&req); err != nil {
-			response := model.ValidationError[*model.Message]("Invalid request body")
+			response := model.ValidationError[*model.Conversation]("Invalid request body")
 			c.JSON(response.Code, response)
 			return
 		}
 
-		response := h.endpoints.Message.CreateMessage(reqCtx, req)
+		response := h.endpoints.Conversation.CreateGroupConversation(reqCtx, req)
 		c.JSON(response.Code, response)
 	}
 }
 
-// GetMessagesByConversationID godoc
-// @Summary Get all messages in a conversation
-// @Description Retrieves all messages from the specified conversation, ordered by creation time (newest first)
-// @Tags messages
+// GetParticipants godoc
+// @Summary List the participants of a conversation
+// @Description Returns every participant of the conversation together with their role
+// @Tags conversations
 // @Security BearerAuth
 // @Produce json
 // @Param conversationID path int true "Conversation ID"
-// @Success 200 {object} model.Response[[]model.Message]
+// @Success 200 {object} model.Response[[]model.ConversationParticipant]
 // @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
 // @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
 // @Failure 500 {object} model.Response[any] "Internal Server Error"
-// @Router /conversations/{conversationID}/messages [get]
-func (h *handler) GetMessagesByConversationID() gin.HandlerFunc {
+// @Router /conversations/{conversationID}/participants [get]
+func (h *handler) GetParticipants() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		conversationIDStr := c.Param("conversationID")
-		conversationID, err := strconv.ParseUint(conversationIDStr, 10, 64)
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[[]*model.ConversationParticipant]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
 		if err != nil {
-			response := model.ValidationError[[]*model.Message]("Invalid conversation ID")
+			response := model.ValidationError[[]*model.ConversationParticipant]("Invalid conversation ID")
 			c.JSON(response.Code, response)
 			return
 		}
-		reqCtx := model.NewRequestContext(c.Request.Context())
-		response := h.endpoints.Message.GetMessagesByConversationID(reqCtx, uint(conversationID))
+
+		response := h.endpoints.Conversation.GetParticipants(reqCtx, conversationID)
 		c.JSON(response.Code, response)
 	}
 }
 
-// GetUsers godoc
-// @Summary Get all registered users
-// @Description Returns a list of all registered users in the system
-// @Tags users
+// GetParticipantsForMember godoc
+// @Summary List the participants of a conversation on behalf of a member
+// @Description Internal: called by the socket service to check that a user typing in a conversation is a member and to find who to tell
+// @Tags internal
+// @Security ServiceAuth
+// @Produce json
+// @Param userID path int true "User ID of the member"
+// @Param conversationID path int true "Conversation ID"
+// @Success 200 {object} model.Response[[]model.ConversationParticipant]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing service key"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid user or conversation ID"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /internal/users/{userID}/conversations/{conversationID}/participants [get]
+func (h *handler) GetParticipantsForMember() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		userID, err := getUintParam(c, "userID")
+		if err != nil {
+			response := model.ValidationError[[]*model.ConversationParticipant]("Invalid user ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[[]*model.ConversationParticipant]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Conversation.GetParticipantsForMember(reqCtx, conversationID, userID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// AddParticipants godoc
+// @Summary Add members to a group conversation
+// @Description Adds users to a group conversation. Only owners and admins can add members
+// @Tags conversations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param request body endpoint.AddParticipantsRequest true "Users to add"
+// @Success 200 {object} model.Response[model.Conversation]
+// @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not allowed to manage members"
+// @Failure 422 {object} model.Response[any] "Validation Error - Unknown user IDs"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /conversations/{conversationID}/participants [post]
+func (h *handler) AddParticipants() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.AddParticipantsRequest
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Conversation]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[*model.Conversation]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[*model.Conversation]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Conversation.AddParticipants(reqCtx, conversationID, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// RemoveParticipant godoc
+// @Summary Remove a member from a group conversation
+// @Description Removes a user from a group conversation. Only owners and admins can remove members
+// @Tags conversations
+// @Security BearerAuth
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param userID path int true "User ID to remove"
+// @Success 200 {object} model.Response[string]
+// @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not allowed to manage members"
+// @Failure 404 {object} model.Response[any] "Not Found - Participant not found"
+// @Router /conversations/{conversationID}/participants/{userID} [delete]
+func (h *handler) RemoveParticipant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[string]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[string]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		userID, err := getUintParam(c, "userID")
+		if err != nil {
+			response := model.ValidationError[string]("Invalid user ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Conversation.RemoveParticipant(reqCtx, conversationID, userID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// PromoteParticipant godoc
+// @Summary Promote a member to admin
+// @Description Grants the admin role to a member of a group conversation. Only the owner can promote
+// @Tags conversations
+// @Security BearerAuth
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param userID path int true "User ID to promote"
+// @Success 200 {object} model.Response[model.ConversationParticipant]
+// @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Only the owner can promote"
+// @Failure 404 {object} model.Response[any] "Not Found - Participant not found"
+// @Router /conversations/{conversationID}/participants/{userID}/promote [post]
+func (h *handler) PromoteParticipant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.ConversationParticipant]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[*model.ConversationParticipant]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		userID, err := getUintParam(c, "userID")
+		if err != nil {
+			response := model.ValidationError[*model.ConversationParticipant]("Invalid user ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Conversation.PromoteParticipant(reqCtx, conversationID, userID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// LeaveConversation godoc
+// @Summary Leave a group conversation
+// @Description Removes the authenticated user from a group conversation. Ownership passes to the longest-standing admin or member
+// @Tags conversations
+// @Security BearerAuth
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Success 200 {object} model.Response[string]
+// @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Router /conversations/{conversationID}/participants/me [delete]
+func (h *handler) LeaveConversation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[string]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[string]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Conversation.LeaveConversation(reqCtx, conversationID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// MarkAsRead godoc
+// @Summary Mark a conversation as read
+// @Description Moves the authenticated user's read marker to the given message, or to the latest message when message_id is omitted, and notifies the other participants
+// @Tags conversations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param request body endpoint.MarkAsReadRequest false "Message to mark as read"
+// @Success 200 {object} model.Response[model.ConversationParticipant]
+// @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Router /conversations/{conversationID}/read [post]
+func (h *handler) MarkAsRead() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.ConversationParticipant]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[*model.ConversationParticipant]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		// The body is optional; an empty one marks everything as read
+		var req endpoint.MarkAsReadRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			response := model.ValidationError[*model.ConversationParticipant]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Conversation.MarkAsRead(reqCtx, conversationID, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// MuteConversation godoc
+// @Summary Mute a conversation
+// @Description Stops notifications for the conversation until the given time, or until it is unmuted when until is omitted. Messages are still delivered, flagged as muted
+// @Tags conversations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param request body endpoint.MuteConversationRequest false "End of the mute"
+// @Success 200 {object} model.Response[model.ConversationParticipant]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid input or end in the past"
+// @Router /conversations/{conversationID}/mute [put]
+func (h *handler) MuteConversation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.ConversationParticipant]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[*model.ConversationParticipant]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		// The body is optional; an empty one mutes indefinitely
+		var req endpoint.MuteConversationRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			response := model.ValidationError[*model.ConversationParticipant]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Conversation.MuteConversation(reqCtx, conversationID, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// UnmuteConversation godoc
+// @Summary Unmute a conversation
+// @Description Turns the conversation's notifications back on for the authenticated user
+// @Tags conversations
+// @Security BearerAuth
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Success 200 {object} model.Response[model.ConversationParticipant]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid conversation ID"
+// @Router /conversations/{conversationID}/mute [delete]
+func (h *handler) UnmuteConversation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.ConversationParticipant]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[*model.ConversationParticipant]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Conversation.UnmuteConversation(reqCtx, conversationID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// CreateMessage godoc
+// @Summary Create a new message in a conversation
+// @Description Creates a new message in the specified conversation
+// @Tags messages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param request body endpoint.CreateMessageRequest true "Message data"
+// @Success 200 {object} model.Response[model.Message]
+// @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 404 {object} model.Response[any] "Not Found - Conversation not found"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /conversations/{conversationID}/messages [post]
+func (h *handler) CreateMessage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.CreateMessageRequest
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Message]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationIDStr := c.Param("conversationID")
+		conversationID, err := strconv.ParseUint(conversationIDStr, 10, 64)
+		if err != nil {
+			response := model.ValidationError[*model.Message]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		req.ConversationID = uint(conversationID)
+		req.SenderID = reqCtx.UserID
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[*model.Message]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Message.CreateMessage(reqCtx, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// GetMessagesByConversationID godoc
+// @Summary Get messages in a conversation
+// @Description Retrieves a page of messages from the specified conversation, ordered by creation time (newest first)
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param before_id query int false "Return messages older than this message ID"
+// @Param after_id query int false "Return messages newer than this message ID"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Success 200 {object} model.Response[model.Page[model.Message]]
+// @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /conversations/{conversationID}/messages [get]
+func (h *handler) GetMessagesByConversationID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Page[*model.Message]]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationIDStr := c.Param("conversationID")
+		conversationID, err := strconv.ParseUint(conversationIDStr, 10, 64)
+		if err != nil {
+			response := model.ValidationError[*model.Page[*model.Message]]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		var cursor model.CursorParams
+		if err := c.ShouldBindQuery(&cursor); err != nil {
+			response := model.ValidationError[*model.Page[*model.Message]]("Invalid pagination parameters")
+			c.JSON(response.Code, response)
+			return
+		}
+		response := h.endpoints.Message.GetMessagesByConversationID(reqCtx, uint(conversationID), cursor)
+		c.JSON(response.Code, response)
+	}
+}
+
+// GetMessageThread godoc
+// @Summary Get the replies in a message thread
+// @Description Retrieves a page of replies in the thread started by the message (newest first). Passing a reply returns the thread it belongs to
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param messageID path int true "Thread root message ID"
+// @Param before_id query int false "Return replies older than this message ID"
+// @Param after_id query int false "Return replies newer than this message ID"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Success 200 {object} model.Response[model.Page[model.Message]]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 404 {object} model.Response[any] "Not Found - Message not found"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid input"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /messages/{messageID}/thread [get]
+func (h *handler) GetMessageThread() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Page[*model.Message]]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		messageID, err := getUintParam(c, "messageID")
+		if err != nil {
+			response := model.ValidationError[*model.Page[*model.Message]]("Invalid message ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		var cursor model.CursorParams
+		if err := c.ShouldBindQuery(&cursor); err != nil {
+			response := model.ValidationError[*model.Page[*model.Message]]("Invalid pagination parameters")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Message.GetThread(reqCtx, messageID, cursor)
+		c.JSON(response.Code, response)
+	}
+}
+
+// SearchMessages godoc
+// @Summary Search messages
+// @Description Finds messages in the caller's conversations containing a word starting with each word of q, newest first. Each result has an HTML-escaped excerpt with the matching words wrapped in <mark>
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Words to search for"
+// @Param conversation_id query int false "Only search this conversation"
+// @Param sender_id query int false "Only messages from this user"
+// @Param from query string false "Only messages sent at or after this time (RFC 3339)"
+// @Param to query string false "Only messages sent at or before this time (RFC 3339)"
+// @Param before_id query int false "Return matches older than this message ID"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Success 200 {object} model.Response[model.Page[model.MessageSearchResult]]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid input"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /search/messages [get]
+func (h *handler) SearchMessages() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Page[*model.MessageSearchResult]]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		var params model.MessageSearchParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			response := model.ValidationError[*model.Page[*model.MessageSearchResult]]("Invalid search parameters")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Message.SearchMessages(reqCtx, params)
+		c.JSON(response.Code, response)
+	}
+}
+
+// AddReaction godoc
+// @Summary React to a message
+// @Description Adds an emoji reaction from the current user. Reacting twice with the same emoji has no effect
+// @Tags messages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param messageID path int true "Message ID"
+// @Param request body endpoint.ReactionRequest true "Emoji"
+// @Success 200 {object} model.Response[[]model.ReactionSummary]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 404 {object} model.Response[any] "Not Found - Message not found"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid input"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /messages/{messageID}/reactions [post]
+func (h *handler) AddReaction() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[[]*model.ReactionSummary]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		messageID, err := getUintParam(c, "messageID")
+		if err != nil {
+			response := model.ValidationError[[]*model.ReactionSummary]("Invalid message ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		var req endpoint.ReactionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[[]*model.ReactionSummary]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Message.AddReaction(reqCtx, messageID, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// RemoveReaction godoc
+// @Summary Remove a reaction from a message
+// @Description Removes the current user's emoji reaction. Removing a reaction that does not exist has no effect
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param messageID path int true "Message ID"
+// @Param emoji query string true "Emoji to remove"
+// @Success 200 {object} model.Response[[]model.ReactionSummary]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 404 {object} model.Response[any] "Not Found - Message not found"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid input"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /messages/{messageID}/reactions [delete]
+func (h *handler) RemoveReaction() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[[]*model.ReactionSummary]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		messageID, err := getUintParam(c, "messageID")
+		if err != nil {
+			response := model.ValidationError[[]*model.ReactionSummary]("Invalid message ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		var req endpoint.ReactionRequest
+		if err := c.ShouldBindQuery(&req); err != nil {
+			response := model.ValidationError[[]*model.ReactionSummary]("Invalid emoji")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Message.RemoveReaction(reqCtx, messageID, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// EditMessage godoc
+// @Summary Edit a message
+// @Description Replaces the content of a message. Only the sender can edit; the previous content is kept in the edit history
+// @Tags messages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param messageID path int true "Message ID"
+// @Param request body endpoint.EditMessageRequest true "New content"
+// @Success 200 {object} model.Response[model.Message]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member or not the sender"
+// @Failure 404 {object} model.Response[any] "Not Found - Message not found"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid input"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /conversations/{conversationID}/messages/{messageID} [patch]
+func (h *handler) EditMessage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Message]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[*model.Message]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		messageID, err := getUintParam(c, "messageID")
+		if err != nil {
+			response := model.ValidationError[*model.Message]("Invalid message ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		var req endpoint.EditMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[*model.Message]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Message.EditMessage(reqCtx, conversationID, messageID, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// DeleteMessage godoc
+// @Summary Delete a message
+// @Description Soft deletes a message so it is listed as a tombstone. Only the sender can delete
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param messageID path int true "Message ID"
+// @Success 200 {object} model.Response[model.Message]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member or not the sender"
+// @Failure 404 {object} model.Response[any] "Not Found - Message not found"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid input"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /conversations/{conversationID}/messages/{messageID} [delete]
+func (h *handler) DeleteMessage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Message]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[*model.Message]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		messageID, err := getUintParam(c, "messageID")
+		if err != nil {
+			response := model.ValidationError[*model.Message]("Invalid message ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Message.DeleteMessage(reqCtx, conversationID, messageID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// UploadAttachment godoc
+// @Summary Upload a file to a conversation
+// @Description Stores a file for the conversation and returns the attachment to reference from an attachment message. The type is detected from the content
+// @Tags attachments
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param conversationID path int true "Conversation ID"
+// @Param file formData file true "File to upload"
+// @Success 200 {object} model.Response[model.Attachment]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 422 {object} model.Response[any] "Validation Error - Missing file, too large or type not allowed"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /conversations/{conversationID}/attachments [post]
+func (h *handler) UploadAttachment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Attachment]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		conversationID, err := getUintParam(c, "conversationID")
+		if err != nil {
+			response := model.ValidationError[*model.Attachment]("Invalid conversation ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		// Leave room for the multipart envelope around the file itself
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.Config.AttachmentMaxBytes+multipartOverhead)
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			response := model.ValidationError[*model.Attachment]("A file no larger than the upload limit is required")
+			c.JSON(response.Code, response)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			response := model.BadRequest[*model.Attachment]("Failed to read file")
+			c.JSON(response.Code, response)
+			return
+		}
+		defer file.Close()
+
+		response := h.endpoints.Attachment.Upload(reqCtx, endpoint.UploadAttachmentRequest{
+			ConversationID: conversationID,
+			FileName:       fileHeader.Filename,
+			Size:           fileHeader.Size,
+			Body:           file,
+		})
+		c.JSON(response.Code, response)
+	}
+}
+
+// UploadAvatar godoc
+// @Summary Upload a profile picture
+// @Description Stores an image to pick as the avatar with PATCH /me/profile. The type is detected from the content
+// @Tags users
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Image to upload"
+// @Success 200 {object} model.Response[model.Attachment]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 422 {object} model.Response[any] "Validation Error - Missing file, too large or not an allowed image type"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /me/avatar [post]
+func (h *handler) UploadAvatar() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.Attachment]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.Config.AttachmentMaxBytes+multipartOverhead)
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			response := model.ValidationError[*model.Attachment]("A file no larger than the upload limit is required")
+			c.JSON(response.Code, response)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			response := model.BadRequest[*model.Attachment]("Failed to read file")
+			c.JSON(response.Code, response)
+			return
+		}
+		defer file.Close()
+
+		response := h.endpoints.Attachment.UploadAvatar(reqCtx, endpoint.UploadAvatarRequest{
+			FileName: fileHeader.Filename,
+			Size:     fileHeader.Size,
+			Body:     file,
+		})
+		c.JSON(response.Code, response)
+	}
+}
+
+// DownloadAttachment godoc
+// @Summary Download an attachment
+// @Description Streams an attachment to a member of the conversation it was shared in
+// @Tags attachments
+// @Security BearerAuth
+// @Produce application/octet-stream
+// @Param attachmentID path int true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} model.Response[any] "Forbidden - Not a member of the conversation"
+// @Failure 404 {object} model.Response[any] "Not Found - Attachment not found"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /attachments/{attachmentID} [get]
+func (h *handler) DownloadAttachment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[any]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		attachmentID, err := getUintParam(c, "attachmentID")
+		if err != nil {
+			response := model.ValidationError[any]("Invalid attachment ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.Attachment.Download(reqCtx, attachmentID)
+		if !response.OK() {
+			c.JSON(response.Code, response)
+			return
+		}
+		content := response.Data
+		defer content.Body.Close()
+		// DataFromReader keeps an existing Content-Type, so replace any default set earlier
+		c.Writer.Header().Set("Content-Type", content.Attachment.ContentType)
+		c.DataFromReader(http.StatusOK, content.Attachment.Size, content.Attachment.ContentType, content.Body, map[string]string{
+			"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": content.Attachment.FileName}),
+			"X-Content-Type-Options": "nosniff",
+			"Cache-Control":          "private, no-store",
+		})
+	}
+}
+
+// GetPresence godoc
+// @Summary Get online presence for users
+// @Description Returns whether each requested user is online and when they were last seen
+// @Tags users
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {object} model.Response[[]model.User]
+// @Param ids query string true "Comma-separated user IDs (max 100)"
+// @Success 200 {object} model.Response[[]model.UserPresence]
 // @Failure 400 {object} model.Response[any] "Bad Request - Invalid input"
 // @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
 // @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /users/presence [get]
+func (h *handler) GetPresence() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[[]*model.UserPresence]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		userIDs, err := parseUintList(c.Query("ids"))
+		if err != nil {
+			response := model.ValidationError[[]*model.UserPresence]("Invalid user IDs")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.GetPresence(reqCtx, userIDs)
+		c.JSON(response.Code, response)
+	}
+}
+
+// SetPresence godoc
+// @Summary Update a user's presence
+// @Description Internal: called by the socket service when a user's first socket connects or last socket disconnects. Records last_seen_at and notifies the user's contacts
+// @Tags internal
+// @Security ServiceAuth
+// @Accept json
+// @Produce json
+// @Param userID path int true "User ID"
+// @Param request body endpoint.SetPresenceRequest true "Presence state"
+// @Success 200 {object} model.Response[model.UserPresence]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing service key"
+// @Failure 404 {object} model.Response[any] "Not Found - User not found"
+// @Failure 422 {object} model.Response[any] "Validation Error"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /internal/users/{userID}/presence [post]
+func (h *handler) SetPresence() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.SetPresenceRequest
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		userID, err := getUintParam(c, "userID")
+		if err != nil {
+			response := model.ValidationError[*model.UserPresence]("Invalid user ID")
+			c.JSON(response.Code, response)
+			return
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[*model.UserPresence]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.SetPresence(reqCtx, userID, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// ResetPresence godoc
+// @Summary Mark every user offline
+// @Description Internal: called by the socket service when it starts, since no socket survives a restart. Records last_seen_at for the users that were online
+// @Tags internal
+// @Security ServiceAuth
+// @Produce json
+// @Success 200 {object} model.Response[int64] "Number of users marked offline"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing service key"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /internal/presence/reset [post]
+func (h *handler) ResetPresence() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		response := h.endpoints.User.ResetPresence(reqCtx)
+		c.JSON(response.Code, response)
+	}
+}
+
+// GetMyProfile godoc
+// @Summary Get the authenticated user's profile
+// @Description Returns the display name, avatar, bio and custom status of the current user
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.Response[model.UserProfile]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /me/profile [get]
+func (h *handler) GetMyProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.UserProfile]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.GetProfile(reqCtx, reqCtx.UserID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// UpdateProfile godoc
+// @Summary Update the authenticated user's profile
+// @Description Changes the fields present in the body and notifies the users who share a conversation with a profile_updated event. An avatar_attachment_id of 0 removes the avatar; a status with an empty text clears it
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body endpoint.UpdateProfileRequest true "Profile fields to change"
+// @Success 200 {object} model.Response[model.UserProfile]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 422 {object} model.Response[any] "Validation Error - Field too long, past status expiry or unknown avatar"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
+// @Router /me/profile [patch]
+func (h *handler) UpdateProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.UpdateProfileRequest
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.UserProfile]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[*model.UserProfile]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.UpdateProfile(reqCtx, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// ListBlocks godoc
+// @Summary List blocked users
+// @Description Returns the users the authenticated user blocked, most recent first
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.Response[[]model.UserBlock]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Router /me/blocks [get]
+func (h *handler) ListBlocks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[[]*model.UserBlock]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.ListBlocks(reqCtx)
+		c.JSON(response.Code, response)
+	}
+}
+
+// BlockUser godoc
+// @Summary Block a user
+// @Description Blocks a user: neither can start a private conversation with or message the other, and they no longer find each other in the user directory
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body endpoint.BlockUserRequest true "User to block"
+// @Success 200 {object} model.Response[model.UserBlock]
+// @Failure 400 {object} model.Response[any] "Bad Request - Blocking yourself"
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 404 {object} model.Response[any] "Not Found - User does not exist"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid input"
+// @Router /me/blocks [post]
+func (h *handler) BlockUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.UserBlock]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		var req endpoint.BlockUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response := model.ValidationError[*model.UserBlock]("Invalid request body")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.Block(reqCtx, req)
+		c.JSON(response.Code, response)
+	}
+}
+
+// UnblockUser godoc
+// @Summary Unblock a user
+// @Description Removes a block the authenticated user placed on another user
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param userID path int true "Blocked user ID"
+// @Success 200 {object} model.Response[bool]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 404 {object} model.Response[any] "Not Found - User is not blocked"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid user ID"
+// @Router /me/blocks/{userID} [delete]
+func (h *handler) UnblockUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[bool]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		userID, err := getUintParam(c, "userID")
+		if err != nil {
+			response := model.ValidationError[bool]("Invalid user ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.Unblock(reqCtx, userID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// GetUserProfile godoc
+// @Summary Get a user's profile
+// @Description Returns the public profile of a user; an expired status is left out
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param userID path int true "User ID"
+// @Success 200 {object} model.Response[model.UserProfile]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 404 {object} model.Response[any] "Not Found - User does not exist"
+// @Failure 422 {object} model.Response[any] "Validation Error - Invalid user ID"
+// @Router /users/{userID} [get]
+func (h *handler) GetUserProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context())
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.UserProfile]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		userID, err := getUintParam(c, "userID")
+		if err != nil {
+			response := model.ValidationError[*model.UserProfile]("Invalid user ID")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.GetProfile(reqCtx, userID)
+		c.JSON(response.Code, response)
+	}
+}
+
+// GetUsers godoc
+// @Summary Search the user directory
+// @Description Returns a page of public user profiles. With q, users whose username or display name starts with it come first, then those containing its characters in order; without q every user is listed by username. Deactivated users and users blocked either way are left out
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Search text (max 64 characters)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Success 200 {object} model.Response[model.UserSearchPage]
+// @Failure 401 {object} model.Response[any] "Unauthorized - Invalid or missing token"
+// @Failure 422 {object} model.Response[any] "Validation Error - Query too long or invalid cursor"
+// @Failure 500 {object} model.Response[any] "Internal Server Error"
 // @Router /users [get]
 func (h *handler) GetUsers() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reqCtx := model.NewRequestContext(c.Request.Context())
-		response := h.endpoints.Auth.GetUsers(reqCtx)
+		if reqCtx.UserID == 0 {
+			response := model.Unauthorized[*model.UserSearchPage]("Unauthorized")
+			c.JSON(response.Code, response)
+			return
+		}
+		var params model.UserSearchParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			response := model.ValidationError[*model.UserSearchPage]("Invalid search parameters")
+			c.JSON(response.Code, response)
+			return
+		}
+
+		response := h.endpoints.User.SearchUsers(reqCtx, params)
 		c.JSON(response.Code, response)
 	}
 }
@@ -1,9 +1,7 @@
 package httpTransport
 
 import (
-	"encoding/base64"
-	"encoding/json"
-	"errors"
+	"crypto/subtle"
 	"fmt"
 	"local/config"
 	"local/endpoint"
@@ -16,37 +14,6 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func decodeJWT(tokenString string) (map[string]interface{}, error) {
-	// Split the token into parts
-	parts := strings.Split(tokenString, ".")
-	if len(parts) != 3 {
-		return nil, errors.New("invalid JWT format")
-	}
-
-	// Decode the payload (second part)
-	payload := parts[1]
-	
-	// Add padding if necessary
-	if len(payload)%4 != 0 {
-		payload += strings.Repeat("=", 4-len(payload)%4)
-	}
-
-	// Base64 decode
-	decoded, err := base64.URLEncoding.DecodeString(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Parse JSON
-	var claims map[string]interface{}
-	err = json.Unmarshal(decoded, &claims)
-	if err != nil {
-		return nil, err
-	}
-
-	return claims, nil
-}
-
 func getToken(c *gin.Context) string {
 	token := c.GetHeader("Authorization")
 	if token == "" {
@@ -58,27 +25,26 @@ func getToken(c *gin.Context) string {
 	return token
 }
 
-func TokenMiddleware() gin.HandlerFunc {
+// authErrorKey holds why a presented token was rejected, for ProtectedMiddleware to report
+const authErrorKey = "auth_error"
+
+// AuthMiddleware verifies the bearer token once per request. Only the claims of a
+// token that passes signature, expiry and revocation checks reach the request
+// context, so the rate limiter and every handler reading reqCtx.UserID can trust it.
+// A missing or rejected token leaves the request anonymous; ProtectedMiddleware
+// turns that into a 401 on routes that need a user
+func AuthMiddleware(endpoints *endpoint.Endpoints) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := getToken(c)
-		// Add token to request context
 		if token != "" {
-			// Parse token to extract user_id and session_id
-			claims, err := decodeJWT(token)
-			if err != nil {
-				// Don't abort here, just skip adding context
-				// Let ProtectedMiddleware handle authentication
-			} else {
-				var userID uint
-				if uid, ok := claims["user_id"].(float64); ok {
-					userID = uint(uid)
-				}
-				sessionID := ""
-				if sid, ok := claims["session_id"].(string); ok {
-					sessionID = sid
-				}
-				reqCtx := model.NewRequestContext(c.Request.Context()).WithClaims(token, userID, sessionID)
+			reqCtx := model.NewRequestContext(c.Request.Context())
+			response := endpoints.Auth.VerifyToken(reqCtx, token)
+			if response.OK() {
+				claims := response.Data
+				reqCtx = reqCtx.WithClaims(token, claims.UserID, claims.SessionID)
 				c.Request = c.Request.WithContext(reqCtx.Context())
+			} else {
+				c.Set(authErrorKey, response.ErrorString())
 			}
 		}
 
@@ -86,22 +52,34 @@ func TokenMiddleware() gin.HandlerFunc {
 	}
 }
 
-func SetupMiddleware(r *gin.Engine) {
+// ClientInfoMiddleware records the caller's IP and user agent on the request context
+func ClientInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqCtx := model.NewRequestContext(c.Request.Context()).WithClient(getClientIP(c), c.Request.UserAgent())
+		c.Request = c.Request.WithContext(reqCtx.Context())
+		c.Next()
+	}
+}
+
+func SetupMiddleware(r *gin.Engine, endpoints *endpoint.Endpoints) {
 	// OpenTelemetry tracing middleware (must be first)
 	r.Use(otelgin.Middleware("simple-chat-api"))
 
 	// CORS middleware
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"*"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	config.AllowHeaders = []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"}
 	config.ExposeHeaders = []string{"Link", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
 	config.AllowCredentials = true
 	config.MaxAge = 12 * time.Hour
 	r.Use(cors.New(config))
 
-	// Token middleware (must be before rate limit to populate user_id)
-	r.Use(TokenMiddleware())
+	// Client info middleware (records IP and user agent for session tracking)
+	r.Use(ClientInfoMiddleware())
+
+	// Auth middleware (must be before rate limit to populate a verified user_id)
+	r.Use(AuthMiddleware(endpoints))
 
 	// Rate limit middleware
 	r.Use(RateLimitMiddleware())
@@ -124,12 +102,39 @@ func JSONContentTypeMiddleware() gin.HandlerFunc {
 	}
 }
 
-func ProtectedMiddleware(endpoints *endpoint.Endpoints) gin.HandlerFunc {
+// ProtectedMiddleware rejects requests that AuthMiddleware did not authenticate
+func ProtectedMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reqCtx := model.NewRequestContext(c.Request.Context())
-		response := endpoints.Auth.Authenticate(reqCtx)
-		if !response.OK() {
-			Unauthorized(c, response.ErrorString())
+		if reqCtx.UserID == 0 {
+			message := c.GetString(authErrorKey)
+			if message == "" {
+				message = "Token is required"
+			}
+			Unauthorized(c, message)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// serviceKeyHeader carries the shared key internal services call the backend with
+const serviceKeyHeader = "X-Service-Key"
+
+// isServiceRequest reports whether the request presents the configured internal service key
+func isServiceRequest(c *gin.Context) bool {
+	key := config.Config.InternalServiceKey
+	presented := c.GetHeader(serviceKeyHeader)
+	return key != "" && presented != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(key)) == 1
+}
+
+// ServiceMiddleware admits only other services of the deployment, such as the
+// socket service, to internal routes. User tokens are not accepted there
+func ServiceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isServiceRequest(c) {
+			Unauthorized(c, "Service key is required")
 			c.Abort()
 			return
 		}
@@ -162,6 +167,12 @@ func RateLimitMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Internal services call on behalf of every user from one address
+		if isServiceRequest(c) {
+			c.Next()
+			return
+		}
+
 		// Determine rate limit key
 		var limiterKey string
 		reqCtx := model.NewRequestContext(c.Request.Context())
@@ -1,7 +1,6 @@
 package httpTransport
 
 import (
-	"net"
 	"sync"
 	"time"
 
@@ -74,29 +73,9 @@ func (rlm *RateLimiterManager) cleanupVisitors() {
 	}
 }
 
-// getClientIP extracts the client IP address from the request
-// It checks X-Forwarded-For, X-Real-IP headers, and falls back to RemoteAddr
+// getClientIP returns the address failed logins, sessions and rate limits are keyed
+// on. Forwarding headers are only honoured from config.Config.TrustedProxies, since
+// anyone else can write any address into them
 func getClientIP(c *gin.Context) string {
-	// Check X-Forwarded-For header first (for proxies/load balancers)
-	xff := c.GetHeader("X-Forwarded-For")
-	if xff != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		if ip, _, err := net.SplitHostPort(xff); err == nil {
-			return ip
-		}
-		return xff
-	}
-
-	// Check X-Real-IP header
-	xri := c.GetHeader("X-Real-IP")
-	if xri != "" {
-		return xri
-	}
-
-	// Fall back to RemoteAddr
-	if ip, _, err := net.SplitHostPort(c.Request.RemoteAddr); err == nil {
-		return ip
-	}
-
-	return c.Request.RemoteAddr
+	return c.ClientIP()
 }
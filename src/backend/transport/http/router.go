@@ -16,34 +16,102 @@ func handleRouter(r *gin.Engine, endpoints *endpoint.Endpoints) {
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Public signing keys for verifying tokens
+	r.GET("/.well-known/jwks.json", h.GetJWKS())
+
 	// API routes
 	v1 := r.Group("/api/v1")
 	{
 		// Auth endpoints
 		v1.POST("/register", h.Register())
 		v1.POST("/login", h.Login())
+		v1.POST("/login/mfa", h.VerifyMFA())
+		v1.GET("/login/oidc", h.OIDCProviders())
+		v1.POST("/login/oidc/:provider", h.StartOIDCLogin())
+		v1.POST("/login/oidc/:provider/callback", h.CompleteOIDCLogin())
+		v1.POST("/token/refresh", h.RefreshToken())
+
+		// Internal routes, called by the socket service with the service key
+		internal := v1.Group("/internal")
+		internal.Use(ServiceMiddleware())
+		{
+			internal.POST("/presence/reset", h.ResetPresence())
+			internal.POST("/users/:userID/presence", h.SetPresence())
+			internal.GET("/users/:userID/conversations/:conversationID/participants", h.GetParticipantsForMember())
+		}
 
 		// Protected routes
 		protected := v1.Group("")
-		protected.Use(ProtectedMiddleware(endpoints))
+		protected.Use(ProtectedMiddleware())
 		{
 			protected.POST("/logout", h.Logout())
+			protected.POST("/logout/all", h.LogoutAll())
 			protected.GET("/me", h.GetMe())
+			protected.POST("/me/password", h.ChangePassword())
+			protected.GET("/me/profile", h.GetMyProfile())
+			protected.PATCH("/me/profile", h.UpdateProfile())
+			protected.POST("/me/avatar", h.UploadAvatar())
+			protected.GET("/me/blocks", h.ListBlocks())
+			protected.POST("/me/blocks", h.BlockUser())
+			protected.DELETE("/me/blocks/:userID", h.UnblockUser())
+
+			// Two-factor authentication endpoints
+			mfa := protected.Group("/me/mfa")
+			{
+				mfa.POST("/enroll", h.EnrollMFA())
+				mfa.POST("/confirm", h.ConfirmMFA())
+				mfa.POST("/disable", h.DisableMFA())
+			}
+
+			// Session endpoints
+			sessions := protected.Group("/sessions")
+			{
+				sessions.GET("/", h.ListSessions())
+				sessions.DELETE("/:sessionID", h.DeleteSession())
+			}
 
 			// Users endpoints
 			users := protected.Group("/users")
 			{
 				users.GET("/", h.GetUsers())
+				users.GET("/presence", h.GetPresence())
+				users.GET("/:userID", h.GetUserProfile())
 			}
 
+			// Message endpoints
+			messages := protected.Group("/messages")
+			{
+				messages.GET("/:messageID/thread", h.GetMessageThread())
+				messages.POST("/:messageID/reactions", h.AddReaction())
+				messages.DELETE("/:messageID/reactions", h.RemoveReaction())
+			}
+
+			// Search endpoints
+			protected.GET("/search/messages", h.SearchMessages())
+
+			// Attachment endpoints
+			protected.GET("/attachments/:attachmentID", h.DownloadAttachment())
+
 			// Conversation endpoints
 			conversations := protected.Group("/conversations")
 			{
 				conversations.POST("/", h.CreateConversation())
 				conversations.GET("/", h.GetConversations())
 				conversations.GET("/user/:userID", h.GetConversationByUserID())
+				conversations.POST("/group", h.CreateGroupConversation())
+				conversations.GET("/:conversationID/participants", h.GetParticipants())
+				conversations.POST("/:conversationID/participants", h.AddParticipants())
+				conversations.DELETE("/:conversationID/participants/me", h.LeaveConversation())
+				conversations.DELETE("/:conversationID/participants/:userID", h.RemoveParticipant())
+				conversations.POST("/:conversationID/participants/:userID/promote", h.PromoteParticipant())
+				conversations.POST("/:conversationID/read", h.MarkAsRead())
+				conversations.PUT("/:conversationID/mute", h.MuteConversation())
+				conversations.DELETE("/:conversationID/mute", h.UnmuteConversation())
+				conversations.POST("/:conversationID/attachments", h.UploadAttachment())
 				conversations.POST("/:conversationID/messages", h.CreateMessage())
 				conversations.GET("/:conversationID/messages", h.GetMessagesByConversationID())
+				conversations.PATCH("/:conversationID/messages/:messageID", h.EditMessage())
+				conversations.DELETE("/:conversationID/messages/:messageID", h.DeleteMessage())
 			}
 		}
 	}
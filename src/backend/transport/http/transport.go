@@ -4,19 +4,26 @@ import (
 	"local/config"
 	"local/endpoint"
 	"local/model"
+	"local/util/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
 func MakeHttpTransport(initParams *model.InitParams, endpoints *endpoint.Endpoints) *gin.Engine {
 	r := gin.Default()
+	// Forwarding headers are only believed from the configured proxies; everyone
+	// else is keyed on the address they connected from
+	if err := r.SetTrustedProxies(config.Config.TrustedProxies); err != nil {
+		logger.Warn(nil, "Invalid TRUSTED_PROXIES, trusting none", map[string]interface{}{"error": err.Error()})
+		_ = r.SetTrustedProxies(nil)
+	}
 
 	// Initialize rate limiter if enabled
 	if config.Config.RateLimitEnabled {
 		InitRateLimiter(config.Config.RateLimitRequestsPerMin, config.Config.RateLimitBurst)
 	}
 
-	SetupMiddleware(r)
+	SetupMiddleware(r, endpoints)
 
 	handleRouter(r, endpoints)
 
@@ -60,9 +60,27 @@ function App() {
       setConnectId(connectId);
     });
 
+    // The session was logged out elsewhere; its token no longer works
+    const signalRevoked = socket.on("session_revoked", () => {
+      localStorage.removeItem("authToken");
+      localStorage.removeItem("refreshToken");
+      localStorage.removeItem("user");
+      setUser(null);
+    });
+
+    // Hand the socket the renewed access token so its backend calls keep working
+    const reauthenticate = () => {
+      socket.emit("authenticate", {
+        token: localStorage.getItem("authToken"),
+      });
+    };
+    window.addEventListener("auth:token-refreshed", reauthenticate);
+
     return () => {
       signalConnected.remove();
       signalConn.remove();
+      signalRevoked.remove();
+      window.removeEventListener("auth:token-refreshed", reauthenticate);
       socket.disconnect();
     };
   }, [user]);
@@ -36,11 +36,13 @@ const auth = {
     }
   },
 
-  // Get users
+  // Search the user directory; pass the previous page's next_cursor to continue
 
-  getUsers: async () => {
+  getUsers: async (query = '', cursor = '') => {
     try {
-      const response = await client.get('/users');
+      const response = await client.get('/users', {
+        params: { q: query || undefined, cursor: cursor || undefined }
+      });
       return response.data;
     } catch (error) {
       throw new Error(error.response?.data?.message || 'Failed to get users');
@@ -56,6 +58,20 @@ const auth = {
     } finally {
       // Clear auth data regardless of API response
       localStorage.removeItem('authToken');
+      localStorage.removeItem('refreshToken');
+      localStorage.removeItem('user');
+    }
+  },
+
+  // Logout every session of the user, including this one
+  logoutAll: async () => {
+    try {
+      await client.post('/logout/all');
+    } catch (error) {
+      console.error('Logout all error:', error);
+    } finally {
+      localStorage.removeItem('authToken');
+      localStorage.removeItem('refreshToken');
       localStorage.removeItem('user');
     }
   },
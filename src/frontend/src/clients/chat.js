@@ -19,6 +19,12 @@ const chat = {
     const response = await client.get(`/conversations/${conversationId}/messages`);
     return response.data;
   },
+  getPresence: async (userIds) => {
+    const response = await client.get(`/users/presence`, {
+      params: { ids: userIds.join(",") },
+    });
+    return response.data;
+  },
   sendMessage: async (conversationId, message) => {
     const response = await client.post(`/conversations/${conversationId}/messages`, { ...message });
     return response.data;
@@ -24,15 +24,48 @@ client.interceptors.request.use(
   }
 );
 
-// Response interceptor to handle 401 errors
+// Refresh tokens work only once, so concurrent 401s must share a single refresh
+let refreshing = null;
+
+const refreshTokens = async () => {
+  const refreshToken = localStorage.getItem('refreshToken');
+  if (!refreshToken) {
+    throw new Error('No refresh token');
+  }
+  const response = await axios.post(`${config.apiUrl}/token/refresh`, {
+    refresh_token: refreshToken,
+  });
+  const { token, refresh_token } = response.data.data;
+  localStorage.setItem('authToken', token);
+  localStorage.setItem('refreshToken', refresh_token);
+  window.dispatchEvent(new Event('auth:token-refreshed'));
+  return token;
+};
+
+// Response interceptor to renew expired access tokens and handle 401 errors
 client.interceptors.response.use(
   (response) => {
     return response;
   },
-  (error) => {
+  async (error) => {
+    const request = error.config;
+    if (error.response?.status === 401 && request && !request._retried) {
+      request._retried = true;
+      try {
+        refreshing = refreshing || refreshTokens();
+        const token = await refreshing;
+        request.headers.Authorization = `Bearer ${token}`;
+        return client(request);
+      } catch {
+        // Fall through to logging out
+      } finally {
+        refreshing = null;
+      }
+    }
     if (error.response?.status === 401) {
       // Remove token and redirect to login
       localStorage.removeItem('authToken');
+      localStorage.removeItem('refreshToken');
       window.location.href = '/';
     }
     return Promise.reject(error);
@@ -9,8 +9,9 @@ const AuthContainer = ({ onAuthSuccess }) => {
 
   const handleLogin = async (loginData) => {
     try {
-      const {data: {token}} = await auth.login(loginData);
+      const {data: {token, refresh_token}} = await auth.login(loginData);
       localStorage.setItem('authToken', token);
+      localStorage.setItem('refreshToken', refresh_token);
       const {data: user} = await auth.getMe();
       localStorage.setItem('user', JSON.stringify(user));
       
@@ -32,10 +33,11 @@ const AuthContainer = ({ onAuthSuccess }) => {
   const handleRegister = async (registerData) => {
     try {
       const {data: user} = await auth.register(registerData);
-      const {data: { token }} = await auth.login(registerData);
+      const {data: { token, refresh_token }} = await auth.login(registerData);
       
-      // Store token in localStorage
+      // Store tokens in localStorage
       localStorage.setItem('authToken', token);
+      localStorage.setItem('refreshToken', refresh_token);
       localStorage.setItem('user', JSON.stringify(user));
       
       // Call success callback
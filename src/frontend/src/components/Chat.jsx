@@ -6,6 +6,11 @@ import { socket, eventListener } from "@/App";
 
 let count = 2;
 
+const displayContent = (message) => {
+  if (message.deleted_at) return "_This message was deleted_";
+  return message.edited_at ? `${message.content} _(edited)_` : message.content;
+};
+
 export function ChatComponent({ onLogout, user, connectId }) {
   const [conversationId, setConversationId] = useState(null);
   const [userId, setUserId] = useState(null);
@@ -68,13 +73,13 @@ export function ChatComponent({ onLogout, user, connectId }) {
   const handleConversationSelect = async (conversationId) => {
     setConversationId(conversationId);
     setMessages([]);
-    const { data: messages } = await chat.getMessages(conversationId);
+    const { data: { items: messages } } = await chat.getMessages(conversationId);
     setMessages(
       messages
         .map((message) => ({
           id: message.id,
           role: user.id === message.sender_id ? "user" : "assistant",
-          content: message.content,
+          content: displayContent(message),
           // createdAt: new Date(message.created_at),
         }))
         .reverse()
@@ -101,8 +106,23 @@ export function ChatComponent({ onLogout, user, connectId }) {
       }
     });
 
+    const replaceMessage = ({ message }) => {
+      if (message.conversation_id != conversationId) return;
+      setMessages((prevMessages) =>
+        prevMessages.map((prev) =>
+          prev.id === message.id
+            ? { ...prev, content: displayContent(message) }
+            : prev
+        )
+      );
+    };
+    const updatedSignal = socket.on("message_updated", replaceMessage);
+    const deletedSignal = socket.on("message_deleted", replaceMessage);
+
     return () => {
       msgSignal.remove();
+      updatedSignal.remove();
+      deletedSignal.remove();
     };
   }, [conversationId]);
 
@@ -8,18 +8,28 @@ const ChatList = ({ onLogout, userId, onUserSelect, onConversationSelect, user,
   const [users, setUsers] = useState([]);
   const [conversations, setConversations] = useState([]);
   const [activeTab, setActiveTab] = useState("conversations");
+  const [online, setOnline] = useState({});
 
   const handleTabClick = (tab) => {
     setActiveTab(tab);
   };
 
   const fetchConversations = async () => {
-    const { data: conversations } = await chat.getConversations();
+    const { data: { items: conversations } } = await chat.getConversations();
     setConversations(conversations);
+    fetchPresence(conversations);
+  };
+
+  const fetchPresence = async (conversations) => {
+    const userIds = [...new Set(conversations.flatMap((conv) => conv.user_ids))]
+      .filter((uId) => uId !== user.id);
+    if (userIds.length === 0) return;
+    const { data: presences } = await chat.getPresence(userIds);
+    setOnline(Object.fromEntries(presences.map((p) => [p.user_id, p.online])));
   };
 
   const fetchUsers = async () => {
-    const { data: users } = await auth.getUsers();
+    const { data: { items: users } } = await auth.getUsers();
     setUsers(users);
   };
 
@@ -54,9 +64,10 @@ const ChatList = ({ onLogout, userId, onUserSelect, onConversationSelect, user,
   }, [activeTab]);
 
   useEffect(() => {
-    const msgSignal = socket.on("message", ({message}) => {
+    const msgSignal = socket.on("message", ({message, muted}) => {
       console.log(message);
-      if (message.conversation_id !== conversationId && message.sender_id !== user.id) {
+      // Muted conversations still update, just without a notification
+      if (!muted && message.conversation_id !== conversationId && message.sender_id !== user.id) {
         toast.success("New message received", {
           position: "top-right",
           style: {
@@ -91,6 +102,19 @@ const ChatList = ({ onLogout, userId, onUserSelect, onConversationSelect, user,
     }
   }, []);
 
+  useEffect(() => {
+    const onlineSignal = socket.on("presence_online", ({ user_id }) => {
+      setOnline((prev) => ({ ...prev, [user_id]: true }));
+    });
+    const offlineSignal = socket.on("presence_offline", ({ user_id }) => {
+      setOnline((prev) => ({ ...prev, [user_id]: false }));
+    });
+    return () => {
+      onlineSignal.remove();
+      offlineSignal.remove();
+    }
+  }, []);
+
   return (
     <div className="w-70 bg-white border rounded-lg mr-4 h-full overflow-y-auto flex flex-col">
       <div className="p-4 border-b">
@@ -123,12 +147,12 @@ const ChatList = ({ onLogout, userId, onUserSelect, onConversationSelect, user,
             {activeTab === "people" ? (
               users.map((user) => (
                 <div
-                  key={user.id}
-                  className={`p-3 hover:bg-gray-50 rounded-lg cursor-pointer border-l-4 bg-blue-50 ${user.id === userId ? "border-blue-500" : ""}`}
-                  onClick={() => handleUserSelect(user.id)}
+                  key={user.user_id}
+                  className={`p-3 hover:bg-gray-50 rounded-lg cursor-pointer border-l-4 bg-blue-50 ${user.user_id === userId ? "border-blue-500" : ""}`}
+                  onClick={() => handleUserSelect(user.user_id)}
                 >
                   <div className="font-medium text-sm text-gray-800">
-                    {user.username}
+                    {user.display_name || user.username}
                   </div>
                 </div>
               ))
@@ -141,7 +165,10 @@ const ChatList = ({ onLogout, userId, onUserSelect, onConversationSelect, user,
                   className={`p-3 hover:bg-gray-50 rounded-lg cursor-pointer border-l-4 bg-blue-50 ${conversation?.id === conversationId ? "border-blue-500" : ""}`}
                   onClick={() => handleConversationSelect(conversation)}
                 >
-                  <div className="font-medium text-sm text-gray-800">
+                  <div className="font-medium text-sm text-gray-800 flex items-center gap-2">
+                    {conversation.user_ids.some((uId) => uId !== user.id && online[uId]) && (
+                      <span className="inline-block w-2 h-2 rounded-full bg-green-500" />
+                    )}
                     {conversation.participants.find(
                       (participant) => participant.user_id !== user.id
                     )?.user?.username ?? conversation.participants[0].user.username}
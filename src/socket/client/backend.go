@@ -1,12 +1,17 @@
 package client
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"local/config"
 	"net/http"
 	"time"
 )
 
+// serviceKeyHeader carries config.Config.ServiceKey on calls to internal endpoints
+const serviceKeyHeader = "X-Service-Key"
+
 type User struct {
 	ID          uint           `json:"id"`
 	UserName    string         `json:"username"`
@@ -14,6 +19,11 @@ type User struct {
 	UpdatedAt   time.Time      `json:"updated_at"`
 }
 
+type Participant struct {
+	UserID         uint `json:"user_id"`
+	ConversationID uint `json:"conversation_id"`
+}
+
 type Response[T any] struct {
 	Data  *T      `json:"data"`
 	Error string `json:"error"`
@@ -43,5 +53,67 @@ func GetMe(token string) (*User, error) {
 	return response.Data, nil
 }
 
+// GetParticipants lists the conversation's participants on behalf of userID.
+// The backend refuses with 403 when the user is not a member. It authenticates
+// as this service, so it does not depend on the user's token still being valid.
+func GetParticipants(userID, conversationID uint) ([]Participant, error) {
+	path := fmt.Sprintf("/api/v1/internal/users/%d/conversations/%d/participants", userID, conversationID)
+	var response Response[[]Participant]
+	if err := callInternal("GET", path, nil, &response); err != nil {
+		return nil, fmt.Errorf("get participants failed: %w", err)
+	}
+	if response.Data == nil {
+		return nil, nil
+	}
 
+	return *response.Data, nil
+}
 
+// SetPresence records a user's online state on the backend, which persists
+// last_seen_at and notifies the user's contacts. It authenticates as this
+// service, so it works after the user's token has expired or been revoked.
+func SetPresence(userID uint, online bool) error {
+	body, err := json.Marshal(map[string]bool{"online": online})
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/v1/internal/users/%d/presence", userID)
+	if err := callInternal("POST", path, body, nil); err != nil {
+		return fmt.Errorf("set presence failed: %w", err)
+	}
+	return nil
+}
+
+// ResetPresence marks every user offline; called on startup, when no socket is connected
+func ResetPresence() error {
+	if err := callInternal("POST", "/api/v1/internal/presence/reset", nil, nil); err != nil {
+		return fmt.Errorf("reset presence failed: %w", err)
+	}
+	return nil
+}
+
+// callInternal calls one of the backend's internal endpoints with the service key
+// and decodes the response into out when it is not nil
+func callInternal(method, path string, body []byte, out any) error {
+	req, err := http.NewRequest(method, config.Config.BackendServerURL+path, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(serviceKeyHeader, config.Config.ServiceKey)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
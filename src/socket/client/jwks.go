@@ -0,0 +1,143 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"local/config"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksMaxAge is how long fetched keys are trusted before they are fetched again
+	jwksMaxAge = 10 * time.Minute
+	// jwksMinRefresh throttles refetches, so tokens naming made-up kids cannot flood the backend
+	jwksMinRefresh = 10 * time.Second
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+type verificationKey struct {
+	alg string
+	key any
+}
+
+// keyCache holds the backend's published signing keys, refetched when they go
+// stale or when a token names a kid it has not seen (the backend rotated)
+type keyCache struct {
+	lock        sync.Mutex
+	keys        map[string]verificationKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+var signingKeys = &keyCache{}
+
+// SigningKey resolves the backend key a token was signed with, for jwt.Parse
+func SigningKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := signingKeys.get(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.key, nil
+}
+
+func (c *keyCache) get(kid string) (verificationKey, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key, ok := c.keys[kid]
+	stale := time.Since(c.fetchedAt) > jwksMaxAge
+	if (!ok || stale) && time.Since(c.attemptedAt) > jwksMinRefresh {
+		c.attemptedAt = time.Now()
+		keys, err := fetchJWKS()
+		if err != nil {
+			// Keep verifying with the keys we have until the backend is reachable again
+			log.Default().Print("fetch jwks error ", err)
+		} else {
+			c.keys, c.fetchedAt = keys, time.Now()
+			key, ok = c.keys[kid]
+		}
+	}
+	if !ok {
+		return verificationKey{}, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func fetchJWKS() (map[string]verificationKey, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(config.Config.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get jwks failed with status %d", resp.StatusCode)
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]verificationKey, len(document.Keys))
+	for _, k := range document.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			log.Default().Printf("skip jwk %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = verificationKey{alg: k.Alg, key: key}
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch {
+	case k.Kty == "RSA" && k.Alg == jwt.SigningMethodRS256.Alg():
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519" && k.Alg == jwt.SigningMethodEdDSA.Alg():
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key %s/%s", k.Kty, k.Alg)
+	}
+}
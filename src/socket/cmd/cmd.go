@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"local/client"
 	"local/config"
 	"local/event"
 	"local/libs/socket"
@@ -16,6 +17,11 @@ func Run() {
 	config.LoadConfig()
 	router := http.NewServeMux()
 
+	// Sockets do not survive a restart, so nobody the backend thinks is online still is
+	if err := client.ResetPresence(); err != nil {
+		log.Error("reset presence error ", err)
+	}
+
 	socketServer := socket.NewServer(router)
 	event.RegisterEvent(socketServer)
 	Router.Register(router, socketServer)
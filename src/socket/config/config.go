@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 type ServiceConfig struct {
@@ -11,7 +12,13 @@ type ServiceConfig struct {
 	
 
 	BackendServerURL string
-	SecretKey string
+	// JWKSURL publishes the backend's token verification keys
+	JWKSURL string
+	// ServiceKey authenticates this service on the backend's internal endpoints
+	ServiceKey string
+
+	// TypingTimeout is how long a typing indicator lives without being refreshed
+	TypingTimeout time.Duration
 }
 
 var Config = ServiceConfig{}
@@ -35,12 +42,24 @@ func LoadConfig() {
 
 
 	backendServerURL := getEnv("BACKEND_SERVER_URL", "http://localhost")
-	secretKey := getEnv("JWT_SECRET", "your-super-secret-jwt")
+	jwksURL := getEnv("JWKS_URL", "")
+	if jwksURL == "" {
+		jwksURL = backendServerURL + "/.well-known/jwks.json"
+	}
+
+	serviceKey := getEnv("INTERNAL_SERVICE_KEY", "")
+
+	typingTimeoutSeconds, err := strconv.Atoi(getEnv("TYPING_TIMEOUT_SECONDS", "6"))
+	if err != nil || typingTimeoutSeconds <= 0 {
+		typingTimeoutSeconds = 6
+	}
 
 	Config = ServiceConfig{
 		HTTPPort: httpPortInt,
 		Host:     host,
 		BackendServerURL: backendServerURL,
-		SecretKey: secretKey,
+		JWKSURL: jwksURL,
+		ServiceKey: serviceKey,
+		TypingTimeout: time.Duration(typingTimeoutSeconds) * time.Second,
 	}
 }
@@ -0,0 +1,64 @@
+package event
+
+import (
+	"local/client"
+	"log"
+	"sync"
+)
+
+// presenceTracker counts live sockets per user so presence only flips when a
+// user's first socket authenticates or their last socket goes away.
+type presenceTracker struct {
+	lock  sync.Mutex
+	conns map[uint]map[string]struct{}
+	users map[string]uint
+}
+
+func newPresenceTracker() *presenceTracker {
+	return &presenceTracker{
+		conns: make(map[uint]map[string]struct{}),
+		users: make(map[string]uint),
+	}
+}
+
+// connect registers a socket and reports whether it is the user's first one.
+// Registering the same socket again is a no-op; a socket that switches users
+// must disconnect from the previous one first
+func (p *presenceTracker) connect(connID string, userID uint) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.users[connID]; ok {
+		return false
+	}
+	p.users[connID] = userID
+	if p.conns[userID] == nil {
+		p.conns[userID] = make(map[string]struct{})
+	}
+	p.conns[userID][connID] = struct{}{}
+	return len(p.conns[userID]) == 1
+}
+
+// disconnect forgets a socket and reports whether it was the user's last one
+func (p *presenceTracker) disconnect(connID string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	userID, ok := p.users[connID]
+	if !ok {
+		return false
+	}
+	delete(p.users, connID)
+	delete(p.conns[userID], connID)
+	if len(p.conns[userID]) > 0 {
+		return false
+	}
+	delete(p.conns, userID)
+	return true
+}
+
+func updatePresence(userID uint, online bool) {
+	if err := client.SetPresence(userID, online); err != nil {
+		log.Default().Print("update presence error ", err)
+	}
+}
@@ -0,0 +1,90 @@
+package event
+
+import "testing"
+
+// presenceStep connects (userID > 0) or disconnects (userID == 0) a socket and
+// says whether presence should flip
+type presenceStep struct {
+	connID string
+	userID uint
+	flips  bool
+}
+
+func TestPresenceTracker(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []presenceStep
+	}{
+		{
+			name: "only the first and last socket of a user flip presence",
+			steps: []presenceStep{
+				{connID: "a", userID: 1, flips: true},
+				{connID: "b", userID: 1, flips: false},
+				{connID: "a", flips: false},
+				{connID: "b", flips: true},
+			},
+		},
+		{
+			name: "users are counted separately",
+			steps: []presenceStep{
+				{connID: "a", userID: 1, flips: true},
+				{connID: "b", userID: 2, flips: true},
+				{connID: "a", flips: true},
+				{connID: "b", flips: true},
+			},
+		},
+		{
+			name: "re-authenticating a socket as the same user counts it once",
+			steps: []presenceStep{
+				{connID: "a", userID: 1, flips: true},
+				{connID: "a", userID: 1, flips: false},
+				{connID: "a", flips: true},
+				{connID: "a", flips: false},
+			},
+		},
+		{
+			name: "re-authenticating a socket as another user moves it",
+			steps: []presenceStep{
+				{connID: "a", userID: 1, flips: true},
+				{connID: "b", userID: 2, flips: true},
+				{connID: "a", flips: true},
+				{connID: "a", userID: 2, flips: false},
+				{connID: "b", flips: false},
+				{connID: "a", flips: true},
+			},
+		},
+		{
+			name: "a socket that never authenticated changes nothing",
+			steps: []presenceStep{
+				{connID: "a", userID: 1, flips: true},
+				{connID: "b", flips: false},
+				{connID: "a", flips: true},
+			},
+		},
+		{
+			name: "a user comes back online after their last socket closed",
+			steps: []presenceStep{
+				{connID: "a", userID: 1, flips: true},
+				{connID: "a", flips: true},
+				{connID: "b", userID: 1, flips: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newPresenceTracker()
+			for i, step := range tt.steps {
+				var flipped bool
+				if step.userID > 0 {
+					flipped = tracker.connect(step.connID, step.userID)
+				} else {
+					flipped = tracker.disconnect(step.connID)
+				}
+				if flipped != step.flips {
+					t.Errorf("step %d (%+v) flipped presence: %v", i, step, flipped)
+				}
+			}
+		})
+	}
+}
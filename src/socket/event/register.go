@@ -10,12 +10,17 @@ type authenticate struct {
 	Token string `json:"token"`
 }
 
+const userKey = "user"
+
 func RegisterEvent(socketServer SK.Server) {
+	typingTracker := newTypingTracker(socketServer)
+	presenceTracker := newPresenceTracker()
+
 	socketServer.Register(ChatPath, func(socket SK.Socket) {
 		socket.On("connected", func(_ any) {
 			socket.Emit("send_connect_id", socket.GetId())
 		})
-		
+
 		socket.On("authenticate", func(payload any) {
 			data := authenticate{}
 			err := mapData(payload, &data)
@@ -28,9 +33,78 @@ func RegisterEvent(socketServer SK.Server) {
 				socket.Emit("authenticate_fail", err)
 				return
 			}
+			if me == nil {
+				socket.Emit("authenticate_fail", nil)
+				return
+			}
+			if previous, ok := authenticated(socket); ok && previous.ID != me.ID {
+				// The connection switched accounts and no longer speaks for the previous user
+				socket.LeaveRom(fmt.Sprintf("%d", int(previous.ID)))
+				typingTracker.stopAll(socket.GetId())
+				if presenceTracker.disconnect(socket.GetId()) {
+					updatePresence(previous.ID, false)
+				}
+			}
 			socket.Join(fmt.Sprintf("%d", int(me.ID)))
+			socket.AttachValue(userKey, me)
+			socket.AttachValue(sessionKey, sessionID(data.Token))
+			if presenceTracker.connect(socket.GetId(), me.ID) {
+				updatePresence(me.ID, true)
+			}
 
 			socket.Emit("authenticate_success", me)
 		})
+
+		socket.On("typing_start", func(payload any) {
+			me, ok := authenticated(socket)
+			if !ok {
+				socket.Emit("typing_fail", "Unauthorized")
+				return
+			}
+			data := typing{}
+			if err := mapData(payload, &data); err != nil || data.ConversationID == 0 {
+				socket.Emit("typing_fail", "Invalid conversation")
+				return
+			}
+			if typingTracker.refresh(socket.GetId(), data.ConversationID) {
+				return
+			}
+			participants, err := client.GetParticipants(me.ID, data.ConversationID)
+			if err != nil {
+				socket.Emit("typing_fail", "Not a member of the conversation")
+				return
+			}
+			typingTracker.start(socket.GetId(), me.ID, data.ConversationID, participants)
+		})
+
+		socket.On("typing_stop", func(payload any) {
+			if _, ok := authenticated(socket); !ok {
+				socket.Emit("typing_fail", "Unauthorized")
+				return
+			}
+			data := typing{}
+			if err := mapData(payload, &data); err != nil || data.ConversationID == 0 {
+				socket.Emit("typing_fail", "Invalid conversation")
+				return
+			}
+			typingTracker.stop(socket.GetId(), data.ConversationID)
+		})
+
+		socket.On("disconnected", func(_ any) {
+			typingTracker.stopAll(socket.GetId())
+			if me, ok := authenticated(socket); ok && presenceTracker.disconnect(socket.GetId()) {
+				updatePresence(me.ID, false)
+			}
+		})
 	})
 }
+
+// authenticated returns the user attached by a successful authenticate event.
+// The user's token is not kept: it expires or is revoked while the socket lives
+func authenticated(socket SK.Socket) (*client.User, bool) {
+	me, ok := socket.GetValueAttach(userKey).(*client.User)
+	if !ok || me == nil {
+		return nil, false
+	}
+	return me, true
+}
@@ -0,0 +1,46 @@
+package event
+
+import (
+	"fmt"
+	SK "local/libs/socket"
+)
+
+const sessionKey = "session"
+
+// sessionID reads the session a token belongs to; the backend has already verified the token
+func sessionID(token string) string {
+	claims, err := decodeJWT(token)
+	if err != nil {
+		return ""
+	}
+	sid, _ := claims["session_id"].(string)
+	return sid
+}
+
+// DisconnectSessions closes a user's sockets that belong to a revoked session, or
+// all of the user's sockets when sessionID is empty, and returns how many were closed
+func DisconnectSessions(socketServer SK.Server, userID uint, sessionID string) int {
+	selected := socketServer.
+		Broadcast().
+		Of(ChatPath).
+		ToRoom(fmt.Sprintf("%d", userID)).
+		GetSocketSelected()
+
+	// Collect first: disconnecting removes sockets from the room being iterated
+	revoked := make([]SK.Socket, 0, len(selected))
+	for _, socket := range selected {
+		if socket == nil {
+			continue
+		}
+		if sessionID != "" && socket.GetValueAttach(sessionKey) != sessionID {
+			continue
+		}
+		revoked = append(revoked, socket)
+	}
+
+	for _, socket := range revoked {
+		socket.Emit("session_revoked", nil)
+		socket.Disconnect()
+	}
+	return len(revoked)
+}
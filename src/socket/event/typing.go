@@ -0,0 +1,147 @@
+package event
+
+import (
+	"fmt"
+	"local/client"
+	"local/config"
+	SK "local/libs/socket"
+	"sync"
+	"time"
+)
+
+type typing struct {
+	ConversationID uint `json:"conversation_id"`
+}
+
+// typingSession is one connection typing in one conversation
+type typingSession struct {
+	connID         string
+	conversationID uint
+	userID         uint
+	rooms          []string
+	timer          *time.Timer
+}
+
+// typingTracker fans typing indicators out to the other participants and
+// expires them when the client stops refreshing, so a dropped connection
+// never leaves an indicator stuck on screen.
+type typingTracker struct {
+	socketServer SK.Server
+	lock         sync.Mutex
+	sessions     map[string]*typingSession
+}
+
+func newTypingTracker(socketServer SK.Server) *typingTracker {
+	return &typingTracker{
+		socketServer: socketServer,
+		sessions:     make(map[string]*typingSession),
+	}
+}
+
+func typingKey(connID string, conversationID uint) string {
+	return fmt.Sprintf("%s:%d", connID, conversationID)
+}
+
+// refresh extends a running indicator and reports whether one was running
+func (t *typingTracker) refresh(connID string, conversationID uint) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	session, ok := t.sessions[typingKey(connID, conversationID)]
+	if !ok {
+		return false
+	}
+	// Reset reports false when the timer already fired; let the caller start over
+	return session.timer.Reset(config.Config.TypingTimeout)
+}
+
+func (t *typingTracker) start(connID string, userID, conversationID uint, participants []client.Participant) {
+	rooms := []string{}
+	for _, participant := range participants {
+		if participant.UserID == userID {
+			continue
+		}
+		rooms = append(rooms, fmt.Sprintf("%d", participant.UserID))
+	}
+
+	key := typingKey(connID, conversationID)
+	t.lock.Lock()
+	if existing, ok := t.sessions[key]; ok {
+		existing.timer.Stop()
+	}
+	session := &typingSession{
+		connID:         connID,
+		conversationID: conversationID,
+		userID:         userID,
+		rooms:          rooms,
+	}
+	session.timer = time.AfterFunc(config.Config.TypingTimeout, func() {
+		t.expire(session)
+	})
+	t.sessions[key] = session
+	t.lock.Unlock()
+
+	t.emit(connID, rooms, "typing_start", userID, conversationID)
+}
+
+func (t *typingTracker) stop(connID string, conversationID uint) {
+	key := typingKey(connID, conversationID)
+	t.lock.Lock()
+	session, ok := t.sessions[key]
+	if ok {
+		session.timer.Stop()
+		delete(t.sessions, key)
+	}
+	t.lock.Unlock()
+
+	if ok {
+		t.emit(connID, session.rooms, "typing_stop", session.userID, conversationID)
+	}
+}
+
+// expire drops a session whose timer fired, unless it has since been replaced
+func (t *typingTracker) expire(session *typingSession) {
+	key := typingKey(session.connID, session.conversationID)
+	t.lock.Lock()
+	current, ok := t.sessions[key]
+	if ok && current == session {
+		delete(t.sessions, key)
+	}
+	t.lock.Unlock()
+
+	if ok && current == session {
+		t.emit(session.connID, session.rooms, "typing_stop", session.userID, session.conversationID)
+	}
+}
+
+// stopAll clears every indicator owned by a connection, e.g. on disconnect
+func (t *typingTracker) stopAll(connID string) {
+	t.lock.Lock()
+	conversationIDs := []uint{}
+	for _, session := range t.sessions {
+		if session.connID == connID {
+			conversationIDs = append(conversationIDs, session.conversationID)
+		}
+	}
+	t.lock.Unlock()
+
+	for _, conversationID := range conversationIDs {
+		t.stop(connID, conversationID)
+	}
+}
+
+func (t *typingTracker) emit(connID string, rooms []string, event string, userID, conversationID uint) {
+	// An empty room set would broadcast to the whole namespace
+	if len(rooms) == 0 {
+		return
+	}
+	t.socketServer.
+		Broadcast().
+		Of(ChatPath).
+		ToRooms(rooms).
+		WithoutConn(connID).
+		Emit(event, map[string]any{
+			"conversation_id": conversationID,
+			"user_id":         userID,
+		})
+}
@@ -0,0 +1,203 @@
+package event
+
+import (
+	"local/client"
+	"local/config"
+	SK "local/libs/socket"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// emission is one event the tracker sent through the socket server
+type emission struct {
+	event          string
+	rooms          []string
+	withoutConn    string
+	userID         uint
+	conversationID uint
+}
+
+// fakeServer records broadcasts instead of writing to sockets
+type fakeServer struct {
+	SK.Server
+	lock      sync.Mutex
+	emissions []emission
+}
+
+func (s *fakeServer) Broadcast() SK.NamespaceBroadcast {
+	return &fakeBroadcast{server: s}
+}
+
+func (s *fakeServer) emitted() []emission {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var emitted []emission
+	return append(emitted, s.emissions...)
+}
+
+type fakeBroadcast struct {
+	SK.NamespaceBroadcast
+	server      *fakeServer
+	rooms       []string
+	withoutConn string
+}
+
+func (b *fakeBroadcast) Of(path string) SK.NamespaceBroadcast {
+	return b
+}
+
+func (b *fakeBroadcast) ToRooms(rooms []string) SK.NamespaceBroadcast {
+	b.rooms = rooms
+	return b
+}
+
+func (b *fakeBroadcast) WithoutConn(conn string) SK.NamespaceBroadcast {
+	b.withoutConn = conn
+	return b
+}
+
+func (b *fakeBroadcast) Emit(event string, message any) {
+	payload := message.(map[string]any)
+	b.server.lock.Lock()
+	defer b.server.lock.Unlock()
+	b.server.emissions = append(b.server.emissions, emission{
+		event:          event,
+		rooms:          b.rooms,
+		withoutConn:    b.withoutConn,
+		userID:         payload["user_id"].(uint),
+		conversationID: payload["conversation_id"].(uint),
+	})
+}
+
+func TestTypingTracker(t *testing.T) {
+	timeout := 100 * time.Millisecond
+	config.Config.TypingTimeout = timeout
+	participants := []client.Participant{{UserID: 1}, {UserID: 2}, {UserID: 3}}
+	started := emission{event: "typing_start", rooms: []string{"2", "3"}, withoutConn: "conn-1", userID: 1, conversationID: 7}
+	stopped := emission{event: "typing_stop", rooms: []string{"2", "3"}, withoutConn: "conn-1", userID: 1, conversationID: 7}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, tracker *typingTracker)
+		want []emission
+	}{
+		{
+			name: "start tells the other participants",
+			run: func(t *testing.T, tracker *typingTracker) {
+				tracker.start("conn-1", 1, 7, participants)
+			},
+			want: []emission{started},
+		},
+		{
+			name: "nobody to tell when the user is alone",
+			run: func(t *testing.T, tracker *typingTracker) {
+				tracker.start("conn-1", 1, 7, []client.Participant{{UserID: 1}})
+				tracker.stop("conn-1", 7)
+			},
+			want: nil,
+		},
+		{
+			name: "refresh without a running indicator asks for a start",
+			run: func(t *testing.T, tracker *typingTracker) {
+				if tracker.refresh("conn-1", 7) {
+					t.Error("refresh reported a running indicator")
+				}
+			},
+			want: nil,
+		},
+		{
+			name: "refresh keeps the indicator alive past the timeout",
+			run: func(t *testing.T, tracker *typingTracker) {
+				tracker.start("conn-1", 1, 7, participants)
+				for i := 0; i < 3; i++ {
+					time.Sleep(timeout / 2)
+					if !tracker.refresh("conn-1", 7) {
+						t.Fatal("refresh lost the running indicator")
+					}
+				}
+				tracker.stop("conn-1", 7)
+			},
+			want: []emission{started, stopped},
+		},
+		{
+			name: "an indicator that is not refreshed expires once",
+			run: func(t *testing.T, tracker *typingTracker) {
+				tracker.start("conn-1", 1, 7, participants)
+				time.Sleep(3 * timeout)
+				if tracker.refresh("conn-1", 7) {
+					t.Error("refresh reported an expired indicator")
+				}
+				tracker.stop("conn-1", 7)
+			},
+			want: []emission{started, stopped},
+		},
+		{
+			name: "a timer that fired during a restart does not stop the new indicator",
+			run: func(t *testing.T, tracker *typingTracker) {
+				tracker.start("conn-1", 1, 7, participants)
+				tracker.lock.Lock()
+				old := tracker.sessions[typingKey("conn-1", 7)]
+				tracker.lock.Unlock()
+				// The timer fired: refresh sees it inactive and the caller starts over
+				old.timer.Stop()
+				if tracker.refresh("conn-1", 7) {
+					t.Fatal("refresh reported a fired indicator")
+				}
+				tracker.start("conn-1", 1, 7, participants)
+				// The old timer's expire only runs now
+				tracker.expire(old)
+				if !tracker.refresh("conn-1", 7) {
+					t.Error("the replacing indicator was dropped")
+				}
+				tracker.stop("conn-1", 7)
+			},
+			want: []emission{started, started, stopped},
+		},
+		{
+			name: "stop twice emits once",
+			run: func(t *testing.T, tracker *typingTracker) {
+				tracker.start("conn-1", 1, 7, participants)
+				tracker.stop("conn-1", 7)
+				tracker.stop("conn-1", 7)
+			},
+			want: []emission{started, stopped},
+		},
+		{
+			name: "stopAll clears only the connection's indicators",
+			run: func(t *testing.T, tracker *typingTracker) {
+				tracker.start("conn-1", 1, 7, participants)
+				tracker.start("conn-2", 2, 7, participants)
+				tracker.stopAll("conn-1")
+				if !tracker.refresh("conn-2", 7) {
+					t.Error("stopAll stopped another connection")
+				}
+				if tracker.refresh("conn-1", 7) {
+					t.Error("stopAll left an indicator running")
+				}
+			},
+			want: []emission{
+				started,
+				{event: "typing_start", rooms: []string{"1", "3"}, withoutConn: "conn-2", userID: 2, conversationID: 7},
+				stopped,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeServer{}
+			tracker := newTypingTracker(server)
+			tt.run(t, tracker)
+			if got := server.emitted(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("emitted %+v, want %+v", got, tt.want)
+			}
+			tracker.lock.Lock()
+			for _, session := range tracker.sessions {
+				session.timer.Stop()
+			}
+			tracker.lock.Unlock()
+		})
+	}
+}
@@ -3,7 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"local/config"
+	"local/client"
 	"local/event"
 	"local/libs/socket"
 	"log"
@@ -15,6 +15,7 @@ import (
 
 type RouterHandler interface {
 	Broadcast(w http.ResponseWriter, r *http.Request)
+	Disconnect(w http.ResponseWriter, r *http.Request)
 }
 
 type handle struct {
@@ -31,11 +32,22 @@ type responseError struct {
 
 type RequestBroadcast struct {
 	UserIds []int `json:"user_ids" validate:"required"`
-	SessionId string `json:"session_id" validate:"required"`
+	SessionId string `json:"session_id"`
 	Event   string `json:"event" validate:"required"`
 	Payload any    `json:"payload"`
 }
 
+// RequestDisconnect names the revoked session; an empty SessionId means every session of the user
+type RequestDisconnect struct {
+	UserId    uint   `json:"user_id" validate:"required"`
+	SessionId string `json:"session_id"`
+}
+
+type responseDisconnect struct {
+	Ok           bool
+	Disconnected int
+}
+
 func (h *handle) responseJSON(w http.ResponseWriter, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	jsonStr, err := json.Marshal(data)
@@ -47,10 +59,15 @@ func (h *handle) responseJSON(w http.ResponseWriter, data any) {
 	w.Write(jsonStr)
 }
 
+// serviceAudience is the aud the backend puts on the tokens it calls us with
+const serviceAudience = "simple-chat-socket"
+
+// checkJWT verifies a backend service token against the backend's published keys
 func checkJWT(token string) (any, error) {
-	claims, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.Config.SecretKey), nil
-	})
+	claims, err := jwt.Parse(token, client.SigningKey,
+		jwt.WithValidMethods([]string{"RS256", "EdDSA"}),
+		jwt.WithAudience(serviceAudience),
+		jwt.WithExpirationRequired())
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +122,44 @@ func (h *handle) Broadcast(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Disconnect closes the live sockets of sessions the backend has revoked
+func (h *handle) Disconnect(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("Authorization")
+	if len(token) < 7 {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.responseJSON(w, &responseError{Error: "Unauthorized"})
+		return
+	}
+
+	_, err := checkJWT(token[7:])
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.responseJSON(w, &responseError{Error: err.Error()})
+		return
+	}
+	validate := validator.New()
+	var res RequestDisconnect
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.responseJSON(w, &responseError{Error: err.Error()})
+		log.Default().Print("decode error ", err)
+		return
+	}
+	if err := validate.Struct(res); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.responseJSON(w, &responseError{Error: err.Error()})
+		log.Default().Print("validate error ", err)
+		return
+	}
+
+	disconnected := event.DisconnectSessions(h.socketServer, res.UserId, res.SessionId)
+	log.Default().Printf("Disconnected %d sockets of user %d", disconnected, res.UserId)
+	h.responseJSON(w, responseDisconnect{
+		Ok:           true,
+		Disconnected: disconnected,
+	})
+}
+
 func NewHandler(socketServer socket.Server) RouterHandler {
 	return &handle{
 		socketServer: socketServer,
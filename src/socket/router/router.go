@@ -10,6 +10,7 @@ func Register(r *http.ServeMux, socketServer SK.Server) {
 	handler := handler.NewHandler(socketServer)
 
 	r.HandleFunc("/broadcast", handler.Broadcast)
+	r.HandleFunc("/disconnect", handler.Disconnect)
 	
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")